@@ -14,13 +14,18 @@ import (
 )
 
 type CalendarWatchCmd struct {
+	Start CalendarWatchStartCmd `cmd:"" name:"start" default:"withargs" help:"Register a push notification channel for calendar events"`
+	Serve CalendarWatchServeCmd `cmd:"" name:"serve" help:"Receive push notifications and emit event changes"`
+}
+
+type CalendarWatchStartCmd struct {
 	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID to watch"`
 	WebhookURL string `arg:"" name:"webhookUrl" help:"HTTPS URL to receive push notifications"`
 	Token      string `name:"token" help:"Verification token sent in X-Goog-Channel-Token header"`
 	TTL        int64  `name:"ttl" help:"Channel TTL in seconds (Google caps at 7 days regardless)"`
 }
 
-func (c *CalendarWatchCmd) Run(ctx context.Context, flags *RootFlags) error {
+func (c *CalendarWatchStartCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
 
 	account, err := requireAccount(flags)
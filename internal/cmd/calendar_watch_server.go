@@ -0,0 +1,502 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/authclient"
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var errNoCalendarChanges = errors.New("no calendar changes")
+
+const (
+	calendarChangeCreated   = "created"
+	calendarChangeUpdated   = "updated"
+	calendarChangeCancelled = "cancelled"
+
+	// Events whose updated time is within this window of their creation time
+	// are reported as created.
+	calendarCreatedSlack = 2 * time.Second
+)
+
+type calendarWatchState struct {
+	Account      string `json:"account"`
+	CalendarID   string `json:"calendarId"`
+	ChannelID    string `json:"channelId,omitempty"`
+	ResourceID   string `json:"resourceId,omitempty"`
+	Address      string `json:"address,omitempty"`
+	Token        string `json:"token,omitempty"`
+	ExpirationMs int64  `json:"expirationMs,omitempty"`
+	SyncToken    string `json:"syncToken,omitempty"`
+	UpdatedAtMs  int64  `json:"updatedAtMs,omitempty"`
+}
+
+type calendarWatchStore = jsonStateStore[calendarWatchState]
+
+func calendarWatchStatePath(account, calendarID string) (string, error) {
+	dir, err := config.EnsureCalendarWatchDir()
+	if err != nil {
+		return "", err
+	}
+	name := sanitizeAccountForPath(account) + "--" + sanitizeAccountForPath(calendarID)
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// openCalendarWatchStore loads the channel/sync state for one calendar, or
+// starts an empty one.
+func openCalendarWatchStore(account, calendarID string) (*calendarWatchStore, error) {
+	path, err := calendarWatchStatePath(account, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	return openJSONStateStore(path, calendarWatchState{Account: account, CalendarID: calendarID}, func(st *calendarWatchState, ms int64) {
+		st.UpdatedAtMs = ms
+	})
+}
+
+type calendarEventChange struct {
+	Change           string `json:"change"`
+	ID               string `json:"id"`
+	Status           string `json:"status,omitempty"`
+	Summary          string `json:"summary,omitempty"`
+	Location         string `json:"location,omitempty"`
+	Start            string `json:"start,omitempty"`
+	End              string `json:"end,omitempty"`
+	AllDay           bool   `json:"allDay,omitempty"`
+	RecurringEventID string `json:"recurringEventId,omitempty"`
+	HTMLLink         string `json:"htmlLink,omitempty"`
+	Organizer        string `json:"organizer,omitempty"`
+	Created          string `json:"created,omitempty"`
+	Updated          string `json:"updated,omitempty"`
+}
+
+type calendarHookPayload struct {
+	Source        string                `json:"source"`
+	Account       string                `json:"account"`
+	CalendarID    string                `json:"calendarId"`
+	ChannelID     string                `json:"channelId,omitempty"`
+	ResourceState string                `json:"resourceState,omitempty"`
+	Resynced      bool                  `json:"resynced,omitempty"`
+	Changes       []calendarEventChange `json:"changes"`
+}
+
+type calendarWatchServer struct {
+	account     string
+	calendarID  string
+	path        string
+	ttl         time.Duration
+	renewBefore time.Duration
+	store       *calendarWatchStore
+	newService  func(context.Context, string) (*calendar.Service, error)
+	sinks       watchSinks
+	sleep       func(context.Context, time.Duration) error
+	syncMu      sync.Mutex
+	logf        func(string, ...any)
+	warnf       func(string, ...any)
+}
+
+func (s *calendarWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !pathMatches(s.path, r.URL.Path) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	state := s.store.Get()
+	got := r.Header.Get("X-Goog-Channel-Token")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(state.Token)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	channelID := r.Header.Get("X-Goog-Channel-ID")
+	if state.ChannelID != "" && channelID != state.ChannelID {
+		// Stale channel (e.g. left over from a renewal): ack so Google stops retrying.
+		s.logf("watch: ignoring notification for channel %s", channelID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	resourceState := r.Header.Get("X-Goog-Resource-State")
+	if resourceState == "sync" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	payload, err := s.syncChanges(r.Context())
+	if err != nil {
+		if errors.Is(err, errNoCalendarChanges) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		s.warnf("watch: calendar sync failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	payload.ChannelID = channelID
+	payload.ResourceState = resourceState
+	s.deliver(r.Context(), payload)
+}
+
+// syncChanges runs an incremental events.list with the stored syncToken and
+// returns the changed events. The first sync only records a token; a resync
+// after the token expired reports an empty, resynced payload.
+func (s *calendarWatchServer) syncChanges(ctx context.Context) (*calendarHookPayload, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	svc, err := s.newService(ctx, s.account)
+	if err != nil {
+		return nil, err
+	}
+	token := s.store.Get().SyncToken
+	resynced := false
+	events, next, err := listCalendarChanges(ctx, svc, s.calendarID, token)
+	if err != nil && token != "" && isGoneAPIError(err) {
+		s.warnf("watch: sync token expired; resyncing %s", s.calendarID)
+		token = ""
+		resynced = true
+		events, next, err = listCalendarChanges(ctx, svc, s.calendarID, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Update(func(st *calendarWatchState) error {
+		st.SyncToken = next
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if token == "" {
+		// Baseline listing: every event would look new, so report nothing.
+		if resynced {
+			return &calendarHookPayload{Source: "calendar", Account: s.account, CalendarID: s.calendarID, Resynced: true, Changes: []calendarEventChange{}}, nil
+		}
+		return nil, errNoCalendarChanges
+	}
+	if len(events) == 0 {
+		return nil, errNoCalendarChanges
+	}
+	changes := make([]calendarEventChange, 0, len(events))
+	for _, ev := range events {
+		changes = append(changes, calendarChangeFromEvent(ev))
+	}
+	return &calendarHookPayload{Source: "calendar", Account: s.account, CalendarID: s.calendarID, Changes: changes}, nil
+}
+
+func listCalendarChanges(ctx context.Context, svc *calendar.Service, calendarID, syncToken string) ([]*calendar.Event, string, error) {
+	var (
+		events    []*calendar.Event
+		pageToken string
+	)
+	for {
+		call := svc.Events.List(calendarID).ShowDeleted(true).MaxResults(250).Context(ctx)
+		if syncToken != "" {
+			call = call.SyncToken(syncToken)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		if syncToken != "" {
+			events = append(events, resp.Items...)
+		}
+		if resp.NextPageToken == "" {
+			if resp.NextSyncToken == "" {
+				return nil, "", errors.New("events.list returned no sync token")
+			}
+			return events, resp.NextSyncToken, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func calendarChangeFromEvent(ev *calendar.Event) calendarEventChange {
+	change := calendarEventChange{
+		Change:           calendarChangeUpdated,
+		ID:               ev.Id,
+		Status:           ev.Status,
+		Summary:          ev.Summary,
+		Location:         ev.Location,
+		RecurringEventID: ev.RecurringEventId,
+		HTMLLink:         ev.HtmlLink,
+		Created:          ev.Created,
+		Updated:          ev.Updated,
+	}
+	if ev.Status == calendarChangeCancelled {
+		change.Change = calendarChangeCancelled
+	} else if isNewCalendarEvent(ev) {
+		change.Change = calendarChangeCreated
+	}
+	if ev.Start != nil {
+		change.Start = ev.Start.DateTime
+		if change.Start == "" {
+			change.Start = ev.Start.Date
+			change.AllDay = ev.Start.Date != ""
+		}
+	}
+	if ev.End != nil {
+		change.End = ev.End.DateTime
+		if change.End == "" {
+			change.End = ev.End.Date
+		}
+	}
+	if ev.Organizer != nil {
+		change.Organizer = ev.Organizer.Email
+	}
+	return change
+}
+
+func isNewCalendarEvent(ev *calendar.Event) bool {
+	created, err := time.Parse(time.RFC3339, ev.Created)
+	if err != nil {
+		return false
+	}
+	updated, err := time.Parse(time.RFC3339, ev.Updated)
+	if err != nil {
+		return false
+	}
+	return updated.Sub(created) <= calendarCreatedSlack
+}
+
+func isGoneAPIError(err error) bool {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return gerr.Code == http.StatusGone
+	}
+	return false
+}
+
+func (s *calendarWatchServer) deliver(ctx context.Context, payload *calendarHookPayload) {
+	_ = s.sinks.deliver(ctx, s.warnf, payload,
+		"GOG_WATCH_SOURCE=calendar",
+		"GOG_WATCH_ACCOUNT="+payload.Account,
+		"GOG_WATCH_CALENDAR_ID="+payload.CalendarID,
+	)
+}
+
+// ensureChannel registers a new channel when none is stored or the stored one
+// expires within renewBefore, then stops the channel it replaced.
+func (s *calendarWatchServer) ensureChannel(ctx context.Context) error {
+	state := s.store.Get()
+	if state.Address == "" {
+		return nil
+	}
+	if state.ChannelID != "" && time.Until(time.UnixMilli(state.ExpirationMs)) > s.renewBefore {
+		return nil
+	}
+	svc, err := s.newService(ctx, s.account)
+	if err != nil {
+		return err
+	}
+	channel := &calendar.Channel{
+		Id:      uuid.New().String(),
+		Type:    "web_hook",
+		Address: state.Address,
+		Token:   state.Token,
+	}
+	if s.ttl > 0 {
+		channel.Params = map[string]string{"ttl": strconv.FormatInt(int64(s.ttl/time.Second), 10)}
+	}
+	created, err := svc.Events.Watch(s.calendarID, channel).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	if err := s.store.Update(func(st *calendarWatchState) error {
+		st.ChannelID = created.Id
+		st.ResourceID = created.ResourceId
+		st.ExpirationMs = created.Expiration
+		return nil
+	}); err != nil {
+		return err
+	}
+	s.logf("watch: registered channel %s (expires %s)", created.Id, formatUnixMillis(created.Expiration))
+
+	if state.ChannelID != "" && state.ResourceID != "" {
+		old := &calendar.Channel{Id: state.ChannelID, ResourceId: state.ResourceID}
+		if err := svc.Channels.Stop(old).Context(ctx).Do(); err != nil && !isNotFoundAPIError(err) {
+			s.warnf("watch: stop old channel %s: %v", state.ChannelID, err)
+		}
+	}
+	return nil
+}
+
+// runRenewals keeps the channel alive until ctx is done.
+func (s *calendarWatchServer) runRenewals(ctx context.Context) {
+	runWatchChannelRenewals(ctx, s.sleep, s.renewBefore, func() int64 {
+		return s.store.Get().ExpirationMs
+	}, s.ensureChannel, s.warnf)
+}
+
+type CalendarWatchServeCmd struct {
+	CalendarID  string         `arg:"" name:"calendarId" optional:"" help:"Calendar ID to watch (default: primary)"`
+	Address     string         `name:"address" help:"Public HTTPS URL Google should call; registers and renews the channel automatically"`
+	Bind        string         `name:"bind" help:"Bind address" default:"127.0.0.1"`
+	Port        int            `name:"port" help:"Listen port" default:"8789"`
+	Path        string         `name:"path" help:"Notification handler path" default:"/calendar-push"`
+	Token       string         `name:"token" help:"Channel token expected in X-Goog-Channel-Token (generated when --address is set)"`
+	TTL         string         `name:"ttl" help:"Requested channel TTL (seconds or duration; Google caps it)"`
+	RenewBefore string         `name:"renew-before" help:"Renew the channel this long before it expires (seconds or duration)" default:"1h"`
+	HookURL     string         `name:"hook-url" help:"Webhook URL to forward event changes"`
+	HookToken   string         `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret  string         `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature header)"`
+	Sinks       WatchSinkFlags `embed:""`
+}
+
+func (c *CalendarWatchServeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return usage("--path must start with '/'")
+	}
+	if c.Port <= 0 {
+		return usage("--port must be > 0")
+	}
+	renewBefore, err := parseDurationSeconds(c.RenewBefore)
+	if err != nil {
+		return err
+	}
+	if renewBefore <= 0 {
+		return usage("--renew-before must be > 0")
+	}
+	var ttl time.Duration
+	if strings.TrimSpace(c.TTL) != "" {
+		ttl, err = parseDurationSeconds(c.TTL)
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			return usage("--ttl must be > 0")
+		}
+	}
+	if c.HookSecret != "" && strings.TrimSpace(c.HookURL) == "" {
+		return usage("--hook-secret requires --hook-url")
+	}
+	sinks, err := c.Sinks.build()
+	if err != nil {
+		return err
+	}
+	if hookURL := strings.TrimSpace(c.HookURL); hookURL != "" {
+		sinks = append(sinks, newWatchHookSink(hookURL, c.HookToken, c.HookSecret))
+	}
+	if len(sinks) == 0 {
+		sinks = watchSinks{&watchWriterSink{w: os.Stdout}}
+	}
+
+	selectedClient := strings.TrimSpace(flags.Client)
+	serviceFactory := func(ctx context.Context, account string) (*calendar.Service, error) {
+		if selectedClient != "" {
+			ctx = authclient.WithClient(ctx, selectedClient)
+		}
+		return newCalendarService(ctx, account)
+	}
+	svc, err := serviceFactory(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err := resolveCalendarSelector(ctx, svc, c.CalendarID, true)
+	if err != nil {
+		return err
+	}
+
+	store, err := openCalendarWatchStore(account, calendarID)
+	if err != nil {
+		return err
+	}
+	state := store.Get()
+	address := strings.TrimSpace(c.Address)
+	if address == "" {
+		address = state.Address
+	}
+	token := c.Token
+	if token == "" {
+		token = state.Token
+	}
+	if token == "" && address != "" {
+		token, err = newWatchChannelToken()
+		if err != nil {
+			return err
+		}
+	}
+	if token == "" {
+		return usage("--token required (the token passed to 'calendar watch --token'), or set --address to register a channel")
+	}
+	if err := store.Update(func(st *calendarWatchState) error {
+		if address != st.Address || token != st.Token {
+			// Settings changed: force a new channel.
+			st.ExpirationMs = 0
+		}
+		st.Account = account
+		st.CalendarID = calendarID
+		st.Address = address
+		st.Token = token
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	server := &calendarWatchServer{
+		account:     account,
+		calendarID:  calendarID,
+		path:        c.Path,
+		ttl:         ttl,
+		renewBefore: renewBefore,
+		store:       store,
+		newService:  serviceFactory,
+		sinks:       sinks,
+		sleep:       watchPollSleep,
+		logf:        u.Err().Printf,
+		warnf:       u.Err().Printf,
+	}
+
+	if store.Get().SyncToken == "" {
+		if _, err := server.syncChanges(ctx); err != nil && !errors.Is(err, errNoCalendarChanges) {
+			return fmt.Errorf("initial sync: %w", err)
+		}
+	}
+	if err := server.ensureChannel(ctx); err != nil {
+		return err
+	}
+	if address != "" {
+		renewCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go server.runRenewals(renewCtx)
+	} else {
+		u.Err().Printf("watch: no --address; channel renewal is disabled")
+	}
+
+	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
+	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return listenAndServe(httpServer)
+}
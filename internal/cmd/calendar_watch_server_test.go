@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type fakeCalendarWatchAPI struct {
+	mu       sync.Mutex
+	watches  []calendar.Channel
+	stops    []calendar.Channel
+	expireAt int64
+}
+
+func (f *fakeCalendarWatchAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/events/watch"):
+		var ch calendar.Channel
+		_ = json.NewDecoder(r.Body).Decode(&ch)
+		f.watches = append(f.watches, ch)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": ch.Id, "resourceId": "res-" + ch.Id, "expiration": strconv.FormatInt(f.expireAt, 10)})
+	case strings.HasSuffix(r.URL.Path, "/channels/stop"):
+		var ch calendar.Channel
+		_ = json.NewDecoder(r.Body).Decode(&ch)
+		f.stops = append(f.stops, ch)
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasSuffix(r.URL.Path, "/events"):
+		if r.URL.Query().Get("showDeleted") != "true" {
+			http.Error(w, "showDeleted missing", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("syncToken") {
+		case "":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"items":         []map[string]any{{"id": "old", "status": "confirmed"}},
+				"nextSyncToken": "s1",
+			})
+		case "s1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"items": []map[string]any{
+					{"id": "e1", "status": "confirmed", "summary": "New", "created": "2026-01-01T10:00:00Z", "updated": "2026-01-01T10:00:01Z", "start": map[string]any{"dateTime": "2026-01-02T09:00:00Z"}},
+					{"id": "e2", "status": "confirmed", "summary": "Moved", "created": "2025-12-01T10:00:00Z", "updated": "2026-01-01T10:00:00Z", "start": map[string]any{"date": "2026-01-03"}},
+					{"id": "e3", "status": "cancelled"},
+				},
+				"nextSyncToken": "s2",
+			})
+		case "s2":
+			_ = json.NewEncoder(w).Encode(map[string]any{"nextSyncToken": "s2"})
+		default:
+			w.WriteHeader(http.StatusGone)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 410, "message": "Sync token is no longer valid"}})
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newCalendarWatchTestServer(t *testing.T, api http.Handler, out io.Writer) *calendarWatchServer {
+	t.Helper()
+	setWatchTestConfigHome(t)
+	svc, closeSrv := newCalendarServiceForTest(t, api)
+	t.Cleanup(closeSrv)
+	store, err := openCalendarWatchStore("a@b.com", "primary")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := store.Update(func(st *calendarWatchState) error {
+		st.Token = "chan-tok"
+		st.ChannelID = "ch1"
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return &calendarWatchServer{
+		account:     "a@b.com",
+		calendarID:  "primary",
+		path:        "/calendar-push",
+		renewBefore: time.Hour,
+		store:       store,
+		newService:  func(context.Context, string) (*calendar.Service, error) { return svc, nil },
+		sinks:       watchSinks{&watchWriterSink{w: out}},
+		logf:        func(string, ...any) {},
+		warnf:       func(string, ...any) {},
+	}
+}
+
+func calendarPing(server http.Handler, token, channelID, state string) int {
+	req := httptest.NewRequest(http.MethodPost, "/calendar-push", nil)
+	req.Header.Set("X-Goog-Channel-Token", token)
+	req.Header.Set("X-Goog-Channel-ID", channelID)
+	req.Header.Set("X-Goog-Resource-State", state)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestCalendarWatchServer_EmitsEventDiffs(t *testing.T) {
+	var out bytes.Buffer
+	server := newCalendarWatchTestServer(t, &fakeCalendarWatchAPI{}, &out)
+
+	if code := calendarPing(server, "wrong", "ch1", "exists"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad token, got %d", code)
+	}
+	if code := calendarPing(server, "chan-tok", "ch1", "sync"); code != http.StatusOK || out.Len() != 0 {
+		t.Fatalf("expected quiet sync ping, got %d %q", code, out.String())
+	}
+
+	// No sync token yet: the first ping only records a baseline.
+	if code := calendarPing(server, "chan-tok", "ch1", "exists"); code != http.StatusOK || out.Len() != 0 {
+		t.Fatalf("expected baseline sync without output, got %d %q", code, out.String())
+	}
+	if server.store.Get().SyncToken != "s1" {
+		t.Fatalf("expected sync token s1, got %q", server.store.Get().SyncToken)
+	}
+
+	if code := calendarPing(server, "chan-tok", "ch1", "exists"); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	var payload calendarHookPayload
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v (%s)", err, out.String())
+	}
+	if payload.Source != "calendar" || payload.ChannelID != "ch1" || len(payload.Changes) != 3 {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+	got := []string{payload.Changes[0].Change, payload.Changes[1].Change, payload.Changes[2].Change}
+	if strings.Join(got, ",") != "created,updated,cancelled" {
+		t.Fatalf("unexpected change kinds: %v", got)
+	}
+	if !payload.Changes[1].AllDay || payload.Changes[1].Start != "2026-01-03" {
+		t.Fatalf("unexpected all-day change: %#v", payload.Changes[1])
+	}
+
+	out.Reset()
+	if code := calendarPing(server, "chan-tok", "ch1", "exists"); code != http.StatusOK || out.Len() != 0 {
+		t.Fatalf("expected no output without changes, got %d %q", code, out.String())
+	}
+	if code := calendarPing(server, "chan-tok", "other", "exists"); code != http.StatusOK || out.Len() != 0 {
+		t.Fatalf("expected stale channel to be ignored, got %d %q", code, out.String())
+	}
+}
+
+func TestCalendarWatchServer_ResyncsOnExpiredToken(t *testing.T) {
+	var out bytes.Buffer
+	server := newCalendarWatchTestServer(t, &fakeCalendarWatchAPI{}, &out)
+	_ = server.store.Update(func(st *calendarWatchState) error {
+		st.SyncToken = "expired"
+		return nil
+	})
+
+	payload, err := server.syncChanges(context.Background())
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !payload.Resynced || len(payload.Changes) != 0 || server.store.Get().SyncToken != "s1" {
+		t.Fatalf("unexpected resync result: %#v token=%q", payload, server.store.Get().SyncToken)
+	}
+}
+
+func TestCalendarWatchServeCmd_RegistersAndRenewsChannel(t *testing.T) {
+	origListen := listenAndServe
+	t.Cleanup(func() { listenAndServe = origListen })
+	setWatchTestConfigHome(t)
+
+	api := &fakeCalendarWatchAPI{expireAt: time.Now().Add(7 * 24 * time.Hour).UnixMilli()}
+	svc, closeSrv := newCalendarServiceForTest(t, api)
+	defer closeSrv()
+	stubCalendarServiceForTest(t, svc)
+
+	var got *calendarWatchServer
+	listenAndServe = func(srv *http.Server) error {
+		got, _ = srv.Handler.(*calendarWatchServer)
+		return nil
+	}
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := ui.WithUI(context.Background(), u)
+	flags := &RootFlags{Account: "a@b.com"}
+
+	if err := runKong(t, &CalendarWatchServeCmd{}, []string{"--bind", "0.0.0.0"}, ctx, flags); err == nil {
+		t.Fatalf("expected error without --token or --address")
+	}
+
+	args := []string{"primary", "--address", "https://example.com/calendar-push", "--ttl", "1h"}
+	if err := runKong(t, &CalendarWatchServeCmd{}, args, ctx, flags); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if got == nil {
+		t.Fatalf("expected server")
+	}
+	state := got.store.Get()
+	if state.SyncToken != "s1" || state.ChannelID == "" || state.Token == "" || len(api.watches) != 1 {
+		t.Fatalf("unexpected state after start: %#v (watches=%d)", state, len(api.watches))
+	}
+	if api.watches[0].Token != state.Token || api.watches[0].Params["ttl"] != "3600" {
+		t.Fatalf("unexpected channel request: %#v", api.watches[0])
+	}
+
+	// A second start reuses the live channel.
+	if err := runKong(t, &CalendarWatchServeCmd{}, args, ctx, flags); err != nil {
+		t.Fatalf("serve again: %v", err)
+	}
+	if len(api.watches) != 1 {
+		t.Fatalf("expected channel reuse, got %d watches", len(api.watches))
+	}
+
+	// Near expiry: renew and stop the old channel.
+	oldID := state.ChannelID
+	_ = got.store.Update(func(st *calendarWatchState) error {
+		st.ExpirationMs = time.Now().Add(time.Minute).UnixMilli()
+		return nil
+	})
+	if err := got.ensureChannel(context.Background()); err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if len(api.watches) != 2 || len(api.stops) != 1 || api.stops[0].Id != oldID {
+		t.Fatalf("unexpected renewal: watches=%d stops=%#v", len(api.watches), api.stops)
+	}
+	if got.store.Get().ChannelID == oldID {
+		t.Fatalf("expected new channel id")
+	}
+}
@@ -34,6 +34,8 @@ type DocsCmd struct {
 	Sed         DocsSedCmd         `cmd:"" name:"sed" help:"Regex find/replace (sed-style: s/pattern/replacement/g)"`
 	Clear       DocsClearCmd       `cmd:"" name:"clear" help:"Clear all content from a Google Doc"`
 	Structure   DocsStructureCmd   `cmd:"" name:"structure" aliases:"struct" help:"Show document structure with numbered paragraphs"`
+	Pull        DocsPullCmd        `cmd:"" name:"pull" help:"Convert a Google Doc to Markdown for editing"`
+	Push        DocsPushCmd        `cmd:"" name:"push" help:"Apply a Markdown file to a Google Doc with minimal edits (keeps comments)"`
 }
 
 type DocsExportCmd struct {
@@ -0,0 +1,1076 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// The types in this file are the Markdown model shared by `docs pull` and
+// `docs push`. Both directions go through the same renderer, so a pulled file
+// pushes back as a no-op and only the blocks a user touched produce requests.
+
+type docsMDStyle struct {
+	Bold   bool
+	Italic bool
+	Strike bool
+	Code   bool
+	Link   string
+}
+
+// docsMDRun is a run of styled text, an inline image, or a footnote reference.
+type docsMDRun struct {
+	Text     string
+	Style    docsMDStyle
+	Image    *docsMDImage
+	Footnote *docsMDFootnote
+}
+
+type docsMDImage struct {
+	Alt      string
+	URL      string
+	WidthPt  float64
+	HeightPt float64
+}
+
+type docsMDFootnote struct {
+	ID   string // set for footnotes read from a document
+	Runs []docsMDRun
+}
+
+type docsMDKind int
+
+const (
+	docsMDParagraph docsMDKind = iota
+	docsMDHeading
+	docsMDBullet
+	docsMDOrdered
+	docsMDQuote
+	docsMDCode
+	docsMDRule
+	docsMDTable
+	docsMDTOC
+)
+
+// docsMDBlock is one top-level Markdown block. Start and End are the body
+// indices of the block when it was read from a document.
+type docsMDBlock struct {
+	Kind  docsMDKind
+	Level int // heading level, or list nesting level (0-based)
+	Runs  []docsMDRun
+	Code  []string
+	Rows  [][][]docsMDRun
+	Start int64
+	End   int64
+
+	para *docs.Paragraph // source paragraph, for in-place edits
+}
+
+const (
+	docsMDTOCMarker   = "<!-- table of contents -->"
+	docsMDImagePrefix = "gdoc-image:"
+)
+
+func (b docsMDBlock) isList() bool {
+	return b.Kind == docsMDBullet || b.Kind == docsMDOrdered
+}
+
+// isParagraphLike reports whether the block maps to a single Docs paragraph.
+func (b docsMDBlock) isParagraphLike() bool {
+	switch b.Kind {
+	case docsMDParagraph, docsMDHeading, docsMDBullet, docsMDOrdered, docsMDQuote:
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeDocsMDBlocks applies the rules both sides agree on: paragraphs made
+// only of code become code blocks, adjacent code blocks merge, and list levels
+// never skip a level.
+func normalizeDocsMDBlocks(blocks []docsMDBlock) []docsMDBlock {
+	out := make([]docsMDBlock, 0, len(blocks))
+	for _, b := range blocks {
+		if b.Kind == docsMDParagraph {
+			if lines, ok := docsMDCodeLines(b.Runs); ok {
+				b = docsMDBlock{Kind: docsMDCode, Code: lines, Start: b.Start, End: b.End}
+			}
+		}
+		if b.isList() {
+			maxLevel := 0
+			if n := len(out); n > 0 && out[n-1].isList() {
+				maxLevel = out[n-1].Level + 1
+			}
+			b.Level = min(max(b.Level, 0), maxLevel)
+		}
+		if n := len(out); n > 0 && b.Kind == docsMDCode && out[n-1].Kind == docsMDCode {
+			out[n-1].Code = append(out[n-1].Code, b.Code...)
+			out[n-1].End = b.End
+			continue
+		}
+		out = append(out, b)
+	}
+	kept := out[:0]
+	for _, b := range out {
+		if b.Kind == docsMDCode {
+			b.Code = trimDocsMDBlankLines(b.Code)
+			if len(b.Code) == 0 {
+				continue
+			}
+		}
+		kept = append(kept, b)
+	}
+	return kept
+}
+
+func docsMDCodeLines(runs []docsMDRun) ([]string, bool) {
+	if len(runs) == 0 {
+		return nil, false
+	}
+	var text strings.Builder
+	for _, r := range runs {
+		if r.Image != nil || r.Footnote != nil || !r.Style.Code || r.Style.Link != "" {
+			return nil, false
+		}
+		text.WriteString(r.Text)
+	}
+	return strings.Split(text.String(), "\v"), true
+}
+
+func trimDocsMDBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// renderDocsMarkdown renders blocks as Markdown, numbering footnotes in
+// reference order and appending their definitions.
+func renderDocsMarkdown(blocks []docsMDBlock) string {
+	var footnotes []string
+	ref := func(f *docsMDFootnote) string {
+		footnotes = append(footnotes, renderDocsMDInline(f.Runs, nil, false))
+		return fmt.Sprintf("[^%d]", len(footnotes))
+	}
+
+	var b strings.Builder
+	var widths []int
+	listKind := docsMDKind(-1)
+	for i, block := range blocks {
+		// A top-level item of the other kind starts a new list.
+		newList := block.isList() && block.Level == 0 && listKind >= 0 && block.Kind != listKind
+		switch {
+		case !block.isList():
+			listKind = -1
+		case block.Level == 0:
+			listKind = block.Kind
+		}
+		if i > 0 {
+			b.WriteString("\n")
+			if !block.isList() || !blocks[i-1].isList() || newList {
+				b.WriteString("\n")
+			}
+		}
+		if !block.isList() || newList {
+			widths = widths[:0]
+		}
+		b.WriteString(renderDocsMDBlock(block, ref, &widths))
+	}
+	if len(blocks) > 0 {
+		b.WriteString("\n")
+	}
+	for i, text := range footnotes {
+		if i == 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[^%d]: %s\n", i+1, text)
+	}
+	return b.String()
+}
+
+// renderDocsMDBlock renders one block. widths tracks the content column of the
+// open list items so nested items line up under their parent's text.
+func renderDocsMDBlock(block docsMDBlock, ref func(*docsMDFootnote) string, widths *[]int) string {
+	switch block.Kind {
+	case docsMDHeading:
+		return strings.Repeat("#", min(max(block.Level, 1), 6)) + " " + renderDocsMDInline(block.Runs, ref, false)
+	case docsMDQuote:
+		return "> " + escapeDocsMDLineStart(renderDocsMDInline(block.Runs, ref, false))
+	case docsMDBullet, docsMDOrdered:
+		level := min(block.Level, len(*widths))
+		indent := 0
+		if level > 0 {
+			indent = (*widths)[level-1]
+		}
+		marker := "- "
+		if block.Kind == docsMDOrdered {
+			marker = "1. "
+		}
+		*widths = append((*widths)[:level], indent+len(marker))
+		return strings.Repeat(" ", indent) + marker + escapeDocsMDLineStart(renderDocsMDInline(block.Runs, ref, false))
+	case docsMDCode:
+		fence := "```"
+		for _, line := range block.Code {
+			for strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence += "`"
+			}
+		}
+		return fence + "\n" + strings.Join(block.Code, "\n") + "\n" + fence
+	case docsMDRule:
+		return "---"
+	case docsMDTOC:
+		return docsMDTOCMarker
+	case docsMDTable:
+		return renderDocsMDTable(block.Rows, ref)
+	default:
+		return escapeDocsMDLineStart(renderDocsMDInline(block.Runs, ref, false))
+	}
+}
+
+func renderDocsMDTable(rows [][][]docsMDRun, ref func(*docsMDFootnote) string) string {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = renderDocsMDInline(row[j], ref, true)
+			}
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// docsMDBlockKey identifies a block for diffing. Footnote content is inlined so
+// editing a footnote changes the key of the paragraph that references it.
+func docsMDBlockKey(block docsMDBlock) string {
+	ref := func(f *docsMDFootnote) string {
+		return "[^{" + renderDocsMDInline(f.Runs, nil, false) + "}]"
+	}
+	switch block.Kind {
+	case docsMDCode:
+		return "code:" + strings.Join(block.Code, "\n")
+	case docsMDRule:
+		return "rule"
+	case docsMDTOC:
+		return "toc"
+	case docsMDTable:
+		return "table:" + renderDocsMDTable(block.Rows, ref)
+	default:
+		return fmt.Sprintf("%d:%d:%s", block.Kind, block.Level, renderDocsMDInline(block.Runs, ref, false))
+	}
+}
+
+type docsMDMarker struct {
+	kind  byte // 'l'ink, 'b'old, 'i'talic, 's'trike
+	url   string
+	delim string
+}
+
+func (m docsMDMarker) open() string {
+	switch m.kind {
+	case 'l':
+		return "["
+	case 'b':
+		return "**"
+	case 's':
+		return "~~"
+	default:
+		return m.delim
+	}
+}
+
+func (m docsMDMarker) close() string {
+	if m.kind == 'l' {
+		url := m.url
+		if strings.ContainsAny(url, " ()<>") {
+			url = "<" + url + ">"
+		}
+		return "](" + url + ")"
+	}
+	return m.open()
+}
+
+func docsMDWantedMarkers(r docsMDRun) []docsMDMarker {
+	if r.Image != nil || r.Footnote != nil {
+		return nil
+	}
+	var want []docsMDMarker
+	if r.Style.Link != "" {
+		want = append(want, docsMDMarker{kind: 'l', url: r.Style.Link})
+	}
+	if r.Style.Bold {
+		want = append(want, docsMDMarker{kind: 'b'})
+	}
+	if r.Style.Italic {
+		want = append(want, docsMDMarker{kind: 'i'})
+	}
+	if r.Style.Strike {
+		want = append(want, docsMDMarker{kind: 's'})
+	}
+	return want
+}
+
+func docsMDHasMarker(list []docsMDMarker, m docsMDMarker) bool {
+	for _, x := range list {
+		if x.kind == m.kind && x.url == m.url {
+			return true
+		}
+	}
+	return false
+}
+
+// renderDocsMDInline renders runs as inline Markdown. Emphasis markers are kept
+// open across runs that share them, and whitespace is moved outside markers so
+// the output parses back to the same runs. ref renders footnote references;
+// nil drops them.
+func renderDocsMDInline(runs []docsMDRun, ref func(*docsMDFootnote) string, table bool) string {
+	runs = mergeDocsMDRuns(runs)
+	var b bytes.Buffer
+	var stack []docsMDMarker
+	closeTo := func(n int) {
+		if len(stack) <= n {
+			return
+		}
+		trimmed := len(bytes.TrimRightFunc(b.Bytes(), unicode.IsSpace))
+		tail := string(b.Bytes()[trimmed:])
+		b.Truncate(trimmed)
+		for len(stack) > n {
+			b.WriteString(stack[len(stack)-1].close())
+			stack = stack[:len(stack)-1]
+		}
+		b.WriteString(tail)
+	}
+	for i, r := range runs {
+		want := docsMDWantedMarkers(r)
+		keep := 0
+		for keep < len(stack) && docsMDHasMarker(want, stack[keep]) {
+			keep++
+		}
+		closeTo(keep)
+
+		text := ""
+		if r.Image == nil && r.Footnote == nil && !r.Style.Code {
+			text = strings.TrimLeftFunc(r.Text, unicode.IsSpace)
+			b.WriteString(escapeDocsMDText(r.Text[:len(r.Text)-len(text)], table))
+			if text == "" {
+				// Whitespace alone never opens a marker.
+				continue
+			}
+		}
+		for _, m := range want {
+			if docsMDHasMarker(stack, m) {
+				continue
+			}
+			if m.kind == 'i' {
+				m.delim = "_"
+				if docsMDAlnumBefore(b.String()) || docsMDAlnumAfterItalic(runs, i) {
+					m.delim = "*"
+				}
+			}
+			b.WriteString(m.open())
+			stack = append(stack, m)
+		}
+		switch {
+		case r.Image != nil:
+			url := r.Image.URL
+			if strings.ContainsAny(url, " ()<>") {
+				url = "<" + url + ">"
+			}
+			b.WriteString("![" + escapeDocsMDText(r.Image.Alt, table) + "](" + url + ")")
+		case r.Footnote != nil:
+			if ref != nil {
+				b.WriteString(ref(r.Footnote))
+			}
+		case r.Style.Code:
+			b.WriteString(renderDocsMDCodeSpan(r.Text, table))
+		default:
+			b.WriteString(escapeDocsMDText(text, table))
+		}
+	}
+	closeTo(0)
+	return b.String()
+}
+
+func docsMDAlnumBefore(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func docsMDAlnumAfterItalic(runs []docsMDRun, i int) bool {
+	for i < len(runs) && runs[i].Style.Italic && runs[i].Image == nil && runs[i].Footnote == nil {
+		i++
+	}
+	if i >= len(runs) || runs[i].Image != nil || runs[i].Footnote != nil {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(runs[i].Text)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func mergeDocsMDRuns(runs []docsMDRun) []docsMDRun {
+	out := make([]docsMDRun, 0, len(runs))
+	for _, r := range runs {
+		if r.Image == nil && r.Footnote == nil {
+			if r.Text == "" {
+				continue
+			}
+			if n := len(out); n > 0 && out[n-1].Image == nil && out[n-1].Footnote == nil && out[n-1].Style == r.Style {
+				out[n-1].Text += r.Text
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func renderDocsMDCodeSpan(text string, table bool) string {
+	longest, current := 0, 0
+	for _, c := range text {
+		if c == '`' {
+			current++
+			longest = max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+	delim := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") ||
+		(len(text) > 1 && strings.HasPrefix(text, " ") && strings.HasSuffix(text, " ") && strings.TrimSpace(text) != "") {
+		text = " " + text + " "
+	}
+	text = strings.ReplaceAll(text, "\v", " ")
+	if table {
+		text = strings.ReplaceAll(text, "|", `\|`)
+	}
+	return delim + text + delim
+}
+
+func isDocsMDWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// escapeDocsMDText escapes the characters the inline parser would otherwise
+// treat as markup, leaving ordinary punctuation alone to keep diffs readable.
+func escapeDocsMDText(s string, table bool) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, c := range rs {
+		var prev, next rune
+		if i > 0 {
+			prev = rs[i-1]
+		}
+		if i+1 < len(rs) {
+			next = rs[i+1]
+		}
+		switch c {
+		case '\\', '*', '`', '[', ']':
+			b.WriteByte('\\')
+		case '_':
+			if !isDocsMDWordRune(prev) || !isDocsMDWordRune(next) {
+				b.WriteByte('\\')
+			}
+		case '~':
+			if prev == '~' || next == '~' {
+				b.WriteByte('\\')
+			}
+		case '<':
+			rest := strings.ToLower(string(rs[i:]))
+			if strings.HasPrefix(rest, "<br") || strings.HasPrefix(rest, "<!--") {
+				b.WriteByte('\\')
+			}
+		case '|':
+			if table {
+				b.WriteByte('\\')
+			}
+		case '\v':
+			b.WriteString("<br>")
+			continue
+		case '\n':
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+var docsMDOrderedStartRe = regexp.MustCompile(`^(\d{1,9})([.)])( |$)`)
+
+// escapeDocsMDLineStart escapes text that would start a different block when
+// it begins a line.
+func escapeDocsMDLineStart(s string) string {
+	switch {
+	case s == "":
+		return s
+	case isHorizontalRule(s):
+		return `\` + s
+	case strings.HasPrefix(s, "#"), strings.HasPrefix(s, ">"), strings.HasPrefix(s, "|"),
+		strings.HasPrefix(s, "- "), strings.HasPrefix(s, "+ "), s == "-", s == "+":
+		return `\` + s
+	}
+	if m := docsMDOrderedStartRe.FindStringSubmatch(s); m != nil {
+		return m[1] + `\` + s[len(m[1]):]
+	}
+	return strings.TrimLeft(s, " ")
+}
+
+var (
+	docsMDListRe     = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])(?: +(.*))?$`)
+	docsMDFootnoteRe = regexp.MustCompile(`^\[\^([^\]\s]+)\]:\s?(.*)$`)
+	docsMDHeadingRe  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+)
+
+// docsMDRawBlock is a block whose inline content is parsed once footnote
+// definitions are known.
+type docsMDRawBlock struct {
+	block docsMDBlock
+	raw   string
+	rows  [][]string
+}
+
+// parseDocsMarkdown parses Markdown into normalized blocks.
+func parseDocsMarkdown(text string) []docsMDBlock {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	defs := map[string]string{}
+	body := make([]string, 0, len(lines))
+	fence := ""
+	for _, line := range lines {
+		if f := docsMDFence(line); f != "" {
+			switch {
+			case fence == "":
+				fence = f
+			case f[0] == fence[0] && len(f) >= len(fence) && strings.TrimSpace(line) == f:
+				fence = ""
+			}
+		} else if fence == "" {
+			if m := docsMDFootnoteRe.FindStringSubmatch(line); m != nil {
+				defs[m[1]] = m[2]
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+
+	var raws []docsMDRawBlock
+	var para []string
+	var listCols []int
+	lastItem := -1
+	flush := func() {
+		if len(para) > 0 {
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: docsMDParagraph}, raw: joinDocsMDLines(para)})
+			para = nil
+		}
+	}
+	endList := func() {
+		listCols = listCols[:0]
+		lastItem = -1
+	}
+
+	for i := 0; i < len(body); i++ {
+		line := expandDocsMDIndent(body[i])
+		trimmed := strings.TrimSpace(line)
+
+		if open := docsMDFence(line); open != "" {
+			flush()
+			endList()
+			var code []string
+			for i++; i < len(body); i++ {
+				if f := docsMDFence(body[i]); f != "" && f[0] == open[0] && len(f) >= len(open) && strings.TrimSpace(body[i]) == f {
+					break
+				}
+				code = append(code, body[i])
+			}
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: docsMDCode, Code: code}})
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+			lastItem = -1
+			continue
+		case strings.EqualFold(trimmed, docsMDTOCMarker):
+			flush()
+			endList()
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: docsMDTOC}})
+			continue
+		case isHorizontalRule(trimmed):
+			flush()
+			endList()
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: docsMDRule}})
+			continue
+		}
+		if m := docsMDHeadingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			endList()
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: docsMDHeading, Level: len(m[1])}, raw: m[2]})
+			continue
+		}
+		if strings.HasPrefix(trimmed, "|") && i+1 < len(body) && isTableSeparator(body[i+1]) {
+			flush()
+			endList()
+			rows := [][]string{splitDocsMDTableRow(trimmed)}
+			for i += 2; i < len(body) && strings.HasPrefix(strings.TrimSpace(body[i]), "|"); i++ {
+				rows = append(rows, splitDocsMDTableRow(strings.TrimSpace(body[i])))
+			}
+			i--
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: docsMDTable}, rows: rows})
+			continue
+		}
+		if m := docsMDListRe.FindStringSubmatch(line); m != nil {
+			flush()
+			indent := len(m[1])
+			for len(listCols) > 0 && indent < listCols[len(listCols)-1] {
+				listCols = listCols[:len(listCols)-1]
+			}
+			level := len(listCols)
+			content := m[3]
+			listCols = append(listCols, indent+len(m[2])+1+len(content)-len(strings.TrimLeft(content, " ")))
+			kind := docsMDOrdered
+			if strings.ContainsAny(m[2], "-*+") {
+				kind = docsMDBullet
+			}
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: kind, Level: level}, raw: strings.TrimSpace(content)})
+			lastItem = len(raws) - 1
+			continue
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			flush()
+			endList()
+			var quote []string
+			for ; i < len(body) && strings.HasPrefix(strings.TrimSpace(body[i]), ">"); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(body[i]), ">"), " "))
+			}
+			i--
+			raws = append(raws, docsMDRawBlock{block: docsMDBlock{Kind: docsMDQuote}, raw: joinDocsMDLines(quote)})
+			continue
+		}
+		if lastItem >= 0 && len(para) == 0 {
+			// Lazy continuation of the previous list item.
+			item := &raws[lastItem]
+			item.raw = joinDocsMDLines([]string{item.raw, line})
+			continue
+		}
+		if len(para) == 0 {
+			endList()
+		}
+		para = append(para, line)
+	}
+	flush()
+
+	blocks := make([]docsMDBlock, 0, len(raws))
+	for _, rb := range raws {
+		b := rb.block
+		switch b.Kind {
+		case docsMDCode, docsMDRule, docsMDTOC:
+		case docsMDTable:
+			for _, row := range rb.rows {
+				cells := make([][]docsMDRun, 0, len(row))
+				for _, cell := range row {
+					cells = append(cells, parseDocsMDInline(cell, defs))
+				}
+				b.Rows = append(b.Rows, cells)
+			}
+		default:
+			b.Runs = parseDocsMDInline(rb.raw, defs)
+		}
+		blocks = append(blocks, b)
+	}
+	return normalizeDocsMDBlocks(blocks)
+}
+
+func docsMDFence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == trimmed[0] {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return trimmed[:n]
+}
+
+func expandDocsMDIndent(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.Contains(line[:len(line)-len(trimmed)], "\t") {
+		return line
+	}
+	col := 0
+	for _, c := range line[:len(line)-len(trimmed)] {
+		if c == '\t' {
+			col += 4 - col%4
+		} else {
+			col++
+		}
+	}
+	return strings.Repeat(" ", col) + trimmed
+}
+
+// joinDocsMDLines joins the lines of a paragraph. Hard breaks (two trailing
+// spaces or a trailing backslash) become line breaks within the paragraph.
+func joinDocsMDLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		text := strings.TrimLeft(line, " \t")
+		if i == len(lines)-1 {
+			b.WriteString(strings.TrimRight(text, " \t"))
+			break
+		}
+		switch {
+		case strings.HasSuffix(text, "  "):
+			b.WriteString(strings.TrimRight(text, " \t") + "<br>")
+		case strings.HasSuffix(text, `\`) && !strings.HasSuffix(text, `\\`):
+			b.WriteString(strings.TrimSuffix(text, `\`) + "<br>")
+		default:
+			b.WriteString(strings.TrimRight(text, " \t") + " ")
+		}
+	}
+	return b.String()
+}
+
+// splitDocsMDTableRow splits a table row on unescaped pipes.
+func splitDocsMDTableRow(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	if rest := strings.TrimSpace(cell.String()); rest != "" {
+		cells = append(cells, rest)
+	}
+	return cells
+}
+
+func isDocsMDPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// parseDocsMDInline parses inline Markdown into runs. defs holds footnote
+// definitions by label; references without a definition stay literal.
+func parseDocsMDInline(s string, defs map[string]string) []docsMDRun {
+	p := &docsMDInlineParser{defs: defs}
+	p.parse(s, docsMDStyle{})
+	return mergeDocsMDRuns(p.out)
+}
+
+type docsMDInlineParser struct {
+	defs map[string]string
+	out  []docsMDRun
+}
+
+func (p *docsMDInlineParser) parse(s string, style docsMDStyle) {
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			p.out = append(p.out, docsMDRun{Text: lit.String(), Style: style})
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isDocsMDPunct(s[i+1]):
+			lit.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '<':
+			if n := docsMDLineBreakLen(s[i:]); n > 0 {
+				lit.WriteByte('\v')
+				i += n
+				continue
+			}
+		case c == '`':
+			if code, next, ok := docsMDCodeSpanAt(s, i); ok {
+				flush()
+				st := style
+				st.Code = true
+				p.out = append(p.out, docsMDRun{Text: code, Style: st})
+				i = next
+				continue
+			}
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if alt, url, next, ok := docsMDLinkAt(s, i+1); ok {
+				img := &docsMDImage{Alt: unescapeDocsMD(alt), URL: url}
+				if next < len(s) && s[next] == '{' {
+					if end := strings.IndexByte(s[next:], '}'); end > 0 {
+						w, h := parseImageDimAttrs(s[next+1 : next+end])
+						img.WidthPt, img.HeightPt = float64(w), float64(h)
+						next += end + 1
+					}
+				}
+				flush()
+				p.out = append(p.out, docsMDRun{Image: img})
+				i = next
+				continue
+			}
+		case c == '[' && i+1 < len(s) && s[i+1] == '^':
+			if end := strings.IndexByte(s[i:], ']'); end > 2 {
+				if def, ok := p.defs[s[i+2:i+end]]; ok {
+					flush()
+					sub := &docsMDInlineParser{}
+					sub.parse(def, docsMDStyle{})
+					p.out = append(p.out, docsMDRun{Footnote: &docsMDFootnote{Runs: mergeDocsMDRuns(sub.out)}})
+					i += end + 1
+					continue
+				}
+			}
+		case c == '[':
+			if text, url, next, ok := docsMDLinkAt(s, i); ok {
+				flush()
+				st := style
+				st.Link = url
+				p.parse(text, st)
+				i = next
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			if inner, next, st, ok := docsMDEmphasisAt(s, i, style); ok {
+				flush()
+				p.parse(inner, st)
+				i = next
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		lit.WriteString(s[i : i+size])
+		i += size
+	}
+	flush()
+}
+
+func docsMDLineBreakLen(s string) int {
+	lower := strings.ToLower(s)
+	for _, tag := range []string{"<br>", "<br/>", "<br />"} {
+		if strings.HasPrefix(lower, tag) {
+			return len(tag)
+		}
+	}
+	return 0
+}
+
+func unescapeDocsMD(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isDocsMDPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func docsMDRunLen(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// docsMDCodeSpanAt parses a code span starting at s[i].
+func docsMDCodeSpanAt(s string, i int) (string, int, bool) {
+	n := docsMDRunLen(s, i)
+	for j := i + n; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := docsMDRunLen(s, j)
+		if m == n {
+			code := s[i+n : j]
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			return code, j + m, true
+		}
+		j += m
+	}
+	return "", 0, false
+}
+
+// docsMDLinkAt parses `[text](url)` starting at s[i] == '['.
+func docsMDLinkAt(s string, i int) (string, string, int, bool) {
+	depth := 0
+	j := i
+	for ; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '`':
+			if _, next, ok := docsMDCodeSpanAt(s, j); ok {
+				j = next - 1
+			}
+			continue
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if j >= len(s)-1 || s[j+1] != '(' {
+		return "", "", 0, false
+	}
+	text := s[i+1 : j]
+	k := j + 2
+	var url string
+	if k < len(s) && s[k] == '<' {
+		end := strings.IndexByte(s[k:], '>')
+		if end < 0 {
+			return "", "", 0, false
+		}
+		url = s[k+1 : k+end]
+		k += end + 1
+	} else {
+		start, parens := k, 0
+		for ; k < len(s); k++ {
+			if s[k] == '(' {
+				parens++
+			} else if s[k] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			} else if s[k] == ' ' {
+				break
+			}
+		}
+		url = s[start:k]
+	}
+	for k < len(s) && s[k] == ' ' {
+		k++
+	}
+	if k < len(s) && (s[k] == '"' || s[k] == '\'') {
+		if end := strings.IndexByte(s[k+1:], s[k]); end >= 0 {
+			k += end + 2
+		}
+	}
+	for k < len(s) && s[k] == ' ' {
+		k++
+	}
+	if k >= len(s) || s[k] != ')' {
+		return "", "", 0, false
+	}
+	return text, url, k + 1, true
+}
+
+// docsMDEmphasisAt parses emphasis (`*`, `_`, `**`, `__`, `***`, `~~`)
+// starting at s[i] and returns the inner text with the resulting style.
+func docsMDEmphasisAt(s string, i int, style docsMDStyle) (string, int, docsMDStyle, bool) {
+	c := s[i]
+	n := docsMDRunLen(s, i)
+	if i+n >= len(s) || s[i+n] == ' ' || s[i+n] == '\t' {
+		return "", 0, style, false
+	}
+	if c == '_' && i > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(s[:i]); isDocsMDWordRune(r) {
+			return "", 0, style, false
+		}
+	}
+	var want []int
+	switch {
+	case c == '~':
+		if n != 2 {
+			return "", 0, style, false
+		}
+		want = []int{2}
+	case n >= 3:
+		n = 3
+		want = []int{3}
+	case n == 2:
+		want = []int{2, 3}
+	default:
+		want = []int{1, 3}
+	}
+	for j := i + n; j < len(s); {
+		switch {
+		case s[j] == '\\':
+			j += 2
+			continue
+		case s[j] == '`':
+			if _, next, ok := docsMDCodeSpanAt(s, j); ok {
+				j = next
+				continue
+			}
+		case s[j] == '[':
+			if _, _, next, ok := docsMDLinkAt(s, j); ok {
+				j = next
+				continue
+			}
+		}
+		if s[j] != c {
+			j++
+			continue
+		}
+		m := docsMDRunLen(s, j)
+		prev := s[j-1]
+		closes := prev != ' ' && prev != '\t'
+		if c == '_' && j+m < len(s) {
+			if r, _ := utf8.DecodeRuneInString(s[j+m:]); isDocsMDWordRune(r) {
+				closes = false
+			}
+		}
+		if closes {
+			for _, w := range want {
+				if m != w {
+					continue
+				}
+				// A longer closing run (`*a **b***`) closes the inner span first.
+				inner := s[i+n : j+m-n]
+				st := style
+				switch {
+				case c == '~':
+					st.Strike = true
+				case n == 3:
+					st.Bold, st.Italic = true, true
+				case n == 2:
+					st.Bold = true
+				default:
+					st.Italic = true
+				}
+				return inner, j + m, st, true
+			}
+		}
+		j += m
+	}
+	return "", 0, style, false
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"testing"
+)
+
+const docsMDRoundTripSample = `# Plan _draft_
+
+Intro with **bold**, _italic_, ~~gone~~, ` + "`code`" + `, and a [link](https://example.com/a_b). Snake_case stays, \*stars\* and \_under\_ are escaped<br>after a break.
+
+- one
+- two **bold _both_**
+  - nested
+    1. deep ordered
+- three
+
+1. first
+1. second
+
+> quoted [^1]
+
+| Name | Notes |
+| --- | --- |
+| a \| b | ` + "`x`" + ` |
+| ![logo](https://example.com/logo.png) |  |
+
+` + "```" + `
+func main() {
+
+	fmt.Println("*")
+}
+` + "```" + `
+
+---
+
+<!-- table of contents -->
+
+\# not a heading
+
+2020\. not a list
+
+[^1]: See _notes_.
+`
+
+func TestDocsMarkdown_RoundTrip(t *testing.T) {
+	blocks := parseDocsMarkdown(docsMDRoundTripSample)
+	if got := renderDocsMarkdown(blocks); got != docsMDRoundTripSample {
+		t.Fatalf("round trip mismatch:\n%s\nwant:\n%s", got, docsMDRoundTripSample)
+	}
+
+	kinds := []docsMDKind{
+		docsMDHeading, docsMDParagraph,
+		docsMDBullet, docsMDBullet, docsMDBullet, docsMDOrdered, docsMDBullet,
+		docsMDOrdered, docsMDOrdered,
+		docsMDQuote, docsMDTable, docsMDCode, docsMDRule, docsMDTOC, docsMDParagraph, docsMDParagraph,
+	}
+	if len(blocks) != len(kinds) {
+		t.Fatalf("expected %d blocks, got %d", len(kinds), len(blocks))
+	}
+	for i, k := range kinds {
+		if blocks[i].Kind != k {
+			t.Fatalf("block %d: expected kind %d, got %d", i, k, blocks[i].Kind)
+		}
+	}
+	if blocks[5].Level != 2 || blocks[4].Level != 1 {
+		t.Fatalf("unexpected list levels: %d %d", blocks[4].Level, blocks[5].Level)
+	}
+	intro := blocks[1].Runs
+	if intro[1].Text != "bold" || !intro[1].Style.Bold || intro[9].Style.Link != "https://example.com/a_b" {
+		t.Fatalf("unexpected intro runs: %#v", intro)
+	}
+	if text := docsMDRunsText(intro); text[len(text)-len("escaped\vafter a break."):] != "escaped\vafter a break." {
+		t.Fatalf("expected a line break, got %q", text)
+	}
+	fn := blocks[9].Runs[1].Footnote
+	if fn == nil || fn.Runs[1].Text != "notes" || !fn.Runs[1].Style.Italic {
+		t.Fatalf("unexpected footnote: %#v", blocks[9].Runs)
+	}
+	table := blocks[10].Rows
+	if docsMDRunsText(table[1][0]) != "a | b" || table[2][0][0].Image == nil {
+		t.Fatalf("unexpected table: %#v", table)
+	}
+	if code := blocks[11].Code; len(code) != 4 || code[1] != "" || code[2] != "\tfmt.Println(\"*\")" {
+		t.Fatalf("unexpected code: %#v", code)
+	}
+}
+
+func TestDocsMarkdown_Normalizes(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"* a\n+ b\n\n2. c\n", "- a\n- b\n\n1. c\n"},
+		{"Some\ntext  \nhere\n", "Some text<br>here\n"},
+		{"__bold__ and *it*alic\n", "**bold** and *it*alic\n"},
+		{"***both***\n", "**_both_**\n"},
+		{"`a` `b`\n", "`a` `b`\n"},
+		{"x ``tick ` inside``\n", "x ``tick ` inside``\n"},
+		{"`only code`\n", "```\nonly code\n```\n"},
+		{"~~~\ncode\n~~~\n", "```\ncode\n```\n"},
+		{"- a\n        - b\n", "- a\n  - b\n"},
+		{"Heading\n# Title #\n", "Heading\n\n# Title\n"},
+		{"[^x] missing\n", "\\[^x\\] missing\n"},
+	} {
+		if got := renderDocsMarkdown(parseDocsMarkdown(tc.in)); got != tc.want {
+			t.Fatalf("%q: got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRenderDocsMDInline_Styles(t *testing.T) {
+	runs := []docsMDRun{
+		{Text: "see "},
+		{Text: "the ", Style: docsMDStyle{Bold: true, Link: "https://x.test"}},
+		{Text: "docs", Style: docsMDStyle{Bold: true, Italic: true, Link: "https://x.test"}},
+		{Text: "page", Style: docsMDStyle{Italic: true}},
+		{Text: "!"},
+	}
+	if got := renderDocsMDInline(runs, nil, false); got != "see [**the _docs_**](https://x.test)_page_!" {
+		t.Fatalf("unexpected inline: %q", got)
+	}
+	if got := renderDocsMDInline(parseDocsMDInline("see [**the _docs_**](https://x.test)_page_!", nil), nil, false); got != "see [**the _docs_**](https://x.test)_page_!" {
+		t.Fatalf("unexpected reparse: %q", got)
+	}
+}
+
+func TestDiffDocsMDKeys(t *testing.T) {
+	hunks := diffDocsMDKeys([]string{"a", "b", "c", "d", "e"}, []string{"a", "x", "c", "e", "f"})
+	want := []docsPushHunk{
+		{oldLo: 1, oldHi: 2, newLo: 1, newHi: 2},
+		{oldLo: 3, oldHi: 4, newLo: 3, newHi: 3},
+		{oldLo: 5, oldHi: 5, newLo: 4, newHi: 5},
+	}
+	if len(hunks) != len(want) {
+		t.Fatalf("unexpected hunks: %#v", hunks)
+	}
+	for i := range want {
+		if hunks[i] != want[i] {
+			t.Fatalf("hunk %d: got %#v, want %#v", i, hunks[i], want[i])
+		}
+	}
+	if diffDocsMDKeys([]string{"a"}, []string{"a"}) != nil {
+		t.Fatal("expected no hunks for equal input")
+	}
+}
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"google.golang.org/api/docs/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type DocsPullCmd struct {
+	DocID string `arg:"" name:"docId" help:"Doc ID"`
+	Path  string `arg:"" name:"file" optional:"" help:"Markdown file to write (omit or '-' for stdout)"`
+}
+
+func (c *DocsPullCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	id := normalizeGoogleID(strings.TrimSpace(c.DocID))
+	if id == "" {
+		return usage("empty docId")
+	}
+
+	svc, err := requireDocsService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	doc, err := getDocsMarkdownDocument(ctx, svc, id)
+	if err != nil {
+		return err
+	}
+	md := renderDocsMarkdown(docsMarkdownBlocks(doc))
+
+	path := strings.TrimSpace(c.Path)
+	if path == "" || path == "-" {
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+				"documentId": doc.DocumentId,
+				"title":      doc.Title,
+				"revisionId": doc.RevisionId,
+				"markdown":   md,
+			})
+		}
+		_, err = os.Stdout.WriteString(md)
+		return err
+	}
+
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(expanded, []byte(md), 0o600); err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"documentId": doc.DocumentId,
+			"path":       expanded,
+			"bytes":      len(md),
+			"revisionId": doc.RevisionId,
+		})
+	}
+	u.Out().Printf("path\t%s", expanded)
+	u.Out().Printf("bytes\t%d", len(md))
+	if doc.RevisionId != "" {
+		u.Out().Printf("revision\t%s", doc.RevisionId)
+	}
+	return nil
+}
+
+func getDocsMarkdownDocument(ctx context.Context, svc *docs.Service, id string) (*docs.Document, error) {
+	doc, err := svc.Documents.Get(id).Context(ctx).Do()
+	if err != nil {
+		if isDocsNotFound(err) {
+			return nil, fmt.Errorf("doc not found or not a Google Doc (id=%s)", id)
+		}
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("doc not found")
+	}
+	return doc, nil
+}
+
+// docsMarkdownBlocks converts the document body into Markdown blocks that
+// remember the body range they came from.
+func docsMarkdownBlocks(doc *docs.Document) []docsMDBlock {
+	if doc == nil || doc.Body == nil {
+		return nil
+	}
+	r := docsMDReader{doc: doc}
+	var blocks []docsMDBlock
+	for _, el := range doc.Body.Content {
+		switch {
+		case el.Table != nil:
+			blocks = append(blocks, docsMDBlock{Kind: docsMDTable, Rows: r.tableRows(el.Table), Start: el.StartIndex, End: el.EndIndex})
+		case el.TableOfContents != nil:
+			blocks = append(blocks, docsMDBlock{Kind: docsMDTOC, Start: el.StartIndex, End: el.EndIndex})
+		case el.Paragraph != nil:
+			if b, ok := r.paragraphBlock(el.Paragraph); ok {
+				b.Start, b.End = el.StartIndex, el.EndIndex
+				blocks = append(blocks, b)
+			}
+		}
+	}
+	return normalizeDocsMDBlocks(blocks)
+}
+
+type docsMDReader struct {
+	doc *docs.Document
+}
+
+func (r docsMDReader) paragraphBlock(p *docs.Paragraph) (docsMDBlock, bool) {
+	runs, rule, mono := r.paragraphRuns(p)
+	text := docsMDRunsText(runs)
+	block := docsMDBlock{Kind: docsMDParagraph, para: p}
+
+	switch {
+	case rule && strings.TrimSpace(text) == "":
+		block.Kind = docsMDRule
+		return block, true
+	case strings.TrimSpace(text) == "" && !docsMDHasObjects(runs):
+		if mono {
+			// Blank line inside a code block.
+			block.Runs = []docsMDRun{{Style: docsMDStyle{Code: true}}}
+			block.Code = []string{""}
+			block.Kind = docsMDCode
+			return block, true
+		}
+		return block, false
+	case len(text) >= 3 && strings.Trim(text, "-") == "" && !docsMDHasObjects(runs):
+		block.Kind = docsMDRule
+		return block, true
+	}
+
+	if lines, ok := docsMDCodeLines(runs); ok {
+		return docsMDBlock{Kind: docsMDCode, Code: lines}, true
+	}
+	block.Runs = trimDocsMDRuns(runs)
+
+	style := p.ParagraphStyle
+	named := ""
+	if style != nil {
+		named = style.NamedStyleType
+	}
+	switch {
+	case p.Bullet != nil:
+		block.Kind = docsMDBullet
+		block.Level = int(p.Bullet.NestingLevel)
+		if r.listOrdered(p.Bullet.ListId, p.Bullet.NestingLevel) {
+			block.Kind = docsMDOrdered
+		}
+	case named == "TITLE":
+		block.Kind, block.Level = docsMDHeading, 1
+	case strings.HasPrefix(named, "HEADING_"):
+		block.Kind = docsMDHeading
+		_, _ = fmt.Sscanf(named, "HEADING_%d", &block.Level)
+	case style != nil && style.IndentStart != nil && style.IndentStart.Magnitude > 0:
+		block.Kind = docsMDQuote
+	}
+	return block, true
+}
+
+// paragraphRuns returns the runs of a paragraph without its final newline,
+// whether it holds a horizontal rule, and whether the paragraph mark is set
+// in a monospace font.
+func (r docsMDReader) paragraphRuns(p *docs.Paragraph) ([]docsMDRun, bool, bool) {
+	var runs []docsMDRun
+	rule, mono := false, false
+	for i, el := range p.Elements {
+		switch {
+		case el.TextRun != nil:
+			text := el.TextRun.Content
+			style := docsMDTextStyle(el.TextRun.TextStyle)
+			if i == len(p.Elements)-1 && strings.HasSuffix(text, "\n") {
+				text = strings.TrimSuffix(text, "\n")
+				mono = style.Code
+			}
+			runs = append(runs, docsMDRun{Text: text, Style: style})
+		case el.InlineObjectElement != nil:
+			runs = append(runs, docsMDRun{Image: r.image(el.InlineObjectElement.InlineObjectId)})
+		case el.FootnoteReference != nil:
+			id := el.FootnoteReference.FootnoteId
+			runs = append(runs, docsMDRun{Footnote: &docsMDFootnote{ID: id, Runs: r.footnoteRuns(id)}})
+		case el.HorizontalRule != nil:
+			rule = true
+		case el.Person != nil && el.Person.PersonProperties != nil:
+			runs = append(runs, docsMDRun{Text: firstNonEmpty(el.Person.PersonProperties.Email, el.Person.PersonProperties.Name)})
+		case el.RichLink != nil && el.RichLink.RichLinkProperties != nil:
+			props := el.RichLink.RichLinkProperties
+			runs = append(runs, docsMDRun{Text: firstNonEmpty(props.Title, props.Uri), Style: docsMDStyle{Link: props.Uri}})
+		}
+	}
+	return runs, rule, mono
+}
+
+func docsMDTextStyle(ts *docs.TextStyle) docsMDStyle {
+	if ts == nil {
+		return docsMDStyle{}
+	}
+	style := docsMDStyle{Bold: ts.Bold, Italic: ts.Italic, Strike: ts.Strikethrough}
+	if ts.Link != nil {
+		style.Link = ts.Link.Url
+	}
+	if ts.WeightedFontFamily != nil {
+		style.Code = isDocsMonospaceFont(ts.WeightedFontFamily.FontFamily)
+	}
+	return style
+}
+
+func isDocsMonospaceFont(family string) bool {
+	switch strings.ToLower(family) {
+	case "courier new", "courier", "consolas", "roboto mono", "source code pro", "inconsolata",
+		"ubuntu mono", "fira code", "jetbrains mono", "space mono", "ibm plex mono", "cousine":
+		return true
+	default:
+		return false
+	}
+}
+
+func (r docsMDReader) image(id string) *docsMDImage {
+	img := &docsMDImage{URL: docsMDImagePrefix + id}
+	obj, ok := r.doc.InlineObjects[id]
+	if !ok || obj.InlineObjectProperties == nil || obj.InlineObjectProperties.EmbeddedObject == nil {
+		return img
+	}
+	emb := obj.InlineObjectProperties.EmbeddedObject
+	img.Alt = firstNonEmpty(emb.Title, emb.Description)
+	if emb.ImageProperties != nil && emb.ImageProperties.SourceUri != "" {
+		img.URL = emb.ImageProperties.SourceUri
+	}
+	return img
+}
+
+// footnoteRuns flattens a footnote into one line, joining its paragraphs
+// with line breaks.
+func (r docsMDReader) footnoteRuns(id string) []docsMDRun {
+	fn, ok := r.doc.Footnotes[id]
+	if !ok {
+		return nil
+	}
+	return trimDocsMDRuns(r.contentRuns(fn.Content))
+}
+
+func (r docsMDReader) contentRuns(content []*docs.StructuralElement) []docsMDRun {
+	var runs []docsMDRun
+	for _, el := range content {
+		if el.Paragraph == nil {
+			continue
+		}
+		paraRuns, _, _ := r.paragraphRuns(el.Paragraph)
+		paraRuns = trimDocsMDRuns(paraRuns)
+		if len(paraRuns) == 0 {
+			continue
+		}
+		if len(runs) > 0 {
+			runs = append(runs, docsMDRun{Text: "\v"})
+		}
+		runs = append(runs, paraRuns...)
+	}
+	return runs
+}
+
+func (r docsMDReader) tableRows(t *docs.Table) [][][]docsMDRun {
+	rows := make([][][]docsMDRun, 0, len(t.TableRows))
+	for _, row := range t.TableRows {
+		cells := make([][]docsMDRun, 0, len(row.TableCells))
+		for _, cell := range row.TableCells {
+			cells = append(cells, r.contentRuns(cell.Content))
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+func (r docsMDReader) listOrdered(listID string, level int64) bool {
+	list, ok := r.doc.Lists[listID]
+	if !ok || list.ListProperties == nil || int(level) >= len(list.ListProperties.NestingLevels) {
+		return false
+	}
+	glyph := list.ListProperties.NestingLevels[level].GlyphType
+	return glyph != "" && glyph != "GLYPH_TYPE_UNSPECIFIED" && glyph != "NONE"
+}
+
+func docsMDRunsText(runs []docsMDRun) string {
+	var b strings.Builder
+	for _, r := range runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+func docsMDHasObjects(runs []docsMDRun) bool {
+	for _, r := range runs {
+		if r.Image != nil || r.Footnote != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// trimDocsMDRuns trims whitespace at both ends of a paragraph, which Markdown
+// cannot represent.
+func trimDocsMDRuns(runs []docsMDRun) []docsMDRun {
+	runs = mergeDocsMDRuns(runs)
+	for len(runs) > 0 && runs[0].Image == nil && runs[0].Footnote == nil {
+		runs[0].Text = strings.TrimLeftFunc(runs[0].Text, unicode.IsSpace)
+		if runs[0].Text != "" {
+			break
+		}
+		runs = runs[1:]
+	}
+	for len(runs) > 0 {
+		last := &runs[len(runs)-1]
+		if last.Image != nil || last.Footnote != nil {
+			break
+		}
+		last.Text = strings.TrimRightFunc(last.Text, unicode.IsSpace)
+		if last.Text != "" {
+			break
+		}
+		runs = runs[:len(runs)-1]
+	}
+	return runs
+}
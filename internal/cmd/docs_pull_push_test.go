@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+const docsPullFixture = `{
+  "documentId": "doc1", "revisionId": "rev1",
+  "body": {"content": [
+    {"startIndex": 0, "endIndex": 1, "sectionBreak": {}},
+    {"startIndex": 1, "endIndex": 6, "paragraph": {"paragraphStyle": {"namedStyleType": "HEADING_1"},
+      "elements": [{"startIndex": 1, "endIndex": 6, "textRun": {"content": "Plan\n"}}]}},
+    {"startIndex": 6, "endIndex": 29, "paragraph": {"paragraphStyle": {"namedStyleType": "NORMAL_TEXT"}, "elements": [
+      {"startIndex": 6, "endIndex": 12, "textRun": {"content": "Hello "}},
+      {"startIndex": 12, "endIndex": 17, "textRun": {"content": "world", "textStyle": {"bold": true}}},
+      {"startIndex": 17, "endIndex": 26, "textRun": {"content": " and more"}},
+      {"startIndex": 26, "endIndex": 27, "footnoteReference": {"footnoteId": "fn1"}},
+      {"startIndex": 27, "endIndex": 29, "textRun": {"content": ".\n"}}]}},
+    {"startIndex": 29, "endIndex": 38, "paragraph": {"bullet": {"listId": "l1"},
+      "elements": [{"startIndex": 29, "endIndex": 38, "textRun": {"content": "item one\n"}}]}},
+    {"startIndex": 38, "endIndex": 47, "paragraph": {"bullet": {"listId": "l1", "nestingLevel": 1},
+      "elements": [{"startIndex": 38, "endIndex": 47, "textRun": {"content": "item two\n"}}]}},
+    {"startIndex": 47, "endIndex": 55, "table": {"rows": 1, "columns": 2, "tableRows": [{"tableCells": [
+      {"content": [{"startIndex": 49, "endIndex": 51, "paragraph": {"elements": [{"textRun": {"content": "A\n"}}]}}]},
+      {"content": [{"startIndex": 52, "endIndex": 54, "paragraph": {"elements": [{"textRun": {"content": "B\n"}}]}}]}]}]}},
+    {"startIndex": 55, "endIndex": 62, "paragraph": {"elements": [
+      {"startIndex": 55, "endIndex": 60, "textRun": {"content": "Tail "}},
+      {"startIndex": 60, "endIndex": 61, "inlineObjectElement": {"inlineObjectId": "img1"}},
+      {"startIndex": 61, "endIndex": 62, "textRun": {"content": "\n"}}]}},
+    {"startIndex": 62, "endIndex": 69, "paragraph": {"elements": [
+      {"startIndex": 62, "endIndex": 69, "textRun": {"content": "x := 1\n", "textStyle": {"weightedFontFamily": {"fontFamily": "Courier New"}}}}]}},
+    {"startIndex": 69, "endIndex": 70, "paragraph": {"elements": [{"startIndex": 69, "endIndex": 70, "textRun": {"content": "\n"}}]}}
+  ]},
+  "lists": {"l1": {"listProperties": {"nestingLevels": [{"glyphSymbol": "●"}, {"glyphSymbol": "○"}]}}},
+  "footnotes": {"fn1": {"footnoteId": "fn1", "content": [
+    {"startIndex": 0, "endIndex": 13, "paragraph": {"elements": [{"startIndex": 0, "endIndex": 13, "textRun": {"content": " Source note\n"}}]}}]}},
+  "inlineObjects": {"img1": {"inlineObjectProperties": {"embeddedObject": {"title": "Logo",
+    "imageProperties": {"sourceUri": "https://example.com/logo.png"}}}}}
+}`
+
+const docsPullMarkdown = "# Plan\n\n" +
+	"Hello **world** and more[^1].\n\n" +
+	"- item one\n  - item two\n\n" +
+	"| A | B |\n| --- | --- |\n\n" +
+	"Tail ![Logo](https://example.com/logo.png)\n\n" +
+	"```\nx := 1\n```\n\n" +
+	"[^1]: Source note\n"
+
+func stubDocsPullPush(t *testing.T) *[]docs.BatchUpdateDocumentRequest {
+	t.Helper()
+	var batches []docs.BatchUpdateDocumentRequest
+	svc, closeSrv := newDocsServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, ":batchUpdate") {
+			var req docs.BatchUpdateDocumentRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decode batchUpdate: %v", err)
+			}
+			batches = append(batches, req)
+			_, _ = fmt.Fprintf(w, `{"documentId": "doc1", "writeControl": {"requiredRevisionId": "rev%d"}}`, len(batches)+1)
+			return
+		}
+		_, _ = w.Write([]byte(docsPullFixture))
+	})
+	t.Cleanup(closeSrv)
+	orig := newDocsService
+	t.Cleanup(func() { newDocsService = orig })
+	newDocsService = func(context.Context, string) (*docs.Service, error) { return svc, nil }
+	return &batches
+}
+
+func TestDocsPullCmd_WritesMarkdown(t *testing.T) {
+	stubDocsPullPush(t)
+	path := filepath.Join(t.TempDir(), "doc.md")
+
+	ctx, out := newDocsCmdOutputContext(t)
+	if err := runKong(t, &DocsPullCmd{}, []string{"doc1", path}, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != docsPullMarkdown {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", data, docsPullMarkdown)
+	}
+	if !strings.Contains(out.String(), "revision\trev1") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestDocsPushCmd_MinimalEdits(t *testing.T) {
+	batches := stubDocsPullPush(t)
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "doc.md")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return path
+	}
+
+	// Pushing an unmodified pull is a no-op.
+	if err := runKong(t, &DocsPushCmd{}, []string{write(docsPullMarkdown), "doc1"}, newDocsCmdContext(t), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("push unchanged: %v", err)
+	}
+	if len(*batches) != 0 {
+		t.Fatalf("expected no updates, got %d", len(*batches))
+	}
+
+	edited := strings.Replace(docsPullMarkdown, "and more", "and much more", 1)
+	edited = strings.Replace(edited, "```\n\n[^1]", "```\n\nNew **para**\n\n[^1]", 1)
+	ctx, out := newDocsCmdOutputContext(t)
+	if err := runKong(t, &DocsPushCmd{}, []string{write(edited), "doc1"}, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if !strings.Contains(out.String(), "unchanged\t6\nupdated\t1\ninserted\t1\ndeleted\t0") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	if len(*batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(*batches))
+	}
+
+	// Bottom-up: the appended paragraph first, guarded by the read revision.
+	appendBatch := (*batches)[0]
+	if appendBatch.WriteControl == nil || appendBatch.WriteControl.RequiredRevisionId != "rev1" {
+		t.Fatalf("expected revision guard, got %#v", appendBatch.WriteControl)
+	}
+	reqs := appendBatch.Requests
+	if reqs[0].InsertText == nil || reqs[0].InsertText.Location.Index != 69 || reqs[0].InsertText.Text != "\nNew para" {
+		t.Fatalf("unexpected append insert: %#v", reqs[0])
+	}
+	last := reqs[len(reqs)-1].UpdateTextStyle
+	if last == nil || !last.TextStyle.Bold || last.Range.StartIndex != 74 || last.Range.EndIndex != 78 {
+		t.Fatalf("unexpected bold request: %#v", reqs[len(reqs)-1])
+	}
+
+	// The edited paragraph only receives the changed characters, guarded by
+	// the revision the first batch produced.
+	reqs = (*batches)[1].Requests
+	if wc := (*batches)[1].WriteControl; wc == nil || wc.RequiredRevisionId != "rev2" || len(reqs) != 2 {
+		t.Fatalf("unexpected update batch: %#v", (*batches)[1])
+	}
+	if reqs[0].InsertText == nil || reqs[0].InsertText.Location.Index != 23 || reqs[0].InsertText.Text != "uch m" {
+		t.Fatalf("unexpected paragraph insert: %#v", reqs[0])
+	}
+	if style := reqs[1].UpdateTextStyle; style == nil || style.Range.StartIndex != 23 || style.Range.EndIndex != 28 || style.TextStyle.Bold {
+		t.Fatalf("unexpected restyle: %#v", reqs[1])
+	}
+}
+
+func TestDocsPushCmd_ReportsFailedBatch(t *testing.T) {
+	var revisions []string
+	svc, closeSrv := newDocsServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			_, _ = w.Write([]byte(docsPullFixture))
+			return
+		}
+		var req docs.BatchUpdateDocumentRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		revisions = append(revisions, req.WriteControl.RequiredRevisionId)
+		if len(revisions) == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "The required revision ID does not match the latest revision.", "status": "FAILED_PRECONDITION"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"documentId": "doc1", "writeControl": {"requiredRevisionId": "rev2"}}`))
+	})
+	t.Cleanup(closeSrv)
+	orig := newDocsService
+	t.Cleanup(func() { newDocsService = orig })
+	newDocsService = func(context.Context, string) (*docs.Service, error) { return svc, nil }
+
+	path := filepath.Join(t.TempDir(), "doc.md")
+	edited := strings.Replace(docsPullMarkdown, "and more", "and much more", 1)
+	edited = strings.Replace(edited, "```\n\n[^1]", "```\n\nNew **para**\n\n[^1]", 1)
+	if err := os.WriteFile(path, []byte(edited), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err := runKong(t, &DocsPushCmd{}, []string{path, "doc1"}, newDocsCmdContext(t), &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "batch 2 failed after 1 batch(es) were applied") {
+		t.Fatalf("expected a batch failure report, got %v", err)
+	}
+	if len(revisions) != 2 || revisions[0] != "rev1" || revisions[1] != "rev2" {
+		t.Fatalf("expected chained revisions, got %v", revisions)
+	}
+}
+
+func TestDocsPushCmd_FootnoteAndListChanges(t *testing.T) {
+	batches := stubDocsPullPush(t)
+	path := filepath.Join(t.TempDir(), "doc.md")
+	edited := strings.Replace(docsPullMarkdown, "Source note", "Better note", 1)
+	edited = strings.Replace(edited, "  - item two\n", "", 1)
+	if err := os.WriteFile(path, []byte(edited), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := runKong(t, &DocsPushCmd{}, []string{path, "doc1"}, newDocsCmdContext(t), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if len(*batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(*batches))
+	}
+	// The newline before the table must stay; it is kept as a plain paragraph.
+	reqs := (*batches)[0].Requests
+	if del := reqs[0].DeleteContentRange; del == nil || del.Range.StartIndex != 38 || del.Range.EndIndex != 46 {
+		t.Fatalf("unexpected list item delete: %#v", reqs[0])
+	}
+	if bullets := reqs[1].DeleteParagraphBullets; bullets == nil || bullets.Range.StartIndex != 38 {
+		t.Fatalf("expected the kept paragraph to lose its bullet: %#v", reqs[1])
+	}
+	reqs = (*batches)[1].Requests
+	if reqs[0].DeleteContentRange == nil || reqs[0].DeleteContentRange.Range.SegmentId != "fn1" ||
+		reqs[1].InsertText == nil || reqs[1].InsertText.Location.SegmentId != "fn1" || reqs[1].InsertText.Text != "Better note" {
+		t.Fatalf("unexpected footnote rewrite: %#v", reqs)
+	}
+}
@@ -0,0 +1,949 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"google.golang.org/api/docs/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type DocsPushCmd struct {
+	Path  string `arg:"" name:"file" help:"Markdown file to push ('-' for stdin)"`
+	DocID string `arg:"" name:"docId" help:"Doc ID"`
+}
+
+func (c *DocsPushCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	id := normalizeGoogleID(strings.TrimSpace(c.DocID))
+	if id == "" {
+		return usage("empty docId")
+	}
+	path := strings.TrimSpace(c.Path)
+	if path == "" {
+		return usage("empty file")
+	}
+	data, err := readTextInput(path)
+	if err != nil {
+		return err
+	}
+
+	account, svc, err := requireGoogleService(ctx, flags, newDocsService)
+	if err != nil {
+		return err
+	}
+	doc, err := getDocsMarkdownDocument(ctx, svc, id)
+	if err != nil {
+		return err
+	}
+
+	p := newDocsPusher(svc, doc, parseDocsMarkdown(string(data)))
+	if err := dryRunExit(ctx, flags, "docs.push", p.dryRunRequest()); err != nil {
+		return err
+	}
+
+	if err := p.apply(ctx); err != nil {
+		return err
+	}
+	if len(p.images) > 0 {
+		basePath := path
+		if path == "-" {
+			basePath = "stdin.md"
+		}
+		imgErr := insertImagesIntoDocs(ctx, account, svc, id, p.images, basePath)
+		cleanupDocsImagePlaceholders(ctx, svc, id, p.images)
+		if imgErr != nil {
+			return fmt.Errorf("insert images: %w", imgErr)
+		}
+	}
+
+	stats := p.stats()
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"documentId": id,
+			"unchanged":  stats.Unchanged,
+			"updated":    stats.Updated,
+			"inserted":   stats.Inserted,
+			"deleted":    stats.Deleted,
+			"requests":   p.requests,
+			"warnings":   p.warnings,
+		})
+	}
+	for _, w := range p.warnings {
+		u.Err().Printf("warning: %s", w)
+	}
+	u.Out().Printf("id\t%s", id)
+	u.Out().Printf("unchanged\t%d", stats.Unchanged)
+	u.Out().Printf("updated\t%d", stats.Updated)
+	u.Out().Printf("inserted\t%d", stats.Inserted)
+	u.Out().Printf("deleted\t%d", stats.Deleted)
+	return nil
+}
+
+// docsPushHunk replaces old blocks [oldLo,oldHi) with new blocks
+// [newLo,newHi). Update hunks edit one paragraph in place.
+type docsPushHunk struct {
+	oldLo, oldHi int
+	newLo, newHi int
+	update       bool
+}
+
+type docsPushStats struct {
+	Unchanged int `json:"unchanged"`
+	Updated   int `json:"updated"`
+	Inserted  int `json:"inserted"`
+	Deleted   int `json:"deleted"`
+}
+
+type docsPushFootnote struct {
+	placeholder string
+	runs        []docsMDRun
+}
+
+type docsPusher struct {
+	svc       *docs.Service
+	doc       *docs.Document
+	old       []docsMDBlock
+	cur       []docsMDBlock
+	hunks     []docsPushHunk
+	token     string
+	images    []markdownImage
+	footnotes []docsPushFootnote
+	warnings  []string
+	requests  int
+	batches   int
+	revision  string
+}
+
+func newDocsPusher(svc *docs.Service, doc *docs.Document, cur []docsMDBlock) *docsPusher {
+	old := docsMarkdownBlocks(doc)
+	return &docsPusher{
+		svc:      svc,
+		doc:      doc,
+		old:      old,
+		cur:      cur,
+		hunks:    planDocsPush(old, cur),
+		token:    imgPlaceholderToken(),
+		revision: doc.RevisionId,
+	}
+}
+
+func (p *docsPusher) stats() docsPushStats {
+	stats := docsPushStats{Unchanged: len(p.old)}
+	for _, h := range p.hunks {
+		stats.Unchanged -= h.oldHi - h.oldLo
+		if h.update {
+			stats.Updated++
+			continue
+		}
+		stats.Inserted += h.newHi - h.newLo
+		stats.Deleted += h.oldHi - h.oldLo
+	}
+	return stats
+}
+
+func (p *docsPusher) dryRunRequest() map[string]any {
+	render := func(blocks []docsMDBlock) []string {
+		out := make([]string, 0, len(blocks))
+		for _, b := range blocks {
+			out = append(out, strings.TrimSuffix(renderDocsMarkdown([]docsMDBlock{b}), "\n"))
+		}
+		return out
+	}
+	changes := make([]map[string]any, 0, len(p.hunks))
+	for _, h := range p.hunks {
+		op := "replace"
+		if h.update {
+			op = "update"
+		}
+		changes = append(changes, map[string]any{
+			"op":  op,
+			"old": render(p.old[h.oldLo:h.oldHi]),
+			"new": render(p.cur[h.newLo:h.newHi]),
+		})
+	}
+	return map[string]any{
+		"document_id": p.doc.DocumentId,
+		"revision_id": p.doc.RevisionId,
+		"stats":       p.stats(),
+		"changes":     changes,
+	}
+}
+
+func (p *docsPusher) warnf(format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// planDocsPush diffs block keys and turns each changed region into hunks,
+// editing paragraphs in place where an old and a new paragraph line up.
+func planDocsPush(old, cur []docsMDBlock) []docsPushHunk {
+	a := make([]string, len(old))
+	for i, b := range old {
+		a[i] = docsMDBlockKey(b)
+	}
+	b := make([]string, len(cur))
+	for i, blk := range cur {
+		b[i] = docsMDBlockKey(blk)
+	}
+
+	var hunks []docsPushHunk
+	for _, h := range diffDocsMDKeys(a, b) {
+		var back []docsPushHunk
+		for h.oldLo < h.oldHi && h.newLo < h.newHi && canUpdateDocsMDBlock(old[h.oldLo], cur[h.newLo]) {
+			hunks = append(hunks, docsPushHunk{oldLo: h.oldLo, oldHi: h.oldLo + 1, newLo: h.newLo, newHi: h.newLo + 1, update: true})
+			h.oldLo++
+			h.newLo++
+		}
+		for h.oldLo < h.oldHi && h.newLo < h.newHi && canUpdateDocsMDBlock(old[h.oldHi-1], cur[h.newHi-1]) {
+			back = append(back, docsPushHunk{oldLo: h.oldHi - 1, oldHi: h.oldHi, newLo: h.newHi - 1, newHi: h.newHi, update: true})
+			h.oldHi--
+			h.newHi--
+		}
+		if h.oldLo < h.oldHi || h.newLo < h.newHi {
+			hunks = append(hunks, h)
+		}
+		for i := len(back) - 1; i >= 0; i-- {
+			hunks = append(hunks, back[i])
+		}
+	}
+	return hunks
+}
+
+// maxDocsDiffCells bounds the LCS table; larger rewrites become one hunk.
+const maxDocsDiffCells = 4_000_000
+
+// diffDocsMDKeys returns the changed regions between a and b in order.
+func diffDocsMDKeys(a, b []string) []docsPushHunk {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	am, bm := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	if len(am) == 0 && len(bm) == 0 {
+		return nil
+	}
+	if len(am)*len(bm) > maxDocsDiffCells {
+		return []docsPushHunk{{oldLo: pre, oldHi: len(a) - suf, newLo: pre, newHi: len(b) - suf}}
+	}
+
+	n, m := len(am), len(bm)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if am[i] == bm[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunks []docsPushHunk
+	var cur *docsPushHunk
+	flush := func() {
+		if cur != nil {
+			hunks = append(hunks, *cur)
+			cur = nil
+		}
+	}
+	open := func(i, j int) {
+		if cur == nil {
+			cur = &docsPushHunk{oldLo: pre + i, oldHi: pre + i, newLo: pre + j, newHi: pre + j}
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && am[i] == bm[j]:
+			flush()
+			i++
+			j++
+		case j >= m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			open(i, j)
+			cur.oldHi++
+			i++
+		default:
+			open(i, j)
+			cur.newHi++
+			j++
+		}
+	}
+	flush()
+	return hunks
+}
+
+// canUpdateDocsMDBlock reports whether new can be applied to old by editing
+// old's paragraph in place, keeping comments anchored to untouched text.
+func canUpdateDocsMDBlock(old, cur docsMDBlock) bool {
+	if old.para == nil || !old.isParagraphLike() || !cur.isParagraphLike() {
+		return false
+	}
+	if (old.isList() || cur.isList()) && (old.Kind != cur.Kind || old.Level != cur.Level) {
+		return false
+	}
+	a, b := []rune(docsMDRunsText(old.Runs)), []rune(docsMDRunsText(cur.Runs))
+	same := 0
+	for same < len(a) && same < len(b) && a[same] == b[same] {
+		same++
+	}
+	for k := 0; k < len(a)-same && k < len(b)-same && a[len(a)-1-k] == b[len(b)-1-k]; k++ {
+		same++
+	}
+	return len(a)+len(b) == 0 || 4*same >= len(a)+len(b)
+}
+
+func (p *docsPusher) apply(ctx context.Context) error {
+	for i := len(p.hunks) - 1; i >= 0; i-- {
+		h := p.hunks[i]
+		if h.update {
+			if reqs, ok := p.updateRequests(p.old[h.oldLo], p.cur[h.newLo]); ok {
+				if _, err := p.batch(ctx, reqs); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := p.replace(ctx, h); err != nil {
+			return err
+		}
+	}
+	return p.fillFootnotes(ctx)
+}
+
+func (p *docsPusher) batch(ctx context.Context, reqs []*docs.Request) (*docs.BatchUpdateDocumentResponse, error) {
+	if len(reqs) == 0 {
+		return &docs.BatchUpdateDocumentResponse{}, nil
+	}
+	req := &docs.BatchUpdateDocumentRequest{Requests: reqs}
+	if p.revision != "" {
+		// The plan was computed against this revision; each batch requires
+		// the revision the previous one produced, so an edit by someone else
+		// in between stops the push instead of being overwritten.
+		req.WriteControl = &docs.WriteControl{RequiredRevisionId: p.revision}
+	}
+	p.batches++
+	resp, err := p.svc.Documents.BatchUpdate(p.doc.DocumentId, req).Context(ctx).Do()
+	if err != nil {
+		if p.batches == 1 {
+			return nil, fmt.Errorf("update document: %w", err)
+		}
+		return nil, fmt.Errorf("update document: batch %d failed after %d batch(es) were applied; pull again before retrying: %w", p.batches, p.batches-1, err)
+	}
+	p.requests += len(reqs)
+	if resp.WriteControl != nil && resp.WriteControl.RequiredRevisionId != "" {
+		p.revision = resp.WriteControl.RequiredRevisionId
+	}
+	return resp, nil
+}
+
+// docsPushUnit is one character of a paragraph, or one inline object.
+type docsPushUnit struct {
+	r        rune
+	width    int64
+	style    docsMDStyle
+	object   string // "image:<url>", "footnote", or another element kind
+	footnote string // footnote ID for footnote references in the document
+	runs     []docsMDRun
+}
+
+func (u docsPushUnit) same(o docsPushUnit) bool {
+	return u.r == o.r && u.object == o.object
+}
+
+func (p *docsPusher) paragraphUnits(para *docs.Paragraph) []docsPushUnit {
+	r := docsMDReader{doc: p.doc}
+	var units []docsPushUnit
+	for i, el := range para.Elements {
+		if el.TextRun != nil {
+			text := el.TextRun.Content
+			if i == len(para.Elements)-1 {
+				text = strings.TrimSuffix(text, "\n")
+			}
+			style := docsMDTextStyle(el.TextRun.TextStyle)
+			for _, c := range text {
+				units = append(units, docsPushUnit{r: c, width: int64(utf16.RuneLen(c)), style: style})
+			}
+			continue
+		}
+		unit := docsPushUnit{r: '\uFFFC', width: max(el.EndIndex-el.StartIndex, 1), object: "element"}
+		switch {
+		case el.InlineObjectElement != nil:
+			unit.object = "image:" + r.image(el.InlineObjectElement.InlineObjectId).URL
+		case el.FootnoteReference != nil:
+			unit.object = "footnote"
+			unit.footnote = el.FootnoteReference.FootnoteId
+			unit.runs = r.footnoteRuns(unit.footnote)
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+func docsMDRunUnits(runs []docsMDRun) []docsPushUnit {
+	var units []docsPushUnit
+	for _, r := range runs {
+		switch {
+		case r.Image != nil:
+			units = append(units, docsPushUnit{r: '\uFFFC', width: 1, object: "image:" + r.Image.URL})
+		case r.Footnote != nil:
+			units = append(units, docsPushUnit{r: '\uFFFC', width: 1, object: "footnote", runs: r.Footnote.Runs})
+		default:
+			for _, c := range r.Text {
+				units = append(units, docsPushUnit{r: c, width: int64(utf16.RuneLen(c)), style: r.Style})
+			}
+		}
+	}
+	return units
+}
+
+func docsPushUnitsWidth(units []docsPushUnit) int64 {
+	var n int64
+	for _, u := range units {
+		n += u.width
+	}
+	return n
+}
+
+// updateRequests edits a paragraph in place: only the changed middle of the
+// text is deleted and reinserted, and only spans whose style differs are
+// restyled. It reports false when the edit adds inline objects, which need a
+// full replace.
+func (p *docsPusher) updateRequests(old, cur docsMDBlock) ([]*docs.Request, bool) {
+	ou := p.paragraphUnits(old.para)
+	nu := docsMDRunUnits(cur.Runs)
+
+	pre := 0
+	for pre < len(ou) && pre < len(nu) && ou[pre].same(nu[pre]) {
+		pre++
+	}
+	suf := 0
+	for suf < len(ou)-pre && suf < len(nu)-pre && ou[len(ou)-1-suf].same(nu[len(nu)-1-suf]) {
+		suf++
+	}
+	mid := nu[pre : len(nu)-suf]
+	for _, u := range mid {
+		if u.object != "" {
+			return nil, false
+		}
+	}
+
+	var reqs []*docs.Request
+	start := old.Start + docsPushUnitsWidth(ou[:pre])
+	if end := old.Start + docsPushUnitsWidth(ou[:len(ou)-suf]); end > start {
+		reqs = append(reqs, docsDeleteRangeRequest(&docs.Range{StartIndex: start, EndIndex: end}))
+	}
+	if len(mid) > 0 {
+		var text strings.Builder
+		for _, u := range mid {
+			text.WriteRune(u.r)
+		}
+		reqs = append(reqs, docsInsertTextRequest(&docs.Location{Index: start}, text.String()))
+	}
+
+	// Restyle the span between the first and last character whose style
+	// changed; inserted text always counts as changed.
+	lo, hi := -1, -1
+	for k, u := range nu {
+		if u.object != "" {
+			continue
+		}
+		changed := k >= pre && k < len(nu)-suf
+		if k < pre {
+			changed = ou[k].style != u.style
+		} else if k >= len(nu)-suf {
+			changed = ou[len(ou)-(len(nu)-k)].style != u.style
+		}
+		if changed {
+			if lo < 0 {
+				lo = k
+			}
+			hi = k
+		}
+	}
+	if lo >= 0 {
+		offset := old.Start + docsPushUnitsWidth(nu[:lo])
+		for k := lo; k <= hi; {
+			style := nu[k].style
+			end := k
+			for end <= hi && (nu[end].object != "" || nu[end].style == style) {
+				end++
+			}
+			width := docsPushUnitsWidth(nu[k:end])
+			reqs = append(reqs, docsMDStyleRequest(&docs.Range{StartIndex: offset, EndIndex: offset + width}, style))
+			offset += width
+			k = end
+		}
+	}
+
+	if old.Kind != cur.Kind || old.Level != cur.Level {
+		named, indent := docsPushParagraphStyle(cur)
+		reqs = append(reqs, docsParagraphStyleRequest(&docs.Range{StartIndex: old.Start, EndIndex: old.Start + 1}, named, indent))
+	}
+
+	// Footnotes that survived in place but whose text changed.
+	for k, u := range nu {
+		if u.object != "footnote" || (k >= pre && k < len(nu)-suf) {
+			continue
+		}
+		j := k
+		if k >= len(nu)-suf {
+			j = len(ou) - (len(nu) - k)
+		}
+		if renderDocsMDInline(ou[j].runs, nil, false) != renderDocsMDInline(u.runs, nil, false) {
+			reqs = append(reqs, p.footnoteRewriteRequests(ou[j].footnote, u.runs)...)
+		}
+	}
+	return reqs, true
+}
+
+func (p *docsPusher) footnoteRewriteRequests(id string, runs []docsMDRun) []*docs.Request {
+	fn, ok := p.doc.Footnotes[id]
+	if !ok || len(fn.Content) == 0 {
+		return nil
+	}
+	first := fn.Content[0].StartIndex
+	end := fn.Content[len(fn.Content)-1].EndIndex - 1
+	var reqs []*docs.Request
+	if end > first {
+		reqs = append(reqs, docsDeleteRangeRequest(&docs.Range{StartIndex: first, EndIndex: end, SegmentId: id}))
+	}
+	return append(reqs, p.segmentTextRequests(id, first, runs)...)
+}
+
+// segmentTextRequests inserts runs into a footnote at index.
+func (p *docsPusher) segmentTextRequests(segment string, index int64, runs []docsMDRun) []*docs.Request {
+	w := &docsMDWriter{p: p, base: index, segment: segment}
+	w.runs(runs, false)
+	if w.n == 0 {
+		return nil
+	}
+	reqs := []*docs.Request{
+		docsInsertTextRequest(&docs.Location{Index: index, SegmentId: segment}, w.text.String()),
+		docsMDStyleRequest(w.rng(0, w.n), docsMDStyle{}),
+	}
+	return append(reqs, w.styles...)
+}
+
+// replace deletes the old blocks of a hunk and inserts the new ones in their
+// place. A document always ends with a paragraph and a table is always
+// preceded by one, so when the deleted range ends there its final newline is
+// kept and reused.
+func (p *docsPusher) replace(ctx context.Context, h docsPushHunk) error {
+	olds := p.old[h.oldLo:h.oldHi]
+	news := p.cur[h.newLo:h.newHi]
+	bodyEnd := docsDocumentEndIndex(p.doc)
+
+	var reqs []*docs.Request
+	var at int64
+	lead, reuseNewline := false, false
+	switch {
+	case len(olds) > 0:
+		start, end := olds[0].Start, olds[len(olds)-1].End
+		if last := olds[len(olds)-1]; last.Kind != docsMDTable && last.Kind != docsMDTOC && (end >= bodyEnd || p.structuralAt(end)) {
+			end--
+			reuseNewline = true
+		}
+		if end > start {
+			reqs = append(reqs, docsDeleteRangeRequest(&docs.Range{StartIndex: start, EndIndex: end}))
+		}
+		at = start
+	case h.oldLo < len(p.old) && p.old[h.oldLo].Kind != docsMDTable && p.old[h.oldLo].Kind != docsMDTOC:
+		at = p.old[h.oldLo].Start
+	default:
+		// Split the preceding paragraph's newline to open a new paragraph
+		// before a table or at the end of the body.
+		next := bodyEnd
+		if h.oldLo < len(p.old) {
+			next = p.old[h.oldLo].Start
+		}
+		at = max(next-1, 1)
+		lead, reuseNewline = true, true
+	}
+	base := at
+	if lead {
+		base++
+	}
+
+	w := &docsMDWriter{p: p, base: base}
+	w.blocks(news)
+	text := w.text.String()
+	if reuseNewline {
+		text = strings.TrimSuffix(text, "\n")
+	}
+	if lead {
+		text = "\n" + text
+	}
+	if text != "" {
+		reqs = append(reqs, docsInsertTextRequest(&docs.Location{Index: at}, text))
+	}
+	switch {
+	case w.n > 0:
+		all := w.rng(0, w.n)
+		reqs = append(reqs,
+			&docs.Request{DeleteParagraphBullets: &docs.DeleteParagraphBulletsRequest{Range: all}},
+			docsMDStyleRequest(all, docsMDStyle{}),
+		)
+		reqs = append(reqs, w.paraStyles...)
+		reqs = append(reqs, w.styles...)
+		for i := len(w.bullets) - 1; i >= 0; i-- {
+			reqs = append(reqs, w.bullets[i])
+		}
+	case reuseNewline && len(olds) > 0:
+		// The kept newline would otherwise keep the old paragraph's bullet.
+		rest := &docs.Range{StartIndex: at, EndIndex: at + 1}
+		reqs = append(reqs,
+			&docs.Request{DeleteParagraphBullets: &docs.DeleteParagraphBulletsRequest{Range: rest}},
+			docsParagraphStyleRequest(rest, "NORMAL_TEXT", 0),
+		)
+	}
+	if _, err := p.batch(ctx, reqs); err != nil {
+		return err
+	}
+
+	for i := len(w.tables) - 1; i >= 0; i-- {
+		t := w.tables[i]
+		index := base + t.offset
+		for _, tab := range w.tabs {
+			if tab < t.offset {
+				index--
+			}
+		}
+		if err := p.insertTable(ctx, index, t.rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *docsPusher) structuralAt(index int64) bool {
+	for _, el := range p.doc.Body.Content {
+		if el.StartIndex == index && (el.Table != nil || el.TableOfContents != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// insertTable inserts a table and fills its cells. InsertTable adds a newline
+// before the table, so the table is looked up just after index.
+func (p *docsPusher) insertTable(ctx context.Context, index int64, rows [][][]docsMDRun) error {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if len(rows) == 0 || cols == 0 {
+		return nil
+	}
+	if _, err := p.batch(ctx, []*docs.Request{{
+		InsertTable: &docs.InsertTableRequest{Rows: int64(len(rows)), Columns: int64(cols), Location: &docs.Location{Index: index}},
+	}}); err != nil {
+		return err
+	}
+	doc, err := getDocsMarkdownDocument(ctx, p.svc, p.doc.DocumentId)
+	if err != nil {
+		return err
+	}
+	var table *docs.Table
+	for _, el := range doc.Body.Content {
+		if el.Table != nil && el.StartIndex >= index && el.StartIndex <= index+2 {
+			table = el.Table
+			break
+		}
+	}
+	if table == nil {
+		return fmt.Errorf("inserted table not found near index %d", index)
+	}
+
+	// Fill cells last to first so earlier cell indices stay valid.
+	var reqs []*docs.Request
+	for ri := len(rows) - 1; ri >= 0; ri-- {
+		if ri >= len(table.TableRows) {
+			continue
+		}
+		cells := table.TableRows[ri].TableCells
+		for ci := len(rows[ri]) - 1; ci >= 0; ci-- {
+			if ci >= len(cells) || len(cells[ci].Content) == 0 {
+				continue
+			}
+			start := cells[ci].Content[0].StartIndex
+			w := &docsMDWriter{p: p, base: start}
+			w.runs(rows[ri][ci], true)
+			if w.n == 0 {
+				continue
+			}
+			reqs = append(reqs, docsInsertTextRequest(&docs.Location{Index: start}, w.text.String()))
+			reqs = append(reqs, w.styles...)
+		}
+	}
+	_, err = p.batch(ctx, reqs)
+	return err
+}
+
+// fillFootnotes turns footnote placeholders into real footnotes: the
+// placeholders are swapped for references, then each new footnote segment
+// receives its text.
+func (p *docsPusher) fillFootnotes(ctx context.Context) error {
+	if len(p.footnotes) == 0 {
+		return nil
+	}
+	doc, err := getDocsMarkdownDocument(ctx, p.svc, p.doc.DocumentId)
+	if err != nil {
+		return err
+	}
+	placeholders := make([]string, 0, len(p.footnotes))
+	for _, fn := range p.footnotes {
+		placeholders = append(placeholders, fn.placeholder)
+	}
+	found := map[string]docRange{}
+	searchElements(doc.Body.Content, placeholders, found)
+
+	type hit struct {
+		footnote docsPushFootnote
+		at       docRange
+	}
+	hits := make([]hit, 0, len(found))
+	for _, fn := range p.footnotes {
+		if at, ok := found[fn.placeholder]; ok {
+			hits = append(hits, hit{footnote: fn, at: at})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].at.startIndex > hits[j].at.startIndex })
+
+	reqs := make([]*docs.Request, 0, 2*len(hits))
+	for _, h := range hits {
+		reqs = append(reqs,
+			docsDeleteRangeRequest(&docs.Range{StartIndex: h.at.startIndex, EndIndex: h.at.endIndex}),
+			&docs.Request{CreateFootnote: &docs.CreateFootnoteRequest{Location: &docs.Location{Index: h.at.startIndex}}},
+		)
+	}
+	resp, err := p.batch(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, reply := range resp.Replies {
+		if reply != nil && reply.CreateFootnote != nil {
+			ids = append(ids, reply.CreateFootnote.FootnoteId)
+		}
+	}
+
+	doc, err = getDocsMarkdownDocument(ctx, p.svc, p.doc.DocumentId)
+	if err != nil {
+		return err
+	}
+	reqs = nil
+	for i, id := range ids {
+		fn, ok := doc.Footnotes[id]
+		if !ok || len(fn.Content) == 0 || i >= len(hits) {
+			continue
+		}
+		end := fn.Content[len(fn.Content)-1].EndIndex - 1
+		reqs = append(reqs, p.segmentTextRequests(id, end, hits[i].footnote.runs)...)
+	}
+	_, err = p.batch(ctx, reqs)
+	return err
+}
+
+type docsPushTable struct {
+	offset int64
+	rows   [][][]docsMDRun
+}
+
+// docsMDWriter lays out blocks as the text to insert plus the requests that
+// style it. Offsets are relative to base.
+type docsMDWriter struct {
+	p       *docsPusher
+	base    int64
+	segment string
+	text    strings.Builder
+	n       int64
+
+	paraStyles []*docs.Request
+	styles     []*docs.Request
+	bullets    []*docs.Request
+	tabs       []int64
+	tables     []docsPushTable
+
+	inList    bool
+	listStart int64
+	listKind  docsMDKind
+}
+
+func (w *docsMDWriter) write(s string) {
+	w.text.WriteString(s)
+	w.n += utf16Len(s)
+}
+
+func (w *docsMDWriter) rng(start, end int64) *docs.Range {
+	return &docs.Range{StartIndex: w.base + start, EndIndex: w.base + end, SegmentId: w.segment}
+}
+
+// runs writes inline content. Images and footnotes become placeholders that
+// are resolved once all text is in place; objects is false inside footnotes.
+func (w *docsMDWriter) runs(runs []docsMDRun, objects bool) {
+	for _, r := range runs {
+		switch {
+		case r.Image != nil:
+			if !objects {
+				w.p.warnf("images in footnotes are not supported: %s", r.Image.URL)
+				continue
+			}
+			if strings.HasPrefix(r.Image.URL, docsMDImagePrefix) {
+				w.p.warnf("image %s has no source URL and cannot be re-inserted", strings.TrimPrefix(r.Image.URL, docsMDImagePrefix))
+				continue
+			}
+			img := markdownImage{
+				index:       len(w.p.images),
+				alt:         r.Image.Alt,
+				originalRef: r.Image.URL,
+				token:       w.p.token,
+				widthPt:     r.Image.WidthPt,
+				heightPt:    r.Image.HeightPt,
+			}
+			w.p.images = append(w.p.images, img)
+			w.write(img.placeholder())
+		case r.Footnote != nil:
+			if !objects {
+				continue
+			}
+			placeholder := fmt.Sprintf("<<FN_%s_%d>>", w.p.token, len(w.p.footnotes))
+			w.p.footnotes = append(w.p.footnotes, docsPushFootnote{placeholder: placeholder, runs: r.Footnote.Runs})
+			w.write(placeholder)
+		default:
+			start := w.n
+			w.write(r.Text)
+			if r.Style != (docsMDStyle{}) {
+				w.styles = append(w.styles, docsMDStyleRequest(w.rng(start, w.n), r.Style))
+			}
+		}
+	}
+}
+
+func (w *docsMDWriter) blocks(blocks []docsMDBlock) {
+	lastTable := false
+	for _, b := range blocks {
+		if !b.isList() || (b.Level == 0 && b.Kind != w.listKind) {
+			w.endList()
+		}
+		lastTable = false
+		start := w.n
+		switch b.Kind {
+		case docsMDTable:
+			w.tables = append(w.tables, docsPushTable{offset: w.n, rows: b.Rows})
+			lastTable = true
+			continue
+		case docsMDTOC:
+			w.p.warnf("a table of contents cannot be created through the Docs API; skipped")
+			continue
+		case docsMDRule:
+			w.write("---\n")
+		case docsMDCode:
+			for _, line := range b.Code {
+				w.write(line + "\n")
+			}
+			w.styles = append(w.styles, docsMDStyleRequest(w.rng(start, w.n), docsMDStyle{Code: true}))
+		case docsMDBullet, docsMDOrdered:
+			if !w.inList {
+				w.inList, w.listStart, w.listKind = true, start, b.Kind
+			}
+			for range b.Level {
+				w.tabs = append(w.tabs, w.n)
+				w.write("\t")
+			}
+			w.runs(b.Runs, true)
+			w.write("\n")
+		default:
+			w.runs(b.Runs, true)
+			w.write("\n")
+		}
+		named, indent := docsPushParagraphStyle(b)
+		w.paraStyles = append(w.paraStyles, docsParagraphStyleRequest(w.rng(start, w.n), named, indent))
+	}
+	w.endList()
+	if lastTable {
+		// A table cannot end the inserted content; keep a paragraph after it.
+		start := w.n
+		w.write("\n")
+		w.paraStyles = append(w.paraStyles, docsParagraphStyleRequest(w.rng(start, w.n), "NORMAL_TEXT", 0))
+	}
+}
+
+func (w *docsMDWriter) endList() {
+	if !w.inList {
+		return
+	}
+	preset := "BULLET_DISC_CIRCLE_SQUARE"
+	if w.listKind == docsMDOrdered {
+		preset = "NUMBERED_DECIMAL_ALPHA_ROMAN"
+	}
+	w.bullets = append(w.bullets, &docs.Request{CreateParagraphBullets: &docs.CreateParagraphBulletsRequest{
+		Range:        w.rng(w.listStart, w.n),
+		BulletPreset: preset,
+	}})
+	w.inList = false
+}
+
+// docsPushQuoteIndentPt is the left indent used for block quotes.
+const docsPushQuoteIndentPt = 36
+
+func docsPushParagraphStyle(b docsMDBlock) (string, float64) {
+	switch b.Kind {
+	case docsMDHeading:
+		return fmt.Sprintf("HEADING_%d", min(max(b.Level, 1), 6)), 0
+	case docsMDQuote:
+		return "NORMAL_TEXT", docsPushQuoteIndentPt
+	default:
+		return "NORMAL_TEXT", 0
+	}
+}
+
+func docsParagraphStyleRequest(r *docs.Range, named string, indent float64) *docs.Request {
+	style := &docs.ParagraphStyle{NamedStyleType: named}
+	if indent > 0 {
+		style.IndentStart = &docs.Dimension{Magnitude: indent, Unit: "PT"}
+		style.IndentFirstLine = &docs.Dimension{Magnitude: indent, Unit: "PT"}
+	}
+	return &docs.Request{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+		Range:          r,
+		ParagraphStyle: style,
+		Fields:         "namedStyleType,indentStart,indentFirstLine",
+	}}
+}
+
+// docsMDStyleRequest sets every text attribute the Markdown model knows
+// about; attributes that are off are reset to the paragraph's defaults.
+func docsMDStyleRequest(r *docs.Range, style docsMDStyle) *docs.Request {
+	ts := &docs.TextStyle{Bold: style.Bold, Italic: style.Italic, Strikethrough: style.Strike}
+	if style.Link != "" {
+		ts.Link = &docs.Link{Url: style.Link}
+	}
+	if style.Code {
+		ts.WeightedFontFamily = &docs.WeightedFontFamily{FontFamily: "Courier New"}
+	}
+	return &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+		Range:     r,
+		TextStyle: ts,
+		Fields:    "bold,italic,strikethrough,link,weightedFontFamily",
+	}}
+}
+
+func docsDeleteRangeRequest(r *docs.Range) *docs.Request {
+	return &docs.Request{DeleteContentRange: &docs.DeleteContentRangeRequest{Range: r}}
+}
+
+func docsInsertTextRequest(loc *docs.Location, text string) *docs.Request {
+	return &docs.Request{InsertText: &docs.InsertTextRequest{Location: loc, Text: text}}
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"crypto/md5" //nolint:gosec // Drive reports md5Checksum; used for integrity checks only
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +13,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/alecthomas/kong"
 	"google.golang.org/api/drive/v3"
 
 	"github.com/steipete/gogcli/internal/googleapi"
@@ -35,6 +38,7 @@ const (
 	driveMimeGoogleSheet   = "application/vnd.google-apps.spreadsheet"
 	driveMimeGoogleSlides  = "application/vnd.google-apps.presentation"
 	driveMimeGoogleDrawing = "application/vnd.google-apps.drawing"
+	driveMimeFolder        = "application/vnd.google-apps.folder"
 	mimePDF                = "application/pdf"
 	mimeCSV                = "text/csv"
 	mimeDocx               = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
@@ -66,43 +70,62 @@ const (
 )
 
 type DriveCmd struct {
-	Ls          DriveLsCmd          `cmd:"" name:"ls" help:"List files in a folder (default: root)"`
-	Search      DriveSearchCmd      `cmd:"" name:"search" help:"Full-text search across Drive"`
-	Get         DriveGetCmd         `cmd:"" name:"get" help:"Get file metadata"`
-	Download    DriveDownloadCmd    `cmd:"" name:"download" help:"Download a file (exports Google Docs formats)"`
-	Copy        DriveCopyCmd        `cmd:"" name:"copy" help:"Copy a file"`
-	Upload      DriveUploadCmd      `cmd:"" name:"upload" help:"Upload a file"`
-	Mkdir       DriveMkdirCmd       `cmd:"" name:"mkdir" help:"Create a folder"`
-	Delete      DriveDeleteCmd      `cmd:"" name:"delete" help:"Move a file to trash (use --permanent to delete forever)" aliases:"rm,del"`
-	Move        DriveMoveCmd        `cmd:"" name:"move" help:"Move a file to a different folder"`
-	Rename      DriveRenameCmd      `cmd:"" name:"rename" help:"Rename a file or folder"`
-	Share       DriveShareCmd       `cmd:"" name:"share" help:"Share a file or folder"`
-	Unshare     DriveUnshareCmd     `cmd:"" name:"unshare" help:"Remove a permission from a file"`
-	Permissions DrivePermissionsCmd `cmd:"" name:"permissions" help:"List permissions on a file"`
-	URL         DriveURLCmd         `cmd:"" name:"url" help:"Print web URLs for files"`
-	Comments    DriveCommentsCmd    `cmd:"" name:"comments" help:"Manage comments on files"`
-	Drives      DriveDrivesCmd      `cmd:"" name:"drives" help:"List shared drives (Team Drives)"`
+	Ls           DriveLsCmd           `cmd:"" name:"ls" help:"List files in a folder (default: root)"`
+	Search       DriveSearchCmd       `cmd:"" name:"search" help:"Full-text search across Drive"`
+	Get          DriveGetCmd          `cmd:"" name:"get" help:"Get file metadata"`
+	Download     DriveDownloadCmd     `cmd:"" name:"download" help:"Download a file (exports Google Docs formats)"`
+	Copy         DriveCopyCmd         `cmd:"" name:"copy" help:"Copy a file"`
+	Upload       DriveUploadCmd       `cmd:"" name:"upload" help:"Upload a file"`
+	Mkdir        DriveMkdirCmd        `cmd:"" name:"mkdir" help:"Create a folder"`
+	Shortcut     DriveShortcutCmd     `cmd:"" name:"shortcut" help:"Create or resolve shortcuts (Drive's replacement for multiple parents)"`
+	Update       DriveUpdateCmd       `cmd:"" name:"update" help:"Update starred, description, folder color, or appProperties"`
+	Delete       DriveDeleteCmd       `cmd:"" name:"delete" help:"Move a file to trash (use --permanent to delete forever)" aliases:"rm,del"`
+	Move         DriveMoveCmd         `cmd:"" name:"move" help:"Move a file to a different folder"`
+	Rename       DriveRenameCmd       `cmd:"" name:"rename" help:"Rename a file or folder"`
+	Share        DriveShareCmd        `cmd:"" name:"share" help:"Share a file or folder"`
+	Unshare      DriveUnshareCmd      `cmd:"" name:"unshare" help:"Remove a permission from a file"`
+	Permissions  DrivePermissionsCmd  `cmd:"" name:"permissions" help:"List permissions on a file, or bulk revoke/downgrade"`
+	URL          DriveURLCmd          `cmd:"" name:"url" help:"Print web URLs for files"`
+	Comments     DriveCommentsCmd     `cmd:"" name:"comments" help:"Manage comments on files"`
+	Drives       DriveDrivesCmd       `cmd:"" name:"drives" help:"List and manage shared drives (Team Drives) and their members"`
+	Changes      DriveChangesCmd      `cmd:"" name:"changes" help:"Track Drive changes since the last run (incremental changes.list)"`
+	Sync         DriveSyncCmd         `cmd:"" name:"sync" help:"Sync a local directory with a Drive folder"`
+	CopyTree     DriveCopyTreeCmd     `cmd:"" name:"copy-tree" help:"Recursively copy a folder to a new parent"`
+	Tree         DriveTreeCmd         `cmd:"" name:"tree" help:"Print a folder hierarchy with sizes"`
+	Labels       DriveLabelsCmd       `cmd:"" name:"labels" help:"List label schemas and read/set labels on files"`
+	Revisions    DriveRevisionsCmd    `cmd:"" name:"revisions" aliases:"revs" help:"List, download, pin, restore, and diff file revisions"`
+	Activity     DriveActivityCmd     `cmd:"" name:"activity" help:"Show who changed what (Drive Activity API)"`
+	Grep         DriveGrepCmd         `cmd:"" name:"grep" help:"Search the text of Docs, Sheets, Slides, text files, and PDFs (scanned PDFs without a text layer are skipped)"`
+	Dupes        DriveDupesCmd        `cmd:"" name:"dupes" help:"Find files with identical content"`
+	Du           DriveDuCmd           `cmd:"" name:"du" help:"Show recursive folder sizes"`
+	Trash        DriveTrashCmd        `cmd:"" name:"trash" help:"List or empty the trash"`
+	Restore      DriveRestoreCmd      `cmd:"" name:"restore" help:"Restore files from the trash"`
+	AuditSharing DriveAuditSharingCmd `cmd:"" name:"audit-sharing" help:"Report files shared publicly or outside your domain"`
 }
 
 type DriveLsCmd struct {
-	Max       int64  `name:"max" aliases:"limit" help:"Max results" default:"20"`
-	Page      string `name:"page" aliases:"cursor" help:"Page token"`
-	Query     string `name:"query" help:"Drive query filter"`
-	Parent    string `name:"parent" help:"Folder ID to list (default: root)"`
-	All       bool   `name:"all" aliases:"global" help:"List all accessible files (mutually exclusive with --parent)"`
-	AllDrives bool   `name:"all-drives" help:"Include shared drives (default: true; use --no-all-drives for My Drive only)" default:"true" negatable:"_"`
+	Max             int64  `name:"max" aliases:"limit" help:"Max results" default:"20"`
+	Page            string `name:"page" aliases:"cursor" help:"Page token"`
+	Query           string `name:"query" help:"Drive query filter"`
+	Parent          string `name:"parent" help:"Folder ID to list (default: root)"`
+	All             bool   `name:"all" aliases:"global" help:"List all accessible files (mutually exclusive with --parent)"`
+	AllDrives       bool   `name:"all-drives" help:"Include shared drives (default: true; use --no-all-drives for My Drive only)" default:"true" negatable:"_"`
+	FollowShortcuts bool   `name:"follow-shortcuts" help:"List a shortcut --parent's target folder and show shortcut targets instead of the shortcuts"`
 }
 
 type DriveSearchCmd struct {
-	Query     []string `arg:"" name:"query" help:"Search query"`
-	RawQuery  bool     `name:"raw-query" aliases:"raw" help:"Treat query as Drive query language (pass through; may error if invalid)"`
-	Max       int64    `name:"max" aliases:"limit" help:"Max results" default:"20"`
-	Page      string   `name:"page" aliases:"cursor" help:"Page token"`
-	AllDrives bool     `name:"all-drives" help:"Include shared drives (default: true; use --no-all-drives for My Drive only)" default:"true" negatable:"_"`
+	Query      []string `arg:"" name:"query" optional:"" help:"Search query (optional with --label/--label-field)"`
+	Label      []string `name:"label" help:"Only files with this label applied (label ID; repeatable)"`
+	LabelField []string `name:"label-field" help:"Only files whose label field equals a value: labelId.fieldId=value (repeatable)"`
+	RawQuery   bool     `name:"raw-query" aliases:"raw" help:"Treat query as Drive query language (pass through; may error if invalid)"`
+	Max        int64    `name:"max" aliases:"limit" help:"Max results" default:"20"`
+	Page       string   `name:"page" aliases:"cursor" help:"Page token"`
+	AllDrives  bool     `name:"all-drives" help:"Include shared drives (default: true; use --no-all-drives for My Drive only)" default:"true" negatable:"_"`
 }
 
 type DriveGetCmd struct {
-	FileID string `arg:"" name:"fileId" help:"File ID"`
+	FileID          string `arg:"" name:"fileId" help:"File ID"`
+	FollowShortcuts bool   `name:"follow-shortcuts" help:"If the file is a shortcut, show its target instead"`
 }
 
 func (c *DriveGetCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -120,10 +143,16 @@ func (c *DriveGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if err != nil {
 		return err
 	}
+	if c.FollowShortcuts {
+		fileID, err = resolveDriveShortcut(ctx, svc, fileID)
+		if err != nil {
+			return err
+		}
+	}
 
 	f, err := svc.Files.Get(fileID).
 		SupportsAllDrives(true).
-		Fields("id, name, mimeType, size, modifiedTime, createdTime, parents, webViewLink, description, starred").
+		Fields("id, name, mimeType, size, modifiedTime, createdTime, parents, webViewLink, description, starred, appProperties, folderColorRgb, shortcutDetails(targetId)").
 		Context(ctx).
 		Do()
 	if err != nil {
@@ -144,6 +173,13 @@ func (c *DriveGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		u.Out().Printf("description\t%s", f.Description)
 	}
 	u.Out().Printf("starred\t%t", f.Starred)
+	if f.FolderColorRgb != "" {
+		u.Out().Printf("color\t%s", f.FolderColorRgb)
+	}
+	printDriveAppProperties(u, f.AppProperties)
+	if f.ShortcutDetails != nil && f.ShortcutDetails.TargetId != "" {
+		u.Out().Printf("target\t%s", f.ShortcutDetails.TargetId)
+	}
 	if f.WebViewLink != "" {
 		u.Out().Printf("link\t%s", f.WebViewLink)
 	}
@@ -151,9 +187,12 @@ func (c *DriveGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type DriveDownloadCmd struct {
-	FileID string         `arg:"" name:"fileId" help:"File ID"`
-	Output OutputPathFlag `embed:""`
-	Format string         `name:"format" help:"Export format for Google Docs files: pdf|csv|xlsx|pptx|txt|png|docx|md (default: inferred)"`
+	FileID          string             `arg:"" name:"fileId" help:"File ID"`
+	Output          OutputPathFlag     `embed:""`
+	Format          string             `name:"format" help:"Export format for Google Docs files: pdf|csv|xlsx|pptx|txt|png|docx|md (default: inferred)"`
+	Parallel        int                `name:"parallel" help:"Parallel Range requests for files larger than one chunk (1 = single stream)" default:"4"`
+	Transfer        DriveTransferFlags `embed:""`
+	FollowShortcuts bool               `name:"follow-shortcuts" help:"If the file is a shortcut, download its target"`
 }
 
 func (c *DriveDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -170,15 +209,33 @@ func (c *DriveDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if formatErr := validateDriveDownloadFormatFlag(c.Format); formatErr != nil {
 		return formatErr
 	}
+	if c.Parallel < 0 {
+		return usage("--parallel must be >= 1")
+	}
+	transfer, err := c.Transfer.options()
+	if err != nil {
+		return err
+	}
+	transfer.parallel = c.Parallel
+	if transfer.parallel == 0 {
+		transfer.parallel = defaultDriveParallelism
+	}
 
 	svc, err := newDriveService(ctx, account)
 	if err != nil {
 		return err
 	}
 
+	if c.FollowShortcuts {
+		fileID, err = resolveDriveShortcut(ctx, svc, fileID)
+		if err != nil {
+			return err
+		}
+	}
+
 	meta, err := svc.Files.Get(fileID).
 		SupportsAllDrives(true).
-		Fields("id, name, mimeType").
+		Fields("id, name, mimeType, size, md5Checksum").
 		Context(ctx).
 		Do()
 	if err != nil {
@@ -196,7 +253,14 @@ func (c *DriveDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	downloadedPath, size, err := downloadDriveFile(ctx, svc, meta, destPath, c.Format)
+	if transfer.parallel > 1 && meta.Size > transfer.chunkSize && !strings.HasPrefix(meta.MimeType, "application/vnd.google-apps.") {
+		transfer.client, err = newDriveHTTPClient(ctx, account)
+		if err != nil {
+			return err
+		}
+	}
+
+	downloadedPath, size, err := downloadDriveFileWithOptions(ctx, svc, meta, destPath, c.Format, transfer)
 	if err != nil {
 		return err
 	}
@@ -226,20 +290,40 @@ func (c *DriveCopyCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type DriveUploadCmd struct {
-	LocalPath           string `arg:"" name:"localPath" help:"Path to local file"`
-	Name                string `name:"name" help:"Override filename (create) or rename target (replace)"`
-	Parent              string `name:"parent" help:"Destination folder ID (create only)"`
-	ReplaceFileID       string `name:"replace" help:"Replace the content of an existing Drive file ID (preserves shared link/permissions)"`
-	MimeType            string `name:"mime-type" help:"Override MIME type inference"`
-	KeepRevisionForever bool   `name:"keep-revision-forever" help:"Keep the new head revision forever (binary files only)"`
-	Convert             bool   `name:"convert" help:"Auto-convert to native Google format based on file extension (create only)"`
-	ConvertTo           string `name:"convert-to" help:"Convert to a specific Google format: doc|sheet|slides (create only)"`
-	KeepFrontmatter     bool   `name:"keep-frontmatter" help:"Keep YAML frontmatter (---) in Markdown when converting to a Google Doc (--convert or --convert-to doc; default: strip)"`
+	LocalPath           string             `arg:"" name:"localPath" help:"Path to local file"`
+	Name                string             `name:"name" help:"Override filename (create) or rename target (replace)"`
+	Parent              string             `name:"parent" help:"Destination folder ID (create only)"`
+	ReplaceFileID       string             `name:"replace" help:"Replace the content of an existing Drive file ID (preserves shared link/permissions)"`
+	MimeType            string             `name:"mime-type" help:"Override MIME type inference"`
+	KeepRevisionForever bool               `name:"keep-revision-forever" help:"Keep the new head revision forever (binary files only)"`
+	Convert             bool               `name:"convert" help:"Auto-convert to native Google format based on file extension (create only)"`
+	ConvertTo           string             `name:"convert-to" help:"Convert to a specific Google format: doc|sheet|slides (create only)"`
+	KeepFrontmatter     bool               `name:"keep-frontmatter" help:"Keep YAML frontmatter (---) in Markdown when converting to a Google Doc (--convert or --convert-to doc; default: strip)"`
+	Resume              bool               `name:"resume" help:"Upload through a resumable session saved on disk; rerun after an interruption to continue where it stopped"`
+	Transfer            DriveTransferFlags `embed:""`
 }
 
 type DriveMkdirCmd struct {
-	Name   string `arg:"" name:"name" help:"Folder name"`
-	Parent string `name:"parent" help:"Parent folder ID"`
+	Name        string `arg:"" name:"name" help:"Folder name"`
+	Parent      string `name:"parent" help:"Parent folder ID"`
+	FolderColor string `name:"folder-color" help:"Folder color as #rrggbb (Drive snaps it to the nearest palette color; --color #rrggbb also works)"`
+}
+
+// applyDriveMkdirColor lets `drive mkdir --color #rrggbb` set the folder
+// color. The global --color (auto|always|never) owns that flag name, so Kong
+// cannot declare it on mkdir; a hex value there is moved to --folder-color.
+func applyDriveMkdirColor(kctx *kong.Context, flags *RootFlags) {
+	if !strings.HasPrefix(strings.TrimSpace(flags.Color), "#") || kctx.Selected() == nil {
+		return
+	}
+	cmd, ok := kctx.Selected().Target.Addr().Interface().(*DriveMkdirCmd)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(cmd.FolderColor) == "" {
+		cmd.FolderColor = flags.Color
+	}
+	flags.Color = colorAuto
 }
 
 func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -253,6 +337,10 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if name == "" {
 		return usage("empty name")
 	}
+	color, err := normalizeDriveFolderColor(c.FolderColor)
+	if err != nil {
+		return err
+	}
 
 	svc, err := newDriveService(ctx, account)
 	if err != nil {
@@ -260,8 +348,9 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	f := &drive.File{
-		Name:     name,
-		MimeType: "application/vnd.google-apps.folder",
+		Name:           name,
+		MimeType:       driveMimeFolder,
+		FolderColorRgb: color,
 	}
 	if strings.TrimSpace(c.Parent) != "" {
 		f.Parents = []string{strings.TrimSpace(c.Parent)}
@@ -269,7 +358,7 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	created, err := svc.Files.Create(f).
 		SupportsAllDrives(true).
-		Fields("id, name, webViewLink").
+		Fields("id, name, webViewLink, folderColorRgb").
 		Context(ctx).
 		Do()
 	if err != nil {
@@ -282,6 +371,9 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	u.Out().Printf("id\t%s", created.Id)
 	u.Out().Printf("name\t%s", created.Name)
+	if created.FolderColorRgb != "" {
+		u.Out().Printf("color\t%s", created.FolderColorRgb)
+	}
 	if created.WebViewLink != "" {
 		u.Out().Printf("link\t%s", created.WebViewLink)
 	}
@@ -289,7 +381,8 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type DriveDeleteCmd struct {
-	FileID    string `arg:"" name:"fileId" help:"File ID"`
+	FileID    string `arg:"" name:"fileId" optional:"" help:"File ID (omit when using --query)"`
+	Query     string `name:"query" help:"Drive query selecting files to delete in bulk (e.g. \"name contains 'tmp'\")"`
 	Permanent bool   `name:"permanent" help:"Permanently delete instead of moving to trash" default:"false"`
 }
 
@@ -300,6 +393,12 @@ func (c *DriveDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 	fileID := strings.TrimSpace(c.FileID)
+	if strings.TrimSpace(c.Query) != "" {
+		if fileID != "" {
+			return usage("fileId cannot be combined with --query")
+		}
+		return c.runQuery(ctx, flags)
+	}
 	if fileID == "" {
 		return usage("empty fileId")
 	}
@@ -345,8 +444,9 @@ func (c *DriveDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type DriveMoveCmd struct {
-	FileID string `arg:"" name:"fileId" help:"File ID"`
-	Parent string `name:"parent" help:"New parent folder ID (required)"`
+	FileID       string `arg:"" name:"fileId" help:"File ID"`
+	Parent       string `name:"parent" help:"New parent folder ID (required)"`
+	CopyFallback bool   `name:"copy-fallback" help:"When Drive refuses to move a folder between My Drive and a shared drive, copy the tree there (with permissions) and trash the original"`
 }
 
 func (c *DriveMoveCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -371,7 +471,7 @@ func (c *DriveMoveCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	meta, err := svc.Files.Get(fileID).
 		SupportsAllDrives(true).
-		Fields("id, name, parents").
+		Fields("id, name, mimeType, parents, driveId").
 		Context(ctx).
 		Do()
 	if err != nil {
@@ -388,7 +488,13 @@ func (c *DriveMoveCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	updated, err := call.Context(ctx).Do()
 	if err != nil {
-		return err
+		if meta.MimeType != driveMimeFolder || !isDriveCrossDriveMove(ctx, svc, meta, parent, err) {
+			return err
+		}
+		if !c.CopyFallback {
+			return fmt.Errorf("%w (Drive cannot move this folder between drives; rerun with --copy-fallback to copy it and trash the original)", err)
+		}
+		return moveDriveFolderByCopy(ctx, flags, svc, meta, parent)
 	}
 
 	if outfmt.IsJSON(ctx) {
@@ -641,13 +747,20 @@ func (c *DriveUnshareCmd) Run(ctx context.Context, flags *RootFlags) error {
 	)
 }
 
+// DrivePermissionsCmd lists permissions on one file (the default) or applies
+// a bulk revoke/downgrade plan.
 type DrivePermissionsCmd struct {
+	List DrivePermissionsListCmd `cmd:"" name:"list" aliases:"ls" default:"withargs" help:"List permissions on a file"`
+	Bulk DrivePermissionsBulkCmd `cmd:"" name:"bulk" help:"Revoke or downgrade permissions from a plan file (CSV or JSON)"`
+}
+
+type DrivePermissionsListCmd struct {
 	FileID string `arg:"" name:"fileId" help:"File ID"`
 	Max    int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
 	Page   string `name:"page" aliases:"cursor" help:"Page token"`
 }
 
-func (c *DrivePermissionsCmd) Run(ctx context.Context, flags *RootFlags) error {
+func (c *DrivePermissionsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
@@ -825,7 +938,7 @@ func escapeDriveQueryString(s string) string {
 }
 
 func driveType(mimeType string) string {
-	if mimeType == "application/vnd.google-apps.folder" {
+	if mimeType == driveMimeFolder {
 		return "folder"
 	}
 	return strFile
@@ -904,6 +1017,14 @@ func guessMimeType(path string) string {
 }
 
 func downloadDriveFile(ctx context.Context, svc *drive.Service, meta *drive.File, destPath string, format string) (string, int64, error) {
+	return downloadDriveFileWithOptions(ctx, svc, meta, destPath, format, driveTransferOptions{})
+}
+
+// downloadDriveFileWithOptions downloads or exports meta to destPath. Binary
+// files larger than one chunk are fetched with parallel Range requests when
+// opts carries an HTTP client; the result is checked against md5Checksum when
+// meta has one.
+func downloadDriveFileWithOptions(ctx context.Context, svc *drive.Service, meta *drive.File, destPath string, format string, opts driveTransferOptions) (string, int64, error) {
 	isGoogleDoc := strings.HasPrefix(meta.MimeType, "application/vnd.google-apps.")
 	normalizedFormat := strings.ToLower(strings.TrimSpace(format))
 	if normalizedFormat == formatAuto {
@@ -917,6 +1038,10 @@ func downloadDriveFile(ctx context.Context, svc *drive.Service, meta *drive.File
 		return "", 0, fileFormatErr
 	}
 
+	if !isGoogleDoc && opts.client != nil && opts.parallel > 1 && meta.Size > opts.chunkSize {
+		return downloadDriveFileRanged(ctx, svc, meta, destPath, opts)
+	}
+
 	var (
 		resp    *http.Response
 		outPath string
@@ -956,13 +1081,51 @@ func downloadDriveFile(ctx context.Context, svc *drive.Service, meta *drive.File
 	}
 	defer f.Close()
 
-	n, err := io.Copy(f, resp.Body)
+	total := meta.Size
+	if isGoogleDoc {
+		total = resp.ContentLength
+	}
+	progress := newTransferProgress(opts.progress, meta.Name, total)
+	h := md5.New() //nolint:gosec // matches Drive md5Checksum
+	n, err := io.Copy(io.MultiWriter(f, h), wrapTransferReader(ctx, resp.Body, opts, progress))
+	progress.finish()
 	if err != nil {
 		return "", 0, err
 	}
+	if !isGoogleDoc {
+		if err := verifyDriveChecksum(meta.Md5Checksum, hex.EncodeToString(h.Sum(nil))); err != nil {
+			return "", 0, err
+		}
+	}
 	return outPath, n, nil
 }
 
+func downloadDriveFileRanged(ctx context.Context, svc *drive.Service, meta *drive.File, destPath string, opts driveTransferOptions) (string, int64, error) {
+	f, outPath, err := createUserOutputFile(destPath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	progress := newTransferProgress(opts.progress, meta.Name, meta.Size)
+	err = downloadDriveRanges(ctx, opts.client, driveMediaURL(svc.BasePath, meta.Id), f, meta.Size, opts, progress)
+	progress.finish()
+	if err != nil {
+		return "", 0, err
+	}
+	if err := f.Close(); err != nil {
+		return "", 0, err
+	}
+	sum, err := fileMD5(outPath)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := verifyDriveChecksum(meta.Md5Checksum, sum); err != nil {
+		return "", 0, err
+	}
+	return outPath, meta.Size, nil
+}
+
 func driveFilesListCallWithDriveSupport(call *drive.FilesListCall, allDrives bool) *drive.FilesListCall {
 	// SupportsAllDrives must be set for shared drive file IDs to behave correctly.
 	call = call.SupportsAllDrives(true).IncludeItemsFromAllDrives(allDrives)
@@ -1066,8 +1229,10 @@ func driveExportMimeTypeForFormat(googleMimeType string, format string) (string,
 			return mimePDF, nil
 		case "pptx":
 			return mimePptx, nil
+		case "txt":
+			return mimeTextPlain, nil
 		default:
-			return "", fmt.Errorf("invalid --format %q for Google Slides (use pdf|pptx)", format)
+			return "", fmt.Errorf("invalid --format %q for Google Slides (use pdf|pptx|txt)", format)
 		}
 	case driveMimeGoogleDrawing:
 		switch format {
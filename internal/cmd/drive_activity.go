@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/driveactivity/v2"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var newDriveActivityService = googleapi.NewDriveActivity
+
+// driveActivityActions maps --action values to Drive Activity
+// detail.action_detail_case filter values.
+var driveActivityActions = map[string]string{
+	"create":     "CREATE",
+	"edit":       "EDIT",
+	"move":       "MOVE",
+	"rename":     "RENAME",
+	"delete":     "DELETE",
+	"restore":    "RESTORE",
+	"permission": "PERMISSION_CHANGE",
+	"comment":    "COMMENT",
+	"label":      "APPLIED_LABEL_CHANGE",
+	"settings":   "SETTINGS_CHANGE",
+}
+
+// peopleBatchGetMax is the People API limit on resourceNames per getBatchGet.
+const peopleBatchGetMax = 200
+
+type DriveActivityCmd struct {
+	FileID    string   `arg:"" name:"fileId" optional:"" help:"File or folder ID (default: all activity you can see)"`
+	Folder    string   `name:"folder" help:"Activity for everything under this folder (recursive)"`
+	Since     string   `name:"since" help:"Only activity after this time (age like 7d, date YYYY-MM-DD, or RFC3339)"`
+	Until     string   `name:"until" help:"Only activity before this time (same formats as --since)"`
+	Actions   []string `name:"action" help:"Only these actions (comma-separated): create|edit|move|rename|delete|restore|permission|comment|label|settings" sep:","`
+	Max       int64    `name:"max" aliases:"limit" help:"Max activities per page" default:"50"`
+	Page      string   `name:"page" aliases:"cursor" help:"Page token"`
+	NoResolve bool     `name:"no-resolve" help:"Show people/ IDs instead of resolving actor emails via the directory"`
+}
+
+type driveActivityTarget struct {
+	ID       string `json:"id,omitempty"`
+	Title    string `json:"title"`
+	MimeType string `json:"mimeType,omitempty"`
+	Kind     string `json:"kind"`
+}
+
+type driveActivityEntry struct {
+	Time    string                `json:"time"`
+	Action  string                `json:"action"`
+	Detail  string                `json:"detail,omitempty"`
+	Actors  []string              `json:"actors"`
+	Targets []driveActivityTarget `json:"targets"`
+}
+
+func (c *DriveActivityCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	folderID := normalizeGoogleID(strings.TrimSpace(c.Folder))
+	if fileID != "" && folderID != "" {
+		return usage("use either <fileId> or --folder, not both")
+	}
+	filter, err := c.filter()
+	if err != nil {
+		return err
+	}
+
+	req := &driveactivity.QueryDriveActivityRequest{
+		Filter:    filter,
+		PageSize:  c.Max,
+		PageToken: strings.TrimSpace(c.Page),
+	}
+	if fileID != "" {
+		req.ItemName = "items/" + fileID
+	}
+	if folderID != "" {
+		req.AncestorName = "items/" + folderID
+	}
+
+	svc, err := newDriveActivityService(ctx, account)
+	if err != nil {
+		return err
+	}
+	resp, err := svc.Activity.Query(req).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	names := map[string]string{}
+	if !c.NoResolve {
+		if resolveErr := resolveDriveActivityPeople(ctx, account, resp.Activities, names); resolveErr != nil {
+			u.Err().Printf("Could not resolve actor emails (%v); showing people IDs (use --no-resolve to skip the lookup)", resolveErr)
+		}
+	}
+	entries := make([]driveActivityEntry, 0, len(resp.Activities))
+	for _, a := range resp.Activities {
+		if a != nil {
+			entries = append(entries, driveActivityEntryFor(a, account, names))
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"activities":    entries,
+			"nextPageToken": resp.NextPageToken,
+		})
+	}
+	if len(entries) == 0 {
+		u.Err().Println("No activity")
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "TIME\tACTOR\tACTION\tTARGET\tDETAIL")
+	for _, e := range entries {
+		target := "-"
+		if len(e.Targets) > 0 {
+			target = e.Targets[0].Title
+			if len(e.Targets) > 1 {
+				target += fmt.Sprintf(" (+%d)", len(e.Targets)-1)
+			}
+		}
+		actor := "-"
+		if len(e.Actors) > 0 {
+			actor = strings.Join(e.Actors, ", ")
+		}
+		detail := e.Detail
+		if detail == "" {
+			detail = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			formatDateTime(e.Time), sanitizeTab(actor), e.Action, sanitizeTab(target), sanitizeTab(detail))
+	}
+	printNextPageHint(u, resp.NextPageToken)
+	return nil
+}
+
+// filter builds the Drive Activity filter expression for the time range and
+// --action values.
+func (c *DriveActivityCmd) filter() (string, error) {
+	var clauses []string
+	for _, bound := range []struct {
+		flag, value, op string
+	}{
+		{"--since", c.Since, ">="},
+		{"--until", c.Until, "<"},
+	} {
+		value := strings.TrimSpace(bound.value)
+		if value == "" {
+			continue
+		}
+		parsed, err := timeparse.ParseSince(value, time.Now(), time.Local)
+		if err != nil {
+			return "", usagef("invalid %s %q (use age like 7d, date YYYY-MM-DD, or RFC3339)", bound.flag, value)
+		}
+		clauses = append(clauses, fmt.Sprintf("time %s %q", bound.op, parsed.Time.Format(time.RFC3339)))
+	}
+
+	var cases []string
+	for _, raw := range c.Actions {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		if name == "" {
+			continue
+		}
+		value, ok := driveActivityActions[name]
+		if !ok {
+			return "", usagef("invalid --action %q (use create|edit|move|rename|delete|restore|permission|comment|label|settings)", raw)
+		}
+		cases = append(cases, value)
+	}
+	if len(cases) > 0 {
+		clauses = append(clauses, "detail.action_detail_case:("+strings.Join(cases, " ")+")")
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+// resolveDriveActivityPeople looks up the emails of the people/ IDs the
+// activities mention, through the same directory service as `people get`.
+func resolveDriveActivityPeople(ctx context.Context, account string, activities []*driveactivity.DriveActivity, names map[string]string) error {
+	seen := map[string]bool{}
+	var resources []string
+	addUser := func(user *driveactivity.User) {
+		if user == nil || user.KnownUser == nil || user.KnownUser.IsCurrentUser {
+			return
+		}
+		if name := user.KnownUser.PersonName; name != "" && !seen[name] {
+			seen[name] = true
+			resources = append(resources, name)
+		}
+	}
+	for _, a := range activities {
+		if a == nil {
+			continue
+		}
+		for _, actor := range a.Actors {
+			if actor == nil {
+				continue
+			}
+			addUser(actor.User)
+			if actor.Impersonation != nil {
+				addUser(actor.Impersonation.ImpersonatedUser)
+			}
+		}
+		if d := a.PrimaryActionDetail; d != nil && d.PermissionChange != nil {
+			for _, p := range append(append([]*driveactivity.Permission{}, d.PermissionChange.AddedPermissions...), d.PermissionChange.RemovedPermissions...) {
+				if p != nil {
+					addUser(p.User)
+				}
+			}
+		}
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+	sort.Strings(resources)
+
+	svc, err := newPeopleDirectoryService(ctx, account)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(resources); start += peopleBatchGetMax {
+		batch := resources[start:min(len(resources), start+peopleBatchGetMax)]
+		resp, err := svc.People.GetBatchGet().ResourceNames(batch...).PersonFields("names,emailAddresses").Context(ctx).Do()
+		if err != nil {
+			return wrapPeopleAPIError(err)
+		}
+		for _, r := range resp.Responses {
+			if r == nil || r.Person == nil {
+				continue
+			}
+			label := firstNonEmpty(primaryEmail(r.Person), primaryName(r.Person))
+			if label != "" {
+				names[r.RequestedResourceName] = label
+			}
+		}
+	}
+	return nil
+}
+
+func driveActivityEntryFor(a *driveactivity.DriveActivity, account string, names map[string]string) driveActivityEntry {
+	e := driveActivityEntry{
+		Time:    a.Timestamp,
+		Actors:  []string{},
+		Targets: []driveActivityTarget{},
+	}
+	if e.Time == "" && a.TimeRange != nil {
+		e.Time = a.TimeRange.EndTime
+	}
+	e.Action, e.Detail = describeDriveActivity(a.PrimaryActionDetail, account, names)
+	for _, actor := range a.Actors {
+		if label := driveActivityActorLabel(actor, account, names); label != "" {
+			e.Actors = append(e.Actors, label)
+		}
+	}
+	for _, t := range a.Targets {
+		if target, ok := driveActivityTargetFor(t); ok {
+			e.Targets = append(e.Targets, target)
+		}
+	}
+	return e
+}
+
+func driveActivityActorLabel(actor *driveactivity.Actor, account string, names map[string]string) string {
+	switch {
+	case actor == nil:
+		return ""
+	case actor.User != nil:
+		return driveActivityUserLabel(actor.User, account, names)
+	case actor.Impersonation != nil:
+		return driveActivityUserLabel(actor.Impersonation.ImpersonatedUser, account, names) + " (impersonated)"
+	case actor.Administrator != nil:
+		return "administrator"
+	case actor.Anonymous != nil:
+		return "anonymous"
+	case actor.System != nil:
+		return "system"
+	default:
+		return "unknown"
+	}
+}
+
+func driveActivityUserLabel(user *driveactivity.User, account string, names map[string]string) string {
+	switch {
+	case user == nil:
+		return "unknown user"
+	case user.KnownUser != nil && user.KnownUser.IsCurrentUser:
+		return account
+	case user.KnownUser != nil:
+		if label := names[user.KnownUser.PersonName]; label != "" {
+			return label
+		}
+		return firstNonEmpty(user.KnownUser.PersonName, "unknown user")
+	case user.DeletedUser != nil:
+		return "deleted user"
+	default:
+		return "unknown user"
+	}
+}
+
+func driveActivityTargetFor(t *driveactivity.Target) (driveActivityTarget, bool) {
+	switch {
+	case t == nil:
+		return driveActivityTarget{}, false
+	case t.DriveItem != nil:
+		return driveActivityTarget{
+			ID:       strings.TrimPrefix(t.DriveItem.Name, "items/"),
+			Title:    t.DriveItem.Title,
+			MimeType: t.DriveItem.MimeType,
+			Kind:     "item",
+		}, true
+	case t.Drive != nil:
+		return driveActivityTarget{ID: driveActivityDriveID(t.Drive.Name), Title: t.Drive.Title, Kind: "drive"}, true
+	case t.FileComment != nil && t.FileComment.Parent != nil:
+		return driveActivityTarget{
+			ID:       strings.TrimPrefix(t.FileComment.Parent.Name, "items/"),
+			Title:    t.FileComment.Parent.Title,
+			MimeType: t.FileComment.Parent.MimeType,
+			Kind:     "comment",
+		}, true
+	default:
+		return driveActivityTarget{}, false
+	}
+}
+
+// driveActivityDriveID strips the collection prefix from a shared drive
+// resource name ("drives/ID").
+func driveActivityDriveID(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func driveActivityRefTitle(ref *driveactivity.TargetReference) string {
+	switch {
+	case ref == nil:
+		return ""
+	case ref.DriveItem != nil:
+		return firstNonEmpty(ref.DriveItem.Title, strings.TrimPrefix(ref.DriveItem.Name, "items/"))
+	case ref.Drive != nil:
+		return firstNonEmpty(ref.Drive.Title, driveActivityDriveID(ref.Drive.Name))
+	default:
+		return ""
+	}
+}
+
+func driveActivityRefTitles(refs []*driveactivity.TargetReference) string {
+	titles := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if title := driveActivityRefTitle(ref); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return strings.Join(titles, ", ")
+}
+
+// describeDriveActivity returns the --action name for d and a short
+// human-readable detail.
+func describeDriveActivity(d *driveactivity.ActionDetail, account string, names map[string]string) (string, string) {
+	switch {
+	case d == nil:
+		return "unknown", ""
+	case d.Create != nil:
+		switch {
+		case d.Create.Copy != nil:
+			return "create", "copy of " + firstNonEmpty(driveActivityRefTitle(d.Create.Copy.OriginalObject), "unknown")
+		case d.Create.Upload != nil:
+			return "create", "upload"
+		default:
+			return "create", ""
+		}
+	case d.Edit != nil:
+		return "edit", ""
+	case d.Move != nil:
+		var parts []string
+		if from := driveActivityRefTitles(d.Move.RemovedParents); from != "" {
+			parts = append(parts, "from "+from)
+		}
+		if to := driveActivityRefTitles(d.Move.AddedParents); to != "" {
+			parts = append(parts, "to "+to)
+		}
+		return "move", strings.Join(parts, " ")
+	case d.Rename != nil:
+		return "rename", fmt.Sprintf("%s -> %s", d.Rename.OldTitle, d.Rename.NewTitle)
+	case d.Delete != nil:
+		if d.Delete.Type == "PERMANENT_DELETE" {
+			return "delete", "permanent"
+		}
+		return "delete", "trash"
+	case d.Restore != nil:
+		return "restore", "untrash"
+	case d.PermissionChange != nil:
+		var parts []string
+		for _, p := range d.PermissionChange.AddedPermissions {
+			parts = append(parts, "+"+driveActivityPermissionLabel(p, account, names))
+		}
+		for _, p := range d.PermissionChange.RemovedPermissions {
+			parts = append(parts, "-"+driveActivityPermissionLabel(p, account, names))
+		}
+		return "permission", strings.Join(parts, ", ")
+	case d.Comment != nil:
+		if d.Comment.Post != nil {
+			return "comment", strings.ToLower(d.Comment.Post.Subtype)
+		}
+		if d.Comment.Suggestion != nil {
+			return "comment", "suggestion " + strings.ToLower(d.Comment.Suggestion.Subtype)
+		}
+		return "comment", ""
+	case d.AppliedLabelChange != nil:
+		return "label", ""
+	case d.SettingsChange != nil:
+		return "settings", ""
+	case d.DlpChange != nil:
+		return "dlp", strings.ToLower(d.DlpChange.Type)
+	case d.Reference != nil:
+		return "reference", strings.ToLower(d.Reference.Type)
+	default:
+		return "unknown", ""
+	}
+}
+
+func driveActivityPermissionLabel(p *driveactivity.Permission, account string, names map[string]string) string {
+	if p == nil {
+		return "?"
+	}
+	var who string
+	switch {
+	case p.User != nil:
+		who = driveActivityUserLabel(p.User, account, names)
+	case p.Group != nil:
+		who = firstNonEmpty(p.Group.Email, p.Group.Title)
+	case p.Domain != nil:
+		who = p.Domain.Name
+	case p.Anyone != nil:
+		who = "anyone"
+	default:
+		who = "?"
+	}
+	return strings.ToLower(p.Role) + ":" + who
+}
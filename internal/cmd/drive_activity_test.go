@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/driveactivity/v2"
+	"google.golang.org/api/option"
+	"google.golang.org/api/people/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+const driveActivityFixture = `{"activities": [
+  {"timestamp": "2026-03-02T10:00:00Z",
+   "primaryActionDetail": {"move": {"removedParents": [{"driveItem": {"name": "items/f1", "title": "Shared"}}], "addedParents": [{"driveItem": {"name": "items/f2", "title": "Archive"}}]}},
+   "actors": [{"user": {"knownUser": {"personName": "people/111"}}}],
+   "targets": [{"driveItem": {"name": "items/doc1", "title": "Plan", "mimeType": "application/vnd.google-apps.document"}}]},
+  {"timeRange": {"startTime": "2026-03-01T09:00:00Z", "endTime": "2026-03-01T09:30:00Z"},
+   "primaryActionDetail": {"permissionChange": {"addedPermissions": [{"role": "EDITOR", "user": {"knownUser": {"personName": "people/222"}}}], "removedPermissions": [{"role": "VIEWER", "anyone": {}}]}},
+   "actors": [{"user": {"knownUser": {"isCurrentUser": true}}}],
+   "targets": [{"driveItem": {"name": "items/doc1", "title": "Plan"}}, {"driveItem": {"name": "items/doc2", "title": "Budget"}}]},
+  {"timestamp": "2026-02-28T08:00:00Z",
+   "primaryActionDetail": {"rename": {"oldTitle": "Draft", "newTitle": "Plan"}},
+   "actors": [{"administrator": {}}],
+   "targets": [{"drive": {"name": "drives/d1", "title": "Eng"}}]}
+], "nextPageToken": "tok2"}`
+
+func stubDriveActivity(t *testing.T, peopleErr bool) (*[]map[string]any, *[]string) {
+	t.Helper()
+	var bodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(driveActivityFixture))
+	}))
+	t.Cleanup(srv.Close)
+	activitySvc, err := driveactivity.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("driveactivity.NewService: %v", err)
+	}
+	origActivity := newDriveActivityService
+	t.Cleanup(func() { newDriveActivityService = origActivity })
+	newDriveActivityService = func(context.Context, string) (*driveactivity.Service, error) { return activitySvc, nil }
+
+	var lookups []string
+	peopleSvc, closePeople := newPeopleService(t, func(w http.ResponseWriter, r *http.Request) {
+		lookups = append(lookups, r.URL.Query()["resourceNames"]...)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"responses": [
+		  {"requestedResourceName": "people/111", "person": {"emailAddresses": [{"value": "ann@example.com"}]}},
+		  {"requestedResourceName": "people/222", "person": {"names": [{"displayName": "Bob"}], "emailAddresses": [{"value": "bob@example.com"}]}}
+		]}`))
+	})
+	t.Cleanup(closePeople)
+	origDirectory := newPeopleDirectoryService
+	t.Cleanup(func() { newPeopleDirectoryService = origDirectory })
+	newPeopleDirectoryService = func(context.Context, string) (*people.Service, error) {
+		if peopleErr {
+			return nil, errors.New("no directory access")
+		}
+		return peopleSvc, nil
+	}
+	return &bodies, &lookups
+}
+
+func TestDriveActivityCmd_JSON(t *testing.T) {
+	bodies, lookups := stubDriveActivity(t, false)
+
+	got := runDriveJSON(t, &DriveActivityCmd{}, "--folder", "f1", "--since", "2026-02-01", "--action", "move,permission", "--max", "10")
+	req := (*bodies)[0]
+	if req["ancestorName"] != "items/f1" || req["itemName"] != nil || req["pageSize"] != float64(10) {
+		t.Fatalf("unexpected request: %#v", req)
+	}
+	if filter, _ := req["filter"].(string); !strings.HasPrefix(filter, `time >= "2026-02-`) || !strings.HasSuffix(filter, " AND detail.action_detail_case:(MOVE PERMISSION_CHANGE)") {
+		t.Fatalf("unexpected filter: %q", filter)
+	}
+	if strings.Join(*lookups, ",") != "people/111,people/222" {
+		t.Fatalf("unexpected people lookups: %v", *lookups)
+	}
+
+	var parsed struct {
+		Activities    []driveActivityEntry `json:"activities"`
+		NextPageToken string               `json:"nextPageToken"`
+	}
+	b, _ := json.Marshal(got)
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if len(parsed.Activities) != 3 || parsed.NextPageToken != "tok2" {
+		t.Fatalf("unexpected activities: %s", b)
+	}
+	move, perm, rename := parsed.Activities[0], parsed.Activities[1], parsed.Activities[2]
+	if move.Action != "move" || move.Detail != "from Shared to Archive" || move.Actors[0] != "ann@example.com" || move.Targets[0].ID != "doc1" {
+		t.Fatalf("unexpected move: %#v", move)
+	}
+	if perm.Action != "permission" || perm.Detail != "+editor:bob@example.com, -viewer:anyone" || perm.Actors[0] != "a@b.com" || perm.Time != "2026-03-01T09:30:00Z" || len(perm.Targets) != 2 {
+		t.Fatalf("unexpected permission change: %#v", perm)
+	}
+	if rename.Action != "rename" || rename.Detail != "Draft -> Plan" || rename.Actors[0] != "administrator" || rename.Targets[0].Kind != "drive" || rename.Targets[0].ID != "d1" {
+		t.Fatalf("unexpected rename: %#v", rename)
+	}
+}
+
+func TestDriveActivityCmd_TextWithoutDirectory(t *testing.T) {
+	bodies, _ := stubDriveActivity(t, true)
+
+	ctx := newQuietUIContext(t)
+	out := captureStdout(t, func() {
+		if err := runKong(t, &DriveActivityCmd{}, []string{"doc1"}, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("activity: %v", err)
+		}
+	})
+	if (*bodies)[0]["itemName"] != "items/doc1" || (*bodies)[0]["filter"] != nil {
+		t.Fatalf("unexpected request: %#v", (*bodies)[0])
+	}
+	for _, want := range []string{
+		"TIME", "2026-03-02 10:00  people/111",
+		"move", "Plan", "from Shared to Archive",
+		"Plan (+1)", "+editor:people/222, -viewer:anyone",
+		"administrator", "Eng",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	jsonCtx := outfmt.WithMode(ctx, outfmt.Mode{JSON: true})
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"doc1", "--folder", "f1"}, "not both"},
+		{[]string{"--action", "share"}, "invalid --action"},
+		{[]string{"--since", "whenever"}, "invalid --since"},
+	} {
+		if err := runKong(t, &DriveActivityCmd{}, tc.args, jsonCtx, &RootFlags{Account: "a@b.com"}); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+}
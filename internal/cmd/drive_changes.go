@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveChangeAdded    = "added"
+	driveChangeEdited   = "edited"
+	driveChangeMetadata = "metadata"
+	driveChangeSharing  = "permissions"
+	driveChangeTrashed  = "trashed"
+	driveChangeRemoved  = "removed"
+	driveChangeDrive    = "drive"
+
+	driveChangesFields = "nextPageToken,newStartPageToken,changes(changeType,time,removed,fileId,driveId," +
+		"file(id,name,mimeType,trashed,createdTime,modifiedTime,parents,size,webViewLink,driveId,shared,permissionIds," +
+		"lastModifyingUser(emailAddress,displayName))," +
+		"drive(id,name))"
+)
+
+type DriveChangesCmd struct {
+	List  DriveChangesListCmd  `cmd:"" name:"list" default:"withargs" aliases:"ls" help:"List changes since the last run (advances the stored page token)"`
+	Watch DriveChangesWatchCmd `cmd:"" name:"watch" help:"Push notifications for Drive changes"`
+}
+
+type DriveChangesWatchCmd struct {
+	Serve DriveChangesWatchServeCmd `cmd:"" name:"serve" help:"Register a changes channel and emit changes on each notification"`
+}
+
+type driveChangesState struct {
+	Account      string `json:"account"`
+	DriveID      string `json:"driveId,omitempty"`
+	PageToken    string `json:"pageToken,omitempty"`
+	LastSyncAtMs int64  `json:"lastSyncAtMs,omitempty"`
+	ChannelID    string `json:"channelId,omitempty"`
+	ResourceID   string `json:"resourceId,omitempty"`
+	Address      string `json:"address,omitempty"`
+	Token        string `json:"token,omitempty"`
+	ExpirationMs int64  `json:"expirationMs,omitempty"`
+	UpdatedAtMs  int64  `json:"updatedAtMs,omitempty"`
+	// Permissions maps file IDs seen in earlier syncs to their sorted
+	// permission IDs, so a later sharing change can be told apart.
+	Permissions map[string]string `json:"permissions,omitempty"`
+}
+
+type driveChangesStore = jsonStateStore[driveChangesState]
+
+func driveChangesStatePath(account, driveID string) (string, error) {
+	dir, err := config.EnsureDriveChangesDir()
+	if err != nil {
+		return "", err
+	}
+	scope := "all"
+	if driveID != "" {
+		scope = sanitizeAccountForPath(driveID)
+	}
+	return filepath.Join(dir, sanitizeAccountForPath(account)+"--"+scope+".json"), nil
+}
+
+// openDriveChangesStore loads the page token (and push channel) for one
+// account and drive scope, or starts an empty one.
+func openDriveChangesStore(account, driveID string) (*driveChangesStore, error) {
+	path, err := driveChangesStatePath(account, driveID)
+	if err != nil {
+		return nil, err
+	}
+	return openJSONStateStore(path, driveChangesState{Account: account, DriveID: driveID}, func(st *driveChangesState, ms int64) {
+		st.UpdatedAtMs = ms
+	})
+}
+
+type driveChange struct {
+	Change       string   `json:"change"`
+	Time         string   `json:"time,omitempty"`
+	FileID       string   `json:"fileId,omitempty"`
+	DriveID      string   `json:"driveId,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	MimeType     string   `json:"mimeType,omitempty"`
+	Parents      []string `json:"parents,omitempty"`
+	Size         int64    `json:"size,omitempty"`
+	ModifiedTime string   `json:"modifiedTime,omitempty"`
+	ModifiedBy   string   `json:"modifiedBy,omitempty"`
+	Shared       bool     `json:"shared,omitempty"`
+	WebViewLink  string   `json:"webViewLink,omitempty"`
+}
+
+type driveChangesResult struct {
+	Changes   []driveChange
+	PageToken string
+	// Seeded is set when there was no stored token: only a start token was recorded.
+	Seeded bool
+}
+
+// syncDriveChanges lists all changes since the stored page token. With save,
+// the new start token is persisted; without a stored token it only records
+// the current one.
+func syncDriveChanges(ctx context.Context, svc *drive.Service, store *driveChangesStore, startToken string, pageSize int64, save bool) (*driveChangesResult, error) {
+	state := store.Get()
+	token := startToken
+	if token == "" {
+		token = state.PageToken
+	}
+	now := time.Now()
+	if token == "" {
+		call := svc.Changes.GetStartPageToken().SupportsAllDrives(true).Context(ctx)
+		if state.DriveID != "" {
+			call = call.DriveId(state.DriveID)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		if save {
+			if err := store.Update(func(st *driveChangesState) error {
+				st.PageToken = resp.StartPageToken
+				st.LastSyncAtMs = now.UnixMilli()
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		return &driveChangesResult{PageToken: resp.StartPageToken, Seeded: true}, nil
+	}
+
+	since := time.UnixMilli(state.LastSyncAtMs)
+	perms := make(map[string]string, len(state.Permissions))
+	maps.Copy(perms, state.Permissions)
+	result := &driveChangesResult{}
+	for {
+		call := svc.Changes.List(token).
+			PageSize(pageSize).
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			IncludeRemoved(true).
+			Fields(driveChangesFields).
+			Context(ctx)
+		if state.DriveID != "" {
+			call = call.DriveId(state.DriveID)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, ch := range resp.Changes {
+			if ch != nil {
+				result.Changes = append(result.Changes, classifyDriveChange(ch, since, perms))
+			}
+		}
+		if resp.NewStartPageToken != "" {
+			result.PageToken = resp.NewStartPageToken
+			break
+		}
+		if resp.NextPageToken == "" {
+			return nil, errors.New("changes.list returned no page token")
+		}
+		token = resp.NextPageToken
+	}
+	if save {
+		if err := store.Update(func(st *driveChangesState) error {
+			st.PageToken = result.PageToken
+			st.LastSyncAtMs = now.UnixMilli()
+			st.Permissions = perms
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// classifyDriveChange labels a change relative to the previous sync time:
+// created after it -> added, content modified after it -> edited, a
+// different permission set than last seen -> permissions, otherwise a
+// metadata-only change such as a rename or move. perms holds the permission
+// IDs last seen per file and is updated in place.
+func classifyDriveChange(ch *drive.Change, since time.Time, perms map[string]string) driveChange {
+	out := driveChange{
+		Change:  driveChangeMetadata,
+		Time:    ch.Time,
+		FileID:  ch.FileId,
+		DriveID: ch.DriveId,
+	}
+	if ch.ChangeType == driveChangeDrive {
+		out.Change = driveChangeDrive
+		if ch.Drive != nil {
+			out.Name = ch.Drive.Name
+		}
+		if ch.Removed {
+			out.Change = driveChangeRemoved
+		}
+		return out
+	}
+	if ch.Removed || ch.File == nil {
+		delete(perms, ch.FileId)
+		out.Change = driveChangeRemoved
+		return out
+	}
+	f := ch.File
+	ids := append([]string(nil), f.PermissionIds...)
+	slices.Sort(ids)
+	permKey := strings.Join(ids, ",")
+	prevPerms, known := perms[ch.FileId]
+	perms[ch.FileId] = permKey
+	out.Name = f.Name
+	out.MimeType = f.MimeType
+	out.Parents = f.Parents
+	out.Size = f.Size
+	out.ModifiedTime = f.ModifiedTime
+	out.WebViewLink = f.WebViewLink
+	out.Shared = f.Shared
+	if f.LastModifyingUser != nil {
+		out.ModifiedBy = f.LastModifyingUser.EmailAddress
+	}
+	switch {
+	case f.Trashed:
+		out.Change = driveChangeTrashed
+	case timeAtOrAfter(f.CreatedTime, since):
+		out.Change = driveChangeAdded
+	case timeAtOrAfter(f.ModifiedTime, since):
+		out.Change = driveChangeEdited
+	case known && prevPerms != permKey:
+		out.Change = driveChangeSharing
+	}
+	return out
+}
+
+func timeAtOrAfter(value string, since time.Time) bool {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return false
+	}
+	return !t.Before(since)
+}
+
+type DriveChangesListCmd struct {
+	Drive string `name:"drive" help:"Shared drive ID (default: everything visible to the account)"`
+	Token string `name:"token" help:"Start from this page token instead of the stored one"`
+	Peek  bool   `name:"peek" help:"Do not advance the stored page token"`
+	Reset bool   `name:"reset" help:"Discard the stored token and start tracking from now"`
+	Max   int64  `name:"max" help:"Changes per API page (1-1000)" default:"100"`
+}
+
+func (c *DriveChangesListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Max <= 0 || c.Max > 1000 {
+		return usage("--max must be between 1 and 1000")
+	}
+	if c.Reset && (c.Peek || strings.TrimSpace(c.Token) != "") {
+		return usage("--reset cannot be combined with --peek or --token")
+	}
+	account, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	driveID := strings.TrimSpace(c.Drive)
+	store, err := openDriveChangesStore(account, driveID)
+	if err != nil {
+		return err
+	}
+	if c.Reset {
+		if err := store.Update(func(st *driveChangesState) error {
+			st.PageToken = ""
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	result, err := syncDriveChanges(ctx, svc, store, strings.TrimSpace(c.Token), c.Max, !c.Peek)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		changes := result.Changes
+		if changes == nil {
+			changes = []driveChange{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"changes":   changes,
+			"pageToken": result.PageToken,
+			"seeded":    result.Seeded,
+		})
+	}
+	if result.Seeded {
+		u.Err().Printf("Tracking changes from now (page token %s); run again to list changes", result.PageToken)
+		return nil
+	}
+	if len(result.Changes) == 0 {
+		u.Err().Println("No changes")
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "TIME\tCHANGE\tID\tNAME\tTYPE")
+	for _, ch := range result.Changes {
+		kind := "-"
+		if ch.MimeType != "" {
+			kind = driveType(ch.MimeType)
+		}
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\n",
+			formatDateTime(ch.Time),
+			ch.Change,
+			ch.FileID,
+			sanitizeTab(ch.Name),
+			kind,
+		)
+	}
+	return nil
+}
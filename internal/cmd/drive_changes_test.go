@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+type fakeDriveChangesAPI struct {
+	t        *testing.T
+	mu       sync.Mutex
+	lists    []string
+	watches  []drive.Channel
+	stops    []drive.Channel
+	expireAt int64
+}
+
+func (f *fakeDriveChangesAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/changes/startPageToken"):
+		requireSupportsAllDrives(f.t, r)
+		_ = json.NewEncoder(w).Encode(map[string]any{"startPageToken": "p1"})
+	case strings.HasSuffix(r.URL.Path, "/changes/watch"):
+		requireQuery(f.t, r, "pageToken", "p1")
+		var ch drive.Channel
+		_ = json.NewDecoder(r.Body).Decode(&ch)
+		f.watches = append(f.watches, ch)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": ch.Id, "resourceId": "res-" + ch.Id, "expiration": strconv.FormatInt(f.expireAt, 10)})
+	case strings.HasSuffix(r.URL.Path, "/channels/stop"):
+		var ch drive.Channel
+		_ = json.NewDecoder(r.Body).Decode(&ch)
+		f.stops = append(f.stops, ch)
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasSuffix(r.URL.Path, "/changes"):
+		requireQuery(f.t, r, "includeItemsFromAllDrives", "true")
+		requireQuery(f.t, r, "includeRemoved", "true")
+		token := r.URL.Query().Get("pageToken")
+		f.lists = append(f.lists, token)
+		switch token {
+		case "p1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"changes": []map[string]any{
+					{"changeType": "file", "fileId": "f1", "time": "2026-01-01T10:00:00Z", "file": map[string]any{
+						"id": "f1", "name": "new.txt", "mimeType": "text/plain", "createdTime": "2999-01-01T00:00:00Z", "modifiedTime": "2999-01-01T00:00:00Z",
+					}},
+					{"changeType": "file", "fileId": "f2", "time": "2026-01-01T10:01:00Z", "file": map[string]any{
+						"id": "f2", "name": "edited.txt", "mimeType": "text/plain", "createdTime": "2020-01-01T00:00:00Z", "modifiedTime": "2999-01-01T00:00:00Z",
+						"lastModifyingUser": map[string]any{"emailAddress": "b@example.com"},
+					}},
+				},
+				"nextPageToken": "p1b",
+			})
+		case "p1b":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"changes": []map[string]any{
+					{"changeType": "file", "fileId": "f3", "time": "2026-01-01T10:02:00Z", "file": map[string]any{
+						"id": "f3", "name": "shared.txt", "mimeType": "text/plain", "createdTime": "2020-01-01T00:00:00Z", "modifiedTime": "2020-01-01T00:00:00Z",
+					}},
+					{"changeType": "file", "fileId": "f4", "time": "2026-01-01T10:03:00Z", "file": map[string]any{
+						"id": "f4", "name": "old.txt", "mimeType": "text/plain", "trashed": true,
+					}},
+					{"changeType": "file", "fileId": "f5", "time": "2026-01-01T10:04:00Z", "removed": true},
+				},
+				"newStartPageToken": "p2",
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"newStartPageToken": token})
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDriveChangesListCmd_SeedsThenLists(t *testing.T) {
+	setWatchTestConfigHome(t)
+	api := &fakeDriveChangesAPI{t: t}
+	svc, closeSrv := newDriveTestService(t, api)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	flags := &RootFlags{Account: "a@b.com"}
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	run := func(args ...string) map[string]any {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := runKong(t, &DriveChangesListCmd{}, args, ctx, flags); err != nil {
+				t.Fatalf("changes %v: %v", args, err)
+			}
+		})
+		var parsed map[string]any
+		if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+			t.Fatalf("json parse: %v\nout=%q", err, out)
+		}
+		return parsed
+	}
+
+	first := run()
+	if first["seeded"] != true || first["pageToken"] != "p1" || len(api.lists) != 0 {
+		t.Fatalf("expected seed only, got %#v (lists=%v)", first, api.lists)
+	}
+
+	peek := run("--peek")
+	if len(peek["changes"].([]any)) != 5 {
+		t.Fatalf("unexpected peek: %#v", peek)
+	}
+	store, err := openDriveChangesStore("a@b.com", "")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if store.Get().PageToken != "p1" {
+		t.Fatalf("expected --peek to keep the token, got %q", store.Get().PageToken)
+	}
+
+	second := run()
+	var changes []string
+	for _, ch := range second["changes"].([]any) {
+		changes = append(changes, ch.(map[string]any)["change"].(string))
+	}
+	if strings.Join(changes, ",") != "added,edited,metadata,trashed,removed" || second["pageToken"] != "p2" {
+		t.Fatalf("unexpected changes: %v (%#v)", changes, second)
+	}
+
+	store, err = openDriveChangesStore("a@b.com", "")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if got := store.Get().Permissions; len(got) != 4 {
+		t.Fatalf("expected permissions of 4 files to be recorded, got %v", got)
+	}
+
+	third := run()
+	if len(third["changes"].([]any)) != 0 || api.lists[len(api.lists)-1] != "p2" {
+		t.Fatalf("expected no changes from p2, got %#v (lists=%v)", third, api.lists)
+	}
+
+	reset := run("--reset")
+	if reset["seeded"] != true {
+		t.Fatalf("expected --reset to reseed, got %#v", reset)
+	}
+}
+
+func TestClassifyDriveChange_Permissions(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	perms := map[string]string{}
+	change := func(permissionIDs ...string) string {
+		return classifyDriveChange(&drive.Change{FileId: "f1", File: &drive.File{
+			Id: "f1", CreatedTime: "2020-01-01T00:00:00Z", ModifiedTime: "2020-01-01T00:00:00Z", PermissionIds: permissionIDs,
+		}}, since, perms).Change
+	}
+
+	// First sighting: nothing to compare against yet.
+	if got := change("p1"); got != driveChangeMetadata {
+		t.Fatalf("first sighting: got %q", got)
+	}
+	if got := change("p1"); got != driveChangeMetadata {
+		t.Fatalf("rename or move: got %q", got)
+	}
+	if got := change("p2", "p1"); got != driveChangeSharing {
+		t.Fatalf("new permission: got %q", got)
+	}
+	if got := change("p1", "p2"); got != driveChangeMetadata {
+		t.Fatalf("same permissions in another order: got %q", got)
+	}
+	classifyDriveChange(&drive.Change{FileId: "f1", Removed: true}, since, perms)
+	if _, ok := perms["f1"]; ok {
+		t.Fatal("expected removed file to be forgotten")
+	}
+}
+
+func TestDriveChangesListCmd_Validation(t *testing.T) {
+	setWatchTestConfigHome(t)
+	flags := &RootFlags{Account: "a@b.com"}
+	cases := [][]string{
+		{"--max", "0"},
+		{"--reset", "--peek"},
+		{"--reset", "--token", "p1"},
+	}
+	for _, args := range cases {
+		if err := runKong(t, &DriveChangesListCmd{}, args, newQuietUIContext(t), flags); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func drivePing(server http.Handler, token, channelID, state string) int {
+	req := httptest.NewRequest(http.MethodPost, "/drive-changes", nil)
+	req.Header.Set("X-Goog-Channel-Token", token)
+	req.Header.Set("X-Goog-Channel-ID", channelID)
+	req.Header.Set("X-Goog-Resource-State", state)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestDriveChangesWatchServer_EmitsChanges(t *testing.T) {
+	setWatchTestConfigHome(t)
+	svc, closeSrv := newDriveTestService(t, &fakeDriveChangesAPI{t: t})
+	defer closeSrv()
+	store, err := openDriveChangesStore("a@b.com", "")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	_ = store.Update(func(st *driveChangesState) error {
+		st.Token = "chan-tok"
+		st.ChannelID = "ch1"
+		st.PageToken = "p1"
+		return nil
+	})
+	var out bytes.Buffer
+	server := &driveChangesWatchServer{
+		account:    "a@b.com",
+		path:       "/drive-changes",
+		pageSize:   100,
+		store:      store,
+		newService: func(context.Context, string) (*drive.Service, error) { return svc, nil },
+		sinks:      watchSinks{&watchWriterSink{w: &out}},
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+
+	if code := drivePing(server, "wrong", "ch1", "change"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad token, got %d", code)
+	}
+	if code := drivePing(server, "chan-tok", "ch1", "sync"); code != http.StatusOK || out.Len() != 0 {
+		t.Fatalf("expected quiet sync ping, got %d %q", code, out.String())
+	}
+	if code := drivePing(server, "chan-tok", "ch1", "change"); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	var payload driveChangesHookPayload
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v (%s)", err, out.String())
+	}
+	if payload.Source != "drive" || payload.ChannelID != "ch1" || payload.PageToken != "p2" || len(payload.Changes) != 5 {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+	if store.Get().PageToken != "p2" {
+		t.Fatalf("expected stored token p2, got %q", store.Get().PageToken)
+	}
+
+	out.Reset()
+	if code := drivePing(server, "chan-tok", "ch1", "change"); code != http.StatusOK || out.Len() != 0 {
+		t.Fatalf("expected no output without changes, got %d %q", code, out.String())
+	}
+	if code := drivePing(server, "chan-tok", "other", "change"); code != http.StatusOK || out.Len() != 0 {
+		t.Fatalf("expected stale channel to be ignored, got %d %q", code, out.String())
+	}
+}
+
+func TestDriveChangesWatchServeCmd_RegistersAndRenewsChannel(t *testing.T) {
+	origListen := listenAndServe
+	t.Cleanup(func() { listenAndServe = origListen })
+	setWatchTestConfigHome(t)
+
+	api := &fakeDriveChangesAPI{t: t, expireAt: time.Now().Add(7 * 24 * time.Hour).UnixMilli()}
+	svc, closeSrv := newDriveTestService(t, api)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	var got *driveChangesWatchServer
+	listenAndServe = func(srv *http.Server) error {
+		got, _ = srv.Handler.(*driveChangesWatchServer)
+		return nil
+	}
+	ctx := newQuietUIContext(t)
+	flags := &RootFlags{Account: "a@b.com"}
+
+	if err := runKong(t, &DriveChangesWatchServeCmd{}, []string{"--address", "https://example.com/drive-changes", "--max", "0"}, ctx, flags); err == nil {
+		t.Fatalf("expected error for --max 0")
+	}
+
+	args := []string{"--address", "https://example.com/drive-changes", "--ttl", "1h"}
+	if err := runKong(t, &DriveChangesWatchServeCmd{}, args, ctx, flags); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if got == nil {
+		t.Fatalf("expected server")
+	}
+	state := got.store.Get()
+	if state.PageToken != "p1" || state.ChannelID == "" || state.Token == "" || len(api.watches) != 1 {
+		t.Fatalf("unexpected state after start: %#v (watches=%d)", state, len(api.watches))
+	}
+	if api.watches[0].Token != state.Token || api.watches[0].Params["ttl"] != "3600" || len(got.sinks) != 1 {
+		t.Fatalf("unexpected channel request: %#v", api.watches[0])
+	}
+
+	if err := runKong(t, &DriveChangesWatchServeCmd{}, args, ctx, flags); err != nil {
+		t.Fatalf("serve again: %v", err)
+	}
+	if len(api.watches) != 1 {
+		t.Fatalf("expected channel reuse, got %d watches", len(api.watches))
+	}
+
+	oldID := state.ChannelID
+	_ = got.store.Update(func(st *driveChangesState) error {
+		st.ExpirationMs = time.Now().Add(time.Minute).UnixMilli()
+		return nil
+	})
+	if err := got.ensureChannel(context.Background()); err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if len(api.watches) != 2 || len(api.stops) != 1 || api.stops[0].Id != oldID {
+		t.Fatalf("unexpected renewal: watches=%d stops=%#v", len(api.watches), api.stops)
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/authclient"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type driveChangesHookPayload struct {
+	Source    string        `json:"source"`
+	Account   string        `json:"account"`
+	DriveID   string        `json:"driveId,omitempty"`
+	ChannelID string        `json:"channelId,omitempty"`
+	PageToken string        `json:"pageToken"`
+	Changes   []driveChange `json:"changes"`
+}
+
+type driveChangesWatchServer struct {
+	account     string
+	path        string
+	ttl         time.Duration
+	renewBefore time.Duration
+	pageSize    int64
+	store       *driveChangesStore
+	newService  func(context.Context, string) (*drive.Service, error)
+	sinks       watchSinks
+	sleep       func(context.Context, time.Duration) error
+	syncMu      sync.Mutex
+	logf        func(string, ...any)
+	warnf       func(string, ...any)
+}
+
+func (s *driveChangesWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !pathMatches(s.path, r.URL.Path) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	state := s.store.Get()
+	got := r.Header.Get("X-Goog-Channel-Token")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(state.Token)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	channelID := r.Header.Get("X-Goog-Channel-ID")
+	if state.ChannelID != "" && channelID != state.ChannelID {
+		s.logf("watch: ignoring notification for channel %s", channelID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Header.Get("X-Goog-Resource-State") == "sync" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	payload, err := s.syncChanges(r.Context())
+	if err != nil {
+		if errors.Is(err, errNoDriveChanges) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		s.warnf("watch: drive changes failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	payload.ChannelID = channelID
+	_ = s.sinks.deliver(r.Context(), s.warnf, payload,
+		"GOG_WATCH_SOURCE=drive",
+		"GOG_WATCH_ACCOUNT="+payload.Account,
+		"GOG_WATCH_DRIVE_ID="+payload.DriveID,
+	)
+}
+
+var errNoDriveChanges = errors.New("no drive changes")
+
+func (s *driveChangesWatchServer) syncChanges(ctx context.Context) (*driveChangesHookPayload, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	svc, err := s.newService(ctx, s.account)
+	if err != nil {
+		return nil, err
+	}
+	result, err := syncDriveChanges(ctx, svc, s.store, "", s.pageSize, true)
+	if err != nil {
+		return nil, err
+	}
+	if result.Seeded || len(result.Changes) == 0 {
+		return nil, errNoDriveChanges
+	}
+	return &driveChangesHookPayload{
+		Source:    "drive",
+		Account:   s.account,
+		DriveID:   s.store.Get().DriveID,
+		PageToken: result.PageToken,
+		Changes:   result.Changes,
+	}, nil
+}
+
+// ensureChannel registers a changes channel when none is stored or the stored
+// one expires within renewBefore, then stops the channel it replaced.
+func (s *driveChangesWatchServer) ensureChannel(ctx context.Context) error {
+	state := s.store.Get()
+	if state.ChannelID != "" && time.Until(time.UnixMilli(state.ExpirationMs)) > s.renewBefore {
+		return nil
+	}
+	svc, err := s.newService(ctx, s.account)
+	if err != nil {
+		return err
+	}
+	channel := &drive.Channel{
+		Id:      uuid.New().String(),
+		Type:    "web_hook",
+		Address: state.Address,
+		Token:   state.Token,
+	}
+	if s.ttl > 0 {
+		channel.Params = map[string]string{"ttl": strconv.FormatInt(int64(s.ttl/time.Second), 10)}
+	}
+	call := svc.Changes.Watch(state.PageToken, channel).
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true).
+		IncludeRemoved(true).
+		Context(ctx)
+	if state.DriveID != "" {
+		call = call.DriveId(state.DriveID)
+	}
+	created, err := call.Do()
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	if err := s.store.Update(func(st *driveChangesState) error {
+		st.ChannelID = created.Id
+		st.ResourceID = created.ResourceId
+		st.ExpirationMs = created.Expiration
+		return nil
+	}); err != nil {
+		return err
+	}
+	s.logf("watch: registered channel %s (expires %s)", created.Id, formatUnixMillis(created.Expiration))
+
+	if state.ChannelID != "" && state.ResourceID != "" {
+		old := &drive.Channel{Id: state.ChannelID, ResourceId: state.ResourceID}
+		if err := svc.Channels.Stop(old).Context(ctx).Do(); err != nil && !isNotFoundAPIError(err) {
+			s.warnf("watch: stop old channel %s: %v", state.ChannelID, err)
+		}
+	}
+	return nil
+}
+
+type DriveChangesWatchServeCmd struct {
+	Address     string         `name:"address" help:"Public HTTPS URL Google should call" required:""`
+	Drive       string         `name:"drive" help:"Shared drive ID (default: everything visible to the account)"`
+	Bind        string         `name:"bind" help:"Bind address" default:"127.0.0.1"`
+	Port        int            `name:"port" help:"Listen port" default:"8791"`
+	Path        string         `name:"path" help:"Notification handler path" default:"/drive-changes"`
+	Token       string         `name:"token" help:"Channel token expected in X-Goog-Channel-Token (default: generated and stored)"`
+	TTL         string         `name:"ttl" help:"Requested channel TTL (seconds or duration; Google caps it)"`
+	RenewBefore string         `name:"renew-before" help:"Renew the channel this long before it expires (seconds or duration)" default:"1h"`
+	Max         int64          `name:"max" help:"Changes per API page (1-1000)" default:"100"`
+	HookURL     string         `name:"hook-url" help:"Webhook URL to forward changes"`
+	HookToken   string         `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret  string         `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature header)"`
+	Sinks       WatchSinkFlags `embed:""`
+}
+
+func (c *DriveChangesWatchServeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	address := strings.TrimSpace(c.Address)
+	if address == "" {
+		return usage("empty --address")
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return usage("--path must start with '/'")
+	}
+	if c.Port <= 0 {
+		return usage("--port must be > 0")
+	}
+	if c.Max <= 0 || c.Max > 1000 {
+		return usage("--max must be between 1 and 1000")
+	}
+	renewBefore, err := parseDurationSeconds(c.RenewBefore)
+	if err != nil {
+		return err
+	}
+	if renewBefore <= 0 {
+		return usage("--renew-before must be > 0")
+	}
+	var ttl time.Duration
+	if strings.TrimSpace(c.TTL) != "" {
+		ttl, err = parseDurationSeconds(c.TTL)
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			return usage("--ttl must be > 0")
+		}
+	}
+	if c.HookSecret != "" && strings.TrimSpace(c.HookURL) == "" {
+		return usage("--hook-secret requires --hook-url")
+	}
+	sinks, err := c.Sinks.build()
+	if err != nil {
+		return err
+	}
+	if hookURL := strings.TrimSpace(c.HookURL); hookURL != "" {
+		sinks = append(sinks, newWatchHookSink(hookURL, c.HookToken, c.HookSecret))
+	}
+	if len(sinks) == 0 {
+		sinks = watchSinks{&watchWriterSink{w: os.Stdout}}
+	}
+
+	store, err := openDriveChangesStore(account, strings.TrimSpace(c.Drive))
+	if err != nil {
+		return err
+	}
+	token := c.Token
+	if token == "" {
+		token = store.Get().Token
+	}
+	if token == "" {
+		token, err = newWatchChannelToken()
+		if err != nil {
+			return err
+		}
+	}
+	if err := store.Update(func(st *driveChangesState) error {
+		if address != st.Address || token != st.Token {
+			st.ExpirationMs = 0
+		}
+		st.Address = address
+		st.Token = token
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	selectedClient := strings.TrimSpace(flags.Client)
+	server := &driveChangesWatchServer{
+		account:     account,
+		path:        c.Path,
+		ttl:         ttl,
+		renewBefore: renewBefore,
+		pageSize:    c.Max,
+		store:       store,
+		newService: func(ctx context.Context, account string) (*drive.Service, error) {
+			if selectedClient != "" {
+				ctx = authclient.WithClient(ctx, selectedClient)
+			}
+			return newDriveService(ctx, account)
+		},
+		sinks: sinks,
+		sleep: watchPollSleep,
+		logf:  u.Err().Printf,
+		warnf: u.Err().Printf,
+	}
+
+	if store.Get().PageToken == "" {
+		if _, err := server.syncChanges(ctx); err != nil && !errors.Is(err, errNoDriveChanges) {
+			return fmt.Errorf("initial sync: %w", err)
+		}
+	}
+	if err := server.ensureChannel(ctx); err != nil {
+		return err
+	}
+	renewCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go runWatchChannelRenewals(renewCtx, server.sleep, renewBefore, func() int64 {
+		return store.Get().ExpirationMs
+	}, server.ensureChannel, server.warnf)
+
+	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
+	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return listenAndServe(httpServer)
+}
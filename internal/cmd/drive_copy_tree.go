@@ -0,0 +1,502 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var errDriveTreeParentMissing = errors.New("parent folder was not copied")
+
+const (
+	defaultDriveCopyTreeConcurrency = 4
+	driveCopyTreePermissionFields   = "permissions(id, type, role, emailAddress, domain, allowFileDiscovery, permissionDetails(inherited))"
+)
+
+type DriveCopyTreeCmd struct {
+	FolderID            string `arg:"" name:"folderId" help:"Source folder ID"`
+	To                  string `name:"to" help:"Destination parent folder ID (My Drive folder or shared drive)" required:""`
+	Name                string `name:"name" help:"Name for the new top-level folder (default: source folder name)"`
+	Concurrency         int    `name:"concurrency" help:"Files copied in parallel" default:"4"`
+	PreservePermissions bool   `name:"preserve-permissions" help:"Recreate sharing permissions (except ownership) on the copies"`
+	RewriteLinks        bool   `name:"rewrite-links" help:"Point links inside copied Google Docs at the copies of files from the same tree"`
+}
+
+type driveCopyTreeFailure struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Step  string `json:"step"`
+	Error string `json:"error"`
+}
+
+type driveCopyTreeResult struct {
+	Folder      *drive.File            `json:"folder"`
+	Folders     int                    `json:"folders"`
+	Files       int                    `json:"files"`
+	Shortcuts   int                    `json:"shortcuts"`
+	Permissions int                    `json:"permissions,omitempty"`
+	LinkedDocs  int                    `json:"linkedDocs,omitempty"`
+	IDs         map[string]string      `json:"ids"`
+	Failed      []driveCopyTreeFailure `json:"failed,omitempty"`
+}
+
+func (c *DriveCopyTreeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	folderID := normalizeGoogleID(strings.TrimSpace(c.FolderID))
+	if folderID == "" {
+		return usage("empty folderId")
+	}
+	to := normalizeGoogleID(strings.TrimSpace(c.To))
+	if to == "" {
+		return usage("missing --to")
+	}
+	if c.Concurrency < 0 {
+		return usage("--concurrency must be >= 1")
+	}
+
+	if err := dryRunExit(ctx, flags, "drive.copy-tree", map[string]any{
+		"folder_id":            folderID,
+		"to":                   to,
+		"name":                 strings.TrimSpace(c.Name),
+		"preserve_permissions": c.PreservePermissions,
+		"rewrite_links":        c.RewriteLinks,
+	}); err != nil {
+		return err
+	}
+
+	account, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	root, err := getDriveTreeRoot(ctx, svc, folderID)
+	if err != nil {
+		return err
+	}
+	tree, err := walkDriveTree(ctx, svc, root)
+	if err != nil {
+		return err
+	}
+
+	copier := &driveTreeCopier{
+		svc:         svc,
+		concurrency: c.Concurrency,
+		ids:         map[string]string{},
+	}
+	if copier.concurrency == 0 {
+		copier.concurrency = defaultDriveCopyTreeConcurrency
+	}
+	if c.RewriteLinks {
+		copier.docs, err = newDocsService(ctx, account)
+		if err != nil {
+			return err
+		}
+	}
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		name = root.Name
+	}
+	result, err := copier.copyTree(ctx, tree, to, name, c.PreservePermissions)
+	if err != nil {
+		return err
+	}
+	return writeDriveCopyTreeResult(ctx, u, result)
+}
+
+// driveTreeCopier recreates a walked tree under a new parent. Folders are
+// created first (breadth-first, so parents exist), then files are copied in
+// parallel; shortcuts, permissions, and link rewrites run last because they
+// need the full old→new ID map.
+type driveTreeCopier struct {
+	svc         *drive.Service
+	docs        *docs.Service
+	concurrency int
+
+	mu     sync.Mutex
+	ids    map[string]string
+	failed []driveCopyTreeFailure
+}
+
+func (tc *driveTreeCopier) newID(oldID string) string {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.ids[oldID]
+}
+
+func (tc *driveTreeCopier) record(oldID, newID string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.ids[oldID] = newID
+}
+
+func (tc *driveTreeCopier) fail(n *driveTreeNode, step string, err error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.failed = append(tc.failed, driveCopyTreeFailure{ID: n.ID, Name: n.Name, Step: step, Error: err.Error()})
+}
+
+func (tc *driveTreeCopier) copyTree(ctx context.Context, tree *driveTreeNode, parent, name string, permissions bool) (*driveCopyTreeResult, error) {
+	top, err := tc.svc.Files.Create(&drive.File{Name: name, MimeType: driveMimeFolder, Parents: []string{parent}}).
+		SupportsAllDrives(true).
+		Fields("id, name, mimeType, webViewLink").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	tc.record(tree.ID, top.Id)
+	result := &driveCopyTreeResult{Folder: top}
+
+	var files, shortcuts, folders []*driveTreeNode
+	for _, n := range tree.flatten()[1:] {
+		switch {
+		case n.folder():
+			folders = append(folders, n)
+		case n.MimeType == driveMimeShortcut:
+			shortcuts = append(shortcuts, n)
+		default:
+			files = append(files, n)
+		}
+	}
+
+	for _, n := range folders {
+		newParent := tc.newID(n.parentID)
+		if newParent == "" {
+			tc.fail(n, "mkdir", errDriveTreeParentMissing)
+			continue
+		}
+		created, err := tc.svc.Files.Create(&drive.File{Name: n.Name, MimeType: driveMimeFolder, Parents: []string{newParent}}).
+			SupportsAllDrives(true).
+			Fields("id").
+			Context(ctx).
+			Do()
+		if err != nil {
+			tc.fail(n, "mkdir", err)
+			continue
+		}
+		tc.record(n.ID, created.Id)
+		result.Folders++
+	}
+
+	errs := runDriveBounded(ctx, tc.concurrency, len(files), func(ctx context.Context, i int) error {
+		n := files[i]
+		newParent := tc.newID(n.parentID)
+		if newParent == "" {
+			return errDriveTreeParentMissing
+		}
+		created, err := tc.svc.Files.Copy(n.ID, &drive.File{Name: n.Name, Parents: []string{newParent}}).
+			SupportsAllDrives(true).
+			Fields("id").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return err
+		}
+		tc.record(n.ID, created.Id)
+		return nil
+	})
+	for i, err := range errs {
+		if err != nil {
+			tc.fail(files[i], "copy", err)
+		} else {
+			result.Files++
+		}
+	}
+
+	for _, n := range shortcuts {
+		if err := tc.copyShortcut(ctx, n); err != nil {
+			tc.fail(n, "shortcut", err)
+			continue
+		}
+		result.Shortcuts++
+	}
+
+	if permissions {
+		result.Permissions = tc.copyPermissions(ctx, tree.flatten())
+	}
+	if tc.docs != nil {
+		result.LinkedDocs = tc.rewriteDocLinks(ctx, files)
+	}
+
+	result.IDs = tc.ids
+	result.Failed = tc.failed
+	return result, nil
+}
+
+// copyShortcut recreates a shortcut, retargeting it at the copy when the
+// target is part of the same tree.
+func (tc *driveTreeCopier) copyShortcut(ctx context.Context, n *driveTreeNode) error {
+	newParent := tc.newID(n.parentID)
+	if newParent == "" {
+		return errDriveTreeParentMissing
+	}
+	target := n.shortcutTarget
+	if mapped := tc.newID(target); mapped != "" {
+		target = mapped
+	}
+	created, err := tc.svc.Files.Create(&drive.File{
+		Name:            n.Name,
+		MimeType:        driveMimeShortcut,
+		Parents:         []string{newParent},
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: target},
+	}).SupportsAllDrives(true).Fields("id").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	tc.record(n.ID, created.Id)
+	return nil
+}
+
+// copyPermissions recreates direct (non-inherited, non-owner) permissions on
+// each copied item without sending notification emails.
+func (tc *driveTreeCopier) copyPermissions(ctx context.Context, nodes []*driveTreeNode) int {
+	var (
+		mu    sync.Mutex
+		total int
+	)
+	errs := runDriveBounded(ctx, tc.concurrency, len(nodes), func(ctx context.Context, i int) error {
+		n := nodes[i]
+		newID := tc.newID(n.ID)
+		if newID == "" {
+			return nil
+		}
+		resp, err := tc.svc.Permissions.List(n.ID).
+			SupportsAllDrives(true).
+			Fields(driveCopyTreePermissionFields).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return err
+		}
+		for _, p := range resp.Permissions {
+			if p == nil || p.Role == "owner" || drivePermissionInherited(p) {
+				continue
+			}
+			perm := &drive.Permission{
+				Type:               p.Type,
+				Role:               p.Role,
+				EmailAddress:       p.EmailAddress,
+				Domain:             p.Domain,
+				AllowFileDiscovery: p.AllowFileDiscovery,
+			}
+			call := tc.svc.Permissions.Create(newID, perm).SupportsAllDrives(true).Fields("id").Context(ctx)
+			if p.Type == driveShareToUser || p.Type == "group" {
+				call = call.SendNotificationEmail(false)
+			}
+			if _, err := call.Do(); err != nil {
+				return fmt.Errorf("%s %s: %w", p.Role, drivePermissionTarget(p), err)
+			}
+			mu.Lock()
+			total++
+			mu.Unlock()
+		}
+		return nil
+	})
+	for i, err := range errs {
+		if err != nil {
+			tc.fail(nodes[i], "permissions", err)
+		}
+	}
+	return total
+}
+
+func drivePermissionInherited(p *drive.Permission) bool {
+	if len(p.PermissionDetails) == 0 {
+		return false
+	}
+	for _, d := range p.PermissionDetails {
+		if d != nil && !d.Inherited {
+			return false
+		}
+	}
+	return true
+}
+
+func drivePermissionTarget(p *drive.Permission) string {
+	switch {
+	case p.EmailAddress != "":
+		return p.EmailAddress
+	case p.Domain != "":
+		return p.Domain
+	default:
+		return p.Type
+	}
+}
+
+// rewriteDocLinks updates hyperlinks in copied Google Docs that point at
+// files from the source tree so they point at the copies instead.
+func (tc *driveTreeCopier) rewriteDocLinks(ctx context.Context, files []*driveTreeNode) int {
+	pairs := make([]string, 0, 2*len(tc.ids))
+	for oldID, newID := range tc.ids {
+		pairs = append(pairs, oldID, newID)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	var docsNodes []*driveTreeNode
+	for _, n := range files {
+		if n.MimeType == driveMimeGoogleDoc && tc.ids[n.ID] != "" {
+			docsNodes = append(docsNodes, n)
+		}
+	}
+	var (
+		mu      sync.Mutex
+		changed int
+	)
+	errs := runDriveBounded(ctx, tc.concurrency, len(docsNodes), func(ctx context.Context, i int) error {
+		docID := tc.newID(docsNodes[i].ID)
+		doc, err := tc.docs.Documents.Get(docID).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		var reqs []*docs.Request
+		if doc.Body != nil {
+			reqs = collectDocLinkRewrites(doc.Body.Content, replacer, reqs)
+		}
+		if len(reqs) == 0 {
+			return nil
+		}
+		if _, err := tc.docs.Documents.BatchUpdate(docID, &docs.BatchUpdateDocumentRequest{Requests: reqs}).Context(ctx).Do(); err != nil {
+			return err
+		}
+		mu.Lock()
+		changed++
+		mu.Unlock()
+		return nil
+	})
+	for i, err := range errs {
+		if err != nil {
+			tc.fail(docsNodes[i], "rewrite-links", err)
+		}
+	}
+	return changed
+}
+
+func collectDocLinkRewrites(elements []*docs.StructuralElement, replacer *strings.Replacer, reqs []*docs.Request) []*docs.Request {
+	for _, el := range elements {
+		switch {
+		case el.Paragraph != nil:
+			for _, pe := range el.Paragraph.Elements {
+				if pe.TextRun == nil || pe.TextRun.TextStyle == nil || pe.TextRun.TextStyle.Link == nil {
+					continue
+				}
+				oldURL := pe.TextRun.TextStyle.Link.Url
+				newURL := replacer.Replace(oldURL)
+				if oldURL == "" || newURL == oldURL {
+					continue
+				}
+				reqs = append(reqs, &docs.Request{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+					Range:     &docs.Range{StartIndex: pe.StartIndex, EndIndex: pe.EndIndex},
+					TextStyle: &docs.TextStyle{Link: &docs.Link{Url: newURL}},
+					Fields:    "link",
+				}})
+			}
+		case el.Table != nil:
+			for _, row := range el.Table.TableRows {
+				for _, cell := range row.TableCells {
+					reqs = collectDocLinkRewrites(cell.Content, replacer, reqs)
+				}
+			}
+		}
+	}
+	return reqs
+}
+
+func writeDriveCopyTreeResult(ctx context.Context, u *ui.UI, result *driveCopyTreeResult) error {
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, result); err != nil {
+			return err
+		}
+	} else {
+		u.Out().Printf("id\t%s", result.Folder.Id)
+		u.Out().Printf("name\t%s", result.Folder.Name)
+		u.Out().Printf("folders\t%d", result.Folders)
+		u.Out().Printf("files\t%d", result.Files)
+		if result.Shortcuts > 0 {
+			u.Out().Printf("shortcuts\t%d", result.Shortcuts)
+		}
+		if result.Permissions > 0 {
+			u.Out().Printf("permissions\t%d", result.Permissions)
+		}
+		if result.LinkedDocs > 0 {
+			u.Out().Printf("linked_docs\t%d", result.LinkedDocs)
+		}
+		if result.Folder.WebViewLink != "" {
+			u.Out().Printf("link\t%s", result.Folder.WebViewLink)
+		}
+		for _, f := range result.Failed {
+			u.Err().Printf("failed\t%s\t%s\t%s\t%s", f.Step, f.ID, sanitizeTab(f.Name), f.Error)
+		}
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("copy-tree: %d item(s) failed", len(result.Failed))
+	}
+	return nil
+}
+
+// isDriveCrossDriveMove reports whether a failed move was rejected because it
+// crosses between My Drive and a shared drive (or between shared drives).
+func isDriveCrossDriveMove(ctx context.Context, svc *drive.Service, meta *drive.File, parent string, moveErr error) bool {
+	var gerr *gapi.Error
+	if !errors.As(moveErr, &gerr) || (gerr.Code != http.StatusForbidden && gerr.Code != http.StatusBadRequest) {
+		return false
+	}
+	dest, err := svc.Files.Get(parent).SupportsAllDrives(true).Fields("id, driveId").Context(ctx).Do()
+	if err != nil {
+		return false
+	}
+	return dest.DriveId != meta.DriveId
+}
+
+// moveDriveFolderByCopy emulates a cross-drive folder move: copy the tree
+// with its permissions, then trash the original only if every item copied.
+func moveDriveFolderByCopy(ctx context.Context, flags *RootFlags, svc *drive.Service, meta *drive.File, parent string) error {
+	u := ui.FromContext(ctx)
+	if err := confirmDestructiveChecked(ctx, flags, fmt.Sprintf("copy folder %s to %s and trash the original", meta.Id, parent)); err != nil {
+		return err
+	}
+	tree, err := walkDriveTree(ctx, svc, meta)
+	if err != nil {
+		return err
+	}
+	copier := &driveTreeCopier{svc: svc, concurrency: defaultDriveCopyTreeConcurrency, ids: map[string]string{}}
+	result, err := copier.copyTree(ctx, tree, parent, meta.Name, true)
+	if err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		err := writeDriveCopyTreeResult(ctx, u, result)
+		return fmt.Errorf("%w; original folder %s was left in place", err, meta.Id)
+	}
+	if _, err := svc.Files.Update(meta.Id, &drive.File{Trashed: true}).
+		SupportsAllDrives(true).
+		Fields("id, trashed").
+		Context(ctx).
+		Do(); err != nil {
+		return fmt.Errorf("copied to %s but could not trash the original: %w", result.Folder.Id, err)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			strFile:     result.Folder,
+			"copied":    true,
+			"trashedId": meta.Id,
+			"folders":   result.Folders,
+			"files":     result.Files,
+		})
+	}
+	u.Out().Printf("id\t%s", result.Folder.Id)
+	u.Out().Printf("name\t%s", result.Folder.Name)
+	u.Out().Printf("copied\ttrue")
+	u.Out().Printf("trashed\t%s", meta.Id)
+	return nil
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/option"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// newCopyTreeFixture builds src/{a.txt, link→a.txt, sub/{b.txt, Notes(doc)}}
+// and an empty dst folder.
+func newCopyTreeFixture(t *testing.T) (*fakeDriveTree, map[string]*fakeDriveFile) {
+	t.Helper()
+	api := newFakeDriveTree(t)
+	src := api.add("root", "src", driveMimeFolder, "")
+	a := api.add(src.ID, "a.txt", "text/plain", "hello")
+	a.Perms = []map[string]any{
+		{"id": "p1", "type": "user", "role": "owner", "emailAddress": "me@example.com"},
+		{"id": "p2", "type": "user", "role": "writer", "emailAddress": "x@example.com"},
+		{"id": "p3", "type": "user", "role": "reader", "emailAddress": "team@example.com", "permissionDetails": []map[string]any{{"inherited": true}}},
+	}
+	sub := api.add(src.ID, "sub", driveMimeFolder, "")
+	b := api.add(sub.ID, "b.txt", "text/plain", "world")
+	doc := api.add(sub.ID, "Notes", driveMimeGoogleDoc, "doc")
+	link := api.add(src.ID, "link", driveMimeShortcut, "")
+	link.Target = a.ID
+	dst := api.add("root", "dst", driveMimeFolder, "")
+	return api, map[string]*fakeDriveFile{"src": src, "a": a, "sub": sub, "b": b, "doc": doc, "link": link, "dst": dst}
+}
+
+func (f *fakeDriveTree) child(parent, name string) *fakeDriveFile {
+	for _, file := range f.files {
+		if file.Parent == parent && file.Name == name && !file.Trashed {
+			return file
+		}
+	}
+	return nil
+}
+
+func TestDriveTreeCmd_TextAndDepth(t *testing.T) {
+	api, ids := newCopyTreeFixture(t)
+	svc, closeSrv := newDriveTestService(t, api)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	var out bytes.Buffer
+	u, err := ui.New(ui.Options{Stdout: &out, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := ui.WithUI(context.Background(), u)
+	flags := &RootFlags{Account: "a@b.com"}
+	if err := runKong(t, &DriveTreeCmd{}, []string{ids["src"].ID}, ctx, flags); err != nil {
+		t.Fatalf("tree: %v", err)
+	}
+	want := strings.Join([]string{
+		"src/  10 B, 4 files",
+		"├── a.txt  5 B",
+		"├── sub/  5 B, 2 files",
+		"│   ├── b.txt  5 B",
+		"│   └── Notes  -",
+		"└── link  -",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("unexpected tree:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	jsonCtx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	raw := captureStdout(t, func() {
+		if err := runKong(t, &DriveTreeCmd{}, []string{ids["src"].ID, "--depth", "1"}, jsonCtx, flags); err != nil {
+			t.Fatalf("tree json: %v", err)
+		}
+	})
+	var parsed struct {
+		Tree driveTreeNode `json:"tree"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, raw)
+	}
+	if parsed.Tree.Size != 10 || len(parsed.Tree.Children) != 3 || parsed.Tree.Children[1].Children != nil || parsed.Tree.Children[1].Files != 2 {
+		t.Fatalf("unexpected pruned tree: %s", raw)
+	}
+}
+
+func TestDriveCopyTreeCmd_CopiesHierarchy(t *testing.T) {
+	api, ids := newCopyTreeFixture(t)
+	svc, closeSrv := newDriveTestService(t, api)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	var (
+		mu      sync.Mutex
+		updates []*docs.Request
+	)
+	docsSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, ":batchUpdate") {
+			var req docs.BatchUpdateDocumentRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			updates = append(updates, req.Requests...)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"body": map[string]any{"content": []any{
+			map[string]any{"paragraph": map[string]any{"elements": []any{
+				map[string]any{"startIndex": 1, "endIndex": 6, "textRun": map[string]any{"content": "a.txt", "textStyle": map[string]any{
+					"link": map[string]any{"url": "https://drive.google.com/file/d/" + ids["a"].ID + "/view"},
+				}}},
+				map[string]any{"startIndex": 6, "endIndex": 12, "textRun": map[string]any{"content": " other", "textStyle": map[string]any{
+					"link": map[string]any{"url": "https://example.com/"},
+				}}},
+			}}},
+		}}})
+	}))
+	defer docsSrv.Close()
+	docSvc, err := docs.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(docsSrv.Client()),
+		option.WithEndpoint(docsSrv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("docs.NewService: %v", err)
+	}
+	origDocs := newDocsService
+	t.Cleanup(func() { newDocsService = origDocs })
+	newDocsService = func(context.Context, string) (*docs.Service, error) { return docSvc, nil }
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	flags := &RootFlags{Account: "a@b.com"}
+	raw := captureStdout(t, func() {
+		args := []string{ids["src"].ID, "--to", ids["dst"].ID, "--name", "copy", "--preserve-permissions", "--rewrite-links", "--concurrency", "2"}
+		if err := runKong(t, &DriveCopyTreeCmd{}, args, ctx, flags); err != nil {
+			t.Fatalf("copy-tree: %v", err)
+		}
+	})
+	var result driveCopyTreeResult
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		t.Fatalf("json: %v\n%s", err, raw)
+	}
+	if result.Folders != 1 || result.Files != 3 || result.Shortcuts != 1 || result.Permissions != 1 || result.LinkedDocs != 1 || len(result.Failed) != 0 {
+		t.Fatalf("unexpected result: %s", raw)
+	}
+
+	top := api.child(ids["dst"].ID, "copy")
+	if top == nil || top.ID != result.Folder.Id {
+		t.Fatalf("top folder not created under dst: %s", raw)
+	}
+	newA := api.child(top.ID, "a.txt")
+	newSub := api.child(top.ID, "sub")
+	if newA == nil || newA.Content != "hello" || newSub == nil || api.child(newSub.ID, "b.txt") == nil || api.child(newSub.ID, "Notes") == nil {
+		t.Fatalf("hierarchy not recreated")
+	}
+	if newLink := api.child(top.ID, "link"); newLink == nil || newLink.Target != newA.ID {
+		t.Fatalf("shortcut should point at the copied file, got %#v", newLink)
+	}
+	if len(newA.Perms) != 1 || newA.Perms[0]["emailAddress"] != "x@example.com" || newA.Perms[0]["role"] != "writer" {
+		t.Fatalf("expected only the direct writer permission, got %#v", newA.Perms)
+	}
+	if len(updates) != 1 || updates[0].UpdateTextStyle == nil ||
+		updates[0].UpdateTextStyle.TextStyle.Link.Url != "https://drive.google.com/file/d/"+newA.ID+"/view" {
+		t.Fatalf("unexpected link rewrites: %#v", updates)
+	}
+}
+
+func TestDriveMoveCmd_CrossDriveFolderFallback(t *testing.T) {
+	api, ids := newCopyTreeFixture(t)
+	shared := api.add("", "Shared", driveMimeFolder, "")
+	shared.DriveID = "sd1"
+	svc, closeSrv := newDriveTestService(t, api)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	err := runKong(t, &DriveMoveCmd{}, []string{ids["src"].ID, "--parent", shared.ID}, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "--copy-fallback") {
+		t.Fatalf("expected hint about --copy-fallback, got %v", err)
+	}
+
+	raw := captureStdout(t, func() {
+		args := []string{ids["src"].ID, "--parent", shared.ID, "--copy-fallback"}
+		if err := runKong(t, &DriveMoveCmd{}, args, ctx, &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+			t.Fatalf("move: %v", err)
+		}
+	})
+	if !strings.Contains(raw, `"copied": true`) {
+		t.Fatalf("unexpected output: %s", raw)
+	}
+	copied := api.child(shared.ID, "src")
+	if copied == nil || api.child(copied.ID, "a.txt") == nil {
+		t.Fatalf("folder was not copied into the shared drive")
+	}
+	if !ids["src"].Trashed {
+		t.Fatalf("original folder should be trashed")
+	}
+
+	// Plain files still move with a parent update.
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &DriveMoveCmd{}, []string{ids["b"].ID, "--parent", shared.ID}, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("file move: %v", err)
+		}
+	})
+	if ids["b"].Parent != shared.ID {
+		t.Fatalf("file was not moved")
+	}
+}
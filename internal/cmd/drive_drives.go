@@ -11,18 +11,32 @@ import (
 	"github.com/steipete/gogcli/internal/ui"
 )
 
-// DriveDrivesCmd lists all shared drives the user has access to.
+// DriveDrivesCmd lists shared drives (the default) and manages them and their
+// members.
 type DriveDrivesCmd struct {
-	Max       int64  `name:"max" aliases:"limit" help:"Max results (max allowed: 100)" default:"100"`
-	Page      string `name:"page" aliases:"cursor" help:"Page token"`
-	All       bool   `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
-	FailEmpty bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
-	Query     string `name:"query" short:"q" help:"Search query for filtering shared drives"`
+	List    DriveDrivesListCmd    `cmd:"" name:"list" aliases:"ls" default:"withargs" help:"List shared drives"`
+	Create  DriveDrivesCreateCmd  `cmd:"" name:"create" help:"Create a shared drive"`
+	Get     DriveDrivesGetCmd     `cmd:"" name:"get" help:"Get shared drive metadata and restrictions"`
+	Update  DriveDrivesUpdateCmd  `cmd:"" name:"update" help:"Rename a shared drive or change its restrictions"`
+	Hide    DriveDrivesHideCmd    `cmd:"" name:"hide" help:"Hide a shared drive from the default view"`
+	Unhide  DriveDrivesUnhideCmd  `cmd:"" name:"unhide" help:"Show a hidden shared drive again"`
+	Delete  DriveDrivesDeleteCmd  `cmd:"" name:"delete" aliases:"rm" help:"Delete a shared drive"`
+	Members DriveDrivesMembersCmd `cmd:"" name:"members" help:"List, add, update, and remove shared drive members"`
 }
 
-func (c *DriveDrivesCmd) Run(ctx context.Context, flags *RootFlags) error {
+// DriveDrivesListCmd lists all shared drives the user has access to.
+type DriveDrivesListCmd struct {
+	Max         int64  `name:"max" aliases:"limit" help:"Max results (max allowed: 100)" default:"100"`
+	Page        string `name:"page" aliases:"cursor" help:"Page token"`
+	All         bool   `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
+	FailEmpty   bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
+	Query       string `name:"query" short:"q" help:"Search query for filtering shared drives"`
+	DomainAdmin bool   `name:"domain-admin" help:"List every shared drive in the domain (useDomainAdminAccess; requires a Workspace admin)"`
+}
+
+func (c *DriveDrivesListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
-	_, svc, err := requireDriveService(ctx, flags)
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
 	if err != nil {
 		return err
 	}
@@ -32,6 +46,9 @@ func (c *DriveDrivesCmd) Run(ctx context.Context, flags *RootFlags) error {
 			PageSize(c.Max).
 			Fields("nextPageToken, drives(id, name, createdTime)").
 			Context(ctx)
+		if c.DomainAdmin {
+			call = call.UseDomainAdminAccess(true)
+		}
 		if page := strings.TrimSpace(pageToken); page != "" {
 			call = call.PageToken(page)
 		}
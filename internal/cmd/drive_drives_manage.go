@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/errfmt"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const driveSharedDriveFields = "id, name, createdTime, hidden, orgUnitId, restrictions"
+
+// requireSharedDriveService is requireDriveService plus a guard for
+// --domain-admin, which only works for Workspace admins (typically through a
+// domain-wide delegation service account: gog auth service-account set).
+func requireSharedDriveService(ctx context.Context, flags *RootFlags, domainAdmin bool) (string, *drive.Service, error) {
+	if domainAdmin {
+		account, err := requireAccount(flags)
+		if err != nil {
+			return "", nil, err
+		}
+		if isConsumerAccount(account) {
+			return "", nil, errfmt.NewUserFacingError("--domain-admin requires a Google Workspace admin account; consumer accounts (gmail.com/googlemail.com) are not supported.", nil)
+		}
+	}
+	return requireDriveService(ctx, flags)
+}
+
+// DriveRestrictionFlags toggles shared drive restrictions; unset flags keep
+// the current value.
+type DriveRestrictionFlags struct {
+	DomainUsersOnly                *bool `name:"domain-users-only" negatable:"" help:"Only allow access to users in the drive's domain"`
+	DriveMembersOnly               *bool `name:"drive-members-only" negatable:"" help:"Only allow access to members of the shared drive"`
+	CopyRequiresWriterPermission   *bool `name:"copy-requires-writer-permission" negatable:"" help:"Disable copy, print, and download for readers and commenters"`
+	AdminManagedRestrictions       *bool `name:"admin-managed-restrictions" negatable:"" help:"Require organizer admin privileges to change restrictions"`
+	SharingFoldersRequireOrganizer *bool `name:"sharing-folders-requires-organizer" negatable:"" help:"Only organizers can share folders"`
+}
+
+// restrictions returns the requested changes, or nil when no flag was set.
+func (f DriveRestrictionFlags) restrictions() *drive.DriveRestrictions {
+	r := &drive.DriveRestrictions{}
+	set := false
+	apply := func(v *bool, field string, dst *bool) {
+		if v == nil {
+			return
+		}
+		*dst = *v
+		r.ForceSendFields = append(r.ForceSendFields, field)
+		set = true
+	}
+	apply(f.DomainUsersOnly, "DomainUsersOnly", &r.DomainUsersOnly)
+	apply(f.DriveMembersOnly, "DriveMembersOnly", &r.DriveMembersOnly)
+	apply(f.CopyRequiresWriterPermission, "CopyRequiresWriterPermission", &r.CopyRequiresWriterPermission)
+	apply(f.AdminManagedRestrictions, "AdminManagedRestrictions", &r.AdminManagedRestrictions)
+	apply(f.SharingFoldersRequireOrganizer, "SharingFoldersRequiresOrganizerPermission", &r.SharingFoldersRequiresOrganizerPermission)
+	if !set {
+		return nil
+	}
+	return r
+}
+
+type DriveDrivesCreateCmd struct {
+	Name         string                `arg:"" name:"name" help:"Shared drive name"`
+	RequestID    string                `name:"request-id" help:"Idempotency key; reuse it to retry a create safely (default: random UUID)"`
+	Restrictions DriveRestrictionFlags `embed:""`
+}
+
+func (c *DriveDrivesCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		return usage("empty name")
+	}
+	requestID := strings.TrimSpace(c.RequestID)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	restrictions := c.Restrictions.restrictions()
+
+	if err := dryRunExit(ctx, flags, "drive.drives.create", map[string]any{
+		"name":         name,
+		"requestId":    requestID,
+		"restrictions": restrictions,
+	}); err != nil {
+		return err
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+
+	created, err := svc.Drives.Create(requestID, &drive.Drive{Name: name}).
+		Fields(driveSharedDriveFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+	// Restrictions go through a separate update so a rejected restriction
+	// still reports the drive that was created.
+	if restrictions != nil {
+		created, err = svc.Drives.Update(created.Id, &drive.Drive{Restrictions: restrictions}).
+			Fields(driveSharedDriveFields).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return fmt.Errorf("shared drive created but restrictions failed: %w", err)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"drive": created})
+	}
+	printSharedDrive(u, created)
+	return nil
+}
+
+type DriveDrivesGetCmd struct {
+	DriveID     string `arg:"" name:"driveId" help:"Shared drive ID"`
+	DomainAdmin bool   `name:"domain-admin" help:"Act as a Workspace admin (useDomainAdminAccess)"`
+}
+
+func (c *DriveDrivesGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+
+	got, err := svc.Drives.Get(driveID).
+		UseDomainAdminAccess(c.DomainAdmin).
+		Fields(driveSharedDriveFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"drive": got})
+	}
+	printSharedDrive(u, got)
+	return nil
+}
+
+type DriveDrivesUpdateCmd struct {
+	DriveID      string                `arg:"" name:"driveId" help:"Shared drive ID"`
+	Name         string                `name:"name" help:"New name"`
+	DomainAdmin  bool                  `name:"domain-admin" help:"Act as a Workspace admin (useDomainAdminAccess)"`
+	Restrictions DriveRestrictionFlags `embed:""`
+}
+
+func (c *DriveDrivesUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+	patch := &drive.Drive{Name: strings.TrimSpace(c.Name), Restrictions: c.Restrictions.restrictions()}
+	if patch.Name == "" && patch.Restrictions == nil {
+		return usage("nothing to update (use --name or a restriction flag such as --domain-users-only)")
+	}
+
+	if err := dryRunExit(ctx, flags, "drive.drives.update", map[string]any{
+		"driveId":     driveID,
+		"drive":       patch,
+		"domainAdmin": c.DomainAdmin,
+	}); err != nil {
+		return err
+	}
+
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+
+	updated, err := svc.Drives.Update(driveID, patch).
+		UseDomainAdminAccess(c.DomainAdmin).
+		Fields(driveSharedDriveFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"drive": updated})
+	}
+	printSharedDrive(u, updated)
+	return nil
+}
+
+type DriveDrivesHideCmd struct {
+	DriveID string `arg:"" name:"driveId" help:"Shared drive ID"`
+}
+
+func (c *DriveDrivesHideCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return setSharedDriveHidden(ctx, flags, c.DriveID, true)
+}
+
+type DriveDrivesUnhideCmd struct {
+	DriveID string `arg:"" name:"driveId" help:"Shared drive ID"`
+}
+
+func (c *DriveDrivesUnhideCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return setSharedDriveHidden(ctx, flags, c.DriveID, false)
+}
+
+func setSharedDriveHidden(ctx context.Context, flags *RootFlags, rawID string, hidden bool) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(rawID))
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+
+	op := "drive.drives.unhide"
+	if hidden {
+		op = "drive.drives.hide"
+	}
+	if err := dryRunExit(ctx, flags, op, map[string]any{"driveId": driveID}); err != nil {
+		return err
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+
+	var got *drive.Drive
+	if hidden {
+		got, err = svc.Drives.Hide(driveID).Context(ctx).Do()
+	} else {
+		got, err = svc.Drives.Unhide(driveID).Context(ctx).Do()
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("id", got.Id),
+		kv("name", got.Name),
+		kv("hidden", got.Hidden),
+	)
+}
+
+type DriveDrivesDeleteCmd struct {
+	DriveID           string `arg:"" name:"driveId" help:"Shared drive ID"`
+	AllowItemDeletion bool   `name:"allow-item-deletion" help:"Also delete everything in the drive (requires --domain-admin)"`
+	DomainAdmin       bool   `name:"domain-admin" help:"Act as a Workspace admin (useDomainAdminAccess)"`
+}
+
+func (c *DriveDrivesDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+	if c.AllowItemDeletion && !c.DomainAdmin {
+		return usage("--allow-item-deletion requires --domain-admin")
+	}
+
+	action := fmt.Sprintf("delete shared drive %s", driveID)
+	if c.AllowItemDeletion {
+		action += " and everything in it"
+	}
+	if err := dryRunAndConfirmDestructive(ctx, flags, "drive.drives.delete", map[string]any{
+		"driveId":           driveID,
+		"allowItemDeletion": c.AllowItemDeletion,
+		"domainAdmin":       c.DomainAdmin,
+	}, action); err != nil {
+		return err
+	}
+
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.Drives.Delete(driveID).
+		UseDomainAdminAccess(c.DomainAdmin).
+		AllowItemDeletion(c.AllowItemDeletion).
+		Context(ctx).
+		Do(); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("deleted", true),
+		kv("driveId", driveID),
+	)
+}
+
+func printSharedDrive(u *ui.UI, d *drive.Drive) {
+	u.Out().Printf("id\t%s", d.Id)
+	u.Out().Printf("name\t%s", d.Name)
+	if d.CreatedTime != "" {
+		u.Out().Printf("created\t%s", formatDateTime(d.CreatedTime))
+	}
+	u.Out().Printf("hidden\t%t", d.Hidden)
+	if d.OrgUnitId != "" {
+		u.Out().Printf("org_unit\t%s", d.OrgUnitId)
+	}
+	if r := d.Restrictions; r != nil {
+		u.Out().Printf("domain_users_only\t%t", r.DomainUsersOnly)
+		u.Out().Printf("drive_members_only\t%t", r.DriveMembersOnly)
+		u.Out().Printf("copy_requires_writer_permission\t%t", r.CopyRequiresWriterPermission)
+		u.Out().Printf("admin_managed_restrictions\t%t", r.AdminManagedRestrictions)
+		u.Out().Printf("sharing_folders_requires_organizer\t%t", r.SharingFoldersRequiresOrganizerPermission)
+	}
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+type fakeSharedDrives struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   []map[string]any
+	perms    []map[string]any
+}
+
+func (f *fakeSharedDrives) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+	q := r.URL.Query()
+	f.requests = append(f.requests, r.Method+" "+p+" admin="+q.Get("useDomainAdminAccess"))
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	if body != nil {
+		f.bodies = append(f.bodies, body)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case p == "/drives" && r.Method == http.MethodPost:
+		body["id"] = "d1"
+		body["requestId"] = q.Get("requestId")
+		_ = json.NewEncoder(w).Encode(body)
+	case p == "/drives/d1/hide" || p == "/drives/d1/unhide":
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "d1", "name": "Eng", "hidden": strings.HasSuffix(p, "/hide")})
+	case p == "/drives/d1" && r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	case p == "/drives/d1":
+		if body == nil {
+			body = map[string]any{"name": "Eng", "restrictions": map[string]any{"domainUsersOnly": true}}
+		}
+		body["id"] = "d1"
+		_ = json.NewEncoder(w).Encode(body)
+	case p == "/files/d1/permissions" && r.Method == http.MethodPost:
+		body["id"] = "p9"
+		f.perms = append(f.perms, body)
+		_ = json.NewEncoder(w).Encode(body)
+	case p == "/files/d1/permissions":
+		_ = json.NewEncoder(w).Encode(map[string]any{"permissions": f.perms})
+	case strings.HasPrefix(p, "/files/d1/permissions/"):
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body["id"] = strings.TrimPrefix(p, "/files/d1/permissions/")
+		_ = json.NewEncoder(w).Encode(body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeSharedDrives(t *testing.T) *fakeSharedDrives {
+	t.Helper()
+	api := &fakeSharedDrives{perms: []map[string]any{
+		{"id": "p1", "type": "user", "role": "organizer", "emailAddress": "owner@example.com"},
+		{"id": "p2", "type": "user", "role": "writer", "emailAddress": "Ann@example.com"},
+		{"id": "p3", "type": "domain", "role": "reader", "domain": "example.com"},
+	}}
+	svc, closeSrv := newDriveTestService(t, api)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+	return api
+}
+
+func TestDriveDrivesCmd_CreateUpdateGetHideDelete(t *testing.T) {
+	api := newFakeSharedDrives(t)
+
+	created := runDriveJSON(t, &DriveDrivesCmd{}, "create", "Eng", "--request-id", "req-1", "--domain-users-only", "--no-copy-requires-writer-permission")
+	if created["drive"].(map[string]any)["id"] != "d1" || len(api.bodies) != 2 {
+		t.Fatalf("unexpected create: %#v bodies=%#v", created, api.bodies)
+	}
+	restr := api.bodies[1]["restrictions"].(map[string]any)
+	if restr["domainUsersOnly"] != true || restr["copyRequiresWriterPermission"] != false || len(restr) != 2 {
+		t.Fatalf("unexpected restrictions: %#v", restr)
+	}
+
+	runDriveJSON(t, &DriveDrivesCmd{}, "update", "d1", "--name", "Engineering", "--drive-members-only", "--domain-admin")
+	if api.bodies[2]["name"] != "Engineering" || api.requests[len(api.requests)-1] != "PATCH /drives/d1 admin=true" {
+		t.Fatalf("unexpected update: %#v %v", api.bodies[2], api.requests)
+	}
+
+	got := runDriveJSON(t, &DriveDrivesCmd{}, "get", "d1")
+	if got["drive"].(map[string]any)["restrictions"].(map[string]any)["domainUsersOnly"] != true {
+		t.Fatalf("unexpected get: %#v", got)
+	}
+	if hidden := runDriveJSON(t, &DriveDrivesCmd{}, "hide", "d1"); hidden["hidden"] != true {
+		t.Fatalf("unexpected hide: %#v", hidden)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	requests := len(api.requests)
+	for _, op := range []string{"delete", "hide", "unhide"} {
+		var err error
+		_ = captureStdout(t, func() {
+			err = runKong(t, &DriveDrivesCmd{}, []string{op, "d1"}, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+		})
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != 0 {
+			t.Fatalf("%s: expected dry-run exit 0, got %v", op, err)
+		}
+	}
+	if len(api.requests) != requests {
+		t.Fatalf("dry runs must not reach the API: %v", api.requests[requests:])
+	}
+	for _, tc := range []struct {
+		args    []string
+		account string
+		want    string
+	}{
+		{[]string{"delete", "d1"}, "a@b.com", "refusing"},
+		{[]string{"delete", "d1", "--allow-item-deletion"}, "a@b.com", "requires --domain-admin"},
+		{[]string{"update", "d1"}, "a@b.com", "nothing to update"},
+		{[]string{"get", "d1", "--domain-admin"}, "me@gmail.com", "Workspace admin"},
+	} {
+		err := runKong(t, &DriveDrivesCmd{}, tc.args, ctx, &RootFlags{Account: tc.account, NoInput: true})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &DriveDrivesCmd{}, []string{"delete", "d1", "--domain-admin", "--allow-item-deletion"}, ctx, &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	})
+	if last := api.requests[len(api.requests)-1]; last != "DELETE /drives/d1 admin=true" {
+		t.Fatalf("unexpected delete request: %v", last)
+	}
+}
+
+func TestDriveDrivesMembersCmd(t *testing.T) {
+	api := newFakeSharedDrives(t)
+
+	listed := runDriveJSON(t, &DriveDrivesCmd{}, "members", "d1", "--domain-admin")
+	if members, _ := listed["members"].([]any); len(members) != 3 || api.requests[0] != "GET /files/d1/permissions admin=true" {
+		t.Fatalf("unexpected members: %#v %v", listed, api.requests)
+	}
+
+	runDriveJSON(t, &DriveDrivesCmd{}, "members", "add", "d1", "team@example.com", "--type", "group", "--role", "content-manager")
+	if b := api.bodies[0]; b["type"] != "group" || b["role"] != "fileOrganizer" || b["emailAddress"] != "team@example.com" {
+		t.Fatalf("unexpected add body: %#v", b)
+	}
+
+	updated := runDriveJSON(t, &DriveDrivesCmd{}, "members", "update", "d1", "ann@example.com", "--role", "organizer")
+	if updated["member"].(map[string]any)["id"] != "p2" || api.bodies[1]["role"] != "organizer" {
+		t.Fatalf("unexpected update: %#v", updated)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	_ = captureStdout(t, func() {
+		if err := runKong(t, &DriveDrivesCmd{}, []string{"members", "remove", "d1", "example.com"}, ctx, &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+	})
+	if last := api.requests[len(api.requests)-1]; last != "DELETE /files/d1/permissions/p3 admin=false" {
+		t.Fatalf("unexpected remove request: %v", last)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"members", "add", "d1", "bob@example.com", "--role", "owner"}, "invalid --role"},
+		{[]string{"members", "add", "d1", "example.com"}, "--type domain"},
+		{[]string{"members", "remove", "d1", "nobody@example.com"}, "not a member"},
+	} {
+		err := runKong(t, &DriveDrivesCmd{}, tc.args, ctx, &RootFlags{Account: "a@b.com", Force: true})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+}
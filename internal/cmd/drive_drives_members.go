@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveMemberFields      = "id, type, role, emailAddress, domain, displayName"
+	drivePermRoleOrganizer = "organizer"
+	drivePermRoleFileOrg   = "fileOrganizer"
+)
+
+// Shared drive members are permissions on the drive ID itself.
+type DriveDrivesMembersCmd struct {
+	List   DriveDrivesMembersListCmd   `cmd:"" name:"list" aliases:"ls" default:"withargs" help:"List members of a shared drive"`
+	Add    DriveDrivesMembersAddCmd    `cmd:"" name:"add" help:"Add a user, group, or domain to a shared drive"`
+	Update DriveDrivesMembersUpdateCmd `cmd:"" name:"update" help:"Change a member's role"`
+	Remove DriveDrivesMembersRemoveCmd `cmd:"" name:"remove" aliases:"rm" help:"Remove a member from a shared drive"`
+}
+
+type DriveDrivesMembersListCmd struct {
+	DriveID     string `arg:"" name:"driveId" help:"Shared drive ID"`
+	DomainAdmin bool   `name:"domain-admin" help:"Act as a Workspace admin (useDomainAdminAccess)"`
+}
+
+func (c *DriveDrivesMembersListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+	members, err := listSharedDriveMembers(ctx, svc, driveID, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if members == nil {
+			members = []*drive.Permission{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"driveId": driveID, "members": members})
+	}
+	if len(members) == 0 {
+		u.Err().Println("No members")
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tTYPE\tROLE\tMEMBER\tNAME")
+	for _, m := range members {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.Id, m.Type, m.Role, firstNonEmpty(m.EmailAddress, m.Domain, "-"), sanitizeTab(m.DisplayName))
+	}
+	return nil
+}
+
+type DriveDrivesMembersAddCmd struct {
+	DriveID     string `arg:"" name:"driveId" help:"Shared drive ID"`
+	Member      string `arg:"" name:"member" help:"User or group email, or a domain with --type domain"`
+	Role        string `name:"role" help:"Role: organizer|fileOrganizer|writer|commenter|reader" default:"writer"`
+	Type        string `name:"type" help:"Member type" enum:"user,group,domain" default:"user"`
+	Notify      bool   `name:"notify" help:"Send a notification email (users and groups only)"`
+	Message     string `name:"message" help:"Custom message for the notification email (implies --notify)"`
+	DomainAdmin bool   `name:"domain-admin" help:"Act as a Workspace admin (useDomainAdminAccess)"`
+}
+
+func (c *DriveDrivesMembersAddCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	member := strings.TrimSpace(c.Member)
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+	if member == "" {
+		return usage("empty member")
+	}
+	role, err := normalizeSharedDriveRole(c.Role)
+	if err != nil {
+		return err
+	}
+	memberType := strings.TrimSpace(c.Type)
+	if memberType == "" {
+		memberType = "user"
+	}
+	perm := &drive.Permission{Type: memberType, Role: role}
+	if memberType == "domain" {
+		perm.Domain = member
+	} else {
+		if !strings.Contains(member, "@") {
+			return usagef("invalid member %q (want an email address, or use --type domain)", member)
+		}
+		perm.EmailAddress = member
+	}
+	notify := c.Notify || strings.TrimSpace(c.Message) != ""
+	if notify && memberType == "domain" {
+		return usage("--notify is only valid for users and groups")
+	}
+
+	if dryErr := dryRunExit(ctx, flags, "drive.drives.members.add", map[string]any{
+		"driveId":     driveID,
+		"permission":  perm,
+		"notify":      notify,
+		"domainAdmin": c.DomainAdmin,
+	}); dryErr != nil {
+		return dryErr
+	}
+
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+
+	call := svc.Permissions.Create(driveID, perm).
+		SupportsAllDrives(true).
+		UseDomainAdminAccess(c.DomainAdmin).
+		SendNotificationEmail(notify).
+		Fields(gapi.Field(driveMemberFields)).
+		Context(ctx)
+	if msg := strings.TrimSpace(c.Message); msg != "" {
+		call = call.EmailMessage(msg)
+	}
+	created, err := call.Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"driveId": driveID, "member": created})
+	}
+	u.Out().Printf("permission_id\t%s", created.Id)
+	u.Out().Printf("member\t%s", firstNonEmpty(created.EmailAddress, created.Domain, member))
+	u.Out().Printf("role\t%s", created.Role)
+	return nil
+}
+
+type DriveDrivesMembersUpdateCmd struct {
+	DriveID     string `arg:"" name:"driveId" help:"Shared drive ID"`
+	Member      string `arg:"" name:"member" help:"Member email, domain, or permission ID"`
+	Role        string `name:"role" required:"" help:"New role: organizer|fileOrganizer|writer|commenter|reader"`
+	DomainAdmin bool   `name:"domain-admin" help:"Act as a Workspace admin (useDomainAdminAccess)"`
+}
+
+func (c *DriveDrivesMembersUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+	role, err := normalizeSharedDriveRole(c.Role)
+	if err != nil {
+		return err
+	}
+
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+	perm, err := findSharedDriveMember(ctx, svc, driveID, c.Member, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+
+	if dryErr := dryRunExit(ctx, flags, "drive.drives.members.update", map[string]any{
+		"driveId":      driveID,
+		"permissionId": perm.Id,
+		"from":         perm.Role,
+		"to":           role,
+	}); dryErr != nil {
+		return dryErr
+	}
+
+	updated, err := svc.Permissions.Update(driveID, perm.Id, &drive.Permission{Role: role}).
+		SupportsAllDrives(true).
+		UseDomainAdminAccess(c.DomainAdmin).
+		Fields(gapi.Field(driveMemberFields)).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"driveId": driveID, "member": updated})
+	}
+	u.Out().Printf("permission_id\t%s", updated.Id)
+	u.Out().Printf("member\t%s", firstNonEmpty(updated.EmailAddress, updated.Domain, perm.EmailAddress, perm.Domain))
+	u.Out().Printf("role\t%s", updated.Role)
+	return nil
+}
+
+type DriveDrivesMembersRemoveCmd struct {
+	DriveID     string `arg:"" name:"driveId" help:"Shared drive ID"`
+	Member      string `arg:"" name:"member" help:"Member email, domain, or permission ID"`
+	DomainAdmin bool   `name:"domain-admin" help:"Act as a Workspace admin (useDomainAdminAccess)"`
+}
+
+func (c *DriveDrivesMembersRemoveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	if driveID == "" {
+		return usage("empty driveId")
+	}
+
+	_, svc, err := requireSharedDriveService(ctx, flags, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+	perm, err := findSharedDriveMember(ctx, svc, driveID, c.Member, c.DomainAdmin)
+	if err != nil {
+		return err
+	}
+
+	who := firstNonEmpty(perm.EmailAddress, perm.Domain, perm.Id)
+	if confirmErr := dryRunAndConfirmDestructive(ctx, flags, "drive.drives.members.remove", map[string]any{
+		"driveId":      driveID,
+		"permissionId": perm.Id,
+		"member":       who,
+		"role":         perm.Role,
+	}, fmt.Sprintf("remove %s (%s) from shared drive %s", who, perm.Role, driveID)); confirmErr != nil {
+		return confirmErr
+	}
+
+	if err := svc.Permissions.Delete(driveID, perm.Id).
+		SupportsAllDrives(true).
+		UseDomainAdminAccess(c.DomainAdmin).
+		Context(ctx).
+		Do(); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("removed", true),
+		kv("driveId", driveID),
+		kv("permissionId", perm.Id),
+		kv("member", who),
+	)
+}
+
+func normalizeSharedDriveRole(role string) (string, error) {
+	role = strings.TrimSpace(role)
+	switch strings.ToLower(role) {
+	case "":
+		return drivePermRoleWriter, nil
+	case drivePermRoleOrganizer:
+		return drivePermRoleOrganizer, nil
+	case "fileorganizer", "file-organizer", "contentmanager", "content-manager":
+		return drivePermRoleFileOrg, nil
+	case drivePermRoleWriter, "contributor":
+		return drivePermRoleWriter, nil
+	case drivePermRoleCommenter:
+		return drivePermRoleCommenter, nil
+	case drivePermRoleReader, "viewer":
+		return drivePermRoleReader, nil
+	default:
+		return "", usagef("invalid --role %q (expected organizer|fileOrganizer|writer|commenter|reader)", role)
+	}
+}
+
+func listSharedDriveMembers(ctx context.Context, svc *drive.Service, driveID string, domainAdmin bool) ([]*drive.Permission, error) {
+	var out []*drive.Permission
+	pageToken := ""
+	for {
+		call := svc.Permissions.List(driveID).
+			SupportsAllDrives(true).
+			UseDomainAdminAccess(domainAdmin).
+			PageSize(100).
+			Fields(gapi.Field("nextPageToken, permissions(" + driveMemberFields + ")")).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, resp.Permissions...)
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// findSharedDriveMember matches a member by permission ID, email address, or
+// domain (case-insensitive).
+func findSharedDriveMember(ctx context.Context, svc *drive.Service, driveID, member string, domainAdmin bool) (*drive.Permission, error) {
+	member = strings.TrimSpace(member)
+	if member == "" {
+		return nil, usage("empty member")
+	}
+	members, err := listSharedDriveMembers(ctx, svc, driveID, domainAdmin)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		if m == nil {
+			continue
+		}
+		if m.Id == member || strings.EqualFold(m.EmailAddress, member) || (m.Type == "domain" && strings.EqualFold(m.Domain, member)) {
+			return m, nil
+		}
+	}
+	return nil, usagef("%s is not a member of shared drive %s", member, driveID)
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const driveDupesFileFields = "id, name, mimeType, size, quotaBytesUsed, md5Checksum, createdTime, modifiedTime, parents, driveId"
+
+type DriveDupesCmd struct {
+	Folder  string `name:"folder" help:"Only look under this folder (recursive; default: everything you can access)"`
+	MinSize string `name:"min-size" help:"Ignore files smaller than this (e.g. 512k, 1MB)"`
+}
+
+type driveDupeFile struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Path           string `json:"path"`
+	CreatedTime    string `json:"createdTime,omitempty"`
+	QuotaBytesUsed int64  `json:"quotaBytesUsed"`
+}
+
+// driveDupeGroup is a set of files with identical content. Files are ordered
+// oldest first; Duplicates lists every copy except the oldest.
+type driveDupeGroup struct {
+	MD5        string          `json:"md5Checksum"`
+	Size       int64           `json:"size"`
+	Wasted     int64           `json:"wastedBytes"`
+	Files      []driveDupeFile `json:"files"`
+	Duplicates []string        `json:"duplicateIds"`
+}
+
+func (c *DriveDupesCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	minSize, err := parseByteSize(c.MinSize)
+	if err != nil {
+		return usagef("invalid --min-size: %v", err)
+	}
+	folderID := normalizeGoogleID(strings.TrimSpace(c.Folder))
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	paths := newDrivePathResolver(svc)
+	var files []*drive.File
+	if folderID != "" {
+		files, err = listDriveFolderFilesWithPaths(ctx, svc, folderID, paths)
+	} else {
+		files, err = listDriveAllBinaryFiles(ctx, svc)
+	}
+	if err != nil {
+		return err
+	}
+
+	byContent := map[string][]*drive.File{}
+	for _, f := range files {
+		if f.Md5Checksum == "" || f.Size < minSize {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", f.Md5Checksum, f.Size)
+		byContent[key] = append(byContent[key], f)
+	}
+
+	var groups []driveDupeGroup
+	var wasted int64
+	for _, same := range byContent {
+		if len(same) < 2 {
+			continue
+		}
+		sort.Slice(same, func(i, j int) bool {
+			if same[i].CreatedTime != same[j].CreatedTime {
+				return same[i].CreatedTime < same[j].CreatedTime
+			}
+			return same[i].Id < same[j].Id
+		})
+		g := driveDupeGroup{MD5: same[0].Md5Checksum, Size: same[0].Size, Wasted: same[0].Size * int64(len(same)-1)}
+		for i, f := range same {
+			p, err := paths.path(ctx, f)
+			if err != nil {
+				return err
+			}
+			g.Files = append(g.Files, driveDupeFile{ID: f.Id, Name: f.Name, Path: p, CreatedTime: f.CreatedTime, QuotaBytesUsed: f.QuotaBytesUsed})
+			if i > 0 {
+				g.Duplicates = append(g.Duplicates, f.Id)
+			}
+		}
+		wasted += g.Wasted
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Wasted != groups[j].Wasted {
+			return groups[i].Wasted > groups[j].Wasted
+		}
+		return groups[i].MD5 < groups[j].MD5
+	})
+
+	if outfmt.IsJSON(ctx) {
+		if groups == nil {
+			groups = []driveDupeGroup{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"groups":      groups,
+			"scanned":     len(files),
+			"wastedBytes": wasted,
+		})
+	}
+	if len(groups) == 0 {
+		u.Err().Printf("No duplicates found (%d files scanned)", len(files))
+		return nil
+	}
+	for i, g := range groups {
+		if i > 0 {
+			u.Out().Println("")
+		}
+		u.Out().Printf("%s  %d copies, %s each, %s wasted", g.MD5, len(g.Files), formatDriveSize(g.Size), formatDriveSize(g.Wasted))
+		for j, f := range g.Files {
+			mark := "  "
+			if j == 0 {
+				mark = "* "
+			}
+			u.Out().Printf("%s%s\t%s\t%s", mark, f.ID, f.Path, formatDateTime(f.CreatedTime))
+		}
+	}
+	u.Err().Printf("%d group(s), %s reclaimable (* = oldest copy, kept out of duplicateIds)", len(groups), formatDriveSize(wasted))
+	return nil
+}
+
+// drivePathResolver builds "/"-joined folder paths from parent IDs, caching
+// folder lookups across files.
+type drivePathResolver struct {
+	svc     *drive.Service
+	folders map[string]*drive.File
+	known   map[string]string
+}
+
+func newDrivePathResolver(svc *drive.Service) *drivePathResolver {
+	return &drivePathResolver{svc: svc, folders: map[string]*drive.File{}, known: map[string]string{}}
+}
+
+func (r *drivePathResolver) path(ctx context.Context, f *drive.File) (string, error) {
+	if p, ok := r.known[f.Id]; ok {
+		return p, nil
+	}
+	parts := []string{f.Name}
+	seen := map[string]bool{f.Id: true}
+	parents := f.Parents
+	for len(parents) > 0 && !seen[parents[0]] {
+		id := parents[0]
+		seen[id] = true
+		if p, ok := r.known[id]; ok {
+			parts = append(parts, p)
+			break
+		}
+		folder, ok := r.folders[id]
+		if !ok {
+			got, err := r.svc.Files.Get(id).
+				SupportsAllDrives(true).
+				Fields("id, name, parents").
+				Context(ctx).
+				Do()
+			if err != nil {
+				if isNotFoundAPIError(err) {
+					break
+				}
+				return "", err
+			}
+			folder = got
+			r.folders[id] = folder
+		}
+		parts = append(parts, folder.Name)
+		parents = folder.Parents
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return path.Join(parts...), nil
+}
+
+// listDriveFolderFilesWithPaths walks a folder and records the path of every
+// item it visits, so grouped files need no extra lookups.
+func listDriveFolderFilesWithPaths(ctx context.Context, svc *drive.Service, folderID string, paths *drivePathResolver) ([]*drive.File, error) {
+	root, err := getDriveTreeRoot(ctx, svc, folderID)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := walkDriveTreeFields(ctx, svc, root, driveDupesFileFields)
+	if err != nil {
+		return nil, err
+	}
+	paths.known[root.Id] = root.Name
+	var out []*drive.File
+	for _, n := range tree.flatten()[1:] {
+		paths.known[n.ID] = paths.known[n.parentID] + "/" + n.Name
+		if !n.folder() {
+			out = append(out, n.file)
+		}
+	}
+	return out, nil
+}
+
+// listDriveAllBinaryFiles lists every non-trashed, non-folder item across My
+// Drive and shared drives. Google Docs/Sheets/Slides have no checksum and are
+// skipped by the caller.
+func listDriveAllBinaryFiles(ctx context.Context, svc *drive.Service) ([]*drive.File, error) {
+	var out []*drive.File
+	pageToken := ""
+	for {
+		call := svc.Files.List().
+			Q(fmt.Sprintf("mimeType != '%s' and trashed = false", driveMimeFolder)).
+			PageSize(driveTreeListPerPage).
+			Corpora("allDrives").
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			Fields(gapi.Field("nextPageToken, files(" + driveDupesFileFields + ")")).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Files {
+			if f != nil {
+				out = append(out, f)
+			}
+		}
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+type DriveDuCmd struct {
+	FolderID string `arg:"" optional:"" name:"folderId" help:"Folder ID (default: root)"`
+	Depth    int    `name:"depth" help:"Subfolder levels to report (0 = all)" default:"1"`
+}
+
+type driveDuEntry struct {
+	ID    string `json:"id"`
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+}
+
+func (c *DriveDuCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if c.Depth < 0 {
+		return usage("--depth must be >= 0")
+	}
+	folderID := normalizeGoogleID(strings.TrimSpace(c.FolderID))
+	if folderID == "" {
+		folderID = "root"
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	root, err := getDriveTreeRoot(ctx, svc, folderID)
+	if err != nil {
+		return err
+	}
+	tree, err := walkDriveTree(ctx, svc, root)
+	if err != nil {
+		return err
+	}
+
+	var entries []driveDuEntry
+	var collect func(n *driveTreeNode, p string, depth int)
+	collect = func(n *driveTreeNode, p string, depth int) {
+		for _, child := range n.Children {
+			if !child.folder() {
+				continue
+			}
+			cp := p + "/" + child.Name
+			entries = append(entries, driveDuEntry{ID: child.ID, Path: cp, Size: child.Size, Files: child.Files})
+			if c.Depth == 0 || depth < c.Depth {
+				collect(child, cp, depth+1)
+			}
+		}
+	}
+	collect(tree, tree.Name, 1)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Size > entries[j].Size })
+
+	if outfmt.IsJSON(ctx) {
+		if entries == nil {
+			entries = []driveDuEntry{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"folder":  driveDuEntry{ID: tree.ID, Path: tree.Name, Size: tree.Size, Files: tree.Files},
+			"folders": entries,
+		})
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "SIZE\tFILES\tPATH\tID")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", formatDriveSize(e.Size), e.Files, sanitizeTab(e.Path), e.ID)
+	}
+	fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", formatDriveSize(tree.Size), tree.Files, sanitizeTab(tree.Name)+" (total)", tree.ID)
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// newDupesFixture builds top/{a.bin, big.bin, sub/{a-copy.bin, big-copy.bin, big-again.bin, other.bin, Doc}}.
+func newDupesFixture(t *testing.T) map[string]*fakeDriveFile {
+	t.Helper()
+	api := newFakeDriveTree(t)
+	big := strings.Repeat("x", 2048)
+	top := api.add("root", "top", driveMimeFolder, "")
+	a := api.add(top.ID, "a.bin", "application/octet-stream", "same")
+	bigA := api.add(top.ID, "big.bin", "application/octet-stream", big)
+	sub := api.add(top.ID, "sub", driveMimeFolder, "")
+	aCopy := api.add(sub.ID, "a-copy.bin", "application/octet-stream", "same")
+	bigB := api.add(sub.ID, "big-copy.bin", "application/octet-stream", big)
+	bigC := api.add(sub.ID, "big-again.bin", "application/octet-stream", big)
+	api.add(sub.ID, "other.bin", "application/octet-stream", "different")
+	api.add(sub.ID, "Doc", driveMimeGoogleDoc, "same")
+	svc, closeSrv := newDriveTestService(t, api)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+	return map[string]*fakeDriveFile{"top": top, "sub": sub, "a": a, "aCopy": aCopy, "bigA": bigA, "bigB": bigB, "bigC": bigC}
+}
+
+func TestDriveDupesCmd_GroupsByContent(t *testing.T) {
+	ids := newDupesFixture(t)
+	flags := &RootFlags{Account: "a@b.com"}
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+
+	raw := captureStdout(t, func() {
+		if err := runKong(t, &DriveDupesCmd{}, []string{"--folder", ids["top"].ID}, ctx, flags); err != nil {
+			t.Fatalf("dupes: %v", err)
+		}
+	})
+	var parsed struct {
+		Groups  []driveDupeGroup `json:"groups"`
+		Scanned int              `json:"scanned"`
+		Wasted  int64            `json:"wastedBytes"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, raw)
+	}
+	if parsed.Scanned != 7 || len(parsed.Groups) != 2 || parsed.Wasted != 2*2048+4 {
+		t.Fatalf("unexpected dupes: %s", raw)
+	}
+	bigGroup := parsed.Groups[0]
+	if bigGroup.Size != 2048 || len(bigGroup.Files) != 3 || bigGroup.Files[0].Path != "top/big.bin" ||
+		bigGroup.Files[1].Path != "top/sub/big-copy.bin" || len(bigGroup.Duplicates) != 2 || bigGroup.Duplicates[0] != ids["bigB"].ID {
+		t.Fatalf("unexpected big group: %#v", bigGroup)
+	}
+
+	raw = captureStdout(t, func() {
+		if err := runKong(t, &DriveDupesCmd{}, []string{"--folder", ids["top"].ID, "--min-size", "1k"}, ctx, flags); err != nil {
+			t.Fatalf("dupes min-size: %v", err)
+		}
+	})
+	parsed.Groups = nil
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil || len(parsed.Groups) != 1 || parsed.Groups[0].Size != 2048 {
+		t.Fatalf("expected only the large group, got %s (err=%v)", raw, err)
+	}
+
+	if err := runKong(t, &DriveDupesCmd{}, []string{"--min-size", "1XB"}, ctx, flags); err == nil || !strings.Contains(err.Error(), "--min-size") {
+		t.Fatalf("expected --min-size error, got %v", err)
+	}
+}
+
+func TestDriveDuCmd_Rollups(t *testing.T) {
+	ids := newDupesFixture(t)
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	flags := &RootFlags{Account: "a@b.com"}
+	text := captureStdout(t, func() {
+		if err := runKong(t, &DriveDuCmd{}, []string{ids["top"].ID}, ui.WithUI(context.Background(), u), flags); err != nil {
+			t.Fatalf("du: %v", err)
+		}
+	})
+	if !strings.Contains(text, "top/sub") || !strings.Contains(text, "top (total)") {
+		t.Fatalf("unexpected du output:\n%s", text)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	raw := captureStdout(t, func() {
+		if err := runKong(t, &DriveDuCmd{}, []string{ids["top"].ID}, ctx, flags); err != nil {
+			t.Fatalf("du json: %v", err)
+		}
+	})
+	var parsed struct {
+		Folder  driveDuEntry   `json:"folder"`
+		Folders []driveDuEntry `json:"folders"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, raw)
+	}
+	if parsed.Folder.Size != 3*2048+4+4+9 || parsed.Folder.Files != 7 || len(parsed.Folders) != 1 ||
+		parsed.Folders[0].ID != ids["sub"].ID || parsed.Folders[0].Size != 2*2048+4+9 || parsed.Folders[0].Files != 5 {
+		t.Fatalf("unexpected du: %s", raw)
+	}
+}
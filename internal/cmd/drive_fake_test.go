@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"crypto/md5" //nolint:gosec // test fake mirrors Drive md5Checksum
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeDriveFile struct {
+	ID       string
+	Name     string
+	MimeType string
+	Parent   string
+	Content  string
+	Modified time.Time
+	Trashed  bool
+	Target   string
+	DriveID  string
+	Perms    []map[string]any
+}
+
+// fakeDriveTree is an in-memory Drive v3 backend shared by the tree-walking
+// command tests (sync, copy, dupes, grep, shortcuts, sharing audit).
+type fakeDriveTree struct {
+	t     *testing.T
+	mu    sync.Mutex
+	next  int
+	files map[string]*fakeDriveFile
+	clock time.Time
+}
+
+func newFakeDriveTree(t *testing.T) *fakeDriveTree {
+	return &fakeDriveTree{t: t, files: map[string]*fakeDriveFile{}, clock: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeDriveTree) add(parent, name, mimeType, content string) *fakeDriveFile {
+	f.next++
+	f.clock = f.clock.Add(time.Minute)
+	file := &fakeDriveFile{ID: fmt.Sprintf("id%d", f.next), Name: name, MimeType: mimeType, Parent: parent, Content: content, Modified: f.clock}
+	f.files[file.ID] = file
+	return file
+}
+
+func (f *fakeDriveTree) byName(name string) *fakeDriveFile {
+	for _, file := range f.files {
+		if file.Name == name && !file.Trashed {
+			return file
+		}
+	}
+	return nil
+}
+
+func (f *fakeDriveTree) json(file *fakeDriveFile) map[string]any {
+	out := map[string]any{
+		"id":           file.ID,
+		"name":         file.Name,
+		"mimeType":     file.MimeType,
+		"modifiedTime": file.Modified.Format(time.RFC3339Nano),
+		"trashed":      file.Trashed,
+	}
+	if !strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
+		sum := md5.Sum([]byte(file.Content)) //nolint:gosec // test fake
+		out["md5Checksum"] = hex.EncodeToString(sum[:])
+		out["size"] = fmt.Sprint(len(file.Content))
+	}
+	if file.DriveID != "" {
+		out["driveId"] = file.DriveID
+	}
+	if len(file.Perms) > 0 {
+		out["shared"] = true
+	}
+	if file.Target != "" {
+		out["shortcutDetails"] = map[string]any{"targetId": file.Target}
+	}
+	return out
+}
+
+func (f *fakeDriveTree) readUpload(r *http.Request) (map[string]any, string) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		f.t.Fatalf("upload content type: %v", err)
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	var meta map[string]any
+	part, err := mr.NextPart()
+	if err != nil {
+		f.t.Fatalf("metadata part: %v", err)
+	}
+	_ = json.NewDecoder(part).Decode(&meta)
+	part, err = mr.NextPart()
+	if err != nil {
+		f.t.Fatalf("media part: %v", err)
+	}
+	body, _ := io.ReadAll(part)
+	return meta, string(body)
+}
+
+func (f *fakeDriveTree) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	upload := strings.HasPrefix(r.URL.Path, "/upload/")
+	p := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/upload"), "/drive/v3")
+	switch {
+	case p == "/files" && r.Method == http.MethodGet:
+		q := r.URL.Query().Get("q")
+		parent := strings.TrimSuffix(strings.TrimPrefix(q, "'"), "' in parents and trashed = false")
+		var out []map[string]any
+		var ids []string
+		for id, file := range f.files {
+			if file.Parent == parent && !file.Trashed {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			out = append(out, f.json(f.files[id]))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"files": out})
+	case p == "/files" && r.Method == http.MethodPost:
+		var (
+			meta    map[string]any
+			content string
+		)
+		if upload {
+			meta, content = f.readUpload(r)
+		} else {
+			_ = json.NewDecoder(r.Body).Decode(&meta)
+		}
+		parents, _ := meta["parents"].([]any)
+		mimeType, _ := meta["mimeType"].(string)
+		if mimeType == "" {
+			mimeType = "text/plain"
+		}
+		file := f.add(parents[0].(string), meta["name"].(string), mimeType, content)
+		if sc, ok := meta["shortcutDetails"].(map[string]any); ok {
+			file.Target, _ = sc["targetId"].(string)
+		}
+		_ = json.NewEncoder(w).Encode(f.json(file))
+	case strings.HasPrefix(p, "/files/") && strings.HasSuffix(p, "/copy"):
+		src := f.files[strings.TrimSuffix(strings.TrimPrefix(p, "/files/"), "/copy")]
+		var meta map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&meta)
+		parents, _ := meta["parents"].([]any)
+		file := f.add(parents[0].(string), meta["name"].(string), src.MimeType, src.Content)
+		_ = json.NewEncoder(w).Encode(f.json(file))
+	case strings.HasPrefix(p, "/files/") && strings.HasSuffix(p, "/permissions"):
+		file := f.files[strings.TrimSuffix(strings.TrimPrefix(p, "/files/"), "/permissions")]
+		if r.Method == http.MethodPost {
+			var perm map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&perm)
+			perm["id"] = fmt.Sprintf("perm%d", len(file.Perms)+1)
+			file.Perms = append(file.Perms, perm)
+			_ = json.NewEncoder(w).Encode(perm)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"permissions": file.Perms})
+	case strings.HasPrefix(p, "/files/") && strings.Contains(p, "/permissions/"):
+		fileID, permID, _ := strings.Cut(strings.TrimPrefix(p, "/files/"), "/permissions/")
+		file := f.files[fileID]
+		idx := -1
+		for i, perm := range file.Perms {
+			if perm["id"] == permID {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":{"code":404,"message":"Permission not found"}}`)
+			return
+		}
+		switch r.Method {
+		case http.MethodDelete:
+			file.Perms = append(file.Perms[:idx], file.Perms[idx+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case http.MethodPatch:
+			var patch map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&patch)
+			file.Perms[idx]["role"] = patch["role"]
+		}
+		_ = json.NewEncoder(w).Encode(file.Perms[idx])
+	case strings.HasPrefix(p, "/files/") && strings.HasSuffix(p, "/export"):
+		file := f.files[strings.TrimSuffix(strings.TrimPrefix(p, "/files/"), "/export")]
+		w.Header().Set("Content-Type", r.URL.Query().Get("mimeType"))
+		_, _ = io.WriteString(w, "exported:"+file.Content)
+	case strings.HasPrefix(p, "/files/"):
+		file := f.files[strings.TrimPrefix(p, "/files/")]
+		if file == nil {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("alt") == "media":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = io.WriteString(w, file.Content)
+			return
+		case r.Method == http.MethodPatch && upload:
+			_, file.Content = f.readUpload(r)
+			f.clock = f.clock.Add(time.Minute)
+			file.Modified = f.clock
+		case r.Method == http.MethodPatch:
+			if to := r.URL.Query().Get("addParents"); to != "" {
+				// Like Drive, refuse to move folders across drive boundaries.
+				if file.MimeType == driveMimeFolder && f.files[to].DriveID != file.DriveID {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = io.WriteString(w, `{"error":{"code":403,"message":"folder move not supported","errors":[{"reason":"teamDrivesFolderMoveInNotSupported"}]}}`)
+					return
+				}
+				file.Parent = to
+			}
+			var meta map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&meta)
+			if trashed, ok := meta["trashed"].(bool); ok {
+				file.Trashed = trashed
+			}
+		}
+		_ = json.NewEncoder(w).Encode(f.json(file))
+	default:
+		http.NotFound(w, r)
+	}
+}
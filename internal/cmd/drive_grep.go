@@ -0,0 +1,494 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/pdftext"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type DriveGrepCmd struct {
+	Pattern     string `arg:"" name:"pattern" help:"Regular expression (RE2 syntax) to search for"`
+	Folder      string `name:"folder" help:"Only search under this folder (recursive)"`
+	Query       string `name:"query" help:"Drive query that selects the files to search (without --folder)"`
+	IgnoreCase  bool   `name:"ignore-case" short:"i" help:"Case-insensitive match"`
+	Fixed       bool   `name:"fixed-strings" short:"F" help:"Treat the pattern as a literal string"`
+	Context     int    `name:"context" short:"C" help:"Lines of context around each match"`
+	FilesOnly   bool   `name:"files-with-matches" short:"l" help:"Only print the files that match"`
+	Tab         string `name:"tab" help:"Google Docs: only search this tab (title or ID)"`
+	AllTabs     bool   `name:"all-tabs" help:"Google Docs: search every tab and report the tab for each match"`
+	MaxFiles    int    `name:"max-files" help:"Maximum files to search" default:"200"`
+	MaxSize     string `name:"max-size" help:"Skip downloaded (non-Google) files larger than this" default:"20MB"`
+	Concurrency int    `name:"concurrency" help:"Parallel exports/downloads" default:"4"`
+	NoCache     bool   `name:"no-cache" help:"Always re-export instead of reusing the local export cache"`
+}
+
+type driveGrepMatch struct {
+	FileID   string   `json:"fileId"`
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	MimeType string   `json:"mimeType"`
+	Tab      string   `json:"tab,omitempty"`
+	Line     int      `json:"line"`
+	Text     string   `json:"text"`
+	Before   []string `json:"before,omitempty"`
+	After    []string `json:"after,omitempty"`
+}
+
+type driveGrepSkip struct {
+	FileID string `json:"fileId"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// driveGrepText is one searchable unit: a whole file, or one tab of a Doc.
+type driveGrepText struct {
+	tab  string
+	text string
+}
+
+func (c *DriveGrepCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	re, err := c.compile()
+	if err != nil {
+		return err
+	}
+	maxSize, err := parseByteSize(c.MaxSize)
+	if err != nil {
+		return usagef("invalid --max-size: %v", err)
+	}
+	folderID := normalizeGoogleID(strings.TrimSpace(c.Folder))
+	userQuery := strings.TrimSpace(c.Query)
+	if folderID != "" && userQuery != "" {
+		return usage("--query cannot be combined with --folder")
+	}
+	literal := c.Fixed || regexp.QuoteMeta(c.Pattern) == c.Pattern
+	if folderID == "" && userQuery == "" && !literal {
+		return usage("regular expressions need --folder or --query to bound the search (or use -F for a literal)")
+	}
+	if c.Tab != "" && c.AllTabs {
+		return usage("--tab cannot be combined with --all-tabs")
+	}
+	if c.Context < 0 {
+		return usage("--context must be >= 0")
+	}
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	maxFiles := c.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = 200
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+
+	paths := newDrivePathResolver(svc)
+	var candidates []*drive.File
+	if folderID != "" {
+		candidates, err = listDriveFolderFilesWithPaths(ctx, svc, folderID, paths)
+	} else {
+		candidates, err = listDriveGrepCandidates(ctx, svc, c.Pattern, userQuery, literal)
+	}
+	if err != nil {
+		return err
+	}
+	var skipped []driveGrepSkip
+	files := make([]*drive.File, 0, len(candidates))
+	for _, f := range candidates {
+		switch {
+		case driveGrepExportFormat(f.MimeType) == "" && !driveGrepDownloadable(f.MimeType):
+			continue
+		case !strings.HasPrefix(f.MimeType, "application/vnd.google-apps.") && maxSize > 0 && f.Size > maxSize:
+			skipped = append(skipped, driveGrepSkip{FileID: f.Id, Name: f.Name, Reason: "larger than --max-size"})
+			continue
+		}
+		files = append(files, f)
+	}
+	for _, f := range files {
+		if _, ok := paths.known[f.Id]; !ok {
+			paths.known[f.Id] = f.Name
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return paths.known[files[i].Id] < paths.known[files[j].Id] })
+	if len(files) > maxFiles {
+		u.Err().Printf("Searching the first %d of %d files (raise --max-files to search more)", maxFiles, len(files))
+		files = files[:maxFiles]
+	}
+
+	cacheDir := ""
+	if !c.NoCache {
+		if cacheDir, err = config.EnsureDriveGrepCacheDir(); err != nil {
+			return err
+		}
+	} else {
+		tmp, tmpErr := os.MkdirTemp("", "gog-drive-grep-*")
+		if tmpErr != nil {
+			return tmpErr
+		}
+		defer os.RemoveAll(tmp)
+		cacheDir = tmp
+	}
+
+	var docsSvc *docs.Service
+	if c.Tab != "" || c.AllTabs {
+		if docsSvc, err = requireDocsService(ctx, flags); err != nil {
+			return err
+		}
+	}
+
+	texts := make([][]driveGrepText, len(files))
+	errs := runDriveBounded(ctx, concurrency, len(files), func(ctx context.Context, i int) error {
+		var textErr error
+		texts[i], textErr = c.fileTexts(ctx, svc, docsSvc, files[i], cacheDir)
+		return textErr
+	})
+
+	var matches []driveGrepMatch
+	matchedFiles := 0
+	for i, f := range files {
+		if errs[i] != nil {
+			if errors.Is(errs[i], context.Canceled) {
+				return errs[i]
+			}
+			skipped = append(skipped, driveGrepSkip{FileID: f.Id, Name: f.Name, Reason: errs[i].Error()})
+			continue
+		}
+		found := false
+		for _, t := range texts[i] {
+			for _, m := range grepDriveText(re, t.text, c.Context) {
+				m.FileID, m.Name, m.Path, m.MimeType, m.Tab = f.Id, f.Name, paths.known[f.Id], f.MimeType, t.tab
+				matches = append(matches, m)
+				found = true
+			}
+		}
+		if found {
+			matchedFiles++
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if matches == nil {
+			matches = []driveGrepMatch{}
+		}
+		if skipped == nil {
+			skipped = []driveGrepSkip{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"matches":      matches,
+			"searched":     len(files),
+			"matchedFiles": matchedFiles,
+			"skipped":      skipped,
+		})
+	}
+
+	for _, s := range skipped {
+		u.Err().Printf("skipped %s (%s): %s", s.Name, s.FileID, s.Reason)
+	}
+	if len(matches) == 0 {
+		u.Err().Printf("No matches (%d files searched)", len(files))
+		return nil
+	}
+	writeDriveGrepMatches(u, matches, c.FilesOnly)
+	return nil
+}
+
+func (c *DriveGrepCmd) compile() (*regexp.Regexp, error) {
+	pattern := c.Pattern
+	if pattern == "" {
+		return nil, usage("empty pattern")
+	}
+	if c.Fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if c.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, usagef("invalid pattern: %v", err)
+	}
+	return re, nil
+}
+
+// fileTexts returns the searchable text of f. Docs with --tab/--all-tabs go
+// through the Docs API like `docs cat`; everything else is exported or
+// downloaded into cacheDir.
+func (c *DriveGrepCmd) fileTexts(ctx context.Context, svc *drive.Service, docsSvc *docs.Service, f *drive.File, cacheDir string) ([]driveGrepText, error) {
+	if docsSvc != nil && f.MimeType == driveMimeGoogleDoc {
+		return c.docTabTexts(ctx, docsSvc, f)
+	}
+	data, err := fetchDriveGrepContent(ctx, svc, f, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	if f.MimeType == mimePDF {
+		text := pdftext.Extract(data)
+		if strings.TrimSpace(text) == "" {
+			return nil, errors.New("no extractable text (scanned or image-only PDF)")
+		}
+		return []driveGrepText{{text: text}}, nil
+	}
+	if !utf8.Valid(data) {
+		return nil, errors.New("not UTF-8 text")
+	}
+	return []driveGrepText{{text: string(data)}}, nil
+}
+
+func (c *DriveGrepCmd) docTabTexts(ctx context.Context, docsSvc *docs.Service, f *drive.File) ([]driveGrepText, error) {
+	doc, err := docsSvc.Documents.Get(f.Id).IncludeTabsContent(true).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	tabs := flattenTabs(doc.Tabs)
+	if c.Tab != "" {
+		tab := findTab(tabs, c.Tab)
+		if tab == nil {
+			return nil, nil
+		}
+		return []driveGrepText{{tab: tabTitle(tab), text: tabPlainText(tab, 0)}}, nil
+	}
+	out := make([]driveGrepText, 0, len(tabs))
+	for _, tab := range tabs {
+		out = append(out, driveGrepText{tab: tabTitle(tab), text: tabPlainText(tab, 0)})
+	}
+	return out, nil
+}
+
+// fetchDriveGrepContent exports or downloads f through the same path as
+// `drive download`, caching the result under a name keyed by modifiedTime so
+// unchanged files are not fetched again.
+func fetchDriveGrepContent(ctx context.Context, svc *drive.Service, f *drive.File, cacheDir string) ([]byte, error) {
+	format := driveGrepExportFormat(f.MimeType)
+	ext := ".dat"
+	if format != "" {
+		exportMime, err := driveExportMimeTypeForFormat(f.MimeType, format)
+		if err != nil {
+			return nil, err
+		}
+		ext = driveExportExtension(exportMime)
+	}
+	stamp := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return -1
+	}, f.ModifiedTime)
+	cachePath := filepath.Join(cacheDir, fmt.Sprintf("%s-%s%s", filepath.Base(f.Id), stamp, ext))
+	if stamp != "" {
+		if data, err := os.ReadFile(cachePath); err == nil { //nolint:gosec // app-owned cache path
+			return data, nil
+		}
+	}
+
+	outPath, _, err := downloadDriveFile(ctx, svc, f, cachePath, format)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(outPath) //nolint:gosec // app-owned cache path
+	if err != nil {
+		return nil, err
+	}
+	if stamp == "" {
+		_ = os.Remove(outPath)
+		return data, nil
+	}
+	// Drop exports of older revisions of the same file.
+	if old, globErr := filepath.Glob(filepath.Join(cacheDir, filepath.Base(f.Id)+"-*")); globErr == nil {
+		for _, p := range old {
+			if p != outPath {
+				_ = os.Remove(p)
+			}
+		}
+	}
+	return data, nil
+}
+
+// driveGrepExportFormat is the text export format for Google Workspace files
+// grep can read, or "" for everything else. Sheets export their first sheet.
+func driveGrepExportFormat(mimeType string) string {
+	switch mimeType {
+	case driveMimeGoogleDoc, driveMimeGoogleSlides:
+		return "txt"
+	case driveMimeGoogleSheet:
+		return "csv"
+	default:
+		return ""
+	}
+}
+
+func driveGrepDownloadable(mimeType string) bool {
+	if strings.HasPrefix(mimeType, "text/") || mimeType == mimePDF {
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/javascript", "application/x-sh",
+		"application/x-yaml", "application/yaml", "application/sql", "application/x-httpd-php":
+		return true
+	}
+	return false
+}
+
+// listDriveGrepCandidates finds files outside a folder walk. Literal patterns
+// are narrowed with Drive's fullText index; regexes rely on the user query.
+func listDriveGrepCandidates(ctx context.Context, svc *drive.Service, pattern, userQuery string, literal bool) ([]*drive.File, error) {
+	var clauses []string
+	if userQuery != "" {
+		clauses = append(clauses, "("+userQuery+")")
+	}
+	if literal {
+		clauses = append(clauses, fmt.Sprintf("fullText contains '%s'", escapeDriveQueryString(pattern)))
+	}
+	clauses = append(clauses, fmt.Sprintf("mimeType != '%s'", driveMimeFolder))
+	q := strings.Join(clauses, " and ")
+	if !hasDriveTrashedPredicate(q) {
+		q += " and trashed = false"
+	}
+
+	var out []*drive.File
+	pageToken := ""
+	for {
+		call := svc.Files.List().
+			Q(q).
+			PageSize(driveTreeListPerPage).
+			Corpora("allDrives").
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			Fields(gapi.Field("nextPageToken, files(" + driveDupesFileFields + ")")).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Files {
+			if f != nil {
+				out = append(out, f)
+			}
+		}
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// grepDriveText returns one match per matching line with up to ctxLines of
+// context on either side.
+func grepDriveText(re *regexp.Regexp, text string, ctxLines int) []driveGrepMatch {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	var out []driveGrepMatch
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		m := driveGrepMatch{Line: i + 1, Text: strings.TrimPrefix(line, "\ufeff")}
+		if ctxLines > 0 {
+			m.Before = append([]string(nil), lines[max(0, i-ctxLines):i]...)
+			m.After = append([]string(nil), lines[i+1:min(len(lines), i+1+ctxLines)]...)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// writeDriveGrepMatches prints grep-style "path:line:text" output. Context
+// lines use "path-line-text", overlapping context is merged, and "--"
+// separates hunks when context is on. Matches are grouped by file ID and
+// tab, since Drive allows several files with the same path.
+func writeDriveGrepMatches(u *ui.UI, matches []driveGrepMatch, filesOnly bool) {
+	type grepFile struct {
+		id, tab string
+	}
+	label := func(m driveGrepMatch) string {
+		if m.Tab != "" {
+			return fmt.Sprintf("%s [%s]", m.Path, m.Tab)
+		}
+		return m.Path
+	}
+	if filesOnly {
+		seen := map[grepFile]bool{}
+		for _, m := range matches {
+			if key := (grepFile{m.FileID, m.Tab}); !seen[key] {
+				seen[key] = true
+				u.Out().Println(label(m))
+			}
+		}
+		return
+	}
+
+	type grepLine struct {
+		text  string
+		match bool
+	}
+	var order []grepFile
+	names := map[grepFile]string{}
+	byFile := map[grepFile]map[int]grepLine{}
+	withContext := false
+	for _, m := range matches {
+		key := grepFile{m.FileID, m.Tab}
+		lines, ok := byFile[key]
+		if !ok {
+			lines = map[int]grepLine{}
+			byFile[key] = lines
+			names[key] = label(m)
+			order = append(order, key)
+		}
+		first := m.Line - len(m.Before)
+		for j, text := range m.Before {
+			if _, ok := lines[first+j]; !ok {
+				lines[first+j] = grepLine{text: text}
+			}
+		}
+		for j, text := range m.After {
+			if _, ok := lines[m.Line+j+1]; !ok {
+				lines[m.Line+j+1] = grepLine{text: text}
+			}
+		}
+		lines[m.Line] = grepLine{text: m.Text, match: true}
+		withContext = withContext || len(m.Before) > 0 || len(m.After) > 0
+	}
+
+	printed := false
+	for _, key := range order {
+		name, lines := names[key], byFile[key]
+		nums := make([]int, 0, len(lines))
+		for n := range lines {
+			nums = append(nums, n)
+		}
+		sort.Ints(nums)
+		for i, n := range nums {
+			if withContext && printed && (i == 0 || n > nums[i-1]+1) {
+				u.Out().Println("--")
+			}
+			sep := "-"
+			if lines[n].match {
+				sep = ":"
+			}
+			u.Out().Printf("%s%s%d%s%s", name, sep, n, sep, lines[n].text)
+			printed = true
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+func TestDriveGrepCmd_FolderSearch(t *testing.T) {
+	setWatchTestConfigHome(t)
+	api := newFakeDriveTree(t)
+	top := api.add("root", "top", driveMimeFolder, "")
+	api.add(top.ID, "notes.txt", "text/plain", "alpha\nbeta needle\ngamma\ndelta\n")
+	api.add(top.ID, "Plan", driveMimeGoogleDoc, "needle in doc")
+	api.add(top.ID, "photo.png", "image/png", "needle")
+	api.add(top.ID, "big.txt", "text/plain", strings.Repeat("needle ", 100))
+	sub := api.add(top.ID, "sub", driveMimeFolder, "")
+	api.add(sub.ID, "report.pdf", mimePDF, "%PDF-1.4\n1 0 obj\n<< /Length 30 >>\nstream\nBT (Needle in a pdf) Tj ET\nendstream\nendobj\n")
+	api.add(sub.ID, "scan.pdf", mimePDF, "%PDF-1.4\n1 0 obj\n<< /Subtype /Image >>\nendobj\n")
+	var fetches atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/export") || r.URL.Query().Get("alt") == "media" {
+			fetches.Add(1)
+		}
+		api.ServeHTTP(w, r)
+	})
+	svc, closeSrv := newDriveTestService(t, h)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+
+	var parsed struct {
+		Matches  []driveGrepMatch `json:"matches"`
+		Searched int              `json:"searched"`
+		Matched  int              `json:"matchedFiles"`
+		Skipped  []driveGrepSkip  `json:"skipped"`
+	}
+	raw := runDriveJSON(t, &DriveGrepCmd{}, "needle", "--folder", top.ID, "-i", "-C", "1", "--max-size", "500")
+	b, _ := json.Marshal(raw)
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if parsed.Searched != 4 || parsed.Matched != 3 || len(parsed.Matches) != 3 || len(parsed.Skipped) != 2 || parsed.Skipped[0].Name != "big.txt" ||
+		parsed.Skipped[1].Name != "scan.pdf" || !strings.Contains(parsed.Skipped[1].Reason, "no extractable text") {
+		t.Fatalf("unexpected grep result: %s", b)
+	}
+	plan, notes, pdf := parsed.Matches[0], parsed.Matches[1], parsed.Matches[2]
+	if plan.Path != "top/Plan" || plan.Text != "exported:needle in doc" {
+		t.Fatalf("unexpected doc match: %#v", plan)
+	}
+	if notes.Path != "top/notes.txt" || notes.Line != 2 || len(notes.Before) != 1 || notes.Before[0] != "alpha" || notes.After[0] != "gamma" {
+		t.Fatalf("unexpected text match: %#v", notes)
+	}
+	if pdf.Path != "top/sub/report.pdf" || pdf.Text != "Needle in a pdf" {
+		t.Fatalf("unexpected pdf match: %#v", pdf)
+	}
+
+	// Unchanged files come from the modifiedTime-keyed cache.
+	before := fetches.Load()
+	runDriveJSON(t, &DriveGrepCmd{}, "needle", "--folder", top.ID, "--max-size", "500")
+	if fetches.Load() != before {
+		t.Fatalf("expected cached exports, got %d new fetches", fetches.Load()-before)
+	}
+	runDriveJSON(t, &DriveGrepCmd{}, "needle", "--folder", top.ID, "--no-cache")
+	if fetches.Load() == before {
+		t.Fatal("--no-cache should fetch again")
+	}
+
+	var out bytes.Buffer
+	u, err := ui.New(ui.Options{Stdout: &out, Stderr: &bytes.Buffer{}, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	if err := runKong(t, &DriveGrepCmd{}, []string{"-F", "a", "--folder", top.ID, "-C", "1"}, ui.WithUI(context.Background(), u), &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("grep text: %v", err)
+	}
+	if !strings.Contains(out.String(), "top/notes.txt:1:alpha\ntop/notes.txt:2:beta needle\ntop/notes.txt:3:gamma\ntop/notes.txt:4:delta\n") {
+		t.Fatalf("unexpected text output:\n%s", out.String())
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"ne+dle"}, "--folder or --query"},
+		{[]string{"needle", "--folder", top.ID, "--query", "x"}, "cannot be combined"},
+		{[]string{"needle", "--tab", "A", "--all-tabs"}, "--all-tabs"},
+		{[]string{"(", "--folder", top.ID}, "invalid pattern"},
+	} {
+		if err := runKong(t, &DriveGrepCmd{}, tc.args, ctx, &RootFlags{Account: "a@b.com"}); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+}
+
+func TestWriteDriveGrepMatches_MergesContext(t *testing.T) {
+	var out bytes.Buffer
+	u, err := ui.New(ui.Options{Stdout: &out, Stderr: &bytes.Buffer{}, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	text := "one\nhit two\nthree\nhit four\nfive\nsix\nseven\nhit eight\n"
+	matches := grepDriveText(mustCompileGrep(t, "hit"), text, 1)
+	for i := range matches {
+		matches[i].Path, matches[i].Tab = "doc", "Notes"
+	}
+	writeDriveGrepMatches(u, matches, false)
+	want := "doc [Notes]-1-one\ndoc [Notes]:2:hit two\ndoc [Notes]-3-three\ndoc [Notes]:4:hit four\ndoc [Notes]-5-five\n--\n" +
+		"doc [Notes]-7-seven\ndoc [Notes]:8:hit eight\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestWriteDriveGrepMatches_KeepsSameNamedFilesApart(t *testing.T) {
+	var out bytes.Buffer
+	u, err := ui.New(ui.Options{Stdout: &out, Stderr: &bytes.Buffer{}, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	matches := []driveGrepMatch{
+		{FileID: "f1", Path: "dir/notes.txt", Line: 1, Text: "hit one"},
+		{FileID: "f2", Path: "dir/notes.txt", Line: 1, Text: "hit two"},
+	}
+	writeDriveGrepMatches(u, matches, false)
+	if want := "dir/notes.txt:1:hit one\ndir/notes.txt:1:hit two\n"; out.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeDriveGrepMatches(u, matches, true)
+	if want := "dir/notes.txt\ndir/notes.txt\n"; out.String() != want {
+		t.Fatalf("unexpected files-only output:\n%s", out.String())
+	}
+}
+
+func mustCompileGrep(t *testing.T, pattern string) *regexp.Regexp {
+	t.Helper()
+	re, err := (&DriveGrepCmd{Pattern: pattern}).compile()
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	return re
+}
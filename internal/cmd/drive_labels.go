@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/drivelabels/v2"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var newDriveLabelsService = googleapi.NewDriveLabels
+
+const (
+	driveLabelFieldSelection = "selection"
+	driveLabelFieldText      = "text"
+	driveLabelFieldDate      = "date"
+	driveLabelFieldUser      = "user"
+	driveLabelFieldInteger   = "integer"
+)
+
+type DriveLabelsCmd struct {
+	List  DriveLabelsListCmd  `cmd:"" name:"list" aliases:"ls" help:"List label schemas available to you"`
+	Get   DriveLabelsGetCmd   `cmd:"" name:"get" help:"Show labels applied to a file"`
+	Set   DriveLabelsSetCmd   `cmd:"" name:"set" help:"Apply a label and set field values on a file"`
+	Unset DriveLabelsUnsetCmd `cmd:"" name:"unset" help:"Remove a label, or clear some of its fields, on a file"`
+}
+
+type DriveLabelsListCmd struct {
+	All bool `name:"all" help:"Include unpublished and disabled labels you can see"`
+}
+
+func (c *DriveLabelsListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newDriveLabelsService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	var labels []*drivelabels.GoogleAppsDriveLabelsV2Label
+	err = svc.Labels.List().
+		View("LABEL_VIEW_FULL").
+		PublishedOnly(!c.All).
+		PageSize(200).
+		Pages(ctx, func(resp *drivelabels.GoogleAppsDriveLabelsV2ListLabelsResponse) error {
+			labels = append(labels, resp.Labels...)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if labels == nil {
+			labels = []*drivelabels.GoogleAppsDriveLabelsV2Label{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"labels": labels})
+	}
+	if len(labels) == 0 {
+		u.Err().Println("No labels")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "LABEL_ID\tTITLE\tFIELD_ID\tFIELD\tTYPE\tCHOICES")
+	for _, l := range labels {
+		title := ""
+		if l.Properties != nil {
+			title = l.Properties.Title
+		}
+		if len(l.Fields) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\n", l.Id, sanitizeTab(title))
+		}
+		for _, f := range l.Fields {
+			choices := "-"
+			if f.SelectionOptions != nil {
+				var names []string
+				for _, ch := range f.SelectionOptions.Choices {
+					names = append(names, ch.Id+"="+driveLabelChoiceName(ch))
+				}
+				choices = strings.Join(names, ", ")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				l.Id, sanitizeTab(title), f.Id, sanitizeTab(driveLabelFieldName(f)), driveLabelFieldType(f), sanitizeTab(choices))
+		}
+	}
+	return nil
+}
+
+type DriveLabelsGetCmd struct {
+	FileID string `arg:"" name:"fileId" help:"File ID"`
+}
+
+func (c *DriveLabelsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	if fileID == "" {
+		return usage("empty fileId")
+	}
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	var labels []*drive.Label
+	err = svc.Files.ListLabels(fileID).MaxResults(100).Pages(ctx, func(resp *drive.LabelList) error {
+		labels = append(labels, resp.Labels...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if labels == nil {
+			labels = []*drive.Label{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"fileId": fileID, "labels": labels})
+	}
+	if len(labels) == 0 {
+		u.Err().Println("No labels")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "LABEL_ID\tFIELD_ID\tTYPE\tVALUE")
+	for _, l := range labels {
+		if len(l.Fields) == 0 {
+			fmt.Fprintf(w, "%s\t-\t-\t-\n", l.Id)
+			continue
+		}
+		ids := make([]string, 0, len(l.Fields))
+		for id := range l.Fields {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			f := l.Fields[id]
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", l.Id, id, f.ValueType, sanitizeTab(strings.Join(driveLabelFieldValues(f), ", ")))
+		}
+	}
+	return nil
+}
+
+func driveLabelFieldValues(f drive.LabelField) []string {
+	var out []string
+	out = append(out, f.Selection...)
+	out = append(out, f.Text...)
+	out = append(out, f.DateString...)
+	for _, n := range f.Integer {
+		out = append(out, strconv.FormatInt(n, 10))
+	}
+	for _, u := range f.User {
+		if u != nil {
+			out = append(out, u.EmailAddress)
+		}
+	}
+	return out
+}
+
+type DriveLabelsSetCmd struct {
+	FileID string   `arg:"" name:"fileId" help:"File ID"`
+	Label  string   `name:"label" required:"" help:"Label ID"`
+	Field  []string `name:"field" help:"Field value as field=value (field ID or name; selection choices by ID or name; repeat for multi-value fields)"`
+}
+
+func (c *DriveLabelsSetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	labelID := strings.TrimSpace(c.Label)
+	if fileID == "" || labelID == "" {
+		return usage("fileId and --label are required")
+	}
+	mod := &drive.LabelModification{LabelId: labelID}
+	if len(c.Field) > 0 {
+		schema, err := getDriveLabelSchema(ctx, flags, labelID)
+		if err != nil {
+			return err
+		}
+		mods, err := buildDriveLabelFieldSets(schema, c.Field)
+		if err != nil {
+			return err
+		}
+		mod.FieldModifications = mods
+	}
+	return modifyDriveLabels(ctx, flags, fileID, mod)
+}
+
+type DriveLabelsUnsetCmd struct {
+	FileID string   `arg:"" name:"fileId" help:"File ID"`
+	Label  string   `name:"label" required:"" help:"Label ID"`
+	Field  []string `name:"field" help:"Only clear these fields (ID or name); default removes the whole label"`
+}
+
+func (c *DriveLabelsUnsetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	labelID := strings.TrimSpace(c.Label)
+	if fileID == "" || labelID == "" {
+		return usage("fileId and --label are required")
+	}
+	mod := &drive.LabelModification{LabelId: labelID}
+	if len(c.Field) == 0 {
+		mod.RemoveLabel = true
+	} else {
+		schema, err := getDriveLabelSchema(ctx, flags, labelID)
+		if err != nil {
+			return err
+		}
+		for _, name := range c.Field {
+			field := findDriveLabelField(schema, strings.TrimSpace(name))
+			if field == nil {
+				return usagef("label %s has no field %q", labelID, name)
+			}
+			mod.FieldModifications = append(mod.FieldModifications, &drive.LabelFieldModification{FieldId: field.Id, UnsetValues: true})
+		}
+	}
+	return modifyDriveLabels(ctx, flags, fileID, mod)
+}
+
+func modifyDriveLabels(ctx context.Context, flags *RootFlags, fileID string, mod *drive.LabelModification) error {
+	u := ui.FromContext(ctx)
+	req := &drive.ModifyLabelsRequest{LabelModifications: []*drive.LabelModification{mod}}
+	if err := dryRunExit(ctx, flags, "drive.labels.modify", map[string]any{"file_id": fileID, "request": req}); err != nil {
+		return err
+	}
+	if mod.RemoveLabel {
+		if err := confirmDestructiveChecked(ctx, flags, fmt.Sprintf("remove label %s from %s", mod.LabelId, fileID)); err != nil {
+			return err
+		}
+	}
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	resp, err := svc.Files.ModifyLabels(fileID, req).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"fileId": fileID, "modifiedLabels": resp.ModifiedLabels})
+	}
+	if mod.RemoveLabel {
+		return writeResult(ctx, u, kv("removed", true), kv("label", mod.LabelId), kv("id", fileID))
+	}
+	return writeResult(ctx, u, kv("label", mod.LabelId), kv("fields", len(mod.FieldModifications)), kv("id", fileID))
+}
+
+func getDriveLabelSchema(ctx context.Context, flags *RootFlags, labelID string) (*drivelabels.GoogleAppsDriveLabelsV2Label, error) {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := newDriveLabelsService(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Labels.Get("labels/" + strings.TrimPrefix(labelID, "labels/")).
+		View("LABEL_VIEW_FULL").
+		Context(ctx).
+		Do()
+}
+
+// buildDriveLabelFieldSets turns field=value pairs into one modification per
+// field, collecting repeated fields into multi-value sets.
+func buildDriveLabelFieldSets(schema *drivelabels.GoogleAppsDriveLabelsV2Label, pairs []string) ([]*drive.LabelFieldModification, error) {
+	byField := map[string]*drive.LabelFieldModification{}
+	var order []string
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, usagef("invalid --field %q (expected field=value)", pair)
+		}
+		field := findDriveLabelField(schema, name)
+		if field == nil {
+			return nil, usagef("label %s has no field %q", schema.Id, name)
+		}
+		mod, seen := byField[field.Id]
+		if !seen {
+			mod = &drive.LabelFieldModification{FieldId: field.Id}
+			byField[field.Id] = mod
+			order = append(order, field.Id)
+		}
+		switch driveLabelFieldType(field) {
+		case driveLabelFieldSelection:
+			choice := findDriveLabelChoice(field, value)
+			if choice == "" {
+				return nil, usagef("field %q has no choice %q", name, value)
+			}
+			mod.SetSelectionValues = append(mod.SetSelectionValues, choice)
+		case driveLabelFieldText:
+			mod.SetTextValues = append(mod.SetTextValues, value)
+		case driveLabelFieldDate:
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return nil, usagef("field %q: invalid date %q (use YYYY-MM-DD)", name, value)
+			}
+			mod.SetDateValues = append(mod.SetDateValues, value)
+		case driveLabelFieldUser:
+			if !strings.Contains(value, "@") {
+				return nil, usagef("field %q: expected an email address, got %q", name, value)
+			}
+			mod.SetUserValues = append(mod.SetUserValues, value)
+		case driveLabelFieldInteger:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, usagef("field %q: invalid integer %q", name, value)
+			}
+			mod.SetIntegerValues = append(mod.SetIntegerValues, n)
+		default:
+			return nil, usagef("field %q has an unsupported type", name)
+		}
+	}
+	out := make([]*drive.LabelFieldModification, 0, len(order))
+	for _, id := range order {
+		out = append(out, byField[id])
+	}
+	return out, nil
+}
+
+func findDriveLabelField(schema *drivelabels.GoogleAppsDriveLabelsV2Label, name string) *drivelabels.GoogleAppsDriveLabelsV2Field {
+	for _, f := range schema.Fields {
+		if f.Id == name {
+			return f
+		}
+	}
+	for _, f := range schema.Fields {
+		if strings.EqualFold(driveLabelFieldName(f), name) {
+			return f
+		}
+	}
+	return nil
+}
+
+func findDriveLabelChoice(field *drivelabels.GoogleAppsDriveLabelsV2Field, value string) string {
+	for _, ch := range field.SelectionOptions.Choices {
+		if ch.Id == value {
+			return ch.Id
+		}
+	}
+	for _, ch := range field.SelectionOptions.Choices {
+		if strings.EqualFold(driveLabelChoiceName(ch), value) {
+			return ch.Id
+		}
+	}
+	return ""
+}
+
+func driveLabelFieldName(f *drivelabels.GoogleAppsDriveLabelsV2Field) string {
+	if f.Properties != nil && f.Properties.DisplayName != "" {
+		return f.Properties.DisplayName
+	}
+	return f.Id
+}
+
+func driveLabelChoiceName(ch *drivelabels.GoogleAppsDriveLabelsV2FieldSelectionOptionsChoice) string {
+	if ch.Properties != nil && ch.Properties.DisplayName != "" {
+		return ch.Properties.DisplayName
+	}
+	return ch.Id
+}
+
+func driveLabelFieldType(f *drivelabels.GoogleAppsDriveLabelsV2Field) string {
+	switch {
+	case f.SelectionOptions != nil:
+		return driveLabelFieldSelection
+	case f.TextOptions != nil:
+		return driveLabelFieldText
+	case f.DateOptions != nil:
+		return driveLabelFieldDate
+	case f.UserOptions != nil:
+		return driveLabelFieldUser
+	case f.IntegerOptions != nil:
+		return driveLabelFieldInteger
+	default:
+		return ""
+	}
+}
+
+// buildDriveLabelQuery turns --label and --label-field filters into Drive
+// query clauses, joined with "and".
+func buildDriveLabelQuery(labels, fields []string) (string, error) {
+	var clauses []string
+	for _, id := range labels {
+		id = strings.TrimPrefix(strings.TrimSpace(id), "labels/")
+		if id == "" {
+			continue
+		}
+		clauses = append(clauses, fmt.Sprintf("'labels/%s' in labels", escapeDriveQueryString(id)))
+	}
+	for _, f := range fields {
+		key, value, ok := strings.Cut(f, "=")
+		key = strings.TrimPrefix(strings.TrimSpace(key), "labels/")
+		labelID, fieldID, hasField := strings.Cut(key, ".")
+		if !ok || !hasField || labelID == "" || fieldID == "" {
+			return "", usagef("invalid --label-field %q (expected labelId.fieldId=value)", f)
+		}
+		clauses = append(clauses, fmt.Sprintf("labels/%s.%s = '%s'", labelID, fieldID, escapeDriveQueryString(strings.TrimSpace(value))))
+	}
+	return strings.Join(clauses, " and "), nil
+}
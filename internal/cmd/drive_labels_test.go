@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/drivelabels/v2"
+	"google.golang.org/api/option"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+var fakeDriveLabelSchema = map[string]any{
+	"id":         "lbl1",
+	"properties": map[string]any{"title": "Classification"},
+	"fields": []any{
+		map[string]any{"id": "f_status", "properties": map[string]any{"displayName": "Status"}, "selectionOptions": map[string]any{
+			"choices": []any{
+				map[string]any{"id": "c_draft", "properties": map[string]any{"displayName": "Draft"}},
+				map[string]any{"id": "c_final", "properties": map[string]any{"displayName": "Final"}},
+			},
+		}},
+		map[string]any{"id": "f_note", "properties": map[string]any{"displayName": "Note"}, "textOptions": map[string]any{}},
+		map[string]any{"id": "f_due", "properties": map[string]any{"displayName": "Due"}, "dateOptions": map[string]any{}},
+		map[string]any{"id": "f_owner", "properties": map[string]any{"displayName": "Owner"}, "userOptions": map[string]any{}},
+	},
+}
+
+type fakeDriveLabels struct {
+	mu       sync.Mutex
+	modified []*drive.ModifyLabelsRequest
+	queries  []string
+}
+
+func (f *fakeDriveLabels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	p := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+	switch {
+	case p == "/v2/labels":
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": []any{fakeDriveLabelSchema}})
+	case p == "/v2/labels/lbl1":
+		_ = json.NewEncoder(w).Encode(fakeDriveLabelSchema)
+	case strings.HasPrefix(p, "/v2/labels/"):
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"label not found"}}`))
+	case p == "/files/file1/listLabels":
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": []any{map[string]any{
+			"id": "lbl1",
+			"fields": map[string]any{
+				"f_status": map[string]any{"id": "f_status", "valueType": "selection", "selection": []string{"c_draft"}},
+				"f_owner":  map[string]any{"id": "f_owner", "valueType": "user", "user": []any{map[string]any{"emailAddress": "ann@example.com"}}},
+			},
+		}}})
+	case p == "/files/file1/modifyLabels":
+		var req drive.ModifyLabelsRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.modified = append(f.modified, &req)
+		_ = json.NewEncoder(w).Encode(map[string]any{"modifiedLabels": []any{map[string]any{"id": "lbl1"}}})
+	case p == "/files":
+		f.queries = append(f.queries, r.URL.Query().Get("q"))
+		_ = json.NewEncoder(w).Encode(map[string]any{"files": []any{}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeDriveLabels(t *testing.T) *fakeDriveLabels {
+	t.Helper()
+	api := &fakeDriveLabels{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	svc, closeSrv := newDriveTestService(t, api)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+	labelsSvc, err := drivelabels.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("drivelabels.NewService: %v", err)
+	}
+	orig := newDriveLabelsService
+	t.Cleanup(func() { newDriveLabelsService = orig })
+	newDriveLabelsService = func(context.Context, string) (*drivelabels.Service, error) { return labelsSvc, nil }
+	return api
+}
+
+func runDriveLabelsJSON(t *testing.T, cmd any, args ...string) map[string]any {
+	t.Helper()
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	out := captureStdout(t, func() {
+		if err := runKong(t, cmd, args, ctx, &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	})
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	return parsed
+}
+
+func TestDriveLabels_ListAndGet(t *testing.T) {
+	newFakeDriveLabels(t)
+
+	listed := runDriveLabelsJSON(t, &DriveLabelsCmd{}, "list")
+	if labels, _ := listed["labels"].([]any); len(labels) != 1 || labels[0].(map[string]any)["id"] != "lbl1" {
+		t.Fatalf("unexpected labels: %#v", listed)
+	}
+
+	got := runDriveLabelsJSON(t, &DriveLabelsCmd{}, "get", "file1")
+	labels, _ := got["labels"].([]any)
+	if len(labels) != 1 {
+		t.Fatalf("unexpected file labels: %#v", got)
+	}
+	fields := labels[0].(map[string]any)["fields"].(map[string]any)
+	if fields["f_status"].(map[string]any)["selection"].([]any)[0] != "c_draft" {
+		t.Fatalf("unexpected fields: %#v", fields)
+	}
+	if values := driveLabelFieldValues(drive.LabelField{User: []*drive.User{{EmailAddress: "ann@example.com"}}}); len(values) != 1 || values[0] != "ann@example.com" {
+		t.Fatalf("unexpected user values: %v", values)
+	}
+}
+
+func TestDriveLabels_SetAndUnset(t *testing.T) {
+	api := newFakeDriveLabels(t)
+
+	runDriveLabelsJSON(t, &DriveLabelsCmd{}, "set", "file1", "--label", "lbl1",
+		"--field", "Status=final", "--field", "f_note=hello", "--field", "Note=world",
+		"--field", "Due=2026-02-01", "--field", "owner=bob@example.com")
+	if len(api.modified) != 1 {
+		t.Fatalf("expected one modifyLabels call, got %d", len(api.modified))
+	}
+	mod := api.modified[0].LabelModifications[0]
+	if mod.LabelId != "lbl1" || len(mod.FieldModifications) != 4 {
+		t.Fatalf("unexpected modification: %#v", mod)
+	}
+	byID := map[string]*drive.LabelFieldModification{}
+	for _, fm := range mod.FieldModifications {
+		byID[fm.FieldId] = fm
+	}
+	if got := byID["f_status"].SetSelectionValues; len(got) != 1 || got[0] != "c_final" {
+		t.Fatalf("unexpected selection: %v", got)
+	}
+	if got := byID["f_note"].SetTextValues; len(got) != 2 || got[1] != "world" {
+		t.Fatalf("unexpected text: %v", got)
+	}
+	if byID["f_due"].SetDateValues[0] != "2026-02-01" || byID["f_owner"].SetUserValues[0] != "bob@example.com" {
+		t.Fatalf("unexpected date/user: %#v %#v", byID["f_due"], byID["f_owner"])
+	}
+
+	runDriveLabelsJSON(t, &DriveLabelsCmd{}, "unset", "file1", "--label", "lbl1", "--field", "Note")
+	runDriveLabelsJSON(t, &DriveLabelsCmd{}, "unset", "file1", "--label", "lbl1")
+	if len(api.modified) != 3 || !api.modified[1].LabelModifications[0].FieldModifications[0].UnsetValues ||
+		!api.modified[2].LabelModifications[0].RemoveLabel {
+		t.Fatalf("unexpected unset requests: %#v", api.modified)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	flags := &RootFlags{Account: "a@b.com"}
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"set", "file1", "--label", "lbl1", "--field", "Status=nope"}, "no choice"},
+		{[]string{"set", "file1", "--label", "lbl1", "--field", "Due=tomorrow"}, "invalid date"},
+		{[]string{"set", "file1", "--label", "lbl1", "--field", "Missing=x"}, "no field"},
+		{[]string{"set", "file1", "--label", "lbl1", "--field", "Status"}, "field=value"},
+		{[]string{"unset", "file1", "--label", "lbl1"}, "refusing"},
+	} {
+		err := runKong(t, &DriveLabelsCmd{}, tc.args, ctx, &RootFlags{Account: flags.Account, NoInput: true})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+
+	err := runKong(t, &DriveLabelsCmd{}, []string{"unset", "file1", "--label", "lbl1"}, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 0 {
+		t.Fatalf("expected dry-run exit 0, got %v", err)
+	}
+}
+
+func TestDriveSearchCmd_LabelFilters(t *testing.T) {
+	api := newFakeDriveLabels(t)
+
+	runDriveLabelsJSON(t, &DriveSearchCmd{}, "--label", "lbl1", "--label-field", "lbl1.f_status=c_final")
+	runDriveLabelsJSON(t, &DriveSearchCmd{}, "report", "--label", "labels/lbl1")
+	want := []string{
+		"'labels/lbl1' in labels and labels/lbl1.f_status = 'c_final' and (trashed = false)",
+		"'labels/lbl1' in labels and (fullText contains 'report' and trashed = false)",
+	}
+	if len(api.queries) != 2 || api.queries[0] != want[0] || api.queries[1] != want[1] {
+		t.Fatalf("unexpected queries:\n%q\nwant:\n%q", api.queries, want)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	err := runKong(t, &DriveSearchCmd{}, []string{"--label-field", "status=final"}, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "labelId.fieldId=value") {
+		t.Fatalf("expected --label-field usage error, got %v", err)
+	}
+}
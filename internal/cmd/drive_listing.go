@@ -12,7 +12,10 @@ import (
 	"github.com/steipete/gogcli/internal/ui"
 )
 
-const driveFileListFields = "nextPageToken, files(id, name, mimeType, size, modifiedTime, parents, webViewLink, owners(emailAddress))"
+const (
+	driveFileItemFields = "id, name, mimeType, size, modifiedTime, parents, webViewLink, owners(emailAddress), shortcutDetails(targetId)"
+	driveFileListFields = "nextPageToken, files(" + driveFileItemFields + ")"
+)
 
 type driveFileListOptions struct {
 	query     string
@@ -35,6 +38,12 @@ func (c *DriveLsCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if err != nil {
 		return err
 	}
+	if c.FollowShortcuts && folderID != "root" && !c.All {
+		folderID, err = resolveDriveShortcut(ctx, svc, folderID)
+		if err != nil {
+			return err
+		}
+	}
 
 	query := buildDriveListQuery(folderID, c.Query)
 	if c.All {
@@ -50,15 +59,29 @@ func (c *DriveLsCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if err != nil {
 		return err
 	}
+	if c.FollowShortcuts {
+		resp.Files, err = followDriveShortcuts(ctx, svc, resp.Files)
+		if err != nil {
+			return err
+		}
+	}
 
 	return writeDriveFileList(ctx, resp, "No files")
 }
 
 func (c *DriveSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 	query := strings.TrimSpace(strings.Join(c.Query, " "))
-	if query == "" {
+	labelQuery, err := buildDriveLabelQuery(c.Label, c.LabelField)
+	if err != nil {
+		return err
+	}
+	if query == "" && labelQuery == "" {
 		return usage("missing query")
 	}
+	q := buildDriveSearchQuery(query, c.RawQuery)
+	if labelQuery != "" {
+		q = labelQuery + " and (" + q + ")"
+	}
 
 	_, svc, err := requireDriveService(ctx, flags)
 	if err != nil {
@@ -66,7 +89,7 @@ func (c *DriveSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	resp, err := listDriveFiles(ctx, svc, driveFileListOptions{
-		query:     buildDriveSearchQuery(query, c.RawQuery),
+		query:     q,
 		max:       c.Max,
 		page:      c.Page,
 		allDrives: c.AllDrives,
@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	drivePermActionRevoke    = "revoke"
+	drivePermActionDowngrade = "downgrade"
+	drivePermActionSkip      = "skip"
+)
+
+var errDrivePermissionPlanEmpty = errors.New("plan has no file_id/permission_id rows")
+
+type DrivePermissionsBulkCmd struct {
+	Plan        string `name:"plan" required:"" help:"Plan file: CSV from 'drive audit-sharing --csv' or JSON"`
+	Action      string `name:"action" help:"Action for rows without one: revoke|downgrade (default: skip them)" enum:",revoke,downgrade" default:""`
+	Role        string `name:"role" help:"Target role for downgrades without new_role: reader|commenter|writer" enum:"reader,commenter,writer" default:"reader"`
+	Concurrency int    `name:"concurrency" help:"Permission changes in flight" default:"4"`
+}
+
+type drivePermissionChange struct {
+	FileID       string `json:"fileId"`
+	PermissionID string `json:"permissionId"`
+	Action       string `json:"action"`
+	NewRole      string `json:"newRole,omitempty"`
+
+	Target     string `json:"target,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Role       string `json:"role,omitempty"`
+	SkipReason string `json:"skipReason,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// drivePermissionPlanRow accepts both the change shape and the finding shape
+// written by `drive audit-sharing --json`.
+type drivePermissionPlanRow struct {
+	FileID          string `json:"fileId"`
+	FileIDSnake     string `json:"file_id"`
+	PermissionID    string `json:"permissionId"`
+	PermissionSnake string `json:"permission_id"`
+	Action          string `json:"action"`
+	NewRole         string `json:"newRole"`
+	NewRoleSnake    string `json:"new_role"`
+}
+
+func (c *DrivePermissionsBulkCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Concurrency < 0 {
+		return usage("--concurrency must be >= 0")
+	}
+	role := c.Role
+	if role == "" {
+		role = "reader"
+	}
+
+	changes, err := readDrivePermissionPlan(c.Plan)
+	if err != nil {
+		return err
+	}
+	for _, ch := range changes {
+		if ch.Action == "" {
+			ch.Action = c.Action
+		}
+		if ch.Action == "" {
+			ch.Action = drivePermActionSkip
+		}
+		switch ch.Action {
+		case drivePermActionRevoke, drivePermActionSkip:
+		case drivePermActionDowngrade:
+			if ch.NewRole == "" {
+				ch.NewRole = role
+			}
+			if drivePermissionRoleRank(ch.NewRole) < 0 {
+				return usagef("%s/%s: unknown new_role %q", ch.FileID, ch.PermissionID, ch.NewRole)
+			}
+		default:
+			return usagef("%s/%s: unknown action %q (expected revoke, downgrade, or skip)", ch.FileID, ch.PermissionID, ch.Action)
+		}
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	concurrency := c.Concurrency
+	if concurrency == 0 {
+		concurrency = 4
+	}
+
+	// Resolve current state first so the dry run shows a real diff and stale
+	// rows are skipped instead of failing.
+	_ = runDriveBounded(ctx, concurrency, len(changes), func(ctx context.Context, i int) error {
+		resolveDrivePermissionChange(ctx, svc, changes[i])
+		return nil
+	})
+
+	var pending []*drivePermissionChange
+	for _, ch := range changes {
+		if ch.SkipReason == "" {
+			pending = append(pending, ch)
+		}
+	}
+
+	if flags != nil && flags.DryRun && !outfmt.IsJSON(ctx) && !outfmt.IsPlain(ctx) {
+		for _, ch := range pending {
+			u.Out().Printf("- %s %s (%s) %s", ch.FileID, ch.Target, ch.Type, ch.Role)
+			if ch.Action == drivePermActionDowngrade {
+				u.Out().Printf("+ %s %s (%s) %s", ch.FileID, ch.Target, ch.Type, ch.NewRole)
+			}
+		}
+		return dryRunExit(ctx, flags, "drive.permissions.bulk", map[string]any{
+			"apply":   len(pending),
+			"skipped": len(changes) - len(pending),
+		})
+	}
+	if err := dryRunExit(ctx, flags, "drive.permissions.bulk", map[string]any{"changes": changes}); err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		if err := confirmDestructiveChecked(ctx, flags, fmt.Sprintf("change %d permission(s)", len(pending))); err != nil {
+			return err
+		}
+	}
+
+	var mu sync.Mutex
+	errs := runDriveBounded(ctx, concurrency, len(pending), func(ctx context.Context, i int) error {
+		ch := pending[i]
+		var err error
+		if ch.Action == drivePermActionRevoke {
+			err = svc.Permissions.Delete(ch.FileID, ch.PermissionID).SupportsAllDrives(true).Context(ctx).Do()
+		} else {
+			_, err = svc.Permissions.Update(ch.FileID, ch.PermissionID, &drive.Permission{Role: ch.NewRole}).
+				SupportsAllDrives(true).
+				Fields("id, role").
+				Context(ctx).
+				Do()
+		}
+		if err != nil {
+			mu.Lock()
+			ch.Error = err.Error()
+			mu.Unlock()
+		}
+		return err
+	})
+	var failed int
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	applied := len(pending) - failed
+	skipped := len(changes) - len(pending)
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"applied": applied,
+			"skipped": skipped,
+			"failed":  failed,
+			"changes": changes,
+		}); err != nil {
+			return err
+		}
+	} else {
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "FILE_ID\tPERMISSION_ID\tTARGET\tACTION\tRESULT")
+		for _, ch := range changes {
+			result := "ok"
+			switch {
+			case ch.SkipReason != "":
+				result = "skipped: " + ch.SkipReason
+			case ch.Error != "":
+				result = "failed: " + ch.Error
+			}
+			action := ch.Action
+			if ch.Action == drivePermActionDowngrade {
+				action += " " + ch.Role + "→" + ch.NewRole
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ch.FileID, ch.PermissionID, sanitizeTab(ch.Target), action, sanitizeTab(result))
+		}
+		flush()
+		u.Err().Printf("%d applied, %d skipped, %d failed", applied, skipped, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d permission change(s) failed", failed)
+	}
+	return nil
+}
+
+// resolveDrivePermissionChange fills in the current role/target and marks
+// changes that no longer apply.
+func resolveDrivePermissionChange(ctx context.Context, svc *drive.Service, ch *drivePermissionChange) {
+	if ch.Action == drivePermActionSkip {
+		ch.SkipReason = "no action"
+		return
+	}
+	p, err := svc.Permissions.Get(ch.FileID, ch.PermissionID).
+		SupportsAllDrives(true).
+		Fields(gapi.Field("id, type, role, emailAddress, domain, permissionDetails(inherited)")).
+		Context(ctx).
+		Do()
+	if err != nil {
+		if isNotFoundAPIError(err) {
+			ch.SkipReason = "permission not found"
+		} else {
+			ch.SkipReason = err.Error()
+		}
+		return
+	}
+	ch.Target = drivePermissionTarget(p)
+	ch.Type = p.Type
+	ch.Role = p.Role
+	switch {
+	case p.Role == "owner":
+		ch.SkipReason = "owner"
+	case drivePermissionInherited(p):
+		ch.SkipReason = "inherited; change it on the parent"
+	case ch.Action == drivePermActionDowngrade && drivePermissionRoleRank(ch.NewRole) >= drivePermissionRoleRank(p.Role):
+		ch.SkipReason = "already " + p.Role
+	}
+}
+
+func drivePermissionRoleRank(role string) int {
+	switch role {
+	case "reader":
+		return 0
+	case "commenter":
+		return 1
+	case "writer":
+		return 2
+	case "fileOrganizer":
+		return 3
+	case "organizer":
+		return 4
+	case "owner":
+		return 5
+	default:
+		return -1
+	}
+}
+
+func readDrivePermissionPlan(path string) ([]*drivePermissionChange, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, usage("--plan is required")
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided plan path
+	if err != nil {
+		return nil, err
+	}
+	var changes []*drivePermissionChange
+	if strings.EqualFold(filepath.Ext(path), ".json") || strings.HasPrefix(strings.TrimSpace(string(data)), "[") ||
+		strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		changes, err = parseDrivePermissionPlanJSON(data)
+	} else {
+		changes, err = parseDrivePermissionPlanCSV(strings.NewReader(string(data)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read plan %s: %w", path, err)
+	}
+	if len(changes) == 0 {
+		return nil, errDrivePermissionPlanEmpty
+	}
+	return changes, nil
+}
+
+func parseDrivePermissionPlanJSON(data []byte) ([]*drivePermissionChange, error) {
+	var rows []drivePermissionPlanRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		var wrapped struct {
+			Changes  []drivePermissionPlanRow `json:"changes"`
+			Findings []drivePermissionPlanRow `json:"findings"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, err
+		}
+		rows = append(wrapped.Changes, wrapped.Findings...)
+	}
+	out := make([]*drivePermissionChange, 0, len(rows))
+	for _, r := range rows {
+		ch := newDrivePermissionChange(
+			firstNonEmpty(r.FileID, r.FileIDSnake),
+			firstNonEmpty(r.PermissionID, r.PermissionSnake),
+			r.Action,
+			firstNonEmpty(r.NewRole, r.NewRoleSnake),
+		)
+		if ch != nil {
+			out = append(out, ch)
+		}
+	}
+	return out, nil
+}
+
+func parseDrivePermissionPlanCSV(r io.Reader) ([]*drivePermissionChange, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := cols["file_id"]; !ok {
+		return nil, errors.New("missing file_id column")
+	}
+	if _, ok := cols["permission_id"]; !ok {
+		return nil, errors.New("missing permission_id column")
+	}
+	get := func(rec []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+	var out []*drivePermissionChange
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ch := newDrivePermissionChange(get(rec, "file_id"), get(rec, "permission_id"), get(rec, "action"), get(rec, "new_role"))
+		if ch != nil {
+			out = append(out, ch)
+		}
+	}
+}
+
+func newDrivePermissionChange(fileID, permissionID, action, newRole string) *drivePermissionChange {
+	fileID = normalizeGoogleID(strings.TrimSpace(fileID))
+	permissionID = strings.TrimSpace(permissionID)
+	if fileID == "" || permissionID == "" {
+		return nil
+	}
+	return &drivePermissionChange{
+		FileID:       fileID,
+		PermissionID: permissionID,
+		Action:       strings.ToLower(strings.TrimSpace(action)),
+		NewRole:      strings.TrimSpace(newRole),
+	}
+}
@@ -67,7 +67,7 @@ func TestDrivePermissionsCmd_TextAndJSON(t *testing.T) {
 	ctx = outfmt.WithMode(ctx, outfmt.Mode{})
 
 	textOut := captureStdout(t, func() {
-		cmd := &DrivePermissionsCmd{}
+		cmd := &DrivePermissionsListCmd{}
 		if execErr := runKong(t, cmd, []string{"--max", "1", "--page", "p1", "id1"}, ctx, flags); execErr != nil {
 			t.Fatalf("execute: %v", execErr)
 		}
@@ -92,7 +92,7 @@ func TestDrivePermissionsCmd_TextAndJSON(t *testing.T) {
 	ctx2 = outfmt.WithMode(ctx2, outfmt.Mode{JSON: true})
 
 	jsonOut := captureStdout(t, func() {
-		cmd := &DrivePermissionsCmd{}
+		cmd := &DrivePermissionsListCmd{}
 		if execErr := runKong(t, cmd, []string{"--max", "1", "--page", "p1", "id1"}, ctx2, flags); execErr != nil {
 			t.Fatalf("execute: %v", execErr)
 		}
@@ -161,7 +161,7 @@ func TestDrivePermissionsCmd_OmitsEmptyPageToken(t *testing.T) {
 	ctx = ui.WithUI(ctx, u)
 
 	out := captureStdout(t, func() {
-		cmd := &DrivePermissionsCmd{}
+		cmd := &DrivePermissionsListCmd{}
 		if execErr := runKong(t, cmd, []string{"--max", "1", "id1"}, ctx, flags); execErr != nil {
 			t.Fatalf("execute: %v", execErr)
 		}
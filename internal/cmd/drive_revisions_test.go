@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"crypto/md5" //nolint:gosec // test fake mirrors Drive md5Checksum
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+type fakeDriveRevisions struct {
+	t      *testing.T
+	srvURL string
+
+	mu       sync.Mutex
+	restored map[string]string // fileID -> "contentType:body"
+	pinned   map[string]bool
+	deleted  []string
+}
+
+var fakeRevisionContent = map[string]string{
+	"bin1/r1":  "old binary",
+	"bin1/r2":  "new binary",
+	"doc1/r1":  "alpha\nbeta\ngamma\n",
+	"doc1/cur": "alpha\nBETA\ngamma\n",
+}
+
+func (f *fakeDriveRevisions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	upload := strings.HasPrefix(r.URL.Path, "/upload/")
+	p := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/upload"), "/drive/v3")
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+
+	switch {
+	case p == "/export":
+		// Export links handed out in revision metadata.
+		key := r.URL.Query().Get("id") + "/" + r.URL.Query().Get("rev")
+		if r.URL.Query().Get("mime") == mimeDocx {
+			_, _ = io.WriteString(w, "docx:"+key)
+			return
+		}
+		_, _ = io.WriteString(w, strings.ReplaceAll(fakeRevisionContent[key], "\n", "\r\n"))
+	case len(parts) == 2 && parts[0] == "files" && r.Method == http.MethodPatch && upload:
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		_, _ = mr.NextPart()
+		media, err := mr.NextPart()
+		if err != nil {
+			f.t.Fatalf("media part: %v", err)
+		}
+		body, _ := io.ReadAll(media)
+		f.restored[parts[1]] = media.Header.Get("Content-Type") + ":" + string(body)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": parts[1], "name": "file", "headRevisionId": "r3"})
+	case len(parts) == 3 && parts[2] == "export":
+		_, _ = io.WriteString(w, fakeRevisionContent[parts[1]+"/cur"])
+	case len(parts) == 2 && parts[0] == "files":
+		mimeType := "application/octet-stream"
+		if strings.HasPrefix(parts[1], "doc") {
+			mimeType = driveMimeGoogleDoc
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": parts[1], "name": "report", "mimeType": mimeType})
+	case len(parts) == 3 && parts[2] == "revisions":
+		_ = json.NewEncoder(w).Encode(map[string]any{"revisions": []map[string]any{
+			{"id": "r1", "modifiedTime": "2026-01-01T10:00:00Z", "size": "10", "lastModifyingUser": map[string]any{"emailAddress": "a@b.com"}},
+			{"id": "r2", "modifiedTime": "2026-01-02T10:00:00Z", "size": "10", "keepForever": true},
+		}})
+	case len(parts) == 4 && parts[2] == "revisions":
+		fileID, revID := parts[1], parts[3]
+		content, ok := fakeRevisionContent[fileID+"/"+revID]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case r.Method == http.MethodDelete:
+			f.deleted = append(f.deleted, revID)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPatch:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			keep, _ := body["keepForever"].(bool)
+			f.pinned[revID] = keep
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": revID, "keepForever": keep})
+		case r.URL.Query().Get("alt") == "media":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = io.WriteString(w, content)
+		case strings.HasPrefix(fileID, "doc"):
+			link := f.srvURL + "/export?id=" + fileID + "&rev=" + revID + "&mime="
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": revID, "mimeType": driveMimeGoogleDoc, "exportLinks": map[string]any{
+				mimeTextPlain: link + "text/plain",
+				mimeDocx:      link + mimeDocx,
+			}})
+		default:
+			sum := md5.Sum([]byte(content)) //nolint:gosec // test fake
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": revID, "mimeType": "application/octet-stream", "md5Checksum": hex.EncodeToString(sum[:])})
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeDriveRevisions(t *testing.T) *fakeDriveRevisions {
+	t.Helper()
+	api := &fakeDriveRevisions{t: t, restored: map[string]string{}, pinned: map[string]bool{}}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	api.srvURL = srv.URL
+	svc, closeSrv := newDriveTestService(t, api)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+	stubDriveHTTPClientForTest(t, srv.Client())
+	return api
+}
+
+func runDriveRevisionsJSON(t *testing.T, args ...string) map[string]any {
+	t.Helper()
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	out := captureStdout(t, func() {
+		if err := runKong(t, &DriveRevisionsCmd{}, args, ctx, &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+			t.Fatalf("revisions %v: %v", args, err)
+		}
+	})
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	return parsed
+}
+
+func TestDriveRevisions_ListPinDelete(t *testing.T) {
+	api := newFakeDriveRevisions(t)
+
+	listed := runDriveRevisionsJSON(t, "list", "bin1")
+	if revs, _ := listed["revisions"].([]any); len(revs) != 2 {
+		t.Fatalf("unexpected list: %#v", listed)
+	}
+
+	runDriveRevisionsJSON(t, "pin", "bin1", "r1")
+	runDriveRevisionsJSON(t, "pin", "bin1", "r2", "--unpin")
+	if !api.pinned["r1"] || api.pinned["r2"] {
+		t.Fatalf("unexpected pins: %#v", api.pinned)
+	}
+	runDriveRevisionsJSON(t, "delete", "bin1", "r1")
+	if len(api.deleted) != 1 || api.deleted[0] != "r1" {
+		t.Fatalf("unexpected deletes: %#v", api.deleted)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	err := runKong(t, &DriveRevisionsCmd{}, []string{"pin", "doc1", "r1"}, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "Google Workspace") {
+		t.Fatalf("expected native pin to be refused, got %v", err)
+	}
+}
+
+func TestDriveRevisions_DownloadAndRestore(t *testing.T) {
+	api := newFakeDriveRevisions(t)
+	dir := t.TempDir()
+
+	got := runDriveRevisionsJSON(t, "download", "bin1", "r1", "--out", filepath.Join(dir, "old.bin"))
+	data, err := os.ReadFile(got["path"].(string))
+	if err != nil || string(data) != "old binary" {
+		t.Fatalf("unexpected download %q err=%v", data, err)
+	}
+	got = runDriveRevisionsJSON(t, "download", "doc1", "r1", "--format", "txt", "--out", filepath.Join(dir, "doc"))
+	if got["path"] != filepath.Join(dir, "doc.txt") {
+		t.Fatalf("unexpected export path: %#v", got)
+	}
+
+	runDriveRevisionsJSON(t, "restore", "bin1", "r1")
+	if api.restored["bin1"] != "application/octet-stream:old binary" {
+		t.Fatalf("unexpected binary restore: %q", api.restored["bin1"])
+	}
+	runDriveRevisionsJSON(t, "restore", "doc1", "r1")
+	if api.restored["doc1"] != mimeDocx+":docx:doc1/r1" {
+		t.Fatalf("unexpected native restore: %q", api.restored["doc1"])
+	}
+}
+
+func TestDriveRevisions_DiffAgainstCurrent(t *testing.T) {
+	newFakeDriveRevisions(t)
+
+	got := runDriveRevisionsJSON(t, "diff", "doc1", "r1")
+	diff, _ := got["diff"].(string)
+	if got["changed"] != true || !strings.Contains(diff, "--- report@r1") || !strings.Contains(diff, "+++ report@current") ||
+		!strings.Contains(diff, "-beta\n") || !strings.Contains(diff, "+BETA\n") || strings.Contains(diff, "\r") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	err := runKong(t, &DriveRevisionsCmd{}, []string{"diff", "bin1", "r1"}, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "Docs and Sheets") {
+		t.Fatalf("expected diff of binary file to be refused, got %v", err)
+	}
+}
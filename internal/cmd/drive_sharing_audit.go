@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveAuditPermissionFields = "id, type, role, emailAddress, domain, allowFileDiscovery, permissionDetails(inherited)"
+	driveAuditFileFields       = "id, name, mimeType, webViewLink, driveId, shared, permissions(" + driveAuditPermissionFields + ")"
+
+	driveRiskAnyoneDiscoverable = "anyone-discoverable"
+	driveRiskAnyoneWithLink     = "anyone-with-link"
+	driveRiskExternalDomain     = "external-domain"
+	driveRiskExternalUser       = "external-user"
+	driveRiskExternalGroup      = "external-group"
+
+	defaultDriveAuditConcurrency = 8
+)
+
+// driveSharingCSVHeader is shared with `drive permissions bulk`, which reads
+// the same columns back after action/new_role have been filled in.
+var driveSharingCSVHeader = []string{"file_id", "file_name", "mime_type", "risk", "type", "role", "target", "permission_id", "inherited", "link", "action", "new_role"}
+
+type DriveAuditSharingCmd struct {
+	Folder      string   `name:"folder" help:"Only audit items under this folder (recursive)"`
+	AllDrives   bool     `name:"all-drives" help:"Audit everything you can access, including shared drives (default: files you own)"`
+	Domain      []string `name:"domain" sep:"," help:"Internal domains (default: the account's domain); sharing outside them is reported"`
+	Concurrency int      `name:"concurrency" help:"Permission lookups in flight" default:"8"`
+	Max         int      `name:"max" help:"Stop after scanning this many items (0 = all)"`
+	CSV         bool     `name:"csv" help:"Write findings as CSV; fill in the action column to use it as a permissions bulk plan"`
+}
+
+type driveSharingFinding struct {
+	FileID       string `json:"fileId"`
+	FileName     string `json:"fileName"`
+	MimeType     string `json:"mimeType"`
+	Link         string `json:"link,omitempty"`
+	Risk         string `json:"risk"`
+	Type         string `json:"type"`
+	Role         string `json:"role"`
+	Target       string `json:"target"`
+	PermissionID string `json:"permissionId"`
+	Inherited    bool   `json:"inherited"`
+}
+
+func (c *DriveAuditSharingCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	folderID := normalizeGoogleID(strings.TrimSpace(c.Folder))
+	if folderID != "" && c.AllDrives {
+		return usage("--folder cannot be combined with --all-drives")
+	}
+	if c.Concurrency < 0 || c.Max < 0 {
+		return usage("--concurrency and --max must be >= 0")
+	}
+	if c.CSV && outfmt.IsJSON(ctx) {
+		return usage("--csv cannot be combined with --json")
+	}
+
+	account, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	internal := driveInternalDomains(account, c.Domain)
+
+	files, err := listDriveAuditFiles(ctx, svc, folderID, c.AllDrives, c.Max)
+	if err != nil {
+		return err
+	}
+	concurrency := c.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultDriveAuditConcurrency
+	}
+	if err := fillDriveAuditPermissions(ctx, svc, files, concurrency); err != nil {
+		return err
+	}
+
+	var findings []driveSharingFinding
+	for _, f := range files {
+		for _, p := range f.Permissions {
+			risk := classifyDrivePermissionRisk(p, internal)
+			if risk == "" {
+				continue
+			}
+			findings = append(findings, driveSharingFinding{
+				FileID:       f.Id,
+				FileName:     f.Name,
+				MimeType:     f.MimeType,
+				Link:         f.WebViewLink,
+				Risk:         risk,
+				Type:         p.Type,
+				Role:         p.Role,
+				Target:       drivePermissionTarget(p),
+				PermissionID: p.Id,
+				Inherited:    drivePermissionInherited(p),
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return driveRiskRank(findings[i].Risk) < driveRiskRank(findings[j].Risk)
+	})
+
+	switch {
+	case c.CSV:
+		return writeDriveSharingCSV(findings)
+	case outfmt.IsJSON(ctx):
+		summary := map[string]int{}
+		for _, f := range findings {
+			summary[f.Risk]++
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"scanned":         len(files),
+			"internalDomains": internal,
+			"findings":        driveSharingFindingsOrEmpty(findings),
+			"summary":         summary,
+		})
+	}
+	if len(findings) == 0 {
+		u.Err().Printf("No risky sharing found (%d items scanned)", len(files))
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "RISK\tFILE_ID\tNAME\tROLE\tTARGET\tINHERITED\tPERMISSION_ID")
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n",
+			f.Risk, f.FileID, sanitizeTab(f.FileName), f.Role, sanitizeTab(f.Target), f.Inherited, f.PermissionID)
+	}
+	u.Err().Printf("%d finding(s) in %d items scanned", len(findings), len(files))
+	return nil
+}
+
+func driveSharingFindingsOrEmpty(findings []driveSharingFinding) []driveSharingFinding {
+	if findings == nil {
+		return []driveSharingFinding{}
+	}
+	return findings
+}
+
+func driveInternalDomains(account string, domains []string) []string {
+	var out []string
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d != "" {
+			out = append(out, d)
+		}
+	}
+	if len(out) == 0 {
+		if _, domain, ok := strings.Cut(account, "@"); ok && domain != "" {
+			out = append(out, strings.ToLower(domain))
+		}
+	}
+	return out
+}
+
+func driveDomainInternal(domain string, internal []string) bool {
+	domain = strings.ToLower(domain)
+	for _, d := range internal {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyDrivePermissionRisk returns the risk label for a permission, or ""
+// when it stays inside the internal domains.
+func classifyDrivePermissionRisk(p *drive.Permission, internal []string) string {
+	if p == nil || p.Role == "owner" {
+		return ""
+	}
+	switch p.Type {
+	case driveShareToAnyone:
+		if p.AllowFileDiscovery {
+			return driveRiskAnyoneDiscoverable
+		}
+		return driveRiskAnyoneWithLink
+	case driveShareToDomain:
+		if !driveDomainInternal(p.Domain, internal) {
+			return driveRiskExternalDomain
+		}
+	case driveShareToUser, "group":
+		_, domain, ok := strings.Cut(p.EmailAddress, "@")
+		if ok && !driveDomainInternal(domain, internal) {
+			if p.Type == "group" {
+				return driveRiskExternalGroup
+			}
+			return driveRiskExternalUser
+		}
+	}
+	return ""
+}
+
+func driveRiskRank(risk string) int {
+	switch risk {
+	case driveRiskAnyoneDiscoverable:
+		return 0
+	case driveRiskAnyoneWithLink:
+		return 1
+	case driveRiskExternalDomain:
+		return 2
+	case driveRiskExternalGroup:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// listDriveAuditFiles lists the items to audit: a folder subtree, every
+// accessible item (allDrives), or the files the user owns.
+func listDriveAuditFiles(ctx context.Context, svc *drive.Service, folderID string, allDrives bool, limit int) ([]*drive.File, error) {
+	var out []*drive.File
+	full := func() bool { return limit > 0 && len(out) >= limit }
+
+	if folderID != "" {
+		root, err := svc.Files.Get(folderID).
+			SupportsAllDrives(true).
+			Fields(gapi.Field(driveAuditFileFields)).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, err
+		}
+		tree, err := walkDriveTreeFields(ctx, svc, root, driveAuditFileFields)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range tree.flatten() {
+			if full() {
+				break
+			}
+			out = append(out, n.file)
+		}
+		return out, nil
+	}
+
+	q := "'me' in owners and trashed = false"
+	if allDrives {
+		q = "trashed = false"
+	}
+	pageToken := ""
+	for !full() {
+		call := svc.Files.List().
+			Q(q).
+			PageSize(driveTreeListPerPage).
+			SupportsAllDrives(true).
+			Fields(gapi.Field("nextPageToken, files(" + driveAuditFileFields + ")")).
+			Context(ctx)
+		if allDrives {
+			call = call.IncludeItemsFromAllDrives(true).Corpora("allDrives")
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Files {
+			if f != nil && !full() {
+				out = append(out, f)
+			}
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	return out, nil
+}
+
+// fillDriveAuditPermissions fetches permissions for items whose listing did
+// not include them (shared drive items never do).
+func fillDriveAuditPermissions(ctx context.Context, svc *drive.Service, files []*drive.File, concurrency int) error {
+	var pending []*drive.File
+	for _, f := range files {
+		if len(f.Permissions) == 0 && (f.Shared || f.DriveId != "") {
+			pending = append(pending, f)
+		}
+	}
+	var mu sync.Mutex
+	errs := runDriveBounded(ctx, concurrency, len(pending), func(ctx context.Context, i int) error {
+		perms, err := listAllDrivePermissions(ctx, svc, pending[i].Id)
+		if err != nil {
+			return fmt.Errorf("permissions for %s: %w", pending[i].Id, err)
+		}
+		mu.Lock()
+		pending[i].Permissions = perms
+		mu.Unlock()
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listAllDrivePermissions(ctx context.Context, svc *drive.Service, fileID string) ([]*drive.Permission, error) {
+	var out []*drive.Permission
+	pageToken := ""
+	for {
+		call := svc.Permissions.List(fileID).
+			SupportsAllDrives(true).
+			PageSize(100).
+			Fields(gapi.Field("nextPageToken, permissions(" + driveAuditPermissionFields + ")")).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, resp.Permissions...)
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func writeDriveSharingCSV(findings []driveSharingFinding) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(driveSharingCSVHeader); err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if err := w.Write([]string{
+			f.FileID, f.FileName, f.MimeType, f.Risk, f.Type, f.Role, f.Target,
+			f.PermissionID, strconv.FormatBool(f.Inherited), f.Link, "", "",
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
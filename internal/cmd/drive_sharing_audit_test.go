@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// newSharingFixture builds top/{public.txt, ext.txt, sub/{domain.txt, inherited.txt}}.
+func newSharingFixture(t *testing.T) (*fakeDriveTree, map[string]*fakeDriveFile) {
+	t.Helper()
+	api := newFakeDriveTree(t)
+	owner := map[string]any{"id": "own", "type": "user", "role": "owner", "emailAddress": "me@example.com"}
+	top := api.add("root", "top", driveMimeFolder, "")
+	public := api.add(top.ID, "public.txt", "text/plain", "p")
+	public.Perms = []map[string]any{owner, {"id": "anyone", "type": "anyone", "role": "reader", "allowFileDiscovery": true}}
+	ext := api.add(top.ID, "ext.txt", "text/plain", "e")
+	ext.Perms = []map[string]any{
+		owner,
+		{"id": "ext", "type": "user", "role": "writer", "emailAddress": "bob@partner.org"},
+		{"id": "int", "type": "user", "role": "writer", "emailAddress": "ann@eu.example.com"},
+	}
+	sub := api.add(top.ID, "sub", driveMimeFolder, "")
+	dom := api.add(sub.ID, "domain.txt", "text/plain", "d")
+	dom.Perms = []map[string]any{owner, {"id": "dom", "type": "domain", "role": "commenter", "domain": "partner.org"}}
+	inh := api.add(sub.ID, "inherited.txt", "text/plain", "i")
+	inh.Perms = []map[string]any{owner, {"id": "inh", "type": "group", "role": "reader", "emailAddress": "ops@vendor.io", "permissionDetails": []map[string]any{{"inherited": true}}}}
+	svc, closeSrv := newDriveTestService(t, api)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+	return api, map[string]*fakeDriveFile{"top": top, "public": public, "ext": ext, "dom": dom, "inh": inh}
+}
+
+func TestClassifyDrivePermissionRisk(t *testing.T) {
+	internal := []string{"example.com"}
+	cases := []struct {
+		perm *drive.Permission
+		want string
+	}{
+		{&drive.Permission{Type: "anyone", Role: "reader", AllowFileDiscovery: true}, driveRiskAnyoneDiscoverable},
+		{&drive.Permission{Type: "anyone", Role: "reader"}, driveRiskAnyoneWithLink},
+		{&drive.Permission{Type: "domain", Role: "reader", Domain: "Example.com"}, ""},
+		{&drive.Permission{Type: "domain", Role: "reader", Domain: "notexample.com"}, driveRiskExternalDomain},
+		{&drive.Permission{Type: "user", Role: "writer", EmailAddress: "a@sub.example.com"}, ""},
+		{&drive.Permission{Type: "user", Role: "writer", EmailAddress: "a@gmail.com"}, driveRiskExternalUser},
+		{&drive.Permission{Type: "group", Role: "reader", EmailAddress: "g@vendor.io"}, driveRiskExternalGroup},
+		{&drive.Permission{Type: "user", Role: "owner", EmailAddress: "a@gmail.com"}, ""},
+	}
+	for _, tc := range cases {
+		if got := classifyDrivePermissionRisk(tc.perm, internal); got != tc.want {
+			t.Errorf("%+v: got %q want %q", tc.perm, got, tc.want)
+		}
+	}
+	if got := driveInternalDomains("me@Example.com", nil); len(got) != 1 || got[0] != "example.com" {
+		t.Fatalf("unexpected default domains: %v", got)
+	}
+}
+
+func TestDriveAuditSharingCmd_FolderJSONAndCSV(t *testing.T) {
+	_, ids := newSharingFixture(t)
+	flags := &RootFlags{Account: "me@example.com"}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	raw := captureStdout(t, func() {
+		if err := runKong(t, &DriveAuditSharingCmd{}, []string{"--folder", ids["top"].ID}, ctx, flags); err != nil {
+			t.Fatalf("audit: %v", err)
+		}
+	})
+	var parsed struct {
+		Scanned  int                   `json:"scanned"`
+		Findings []driveSharingFinding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, raw)
+	}
+	if parsed.Scanned != 6 || len(parsed.Findings) != 4 {
+		t.Fatalf("unexpected audit: %s", raw)
+	}
+	wantRisks := []string{driveRiskAnyoneDiscoverable, driveRiskExternalDomain, driveRiskExternalGroup, driveRiskExternalUser}
+	for i, want := range wantRisks {
+		if parsed.Findings[i].Risk != want {
+			t.Fatalf("finding %d: got %q want %q\n%s", i, parsed.Findings[i].Risk, want, raw)
+		}
+	}
+	if !parsed.Findings[2].Inherited || parsed.Findings[3].Target != "bob@partner.org" {
+		t.Fatalf("unexpected finding details: %s", raw)
+	}
+
+	var errBuf bytes.Buffer
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: &errBuf, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	csvOut := captureStdout(t, func() {
+		args := []string{"--folder", ids["top"].ID, "--csv", "--domain", "example.com,partner.org"}
+		if err := runKong(t, &DriveAuditSharingCmd{}, args, ui.WithUI(context.Background(), u), flags); err != nil {
+			t.Fatalf("audit csv: %v", err)
+		}
+	})
+	records, err := csv.NewReader(strings.NewReader(csvOut)).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v\n%s", err, csvOut)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(driveSharingCSVHeader, ",") ||
+		records[1][3] != driveRiskAnyoneDiscoverable || records[2][3] != driveRiskExternalGroup {
+		t.Fatalf("unexpected csv:\n%s", csvOut)
+	}
+
+	err = runKong(t, &DriveAuditSharingCmd{}, []string{"--folder", "x", "--all-drives"}, ctx, flags)
+	if err == nil || !strings.Contains(err.Error(), "--all-drives") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestDrivePermissionsBulkCmd_DryRunAndApply(t *testing.T) {
+	api, ids := newSharingFixture(t)
+	flags := &RootFlags{Account: "me@example.com", Force: true}
+	plan := filepath.Join(t.TempDir(), "plan.csv")
+	rows := [][]string{
+		driveSharingCSVHeader,
+		{ids["public"].ID, "public.txt", "", driveRiskAnyoneDiscoverable, "anyone", "reader", "anyone", "anyone", "false", "", "revoke", ""},
+		{ids["ext"].ID, "ext.txt", "", driveRiskExternalUser, "user", "writer", "bob@partner.org", "ext", "false", "", "downgrade", "commenter"},
+		{ids["dom"].ID, "domain.txt", "", driveRiskExternalDomain, "domain", "commenter", "partner.org", "dom", "false", "", "downgrade", "writer"},
+		{ids["inh"].ID, "inherited.txt", "", driveRiskExternalGroup, "group", "reader", "ops@vendor.io", "inh", "true", "", "revoke", ""},
+		{ids["ext"].ID, "ext.txt", "", driveRiskExternalUser, "user", "writer", "gone", "missing", "false", "", "revoke", ""},
+		{ids["dom"].ID, "domain.txt", "", "", "", "", "", "own", "false", "", "", ""},
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.WriteAll(rows)
+	if err := os.WriteFile(plan, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+
+	var out bytes.Buffer
+	u, err := ui.New(ui.Options{Stdout: &out, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	dry := &RootFlags{Account: "me@example.com", DryRun: true}
+	err = runKong(t, &DrivePermissionsCmd{}, []string{"bulk", "--plan", plan}, ui.WithUI(context.Background(), u), dry)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 0 {
+		t.Fatalf("expected dry-run exit 0, got %v", err)
+	}
+	diff := out.String()
+	if !strings.Contains(diff, "- "+ids["public"].ID+" anyone (anyone) reader") ||
+		!strings.Contains(diff, "- "+ids["ext"].ID+" bob@partner.org (user) writer\n+ "+ids["ext"].ID+" bob@partner.org (user) commenter") ||
+		strings.Contains(diff, "ops@vendor.io") || !strings.Contains(diff, `"apply": 2`) {
+		t.Fatalf("unexpected dry run:\n%s", diff)
+	}
+	if len(ids["public"].Perms) != 2 || ids["ext"].Perms[1]["role"] != "writer" {
+		t.Fatalf("dry run must not change permissions")
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	raw := captureStdout(t, func() {
+		if err := runKong(t, &DrivePermissionsCmd{}, []string{"bulk", "--plan", plan}, ctx, flags); err != nil {
+			t.Fatalf("bulk: %v", err)
+		}
+	})
+	var result struct {
+		Applied int                     `json:"applied"`
+		Skipped int                     `json:"skipped"`
+		Changes []drivePermissionChange `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		t.Fatalf("json: %v\n%s", err, raw)
+	}
+	if result.Applied != 2 || result.Skipped != 4 {
+		t.Fatalf("unexpected result: %s", raw)
+	}
+	reasons := map[string]string{}
+	for _, ch := range result.Changes {
+		reasons[ch.PermissionID] = ch.SkipReason
+	}
+	if reasons["dom"] != "already commenter" || !strings.Contains(reasons["inh"], "inherited") ||
+		reasons["missing"] != "permission not found" || reasons["own"] != "no action" {
+		t.Fatalf("unexpected skip reasons: %#v", reasons)
+	}
+	if len(ids["public"].Perms) != 1 || ids["ext"].Perms[1]["role"] != "commenter" || len(api.files[ids["inh"].ID].Perms) != 2 {
+		t.Fatalf("permissions not applied as planned")
+	}
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const driveShortcutFields = "id, name, mimeType, parents, webViewLink, shortcutDetails(targetId, targetMimeType)"
+
+type DriveShortcutCmd struct {
+	Create  DriveShortcutCreateCmd  `cmd:"" name:"create" aliases:"add" help:"Create a shortcut to a file or folder"`
+	Resolve DriveShortcutResolveCmd `cmd:"" name:"resolve" help:"Show the file a shortcut points to"`
+}
+
+type DriveShortcutCreateCmd struct {
+	TargetID string `arg:"" name:"targetId" help:"File or folder ID the shortcut points to"`
+	Parent   string `name:"parent" help:"Folder ID to place the shortcut in (default: root)"`
+	Name     string `name:"name" help:"Shortcut name (default: the target's name)"`
+}
+
+func (c *DriveShortcutCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	targetID := normalizeGoogleID(strings.TrimSpace(c.TargetID))
+	if targetID == "" {
+		return usage("empty targetId")
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		target, getErr := svc.Files.Get(targetID).
+			SupportsAllDrives(true).
+			Fields("id, name").
+			Context(ctx).
+			Do()
+		if getErr != nil {
+			return getErr
+		}
+		name = target.Name
+	}
+
+	f := &drive.File{
+		Name:            name,
+		MimeType:        driveMimeShortcut,
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: targetID},
+	}
+	if parent := normalizeGoogleID(strings.TrimSpace(c.Parent)); parent != "" {
+		f.Parents = []string{parent}
+	}
+
+	created, err := svc.Files.Create(f).
+		SupportsAllDrives(true).
+		Fields(driveShortcutFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"shortcut": created})
+	}
+
+	u.Out().Printf("id\t%s", created.Id)
+	u.Out().Printf("name\t%s", created.Name)
+	u.Out().Printf("target\t%s", targetID)
+	if created.WebViewLink != "" {
+		u.Out().Printf("link\t%s", created.WebViewLink)
+	}
+	return nil
+}
+
+type DriveShortcutResolveCmd struct {
+	FileID string `arg:"" name:"fileId" help:"Shortcut ID"`
+}
+
+func (c *DriveShortcutResolveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	if fileID == "" {
+		return usage("empty fileId")
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+
+	shortcut, err := svc.Files.Get(fileID).
+		SupportsAllDrives(true).
+		Fields(driveShortcutFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+	if shortcut.MimeType != driveMimeShortcut || shortcut.ShortcutDetails == nil || shortcut.ShortcutDetails.TargetId == "" {
+		return usagef("%s is not a shortcut", fileID)
+	}
+
+	target, err := svc.Files.Get(shortcut.ShortcutDetails.TargetId).
+		SupportsAllDrives(true).
+		Fields(driveFileItemFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"shortcut": shortcut, "target": target})
+	}
+
+	u.Out().Printf("id\t%s", target.Id)
+	u.Out().Printf("name\t%s", target.Name)
+	u.Out().Printf("type\t%s", target.MimeType)
+	if target.WebViewLink != "" {
+		u.Out().Printf("link\t%s", target.WebViewLink)
+	}
+	return nil
+}
+
+// resolveDriveShortcut returns the target ID when fileID is a shortcut, and
+// fileID unchanged otherwise.
+func resolveDriveShortcut(ctx context.Context, svc *drive.Service, fileID string) (string, error) {
+	f, err := svc.Files.Get(fileID).
+		SupportsAllDrives(true).
+		Fields("id, mimeType, shortcutDetails(targetId)").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", err
+	}
+	if f.MimeType == driveMimeShortcut && f.ShortcutDetails != nil && f.ShortcutDetails.TargetId != "" {
+		return f.ShortcutDetails.TargetId, nil
+	}
+	return fileID, nil
+}
+
+// followDriveShortcuts replaces listed shortcuts with their targets. Targets
+// the caller cannot read (deleted or unshared) stay listed as the shortcut.
+func followDriveShortcuts(ctx context.Context, svc *drive.Service, files []*drive.File) ([]*drive.File, error) {
+	out := make([]*drive.File, 0, len(files))
+	for _, f := range files {
+		if f == nil || f.MimeType != driveMimeShortcut || f.ShortcutDetails == nil || f.ShortcutDetails.TargetId == "" {
+			out = append(out, f)
+			continue
+		}
+		target, err := svc.Files.Get(f.ShortcutDetails.TargetId).
+			SupportsAllDrives(true).
+			Fields(driveFileItemFields).
+			Context(ctx).
+			Do()
+		if err != nil {
+			if isNotFoundAPIError(err) {
+				out = append(out, f)
+				continue
+			}
+			return nil, err
+		}
+		out = append(out, target)
+	}
+	return out, nil
+}
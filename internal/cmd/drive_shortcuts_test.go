@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+func runDriveJSON(t *testing.T, cmd any, args ...string) map[string]any {
+	t.Helper()
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	out := captureStdout(t, func() {
+		if err := runKong(t, cmd, args, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	})
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	return parsed
+}
+
+func TestDriveShortcut_CreateResolveAndFollow(t *testing.T) {
+	api := newFakeDriveTree(t)
+	docs := api.add("root", "Docs", driveMimeFolder, "")
+	report := api.add(docs.ID, "report.txt", "text/plain", "quarterly")
+	links := api.add("root", "Links", driveMimeFolder, "")
+	svc, closeSrv := newDriveTestService(t, api)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+
+	created := runDriveJSON(t, &DriveShortcutCmd{}, "create", report.ID, "--parent", links.ID)
+	shortcut := created["shortcut"].(map[string]any)
+	if shortcut["mimeType"] != driveMimeShortcut || shortcut["name"] != "report.txt" {
+		t.Fatalf("unexpected shortcut: %#v", created)
+	}
+	sc := api.files[shortcut["id"].(string)]
+	if sc.Parent != links.ID || sc.Target != report.ID {
+		t.Fatalf("unexpected shortcut file: %#v", sc)
+	}
+	folderLink := runDriveJSON(t, &DriveShortcutCmd{}, "create", docs.ID, "--parent", links.ID, "--name", "Docs link")
+	folderSC := folderLink["shortcut"].(map[string]any)["id"].(string)
+
+	resolved := runDriveJSON(t, &DriveShortcutCmd{}, "resolve", sc.ID)
+	if resolved["target"].(map[string]any)["id"] != report.ID {
+		t.Fatalf("unexpected resolve: %#v", resolved)
+	}
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	if err := runKong(t, &DriveShortcutCmd{}, []string{"resolve", report.ID}, ctx, &RootFlags{Account: "a@b.com"}); err == nil ||
+		!strings.Contains(err.Error(), "not a shortcut") {
+		t.Fatalf("expected not-a-shortcut error, got %v", err)
+	}
+
+	if got := runDriveJSON(t, &DriveGetCmd{}, sc.ID)["file"].(map[string]any); got["id"] != sc.ID {
+		t.Fatalf("get without follow should return the shortcut: %#v", got)
+	}
+	if got := runDriveJSON(t, &DriveGetCmd{}, sc.ID, "--follow-shortcuts")["file"].(map[string]any); got["id"] != report.ID {
+		t.Fatalf("get --follow-shortcuts should return the target: %#v", got)
+	}
+
+	listed := runDriveJSON(t, &DriveLsCmd{}, "--parent", links.ID, "--follow-shortcuts")
+	files, _ := listed["files"].([]any)
+	if len(files) != 2 || files[0].(map[string]any)["id"] != report.ID || files[1].(map[string]any)["id"] != docs.ID {
+		t.Fatalf("ls --follow-shortcuts should list targets: %#v", listed)
+	}
+	listed = runDriveJSON(t, &DriveLsCmd{}, "--parent", folderSC, "--follow-shortcuts")
+	if files, _ = listed["files"].([]any); len(files) != 1 || files[0].(map[string]any)["id"] != report.ID {
+		t.Fatalf("ls of a folder shortcut should list the target folder: %#v", listed)
+	}
+
+	dest := filepath.Join(t.TempDir(), "report.txt")
+	runDriveJSON(t, &DriveDownloadCmd{}, sc.ID, "--out", dest, "--follow-shortcuts")
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "quarterly" {
+		t.Fatalf("unexpected download %q (err=%v)", data, err)
+	}
+}
+
+func TestDriveUpdateAndMkdirColor(t *testing.T) {
+	var bodies []map[string]any
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(raw, &body)
+		bodies = append(bodies, body)
+		body["id"] = "f1"
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	})
+	svc, closeSrv := newDriveTestService(t, h)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+
+	runDriveJSON(t, &DriveUpdateCmd{}, "f1", "--no-starred", "--description", "",
+		"--properties", "project=alpha,beta", "--property", "stale=", "--folder-color", "AABBCC")
+	got := bodies[0]
+	if got["starred"] != false || got["description"] != "" || got["folderColorRgb"] != "#aabbcc" {
+		t.Fatalf("unexpected update body: %#v", got)
+	}
+	props, _ := got["appProperties"].(map[string]any)
+	if v, ok := props["stale"]; props["project"] != "alpha,beta" || !ok || v != nil {
+		t.Fatalf("unexpected appProperties: %#v", props)
+	}
+
+	runDriveJSON(t, &DriveMkdirCmd{}, "Projects", "--folder-color", "#4986e7")
+	if bodies[1]["folderColorRgb"] != "#4986e7" || bodies[1]["mimeType"] != driveMimeFolder {
+		t.Fatalf("unexpected mkdir body: %#v", bodies[1])
+	}
+
+	// --color belongs to the root flags; a hex value goes to the folder.
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "mkdir", "Colored", "--color", "#16a765"}); err != nil {
+			t.Fatalf("mkdir --color: %v", err)
+		}
+	})
+	if bodies[2]["folderColorRgb"] != "#16a765" {
+		t.Fatalf("unexpected mkdir --color body: %#v", bodies[2])
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	for _, tc := range []struct {
+		cmd  any
+		args []string
+		want string
+	}{
+		{&DriveUpdateCmd{}, []string{"f1"}, "nothing to update"},
+		{&DriveUpdateCmd{}, []string{"f1", "--properties", "novalue"}, "key=value"},
+		{&DriveMkdirCmd{}, []string{"x", "--folder-color", "blue"}, "#rrggbb"},
+	} {
+		if err := runKong(t, tc.cmd, tc.args, ctx, &RootFlags{Account: "a@b.com"}); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%v: expected %q error, got %v", tc.args, tc.want, err)
+		}
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("invalid input should not reach the API, got %d requests", len(bodies))
+	}
+}
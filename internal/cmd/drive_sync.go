@@ -0,0 +1,924 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // Drive reports md5Checksum; used for content comparison only
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveSyncUp   = "up"
+	driveSyncDown = "down"
+	driveSyncBoth = "both"
+
+	driveSyncConflictNewer  = "newer"
+	driveSyncConflictLocal  = "local"
+	driveSyncConflictRemote = "remote"
+
+	driveSyncOpUpload      = "upload"
+	driveSyncOpUpdate      = "update"
+	driveSyncOpDownload    = "download"
+	driveSyncOpMkdirRemote = "mkdir-remote"
+	driveSyncOpMkdirLocal  = "mkdir-local"
+	driveSyncOpTrashRemote = "trash-remote"
+	driveSyncOpDeleteLocal = "delete-local"
+	driveSyncOpConflict    = "conflict"
+
+	driveSyncFileFields = "id, name, mimeType, md5Checksum, modifiedTime, size"
+)
+
+type DriveSyncCmd struct {
+	LocalDir  string   `arg:"" name:"localDir" help:"Local directory"`
+	FolderID  string   `arg:"" name:"folderId" help:"Drive folder ID"`
+	Direction string   `name:"direction" help:"Sync direction: up|down|both" enum:"up,down,both" default:"both"`
+	Conflict  string   `name:"conflict" help:"When both sides changed: newer|local|remote|skip" enum:"newer,local,remote,skip" default:"newer"`
+	Delete    bool     `name:"delete" help:"Propagate deletions (trash on Drive, remove locally)"`
+	Format    []string `name:"format" help:"Export Google-native files on download, trying formats in order (e.g. docx,xlsx,pptx; auto = default per type). Default: skip them" sep:","`
+	Exclude   []string `name:"exclude" help:"Skip paths matching a glob (relative path or base name; repeatable)"`
+}
+
+type driveSyncRemote struct {
+	ID           string
+	Name         string
+	MimeType     string
+	MD5          string
+	ModifiedTime string
+	Size         int64
+	Folder       bool
+	// Native files are Google Docs/Sheets/Slides/Drawings exported with ExportFormat.
+	Native       bool
+	ExportFormat string
+}
+
+type driveSyncLocal struct {
+	Size    int64
+	ModTime time.Time
+	Dir     bool
+}
+
+type driveSyncEntry struct {
+	FileID         string `json:"fileId"`
+	MD5            string `json:"md5,omitempty"`
+	RemoteModified string `json:"remoteModified,omitempty"`
+	Size           int64  `json:"size"`
+	LocalModNs     int64  `json:"localModNs"`
+	// Export marks a local copy exported from a Google-native file. MD5 is
+	// then the local file's checksum; the copy is never uploaded.
+	Export bool `json:"export,omitempty"`
+	// Folder entries record directories present on both sides, so a later
+	// deletion on one side is not mistaken for a new folder on the other.
+	Folder bool `json:"folder,omitempty"`
+}
+
+type driveSyncState struct {
+	Account      string                    `json:"account"`
+	FolderID     string                    `json:"folderId"`
+	LocalDir     string                    `json:"localDir"`
+	LastSyncAtMs int64                     `json:"lastSyncAtMs,omitempty"`
+	Files        map[string]driveSyncEntry `json:"files"`
+}
+
+type driveSyncAction struct {
+	Op     string `json:"op"`
+	Path   string `json:"path"`
+	FileID string `json:"fileId,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func driveSyncStatePath(account, folderID, localDir string) (string, error) {
+	dir, err := config.EnsureDriveSyncDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(localDir))
+	name := fmt.Sprintf("%s--%s--%s.json", sanitizeAccountForPath(account), sanitizeAccountForPath(folderID), hex.EncodeToString(sum[:6]))
+	return filepath.Join(dir, name), nil
+}
+
+func loadDriveSyncState(path string) (*driveSyncState, error) {
+	st := &driveSyncState{}
+	data, err := os.ReadFile(path) //nolint:gosec // path built from config dir
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("read sync state %s: %w", path, err)
+		}
+	}
+	if st.Files == nil {
+		st.Files = map[string]driveSyncEntry{}
+	}
+	return st, nil
+}
+
+func saveDriveSyncState(path string, st *driveSyncState) error {
+	payload, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(payload, '\n'), 0o600)
+}
+
+func driveSyncExcluded(rel string, patterns []string) bool {
+	base := path.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// driveSyncExportFormat picks the first requested format that applies to a
+// Google-native file; ok is false when the file should be skipped.
+func driveSyncExportFormat(mimeType string, formats []string) (string, string, bool) {
+	switch mimeType {
+	case driveMimeGoogleDoc, driveMimeGoogleSheet, driveMimeGoogleSlides, driveMimeGoogleDrawing:
+	default:
+		return "", "", false
+	}
+	for _, f := range formats {
+		exportMime, err := driveExportMimeTypeForFormat(mimeType, f)
+		if err != nil {
+			continue
+		}
+		if f == formatAuto {
+			f = ""
+		}
+		return f, driveExportExtension(exportMime), true
+	}
+	return "", "", false
+}
+
+func driveSyncExportName(name, ext string) string {
+	if strings.EqualFold(filepath.Ext(name), ext) {
+		return name
+	}
+	return name + ext
+}
+
+// walkDriveSyncRemote maps the folder's contents by relative path, with
+// Google-native files named after their export format. Among items that map
+// to the same path the most recently modified one wins.
+func walkDriveSyncRemote(ctx context.Context, svc *drive.Service, folderID string, formats, exclude []string, warnf func(string, ...any)) (map[string]*driveSyncRemote, map[string]string, error) {
+	tree, err := walkDriveTreeFields(ctx, svc, &drive.File{Id: folderID, MimeType: driveMimeFolder}, driveSyncFileFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := map[string]*driveSyncRemote{}
+	folders := map[string]string{"": folderID}
+	var visit func(dir *driveTreeNode, dirRel string)
+	visit = func(dir *driveTreeNode, dirRel string) {
+		children := append([]*driveTreeNode(nil), dir.Children...)
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].file.ModifiedTime > children[j].file.ModifiedTime
+		})
+		for _, node := range children {
+			f := node.file
+			if f.Name == "" || f.Name == "." || f.Name == ".." || strings.Contains(f.Name, "/") {
+				warnf("drive sync: skipping %q in %s (name not usable as a path)", f.Name, dir.ID)
+				continue
+			}
+			entry := &driveSyncRemote{
+				ID:           f.Id,
+				Name:         f.Name,
+				MimeType:     f.MimeType,
+				MD5:          f.Md5Checksum,
+				ModifiedTime: f.ModifiedTime,
+				Size:         f.Size,
+				Folder:       node.folder(),
+			}
+			name := f.Name
+			if !entry.Folder && strings.HasPrefix(f.MimeType, "application/vnd.google-apps.") {
+				format, ext, ok := driveSyncExportFormat(f.MimeType, formats)
+				if !ok {
+					continue
+				}
+				entry.Native = true
+				entry.ExportFormat = format
+				name = driveSyncExportName(name, ext)
+			}
+			rel := path.Join(dirRel, name)
+			if driveSyncExcluded(rel, exclude) {
+				continue
+			}
+			if _, dup := files[rel]; dup {
+				warnf("drive sync: skipping duplicate %s (%s)", rel, f.Id)
+				continue
+			}
+			files[rel] = entry
+			if entry.Folder {
+				folders[rel] = f.Id
+				visit(node, rel)
+			}
+		}
+	}
+	visit(tree, "")
+	return files, folders, nil
+}
+
+func walkDriveSyncLocal(root string, exclude []string) (map[string]*driveSyncLocal, error) {
+	files := map[string]*driveSyncLocal{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if driveSyncExcluded(rel, exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[rel] = &driveSyncLocal{Size: info.Size(), ModTime: info.ModTime(), Dir: d.IsDir()}
+		return nil
+	})
+	return files, err
+}
+
+func fileMD5(p string) (string, error) {
+	f, err := os.Open(p) //nolint:gosec // user-provided sync directory
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New() //nolint:gosec // matches Drive md5Checksum
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type driveSyncPlanner struct {
+	root      string
+	direction string
+	conflict  string
+	delete    bool
+	local     map[string]*driveSyncLocal
+	remote    map[string]*driveSyncRemote
+	state     map[string]driveSyncEntry
+	// next is the state to persist; the executor updates it as actions succeed.
+	next      map[string]driveSyncEntry
+	localMD5s map[string]string
+}
+
+// localMD5 hashes a local file, reusing the stored checksum when size and
+// mtime are unchanged since the last run.
+func (p *driveSyncPlanner) localMD5(rel string) (string, error) {
+	if sum, ok := p.localMD5s[rel]; ok {
+		return sum, nil
+	}
+	l := p.local[rel]
+	if prev, ok := p.state[rel]; ok && prev.MD5 != "" && prev.Size == l.Size && prev.LocalModNs == l.ModTime.UnixNano() {
+		p.localMD5s[rel] = prev.MD5
+		return prev.MD5, nil
+	}
+	sum, err := fileMD5(filepath.Join(p.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", err
+	}
+	p.localMD5s[rel] = sum
+	return sum, nil
+}
+
+func (p *driveSyncPlanner) plan() ([]driveSyncAction, error) {
+	paths := make([]string, 0, len(p.local)+len(p.remote))
+	seen := map[string]bool{}
+	for rel := range p.local {
+		paths = append(paths, rel)
+		seen[rel] = true
+	}
+	for rel := range p.remote {
+		if !seen[rel] {
+			paths = append(paths, rel)
+		}
+	}
+	sort.Strings(paths)
+
+	var (
+		actions  []driveSyncAction
+		trashed  []string
+		underDir = func(rel string) bool {
+			for _, dir := range trashed {
+				if strings.HasPrefix(rel, dir+"/") {
+					return true
+				}
+			}
+			return false
+		}
+	)
+	for _, rel := range paths {
+		if underDir(rel) {
+			delete(p.next, rel)
+			continue
+		}
+		l, r := p.local[rel], p.remote[rel]
+		switch {
+		case l != nil && r != nil && l.Dir != r.Folder:
+			actions = append(actions, driveSyncAction{Op: driveSyncOpConflict, Path: rel, FileID: r.ID, Reason: "file on one side, folder on the other"})
+		case l != nil && l.Dir:
+			if r == nil {
+				actions = append(actions, p.planLocalDir(rel)...)
+			} else {
+				p.next[rel] = driveSyncEntry{FileID: r.ID, Folder: true}
+			}
+		case r != nil && r.Folder:
+			if l == nil {
+				dirActions := p.planRemoteDir(rel, r)
+				for _, a := range dirActions {
+					if a.Op == driveSyncOpTrashRemote {
+						trashed = append(trashed, rel)
+					}
+				}
+				actions = append(actions, dirActions...)
+			}
+		default:
+			fileActions, err := p.planFile(rel, l, r)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, fileActions...)
+		}
+	}
+	return keepDriveSyncDirsWithUploads(actions), nil
+}
+
+func (p *driveSyncPlanner) planLocalDir(rel string) []driveSyncAction {
+	switch {
+	case p.direction == driveSyncDown:
+		if p.delete {
+			return []driveSyncAction{{Op: driveSyncOpDeleteLocal, Path: rel, Reason: "folder not on Drive"}}
+		}
+		return nil
+	case p.direction == driveSyncBoth && p.delete && p.state[rel].Folder:
+		delete(p.next, rel)
+		return []driveSyncAction{{Op: driveSyncOpDeleteLocal, Path: rel, Reason: "folder deleted on Drive"}}
+	}
+	return []driveSyncAction{{Op: driveSyncOpMkdirRemote, Path: rel}}
+}
+
+func (p *driveSyncPlanner) planRemoteDir(rel string, r *driveSyncRemote) []driveSyncAction {
+	switch {
+	case p.direction == driveSyncUp:
+		if p.delete {
+			return []driveSyncAction{{Op: driveSyncOpTrashRemote, Path: rel, FileID: r.ID, Reason: "folder not local"}}
+		}
+		return nil
+	case p.direction == driveSyncBoth && p.delete && p.state[rel].Folder && p.state[rel].FileID == r.ID:
+		delete(p.next, rel)
+		return []driveSyncAction{{Op: driveSyncOpTrashRemote, Path: rel, FileID: r.ID, Reason: "folder deleted locally"}}
+	}
+	return []driveSyncAction{{Op: driveSyncOpMkdirLocal, Path: rel, FileID: r.ID}}
+}
+
+// keepDriveSyncDirsWithUploads turns the removal of a local folder that was
+// deleted on Drive back into a mkdir when files inside it changed locally
+// and still need uploading.
+func keepDriveSyncDirsWithUploads(actions []driveSyncAction) []driveSyncAction {
+	for i, a := range actions {
+		if a.Op != driveSyncOpDeleteLocal || a.Reason != "folder deleted on Drive" {
+			continue
+		}
+		for _, child := range actions {
+			if child.Op == driveSyncOpUpload && strings.HasPrefix(child.Path, a.Path+"/") {
+				actions[i] = driveSyncAction{Op: driveSyncOpMkdirRemote, Path: a.Path, Reason: "holds local changes"}
+				break
+			}
+		}
+	}
+	return actions
+}
+
+func (p *driveSyncPlanner) planFile(rel string, l *driveSyncLocal, r *driveSyncRemote) ([]driveSyncAction, error) {
+	prev, hasPrev := p.state[rel]
+	remoteChanged := r != nil && (!hasPrev || prev.FileID != r.ID ||
+		(r.MD5 != "" && r.MD5 != prev.MD5) ||
+		(r.MD5 == "" && r.ModifiedTime != prev.RemoteModified))
+
+	var localSum string
+	if l != nil && (r != nil || hasPrev) {
+		sum, err := p.localMD5(rel)
+		if err != nil {
+			return nil, err
+		}
+		localSum = sum
+	}
+	localChanged := l != nil && (!hasPrev || localSum != prev.MD5)
+
+	var download []driveSyncAction
+	if r != nil {
+		download = []driveSyncAction{{Op: driveSyncOpDownload, Path: rel, FileID: r.ID}}
+	}
+
+	switch {
+	case l != nil && r != nil:
+		if r.Native {
+			// Exports are download-only: local edits never replace a Google Doc.
+			if p.direction == driveSyncUp || !remoteChanged {
+				p.keep(rel, l, r, prev.MD5)
+				return nil, nil
+			}
+			download[0].Reason = "exported"
+			return download, nil
+		}
+		if localSum == r.MD5 {
+			p.keep(rel, l, r, localSum)
+			return nil, nil
+		}
+		update := []driveSyncAction{{Op: driveSyncOpUpdate, Path: rel, FileID: r.ID, Reason: "content differs"}}
+		switch p.direction {
+		case driveSyncUp:
+			return update, nil
+		case driveSyncDown:
+			download[0].Reason = "content differs"
+			return download, nil
+		}
+		switch {
+		case localChanged && !remoteChanged:
+			update[0].Reason = "changed locally"
+			return update, nil
+		case remoteChanged && !localChanged:
+			download[0].Reason = "changed on Drive"
+			return download, nil
+		}
+		return p.resolveConflict(rel, l, r, update, download), nil
+
+	case l != nil && hasPrev && prev.Export:
+		// The Doc is gone from Drive or no longer exported (no --format).
+		// Uploading the export would create a binary copy, so never do that.
+		if p.delete && p.direction != driveSyncUp && !localChanged {
+			delete(p.next, rel)
+			return []driveSyncAction{{Op: driveSyncOpDeleteLocal, Path: rel, Reason: "export not on Drive"}}, nil
+		}
+		return nil, nil
+
+	case l != nil:
+		upload := []driveSyncAction{{Op: driveSyncOpUpload, Path: rel}}
+		switch p.direction {
+		case driveSyncUp:
+			return upload, nil
+		case driveSyncDown:
+			delete(p.next, rel)
+			if p.delete {
+				return []driveSyncAction{{Op: driveSyncOpDeleteLocal, Path: rel, Reason: "not on Drive"}}, nil
+			}
+			return nil, nil
+		}
+		if hasPrev {
+			if p.delete && !localChanged {
+				return []driveSyncAction{{Op: driveSyncOpDeleteLocal, Path: rel, Reason: "deleted on Drive"}}, nil
+			}
+			upload[0].Reason = "missing on Drive"
+		}
+		return upload, nil
+
+	default:
+		switch p.direction {
+		case driveSyncUp:
+			delete(p.next, rel)
+			if p.delete && !r.Native {
+				return []driveSyncAction{{Op: driveSyncOpTrashRemote, Path: rel, FileID: r.ID, Reason: "not local"}}, nil
+			}
+			return nil, nil
+		case driveSyncDown:
+			return download, nil
+		}
+		if hasPrev {
+			if p.delete && !remoteChanged && !r.Native {
+				return []driveSyncAction{{Op: driveSyncOpTrashRemote, Path: rel, FileID: r.ID, Reason: "deleted locally"}}, nil
+			}
+			download[0].Reason = "missing locally"
+		}
+		return download, nil
+	}
+}
+
+func (p *driveSyncPlanner) resolveConflict(rel string, l *driveSyncLocal, r *driveSyncRemote, update, download []driveSyncAction) []driveSyncAction {
+	switch p.conflict {
+	case driveSyncConflictLocal:
+		update[0].Reason = "conflict: keeping local"
+		return update
+	case driveSyncConflictRemote:
+		download[0].Reason = "conflict: keeping Drive"
+		return download
+	case driveSyncConflictNewer:
+		remoteTime, err := time.Parse(time.RFC3339Nano, r.ModifiedTime)
+		if err == nil && l.ModTime.After(remoteTime) {
+			update[0].Reason = "conflict: local is newer"
+			return update
+		}
+		if err == nil && remoteTime.After(l.ModTime) {
+			download[0].Reason = "conflict: Drive is newer"
+			return download
+		}
+	}
+	return []driveSyncAction{{Op: driveSyncOpConflict, Path: rel, FileID: r.ID, Reason: "changed on both sides"}}
+}
+
+func (p *driveSyncPlanner) keep(rel string, l *driveSyncLocal, r *driveSyncRemote, sum string) {
+	p.next[rel] = driveSyncEntry{
+		FileID:         r.ID,
+		MD5:            sum,
+		RemoteModified: r.ModifiedTime,
+		Size:           l.Size,
+		LocalModNs:     l.ModTime.UnixNano(),
+		Export:         r.Native,
+	}
+}
+
+type driveSyncExecutor struct {
+	svc     *drive.Service
+	root    string
+	remote  map[string]*driveSyncRemote
+	folders map[string]string
+	next    map[string]driveSyncEntry
+}
+
+func (e *driveSyncExecutor) localPath(rel string) string {
+	return filepath.Join(e.root, filepath.FromSlash(rel))
+}
+
+func (e *driveSyncExecutor) record(rel, fileID, sum, remoteModified string, export bool) error {
+	info, err := os.Stat(e.localPath(rel))
+	if err != nil {
+		return err
+	}
+	e.next[rel] = driveSyncEntry{
+		FileID:         fileID,
+		MD5:            sum,
+		RemoteModified: remoteModified,
+		Size:           info.Size(),
+		LocalModNs:     info.ModTime().UnixNano(),
+		Export:         export,
+	}
+	return nil
+}
+
+// driveSyncPhase orders actions so parents exist before children and
+// deletions run last (local folders deepest first).
+func driveSyncPhase(op string) int {
+	switch op {
+	case driveSyncOpMkdirRemote, driveSyncOpMkdirLocal:
+		return 0
+	case driveSyncOpTrashRemote, driveSyncOpDeleteLocal:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func (e *driveSyncExecutor) run(ctx context.Context, actions []driveSyncAction) error {
+	ordered := append([]driveSyncAction(nil), actions...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, pj := driveSyncPhase(ordered[i].Op), driveSyncPhase(ordered[j].Op)
+		if pi != pj {
+			return pi < pj
+		}
+		if pi == 2 {
+			return ordered[i].Path > ordered[j].Path
+		}
+		return false
+	})
+	for _, a := range ordered {
+		if err := e.apply(ctx, a); err != nil {
+			return fmt.Errorf("%s %s: %w", a.Op, a.Path, err)
+		}
+	}
+	return nil
+}
+
+func (e *driveSyncExecutor) apply(ctx context.Context, a driveSyncAction) error {
+	switch a.Op {
+	case driveSyncOpMkdirRemote:
+		created, err := e.svc.Files.Create(&drive.File{
+			Name:     path.Base(a.Path),
+			MimeType: driveMimeFolder,
+			Parents:  []string{e.folders[driveSyncParent(a.Path)]},
+		}).SupportsAllDrives(true).Fields("id").Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		e.folders[a.Path] = created.Id
+		e.next[a.Path] = driveSyncEntry{FileID: created.Id, Folder: true}
+		return nil
+	case driveSyncOpMkdirLocal:
+		if err := os.MkdirAll(e.localPath(a.Path), 0o755); err != nil { //nolint:gosec // user-visible sync directory
+			return err
+		}
+		e.next[a.Path] = driveSyncEntry{FileID: a.FileID, Folder: true}
+		return nil
+	case driveSyncOpUpload, driveSyncOpUpdate:
+		f, err := e.upload(ctx, a)
+		if err != nil {
+			return err
+		}
+		return e.record(a.Path, f.Id, f.Md5Checksum, f.ModifiedTime, false)
+	case driveSyncOpDownload:
+		return e.download(ctx, a)
+	case driveSyncOpTrashRemote:
+		delete(e.next, a.Path)
+		_, err := e.svc.Files.Update(a.FileID, &drive.File{Trashed: true}).
+			SupportsAllDrives(true).
+			Fields("id, trashed").
+			Context(ctx).
+			Do()
+		return err
+	case driveSyncOpDeleteLocal:
+		delete(e.next, a.Path)
+		target := e.localPath(a.Path)
+		info, err := os.Lstat(target)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(target); err != nil && !info.IsDir() {
+			return err
+		}
+		// Folders still holding excluded files are left in place.
+		return nil
+	}
+	return nil
+}
+
+func driveSyncParent(rel string) string {
+	dir := path.Dir(rel)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+func (e *driveSyncExecutor) upload(ctx context.Context, a driveSyncAction) (*drive.File, error) {
+	local := e.localPath(a.Path)
+	f, err := os.Open(local) //nolint:gosec // user-provided sync directory
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	media := gapi.ContentType(guessMimeType(local))
+	if a.Op == driveSyncOpUpdate {
+		return e.svc.Files.Update(a.FileID, &drive.File{}).
+			SupportsAllDrives(true).
+			Media(f, media).
+			Fields(driveSyncFileFields).
+			Context(ctx).
+			Do()
+	}
+	parent, ok := e.folders[driveSyncParent(a.Path)]
+	if !ok {
+		return nil, fmt.Errorf("no Drive folder for %s", driveSyncParent(a.Path))
+	}
+	return e.svc.Files.Create(&drive.File{Name: path.Base(a.Path), Parents: []string{parent}}).
+		SupportsAllDrives(true).
+		Media(f, media).
+		Fields(driveSyncFileFields).
+		Context(ctx).
+		Do()
+}
+
+func (e *driveSyncExecutor) download(ctx context.Context, a driveSyncAction) error {
+	r := e.remote[a.Path]
+	if r == nil {
+		return errors.New("missing remote metadata")
+	}
+	dest := e.localPath(a.Path)
+	meta := &drive.File{Id: r.ID, Name: r.Name, MimeType: r.MimeType}
+	if _, _, err := downloadDriveFile(ctx, e.svc, meta, dest, r.ExportFormat); err != nil {
+		return err
+	}
+	if mod, err := time.Parse(time.RFC3339Nano, r.ModifiedTime); err == nil {
+		if err := os.Chtimes(dest, mod, mod); err != nil {
+			return err
+		}
+	}
+	sum := r.MD5
+	if r.Native {
+		// Exports have no Drive checksum; remember the local one so later
+		// runs can tell whether the copy was edited.
+		localSum, err := fileMD5(dest)
+		if err != nil {
+			return err
+		}
+		sum = localSum
+	}
+	return e.record(a.Path, r.ID, sum, r.ModifiedTime, r.Native)
+}
+
+func (c *DriveSyncCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	localDir := strings.TrimSpace(c.LocalDir)
+	folderID := strings.TrimSpace(c.FolderID)
+	if localDir == "" {
+		return usage("empty localDir")
+	}
+	if folderID == "" {
+		return usage("empty folderId")
+	}
+	formats := make([]string, 0, len(c.Format))
+	for _, f := range c.Format {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if f != formatAuto {
+			if err := validateDriveDownloadFormatFlag(f); err != nil {
+				return err
+			}
+		}
+		formats = append(formats, f)
+	}
+	for _, pattern := range c.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return usagef("invalid --exclude %q: %v", pattern, err)
+		}
+	}
+
+	expanded, err := config.ExpandPath(localDir)
+	if err != nil {
+		return err
+	}
+	root, err := filepath.Abs(expanded)
+	if err != nil {
+		return err
+	}
+	// A missing directory is fine when pulling: it is created on the first run.
+	info, statErr := os.Stat(root)
+	switch {
+	case statErr == nil && !info.IsDir():
+		return usagef("%s is not a directory", root)
+	case errors.Is(statErr, os.ErrNotExist) && c.Direction != driveSyncDown:
+		return usagef("%s does not exist", root)
+	case statErr != nil && !errors.Is(statErr, os.ErrNotExist):
+		return statErr
+	}
+
+	account, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	statePath, err := driveSyncStatePath(account, folderID, root)
+	if err != nil {
+		return err
+	}
+	state, err := loadDriveSyncState(statePath)
+	if err != nil {
+		return err
+	}
+
+	remote, folders, err := walkDriveSyncRemote(ctx, svc, folderID, formats, c.Exclude, u.Err().Printf)
+	if err != nil {
+		return err
+	}
+	local := map[string]*driveSyncLocal{}
+	if statErr == nil {
+		local, err = walkDriveSyncLocal(root, c.Exclude)
+		if err != nil {
+			return err
+		}
+	}
+
+	next := make(map[string]driveSyncEntry, len(state.Files))
+	for rel, entry := range state.Files {
+		if local[rel] != nil || remote[rel] != nil {
+			next[rel] = entry
+		}
+	}
+	planner := &driveSyncPlanner{
+		root:      root,
+		direction: c.Direction,
+		conflict:  c.Conflict,
+		delete:    c.Delete,
+		local:     local,
+		remote:    remote,
+		state:     state.Files,
+		next:      next,
+		localMD5s: map[string]string{},
+	}
+	actions, err := planner.plan()
+	if err != nil {
+		return err
+	}
+
+	if err := dryRunExit(ctx, flags, "drive.sync", map[string]any{
+		"localDir":  root,
+		"folderId":  folderID,
+		"direction": c.Direction,
+		"actions":   driveSyncActionsOrEmpty(actions),
+	}); err != nil {
+		return err
+	}
+	if deletes := countDriveSyncDeletes(actions); deletes > 0 {
+		if err := confirmDestructiveChecked(ctx, flags, fmt.Sprintf("delete %d item(s) while syncing %s with %s", deletes, root, folderID)); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil { //nolint:gosec // user-visible sync directory
+		return err
+	}
+	executor := &driveSyncExecutor{svc: svc, root: root, remote: remote, folders: folders, next: next}
+	runErr := executor.run(ctx, actions)
+
+	state.Account = account
+	state.FolderID = folderID
+	state.LocalDir = root
+	state.Files = next
+	if runErr == nil {
+		state.LastSyncAtMs = time.Now().UnixMilli()
+	}
+	if err := saveDriveSyncState(statePath, state); err != nil {
+		if runErr != nil {
+			return runErr
+		}
+		return err
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	summary := map[string]int{}
+	for _, a := range actions {
+		summary[a.Op]++
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"localDir":  root,
+			"folderId":  folderID,
+			"direction": c.Direction,
+			"actions":   driveSyncActionsOrEmpty(actions),
+			"summary":   summary,
+		})
+	}
+	if len(actions) == 0 {
+		u.Err().Println("Already in sync")
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "OP\tPATH\tREASON")
+	for _, a := range actions {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", a.Op, sanitizeTab(a.Path), sanitizeTab(a.Reason))
+	}
+	if summary[driveSyncOpConflict] > 0 {
+		u.Err().Printf("%d conflict(s) left unchanged; rerun with --conflict local|remote to resolve", summary[driveSyncOpConflict])
+	}
+	return nil
+}
+
+func driveSyncActionsOrEmpty(actions []driveSyncAction) []driveSyncAction {
+	if actions == nil {
+		return []driveSyncAction{}
+	}
+	return actions
+}
+
+func countDriveSyncDeletes(actions []driveSyncAction) int {
+	n := 0
+	for _, a := range actions {
+		if a.Op == driveSyncOpTrashRemote || a.Op == driveSyncOpDeleteLocal {
+			n++
+		}
+	}
+	return n
+}
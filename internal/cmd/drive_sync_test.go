@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+type driveSyncTestResult struct {
+	Actions []driveSyncAction `json:"actions"`
+	Summary map[string]int    `json:"summary"`
+}
+
+func runDriveSyncForTest(t *testing.T, flags *RootFlags, args ...string) driveSyncTestResult {
+	t.Helper()
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	out := captureStdout(t, func() {
+		if err := runKong(t, &DriveSyncCmd{}, args, ctx, flags); err != nil {
+			t.Fatalf("sync %v: %v", args, err)
+		}
+	})
+	var result driveSyncTestResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	return result
+}
+
+func driveSyncOps(actions []driveSyncAction) string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		parts = append(parts, a.Op+":"+a.Path)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+func writeSyncFile(t *testing.T, root, rel, content string, mod time.Time) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(p, mod, mod); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestDriveSyncCmd_BothDirections(t *testing.T) {
+	setWatchTestConfigHome(t)
+	tree := newFakeDriveTree(t)
+	tree.add("root1", "c.txt", "text/plain", "C")
+	tree.add("root1", "Plan", driveMimeGoogleDoc, "plan")
+	tree.add("root1", "Form", "application/vnd.google-apps.form", "")
+	rem := tree.add("root1", "rem", driveMimeFolder, "")
+	tree.add(rem.ID, "d.txt", "text/plain", "D")
+	svc, closeSrv := newDriveTestService(t, tree)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	root := t.TempDir()
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeSyncFile(t, root, "a.txt", "A", old)
+	writeSyncFile(t, root, "sub/b.txt", "B", old)
+	writeSyncFile(t, root, "skip.log", "x", old)
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+	args := []string{root, "root1", "--format", "docx", "--exclude", "*.log"}
+
+	dry := captureStdout(t, func() {
+		ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+		err := runKong(t, &DriveSyncCmd{}, args, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+		if err != nil && ExitCode(err) != 0 {
+			t.Fatalf("dry run: %v", err)
+		}
+	})
+	if !strings.Contains(dry, `"dry_run": true`) || !strings.Contains(dry, "sub/b.txt") || tree.byName("a.txt") != nil {
+		t.Fatalf("unexpected dry run: %s", dry)
+	}
+
+	first := runDriveSyncForTest(t, flags, args...)
+	want := "download:Plan.docx download:c.txt download:rem/d.txt mkdir-local:rem mkdir-remote:sub upload:a.txt upload:sub/b.txt"
+	if got := driveSyncOps(first.Actions); got != want {
+		t.Fatalf("unexpected first plan:\n got %s\nwant %s", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "Plan.docx")); string(data) != "exported:plan" {
+		t.Fatalf("unexpected export: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "rem", "d.txt")); string(data) != "D" {
+		t.Fatalf("unexpected download: %q", data)
+	}
+	sub := tree.byName("sub")
+	if b := tree.byName("b.txt"); sub == nil || b == nil || b.Parent != sub.ID || b.Content != "B" {
+		t.Fatalf("unexpected upload tree: sub=%#v b=%#v", sub, b)
+	}
+
+	if again := runDriveSyncForTest(t, flags, args...); len(again.Actions) != 0 {
+		t.Fatalf("expected no actions, got %v", driveSyncOps(again.Actions))
+	}
+
+	// One side changed each, then a real conflict.
+	later := time.Now().Add(time.Hour)
+	writeSyncFile(t, root, "a.txt", "A2", later)
+	tree.byName("c.txt").Content = "C2"
+	writeSyncFile(t, root, "rem/d.txt", "D-local", later)
+	tree.byName("d.txt").Content = "D-remote"
+
+	second := runDriveSyncForTest(t, flags, append(args, "--conflict", "skip")...)
+	if got := driveSyncOps(second.Actions); got != "conflict:rem/d.txt download:c.txt update:a.txt" {
+		t.Fatalf("unexpected second plan: %s", got)
+	}
+	if tree.byName("a.txt").Content != "A2" {
+		t.Fatalf("expected a.txt to be updated on Drive")
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "c.txt")); string(data) != "C2" {
+		t.Fatalf("expected c.txt to be downloaded, got %q", data)
+	}
+
+	third := runDriveSyncForTest(t, flags, append(args, "--conflict", "local")...)
+	if got := driveSyncOps(third.Actions); got != "update:rem/d.txt" || tree.byName("d.txt").Content != "D-local" {
+		t.Fatalf("unexpected conflict resolution: %s", got)
+	}
+
+	// Deletions only propagate with --delete; Google Docs are never trashed.
+	if err := os.Remove(filepath.Join(root, "a.txt")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := os.Remove(filepath.Join(root, "Plan.docx")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	tree.byName("c.txt").Trashed = true
+	fourth := runDriveSyncForTest(t, flags, append(args, "--delete")...)
+	if got := driveSyncOps(fourth.Actions); got != "delete-local:c.txt download:Plan.docx trash-remote:a.txt" {
+		t.Fatalf("unexpected delete plan: %s", got)
+	}
+	if tree.byName("a.txt") != nil {
+		t.Fatalf("expected a.txt to be trashed")
+	}
+	if _, err := os.Stat(filepath.Join(root, "c.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected c.txt to be removed locally: %v", err)
+	}
+}
+
+func TestDriveSyncCmd_ExportsAreNeverUploaded(t *testing.T) {
+	setWatchTestConfigHome(t)
+	tree := newFakeDriveTree(t)
+	tree.add("root1", "Plan", driveMimeGoogleDoc, "plan")
+	svc, closeSrv := newDriveTestService(t, tree)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	root := t.TempDir()
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+	if got := driveSyncOps(runDriveSyncForTest(t, flags, root, "root1", "--format", "docx").Actions); got != "download:Plan.docx" {
+		t.Fatalf("unexpected first plan: %s", got)
+	}
+
+	// Without --format the Doc is not listed; the export must not be uploaded.
+	if again := runDriveSyncForTest(t, flags, root, "root1"); len(again.Actions) != 0 {
+		t.Fatalf("expected no actions, got %s", driveSyncOps(again.Actions))
+	}
+	if up := runDriveSyncForTest(t, flags, root, "root1", "--direction", "up"); len(up.Actions) != 0 {
+		t.Fatalf("expected no actions pushing up, got %s", driveSyncOps(up.Actions))
+	}
+	if tree.byName("Plan.docx") != nil {
+		t.Fatalf("export was uploaded as a binary")
+	}
+
+	pruned := runDriveSyncForTest(t, flags, root, "root1", "--delete")
+	if got := driveSyncOps(pruned.Actions); got != "delete-local:Plan.docx" {
+		t.Fatalf("unexpected delete plan: %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Plan.docx")); !os.IsNotExist(err) {
+		t.Fatalf("expected export removed: %v", err)
+	}
+}
+
+func TestDriveSyncCmd_DeletesFoldersBothWays(t *testing.T) {
+	setWatchTestConfigHome(t)
+	tree := newFakeDriveTree(t)
+	rem := tree.add("root1", "rem", driveMimeFolder, "")
+	tree.add(rem.ID, "r.txt", "text/plain", "R")
+	svc, closeSrv := newDriveTestService(t, tree)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	root := t.TempDir()
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeSyncFile(t, root, "loc/a.txt", "A", old)
+	writeSyncFile(t, root, "keep/b.txt", "B", old)
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+	args := []string{root, "root1", "--delete"}
+
+	first := runDriveSyncForTest(t, flags, args...)
+	if got := driveSyncOps(first.Actions); got != "download:rem/r.txt mkdir-local:rem mkdir-remote:keep mkdir-remote:loc upload:keep/b.txt upload:loc/a.txt" {
+		t.Fatalf("unexpected first plan: %s", got)
+	}
+	if again := runDriveSyncForTest(t, flags, args...); len(again.Actions) != 0 {
+		t.Fatalf("expected no actions, got %s", driveSyncOps(again.Actions))
+	}
+
+	// Folders deleted on one side are deleted on the other, not recreated.
+	tree.byName("loc").Trashed = true
+	tree.byName("keep").Trashed = true
+	writeSyncFile(t, root, "keep/b.txt", "B2", time.Now().Add(time.Hour))
+	if err := os.RemoveAll(filepath.Join(root, "rem")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	second := runDriveSyncForTest(t, flags, args...)
+	want := "delete-local:loc delete-local:loc/a.txt mkdir-remote:keep trash-remote:rem upload:keep/b.txt"
+	if got := driveSyncOps(second.Actions); got != want {
+		t.Fatalf("unexpected delete plan:\n got %s\nwant %s", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(root, "loc")); !os.IsNotExist(err) {
+		t.Fatalf("expected loc removed locally: %v", err)
+	}
+	if tree.byName("rem") != nil {
+		t.Fatalf("expected rem to be trashed on Drive")
+	}
+	reuploaded := false
+	for _, f := range tree.files {
+		if f.Name == "b.txt" && f.Content == "B2" && !tree.files[f.Parent].Trashed {
+			reuploaded = true
+		}
+	}
+	if !reuploaded {
+		t.Fatalf("expected the locally changed file to be re-uploaded")
+	}
+}
+
+func TestDriveSyncCmd_DownMirrorsAndPrunes(t *testing.T) {
+	setWatchTestConfigHome(t)
+	tree := newFakeDriveTree(t)
+	tree.add("root1", "keep.txt", "text/plain", "K")
+	svc, closeSrv := newDriveTestService(t, tree)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+
+	root := filepath.Join(t.TempDir(), "mirror")
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+	first := runDriveSyncForTest(t, flags, root, "root1", "--direction", "down")
+	if got := driveSyncOps(first.Actions); got != "download:keep.txt" {
+		t.Fatalf("unexpected plan: %s", got)
+	}
+
+	writeSyncFile(t, root, "stale/old.txt", "x", time.Now())
+	writeSyncFile(t, root, "keep.txt", "changed", time.Now())
+	second := runDriveSyncForTest(t, flags, root, "root1", "--direction", "down", "--delete")
+	if got := driveSyncOps(second.Actions); got != "delete-local:stale delete-local:stale/old.txt download:keep.txt" {
+		t.Fatalf("unexpected plan: %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "stale")); !os.IsNotExist(err) {
+		t.Fatalf("expected stale dir removed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "keep.txt")); string(data) != "K" {
+		t.Fatalf("expected keep.txt restored, got %q", data)
+	}
+}
+
+func TestWalkDriveSyncRemote_NewestDuplicateAndExcludedFolders(t *testing.T) {
+	tree := newFakeDriveTree(t)
+	tree.add("root1", "a.txt", "text/plain", "old")
+	newer := tree.add("root1", "a.txt", "text/plain", "new")
+	sub := tree.add("root1", "sub", driveMimeFolder, "")
+	tree.add(sub.ID, "b.txt", "text/plain", "B")
+	skip := tree.add("root1", "tmp", driveMimeFolder, "")
+	tree.add(skip.ID, "c.txt", "text/plain", "C")
+	svc, closeSrv := newDriveTestService(t, tree)
+	defer closeSrv()
+
+	var warnings []string
+	files, folders, err := walkDriveSyncRemote(context.Background(), svc, "root1", nil, []string{"tmp"}, func(format string, args ...any) {
+		warnings = append(warnings, format)
+	})
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+	paths := make([]string, 0, len(files))
+	for rel := range files {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	if got := strings.Join(paths, " "); got != "a.txt sub sub/b.txt" {
+		t.Fatalf("unexpected remote paths: %s", got)
+	}
+	if files["a.txt"].ID != newer.ID || len(warnings) != 1 {
+		t.Fatalf("expected the newest a.txt to win with one warning, got %s (%v)", files["a.txt"].ID, warnings)
+	}
+	if folders["sub"] != sub.ID {
+		t.Fatalf("unexpected folders: %v", folders)
+	}
+}
+
+func TestDriveSyncCmd_Validation(t *testing.T) {
+	setWatchTestConfigHome(t)
+	flags := &RootFlags{Account: "a@b.com"}
+	missing := filepath.Join(t.TempDir(), "missing")
+	cases := [][]string{
+		{missing, "root1"},
+		{t.TempDir(), "root1", "--format", "gif"},
+		{t.TempDir(), "root1", "--exclude", "["},
+		{t.TempDir(), "root1", "--direction", "sideways"},
+	}
+	for _, args := range cases {
+		if err := runKong(t, &DriveSyncCmd{}, args, newQuietUIContext(t), flags); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+)
+
+var newDriveHTTPClient = googleapi.NewDriveHTTPClient
+
+const (
+	// Resumable upload chunks must be multiples of 256 KiB.
+	driveUploadChunkAlign   = 256 << 10
+	driveProgressInterval   = 100 * time.Millisecond
+	driveProgressBarWidth   = 24
+	defaultDriveChunkSize   = "16MiB"
+	defaultDriveParallelism = 4
+)
+
+type DriveTransferFlags struct {
+	ChunkSize      string `name:"chunk-size" help:"Chunk size for resumable uploads and ranged downloads (e.g. 8MiB)" default:"16MiB"`
+	MaxBytesPerSec string `name:"max-bytes-per-sec" help:"Throttle the transfer (e.g. 512k, 10M)"`
+	Progress       bool   `name:"progress" help:"Show a progress bar on stderr when it is a terminal" default:"true" negatable:""`
+}
+
+type driveTransferOptions struct {
+	client    *http.Client
+	chunkSize int64
+	parallel  int
+	limiter   *byteRateLimiter
+	progress  bool
+}
+
+func (f DriveTransferFlags) options() (driveTransferOptions, error) {
+	raw := f.ChunkSize
+	if strings.TrimSpace(raw) == "" {
+		raw = defaultDriveChunkSize
+	}
+	chunk, err := parseByteSize(raw)
+	if err != nil {
+		return driveTransferOptions{}, usagef("invalid --chunk-size: %v", err)
+	}
+	chunk -= chunk % driveUploadChunkAlign
+	if chunk <= 0 {
+		return driveTransferOptions{}, usage("--chunk-size must be at least 256KiB")
+	}
+	rate, err := parseByteSize(f.MaxBytesPerSec)
+	if err != nil {
+		return driveTransferOptions{}, usagef("invalid --max-bytes-per-sec: %v", err)
+	}
+	return driveTransferOptions{
+		chunkSize: chunk,
+		limiter:   newByteRateLimiter(rate),
+		progress:  f.Progress,
+	}, nil
+}
+
+// byteRateLimiter paces transfers to an average rate shared by all workers.
+type byteRateLimiter struct {
+	rate  float64
+	mu    sync.Mutex
+	start time.Time
+	sent  int64
+}
+
+func newByteRateLimiter(bytesPerSec int64) *byteRateLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &byteRateLimiter{rate: float64(bytesPerSec)}
+}
+
+func (l *byteRateLimiter) wait(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+	l.sent += int64(n)
+	due := l.start.Add(time.Duration(float64(l.sent) / l.rate * float64(time.Second)))
+	l.mu.Unlock()
+
+	d := time.Until(due)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// transferProgress renders a single-line progress bar on stderr. A nil
+// *transferProgress is a no-op, so callers never check whether it is enabled.
+type transferProgress struct {
+	w     io.Writer
+	label string
+	total int64
+	start time.Time
+
+	mu   sync.Mutex
+	done int64
+	last time.Time
+}
+
+func newTransferProgress(enabled bool, label string, total int64) *transferProgress {
+	if !enabled || !term.IsTerminal(int(os.Stderr.Fd())) { //nolint:gosec // os file descriptor fits int on supported targets
+		return nil
+	}
+	return &transferProgress{w: os.Stderr, label: label, total: total, start: time.Now()}
+}
+
+func (p *transferProgress) add(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	p.renderLocked(false)
+}
+
+func (p *transferProgress) set(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done = n
+	p.renderLocked(false)
+}
+
+func (p *transferProgress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.renderLocked(true)
+	fmt.Fprintln(p.w)
+}
+
+func (p *transferProgress) renderLocked(force bool) {
+	now := time.Now()
+	if !force && now.Sub(p.last) < driveProgressInterval {
+		return
+	}
+	p.last = now
+	fmt.Fprintf(p.w, "\r%s", formatTransferProgress(p.label, p.done, p.total, now.Sub(p.start)))
+}
+
+func formatTransferProgress(label string, done, total int64, elapsed time.Duration) string {
+	var rate string
+	if secs := elapsed.Seconds(); secs > 0 {
+		rate = formatDriveSize(int64(float64(done)/secs)) + "/s"
+	}
+	if total <= 0 {
+		return fmt.Sprintf("%s %s %s", label, formatDriveSize(done), rate)
+	}
+	frac := float64(done) / float64(total)
+	if frac > 1 {
+		frac = 1
+	}
+	filled := int(frac * driveProgressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", driveProgressBarWidth-filled)
+	return fmt.Sprintf("%s [%s] %3d%% %s/%s %s", label, bar, int(frac*100), formatDriveSize(done), formatDriveSize(total), rate)
+}
+
+// transferReader throttles and reports progress for a streaming body.
+type transferReader struct {
+	ctx      context.Context //nolint:containedctx // io.Reader has no context parameter
+	r        io.Reader
+	limiter  *byteRateLimiter
+	progress *transferProgress
+}
+
+func (t *transferReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	t.progress.add(int64(n))
+	if waitErr := t.limiter.wait(t.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
+
+func wrapTransferReader(ctx context.Context, r io.Reader, opts driveTransferOptions, progress *transferProgress) io.Reader {
+	if opts.limiter == nil && progress == nil {
+		return r
+	}
+	return &transferReader{ctx: ctx, r: r, limiter: opts.limiter, progress: progress}
+}
+
+// chunkReader is one resumable-upload chunk. It stays seekable so the retry
+// transport can rewind it instead of buffering a copy, and reports absolute
+// progress so replays are not double counted.
+type chunkReader struct {
+	*io.SectionReader
+	ctx      context.Context //nolint:containedctx // io.Reader has no context parameter
+	base     int64
+	limiter  *byteRateLimiter
+	progress *transferProgress
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	n, err := c.SectionReader.Read(p)
+	if pos, seekErr := c.Seek(0, io.SeekCurrent); seekErr == nil {
+		c.progress.set(c.base + pos)
+	}
+	if waitErr := c.limiter.wait(c.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
+
+func driveMediaURL(basePath, fileID string) string {
+	u := gapi.ResolveRelative(basePath, "files/"+url.PathEscape(fileID))
+	return u + "?alt=media&supportsAllDrives=true"
+}
+
+// downloadDriveRanges fetches [0,size) with parallel Range requests directly
+// into f.
+func downloadDriveRanges(ctx context.Context, client *http.Client, mediaURL string, f *os.File, size int64, opts driveTransferOptions, progress *transferProgress) error {
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	type part struct{ start, end int64 }
+	parts := make(chan part)
+	errs := make(chan error, opts.parallel)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for range opts.parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range parts {
+				if err := downloadDriveRange(ctx, client, mediaURL, f, p.start, p.end, opts, progress); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+feed:
+	for start := int64(0); start < size; start += opts.chunkSize {
+		end := min(start+opts.chunkSize, size) - 1
+		select {
+		case parts <- part{start: start, end: end}:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(parts)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func downloadDriveRange(ctx context.Context, client *http.Client, mediaURL string, f *os.File, start, end int64, opts driveTransferOptions, progress *transferProgress) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		if err := gapi.CheckResponse(resp); err != nil {
+			return err
+		}
+		return fmt.Errorf("range request for bytes %d-%d returned %s", start, end, resp.Status)
+	}
+	body := wrapTransferReader(ctx, io.LimitReader(resp.Body, end-start+1), opts, progress)
+	n, err := io.Copy(io.NewOffsetWriter(f, start), body)
+	if err != nil {
+		return err
+	}
+	if n != end-start+1 {
+		return fmt.Errorf("short read for bytes %d-%d: got %d bytes", start, end, n)
+	}
+	return nil
+}
+
+func verifyDriveChecksum(want, got string) error {
+	if want == "" || strings.EqualFold(want, got) {
+		return nil
+	}
+	return fmt.Errorf("checksum mismatch: Drive md5 %s, downloaded %s", want, got)
+}
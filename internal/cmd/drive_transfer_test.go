@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // test fake mirrors Drive md5Checksum
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+func stubDriveHTTPClientForTest(t *testing.T, client *http.Client) {
+	t.Helper()
+	orig := newDriveHTTPClient
+	t.Cleanup(func() { newDriveHTTPClient = orig })
+	newDriveHTTPClient = func(context.Context, string) (*http.Client, error) { return client, nil }
+}
+
+type fakeResumableUpload struct {
+	t        *testing.T
+	mu       sync.Mutex
+	srvURL   string
+	meta     map[string]any
+	data     []byte
+	puts     int
+	queries  int
+	failPuts map[int]bool
+}
+
+func (f *fakeResumableUpload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/upload/drive/v3/files"):
+		requireQuery(f.t, r, "uploadType", "resumable")
+		if r.Header.Get("X-Upload-Content-Length") == "" {
+			f.t.Errorf("missing X-Upload-Content-Length")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&f.meta)
+		w.Header().Set("Location", f.srvURL+"/session/1")
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPut && r.URL.Path == "/session/1":
+		rng := r.Header.Get("Content-Range")
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(rng, "bytes */") {
+			f.queries++
+		} else {
+			f.puts++
+			if f.failPuts[f.puts] {
+				http.Error(w, `{"error":{"code":400,"message":"boom"}}`, http.StatusBadRequest)
+				return
+			}
+			var start, end, total int64
+			if _, err := fmt.Sscanf(rng, "bytes %d-%d/%d", &start, &end, &total); err != nil || start != int64(len(f.data)) || end-start+1 != int64(len(body)) {
+				f.t.Errorf("unexpected chunk %q (have %d bytes, got %d)", rng, len(f.data), len(body))
+			}
+			f.data = append(f.data, body...)
+		}
+		total, _ := strconv.Atoi(rng[strings.LastIndex(rng, "/")+1:])
+		if len(f.data) == total {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "up1", "name": f.meta["name"], "size": strconv.Itoa(total)})
+			return
+		}
+		if len(f.data) > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(f.data)-1))
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDriveUploadCmd_ResumeContinuesSession(t *testing.T) {
+	setWatchTestConfigHome(t)
+	api := &fakeResumableUpload{t: t, failPuts: map[int]bool{2: true}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	api.srvURL = srv.URL
+	svc, closeSrv := newDriveTestService(t, api)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+	stubDriveHTTPClientForTest(t, srv.Client())
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 40000) // 625 KiB: three 256 KiB chunks
+	local := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(local, content, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	flags := &RootFlags{Account: "a@b.com"}
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	args := []string{local, "--resume", "--chunk-size", "256k", "--parent", "p1"}
+
+	err := runKong(t, &DriveUploadCmd{}, args, ctx, flags)
+	if err == nil || !strings.Contains(err.Error(), "rerun with --resume") {
+		t.Fatalf("expected interrupted upload, got %v", err)
+	}
+	if len(api.data) != 256<<10 {
+		t.Fatalf("expected one committed chunk, got %d bytes", len(api.data))
+	}
+	if sessions := driveUploadSessionFiles(t); len(sessions) != 1 {
+		t.Fatalf("expected a saved session, got %v", sessions)
+	}
+
+	out := captureStdout(t, func() {
+		if err := runKong(t, &DriveUploadCmd{}, args, ctx, flags); err != nil {
+			t.Fatalf("resume: %v", err)
+		}
+	})
+	if api.queries != 1 || !bytes.Equal(api.data, content) {
+		t.Fatalf("expected resumed upload (queries=%d, bytes=%d)", api.queries, len(api.data))
+	}
+	if api.meta["name"] != "big.bin" {
+		t.Fatalf("unexpected metadata: %#v", api.meta)
+	}
+	if !strings.Contains(out, `"id": "up1"`) {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if sessions := driveUploadSessionFiles(t); len(sessions) != 0 {
+		t.Fatalf("expected session file to be removed, got %v", sessions)
+	}
+}
+
+func driveUploadSessionFiles(t *testing.T) []string {
+	t.Helper()
+	dir, err := config.DriveUploadSessionsDir()
+	if err != nil {
+		t.Fatalf("sessions dir: %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	return files
+}
+
+func TestDriveDownloadCmd_ParallelRangesVerifyChecksum(t *testing.T) {
+	content := bytes.Repeat([]byte("abcdefghijklmnop"), 45000) // ~703 KiB
+	sum := md5.Sum(content)                                    //nolint:gosec // test fake
+	checksum := hex.EncodeToString(sum[:])
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/files/big1") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("alt") != "media" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "big1", "name": "big.bin", "mimeType": "application/octet-stream",
+				"size": strconv.Itoa(len(content)), "md5Checksum": checksum,
+			})
+			return
+		}
+		requireSupportsAllDrives(t, r)
+		rng := r.Header.Get("Range")
+		mu.Lock()
+		ranges = append(ranges, rng)
+		mu.Unlock()
+		var start, end int
+		if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil {
+			t.Errorf("bad range %q", rng)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(content[start : end+1])
+	})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	svc, closeSrv := newDriveTestService(t, handler)
+	defer closeSrv()
+	stubDriveServiceForTest(t, svc)
+	stubDriveHTTPClientForTest(t, srv.Client())
+
+	dest := filepath.Join(t.TempDir(), "out.bin")
+	flags := &RootFlags{Account: "a@b.com"}
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	_ = captureStdout(t, func() {
+		args := []string{"big1", "--out", dest, "--chunk-size", "256k", "--parallel", "3", "--max-bytes-per-sec", "1G"}
+		if err := runKong(t, &DriveDownloadCmd{}, args, ctx, flags); err != nil {
+			t.Fatalf("download: %v", err)
+		}
+	})
+	got, err := os.ReadFile(dest)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("unexpected download (%d bytes, err=%v)", len(got), err)
+	}
+	if len(ranges) != 3 {
+		t.Fatalf("expected 3 range requests, got %v", ranges)
+	}
+
+	checksum = strings.Repeat("0", 32)
+	err = runKong(t, &DriveDownloadCmd{}, []string{"big1", "--out", dest, "--chunk-size", "256k"}, ctx, flags)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestDriveTransferFlags_Options(t *testing.T) {
+	opts, err := DriveTransferFlags{ChunkSize: "1M", MaxBytesPerSec: "512k"}.options()
+	if err != nil || opts.chunkSize != 1<<20 || opts.limiter == nil || opts.limiter.rate != 512<<10 {
+		t.Fatalf("unexpected options: %#v err=%v", opts, err)
+	}
+	if opts, err := (DriveTransferFlags{ChunkSize: "300k"}).options(); err != nil || opts.chunkSize != 256<<10 {
+		t.Fatalf("expected chunk rounded down to 256KiB, got %#v err=%v", opts, err)
+	}
+	for _, f := range []DriveTransferFlags{{ChunkSize: "100k"}, {ChunkSize: "lots"}, {MaxBytesPerSec: "fast"}} {
+		if _, err := f.options(); err == nil {
+			t.Fatalf("expected error for %#v", f)
+		}
+	}
+}
+
+func TestFormatTransferProgress(t *testing.T) {
+	got := formatTransferProgress("big.bin", 512, 1024, time.Second)
+	if !strings.Contains(got, "[############------------]") || !strings.Contains(got, " 50% ") || !strings.Contains(got, "512 B/s") {
+		t.Fatalf("unexpected progress line: %q", got)
+	}
+}
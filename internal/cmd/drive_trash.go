@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveTrashFileFields     = "id, name, mimeType, size, trashedTime, trashingUser(emailAddress), driveId"
+	defaultDriveBulkParallel = 4
+)
+
+type DriveTrashCmd struct {
+	List  DriveTrashListCmd  `cmd:"" name:"list" aliases:"ls" default:"withargs" help:"List trashed files"`
+	Empty DriveTrashEmptyCmd `cmd:"" name:"empty" help:"Permanently delete trashed files"`
+}
+
+// DriveTrashFilter selects trashed items shared by list and empty.
+type DriveTrashFilter struct {
+	Drive         string `name:"drive" help:"Shared drive ID (default: My Drive)"`
+	TrashedBefore string `name:"trashed-before" help:"Only items trashed before this time (date YYYY-MM-DD, RFC3339, or age like 30d)"`
+}
+
+func (f DriveTrashFilter) cutoff() (time.Time, error) {
+	value := strings.TrimSpace(f.TrashedBefore)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := timeparse.ParseSince(value, time.Now(), time.Local)
+	if err != nil {
+		return time.Time{}, usagef("invalid --trashed-before %q (use date YYYY-MM-DD, RFC3339, or age like 30d)", value)
+	}
+	return parsed.Time, nil
+}
+
+type DriveTrashListCmd struct {
+	Filter DriveTrashFilter `embed:""`
+	Max    int              `name:"max" aliases:"limit" help:"Max results (0 = all)" default:"100"`
+}
+
+func (c *DriveTrashListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	before, err := c.Filter.cutoff()
+	if err != nil {
+		return err
+	}
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	files, err := listDriveTrash(ctx, svc, strings.TrimSpace(c.Filter.Drive), before, c.Max)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if files == nil {
+			files = []*drive.File{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"files": files})
+	}
+	if len(files) == 0 {
+		u.Err().Println("Trash is empty")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSIZE\tTRASHED\tBY")
+	for _, f := range files {
+		by := "-"
+		if f.TrashingUser != nil && f.TrashingUser.EmailAddress != "" {
+			by = f.TrashingUser.EmailAddress
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.Id, sanitizeTab(f.Name), driveType(f.MimeType), formatDriveSize(f.Size), formatDateTime(f.TrashedTime), by)
+	}
+	return nil
+}
+
+type DriveTrashEmptyCmd struct {
+	Filter DriveTrashFilter `embed:""`
+}
+
+func (c *DriveTrashEmptyCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	before, err := c.Filter.cutoff()
+	if err != nil {
+		return err
+	}
+	driveID := strings.TrimSpace(c.Filter.Drive)
+	where := "My Drive"
+	if driveID != "" {
+		where = "shared drive " + driveID
+	}
+
+	if before.IsZero() {
+		if confirmErr := dryRunAndConfirmDestructive(ctx, flags, "drive.trash.empty", map[string]any{
+			"drive_id": driveID,
+		}, "permanently delete everything in the trash of "+where); confirmErr != nil {
+			return confirmErr
+		}
+		_, svc, err := requireDriveService(ctx, flags)
+		if err != nil {
+			return err
+		}
+		call := svc.Files.EmptyTrash().Context(ctx)
+		if driveID != "" {
+			call = call.DriveId(driveID)
+		}
+		if err := call.Do(); err != nil {
+			return err
+		}
+		return writeResult(ctx, u, kv("emptied", true), kv("drive_id", driveID))
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	files, err := listDriveTrash(ctx, svc, driveID, before, 0)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		ids = append(ids, f.Id)
+	}
+	if confirmErr := dryRunAndConfirmDestructive(ctx, flags, "drive.trash.empty", map[string]any{
+		"drive_id":       driveID,
+		"trashed_before": before.Format(time.RFC3339),
+		"file_ids":       ids,
+	}, fmt.Sprintf("permanently delete %d trashed item(s) from %s", len(ids), where)); confirmErr != nil {
+		return confirmErr
+	}
+	return runDriveBulk(ctx, u, ids, "deleted", func(ctx context.Context, id string) error {
+		return svc.Files.Delete(id).SupportsAllDrives(true).Context(ctx).Do()
+	})
+}
+
+// listDriveTrash returns trashed items. The --trashed-before cutoff is applied
+// client-side because Drive queries cannot filter on trashedTime.
+func listDriveTrash(ctx context.Context, svc *drive.Service, driveID string, before time.Time, limit int) ([]*drive.File, error) {
+	var out []*drive.File
+	pageToken := ""
+	for {
+		call := svc.Files.List().
+			Q("trashed = true").
+			PageSize(driveTreeListPerPage).
+			SupportsAllDrives(true).
+			Fields(gapi.Field("nextPageToken, files(" + driveTrashFileFields + ")")).
+			Context(ctx)
+		if driveID != "" {
+			call = call.Corpora("drive").DriveId(driveID).IncludeItemsFromAllDrives(true)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Files {
+			if f == nil || !driveTrashedBefore(f, before) {
+				continue
+			}
+			out = append(out, f)
+			if limit > 0 && len(out) >= limit {
+				return out, nil
+			}
+		}
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func driveTrashedBefore(f *drive.File, before time.Time) bool {
+	if before.IsZero() {
+		return true
+	}
+	t, err := time.Parse(time.RFC3339, f.TrashedTime)
+	if err != nil {
+		return false
+	}
+	return t.Before(before)
+}
+
+type DriveRestoreCmd struct {
+	FileIDs []string `arg:"" name:"fileId" help:"File IDs to restore from the trash"`
+}
+
+func (c *DriveRestoreCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	var ids []string
+	for _, id := range c.FileIDs {
+		if id = normalizeGoogleID(strings.TrimSpace(id)); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return usage("empty fileId")
+	}
+	if err := dryRunExit(ctx, flags, "drive.restore", map[string]any{"file_ids": ids}); err != nil {
+		return err
+	}
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	return runDriveBulk(ctx, u, ids, "restored", func(ctx context.Context, id string) error {
+		_, err := svc.Files.Update(id, &drive.File{Trashed: false, ForceSendFields: []string{"Trashed"}}).
+			SupportsAllDrives(true).
+			Fields("id, trashed").
+			Context(ctx).
+			Do()
+		return err
+	})
+}
+
+// runDriveBulk applies fn to each ID with bounded concurrency and reports
+// which IDs succeeded (under key) and which failed.
+func runDriveBulk(ctx context.Context, u *ui.UI, ids []string, key string, fn func(context.Context, string) error) error {
+	var mu sync.Mutex
+	failed := map[string]string{}
+	errs := runDriveBounded(ctx, defaultDriveBulkParallel, len(ids), func(ctx context.Context, i int) error {
+		err := fn(ctx, ids[i])
+		if err != nil {
+			mu.Lock()
+			failed[ids[i]] = err.Error()
+			mu.Unlock()
+		}
+		return err
+	})
+	done := make([]string, 0, len(ids))
+	for i, err := range errs {
+		if err == nil {
+			done = append(done, ids[i])
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{key: done, "failed": failed}); err != nil {
+			return err
+		}
+	} else {
+		for _, id := range done {
+			u.Out().Printf("%s\t%s", key, id)
+		}
+		for _, id := range ids {
+			if msg, ok := failed[id]; ok {
+				u.Err().Printf("failed\t%s\t%s", id, msg)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d item(s) failed", len(failed), len(ids))
+	}
+	return nil
+}
+
+// runQuery trashes or permanently deletes every file matching c.Query.
+func (c *DriveDeleteCmd) runQuery(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	// Parenthesise the filter so an "or" in it cannot bypass the trashed guard.
+	q := strings.TrimSpace(c.Query)
+	if !hasDriveTrashedPredicate(q) {
+		q = "(" + q + ") and trashed = false"
+	}
+	var ids []string
+	pageToken := ""
+	for {
+		call := svc.Files.List().
+			Q(q).
+			PageSize(driveTreeListPerPage).
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			Fields("nextPageToken, files(id)").
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return err
+		}
+		for _, f := range resp.Files {
+			if f != nil {
+				ids = append(ids, f.Id)
+			}
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	action, key := "trash", "trashed"
+	if c.Permanent {
+		action, key = "permanently delete", "deleted"
+	}
+	if len(ids) == 0 {
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{key: []string{}, "failed": map[string]string{}})
+		}
+		u.Err().Println("No files")
+		return nil
+	}
+	if confirmErr := dryRunAndConfirmDestructive(ctx, flags, "drive.delete", map[string]any{
+		"query":     c.Query,
+		"file_ids":  ids,
+		"permanent": c.Permanent,
+	}, fmt.Sprintf("%s %d drive file(s) matching %q", action, len(ids), c.Query)); confirmErr != nil {
+		return confirmErr
+	}
+	if c.Permanent {
+		return runDriveBulk(ctx, u, ids, key, func(ctx context.Context, id string) error {
+			return svc.Files.Delete(id).SupportsAllDrives(true).Context(ctx).Do()
+		})
+	}
+	return runDriveBulk(ctx, u, ids, key, func(ctx context.Context, id string) error {
+		_, err := svc.Files.Update(id, &drive.File{Trashed: true}).
+			SupportsAllDrives(true).
+			Fields("id, trashed").
+			Context(ctx).
+			Do()
+		return err
+	})
+}
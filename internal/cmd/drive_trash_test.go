@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+)
+
+type fakeDriveTrash struct {
+	mu       sync.Mutex
+	queries  []string
+	deleted  []string
+	patched  map[string]map[string]any
+	emptied  []string
+	trash    []map[string]any
+	matching []map[string]any
+}
+
+func (f *fakeDriveTrash) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	p := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+	switch {
+	case p == "/files" && r.Method == http.MethodGet:
+		q := r.URL.Query().Get("q")
+		f.queries = append(f.queries, q+"|"+r.URL.Query().Get("driveId"))
+		files := f.matching
+		if q == "trashed = true" {
+			files = f.trash
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"files": files})
+	case p == "/files/trash" && r.Method == http.MethodDelete:
+		f.emptied = append(f.emptied, r.URL.Query().Get("driveId"))
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(p, "/files/") && r.Method == http.MethodDelete:
+		f.deleted = append(f.deleted, strings.TrimPrefix(p, "/files/"))
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(p, "/files/") && r.Method == http.MethodPatch:
+		id := strings.TrimPrefix(p, "/files/")
+		if id == "gone" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"File not found"}}`))
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.patched[id] = body
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": id})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeDriveTrash(t *testing.T) *fakeDriveTrash {
+	t.Helper()
+	api := &fakeDriveTrash{
+		patched: map[string]map[string]any{},
+		trash: []map[string]any{
+			{"id": "old", "name": "old.txt", "trashedTime": "2026-01-01T00:00:00Z"},
+			{"id": "new", "name": "new.txt", "trashedTime": "2026-03-01T00:00:00Z"},
+		},
+		matching: []map[string]any{{"id": "t1"}, {"id": "t2"}},
+	}
+	svc, closeSrv := newDriveTestService(t, api)
+	t.Cleanup(closeSrv)
+	stubDriveServiceForTest(t, svc)
+	return api
+}
+
+func runDriveTrashJSON(t *testing.T, cmd any, flags *RootFlags, args ...string) map[string]any {
+	t.Helper()
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	out := captureStdout(t, func() {
+		if err := runKong(t, cmd, args, ctx, flags); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	})
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	return parsed
+}
+
+func TestDriveTrash_ListAndEmpty(t *testing.T) {
+	api := newFakeDriveTrash(t)
+	flags := &RootFlags{Account: "a@b.com", Force: true}
+
+	listed := runDriveTrashJSON(t, &DriveTrashCmd{}, flags, "--trashed-before", "2026-02-01")
+	if files, _ := listed["files"].([]any); len(files) != 1 || files[0].(map[string]any)["id"] != "old" {
+		t.Fatalf("unexpected trash list: %#v", listed)
+	}
+
+	runDriveTrashJSON(t, &DriveTrashCmd{}, flags, "empty", "--trashed-before", "2026-02-01", "--drive", "sd1")
+	if len(api.deleted) != 1 || api.deleted[0] != "old" || len(api.emptied) != 0 {
+		t.Fatalf("expected only the old item to be deleted: deleted=%v emptied=%v", api.deleted, api.emptied)
+	}
+	if last := api.queries[len(api.queries)-1]; last != "trashed = true|sd1" {
+		t.Fatalf("unexpected trash query: %q", last)
+	}
+
+	runDriveTrashJSON(t, &DriveTrashCmd{}, flags, "empty")
+	if len(api.emptied) != 1 || api.emptied[0] != "" {
+		t.Fatalf("expected emptyTrash call, got %v", api.emptied)
+	}
+
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	err := runKong(t, &DriveTrashCmd{}, []string{"empty"}, ctx, &RootFlags{Account: "a@b.com", NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Fatalf("expected confirmation guard, got %v", err)
+	}
+}
+
+func TestDriveRestoreCmd_UntrashesFiles(t *testing.T) {
+	api := newFakeDriveTrash(t)
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+	var result map[string]any
+	out := captureStdout(t, func() {
+		err := runKong(t, &DriveRestoreCmd{}, []string{"a1", "gone"}, ctx, &RootFlags{Account: "a@b.com"})
+		if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+			t.Fatalf("expected partial failure, got %v", err)
+		}
+	})
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	if restored, _ := result["restored"].([]any); len(restored) != 1 || restored[0] != "a1" {
+		t.Fatalf("unexpected restore result: %s", out)
+	}
+	if trashed, ok := api.patched["a1"]["trashed"]; !ok || trashed != false {
+		t.Fatalf("restore must send trashed=false explicitly: %#v", api.patched["a1"])
+	}
+}
+
+func TestDriveDeleteCmd_Query(t *testing.T) {
+	api := newFakeDriveTrash(t)
+	flags := &RootFlags{Account: "a@b.com"}
+	ctx := outfmt.WithMode(newQuietUIContext(t), outfmt.Mode{JSON: true})
+
+	out := captureStdout(t, func() {
+		err := runKong(t, &DriveDeleteCmd{}, []string{"--query", "name contains 'tmp'"}, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != 0 {
+			t.Fatalf("expected dry-run exit 0, got %v", err)
+		}
+	})
+	if !strings.Contains(out, `"t2"`) || len(api.patched) != 0 {
+		t.Fatalf("unexpected dry run: %s", out)
+	}
+	if api.queries[0] != "(name contains 'tmp') and trashed = false|" {
+		t.Fatalf("unexpected query: %q", api.queries[0])
+	}
+
+	if err := runKong(t, &DriveDeleteCmd{}, []string{"--query", "name contains 'tmp'"}, ctx, &RootFlags{Account: "a@b.com", NoInput: true}); err == nil ||
+		!strings.Contains(err.Error(), "refusing") {
+		t.Fatalf("expected confirmation guard, got %v", err)
+	}
+
+	flags.Force = true
+	result := runDriveTrashJSON(t, &DriveDeleteCmd{}, flags, "--query", "name contains 'tmp'")
+	if trashed, _ := result["trashed"].([]any); len(trashed) != 2 || api.patched["t1"]["trashed"] != true {
+		t.Fatalf("unexpected bulk trash: %#v", result)
+	}
+	result = runDriveTrashJSON(t, &DriveDeleteCmd{}, flags, "--query", "name contains 'tmp'", "--permanent")
+	if deleted, _ := result["deleted"].([]any); len(deleted) != 2 || len(api.deleted) != 2 {
+		t.Fatalf("unexpected bulk delete: %#v", result)
+	}
+
+	api.matching = nil
+	api.patched = map[string]map[string]any{}
+	result = runDriveTrashJSON(t, &DriveDeleteCmd{}, &RootFlags{Account: "a@b.com", NoInput: true}, "--query", "name = 'a' or name = 'b'")
+	if trashed, _ := result["trashed"].([]any); trashed == nil || len(trashed) != 0 || len(api.patched) != 0 {
+		t.Fatalf("expected an empty result without confirmation, got %#v", result)
+	}
+	if q := api.queries[len(api.queries)-1]; q != "(name = 'a' or name = 'b') and trashed = false|" {
+		t.Fatalf("unexpected query: %q", q)
+	}
+
+	if err := runKong(t, &DriveDeleteCmd{}, []string{"f1", "--query", "x"}, ctx, flags); err == nil {
+		t.Fatalf("expected fileId + --query to be rejected")
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveMimeShortcut    = "application/vnd.google-apps.shortcut"
+	driveTreeFileFields  = "id, name, mimeType, size, shortcutDetails(targetId)"
+	driveTreeRootFields  = "id, name, mimeType, size, driveId"
+	driveTreeListPerPage = 1000
+)
+
+// driveTreeNode is one item in a walked folder hierarchy. Folder sizes and
+// file counts cover the whole subtree.
+type driveTreeNode struct {
+	ID       string           `json:"id"`
+	Name     string           `json:"name"`
+	MimeType string           `json:"mimeType"`
+	Size     int64            `json:"size"`
+	Files    int              `json:"files,omitempty"`
+	Children []*driveTreeNode `json:"children,omitempty"`
+
+	parentID       string
+	shortcutTarget string
+	file           *drive.File
+}
+
+func (n *driveTreeNode) folder() bool { return n.MimeType == driveMimeFolder }
+
+func (n *driveTreeNode) sumSizes() {
+	for _, c := range n.Children {
+		if c.folder() {
+			c.sumSizes()
+			n.Files += c.Files
+		} else {
+			n.Files++
+		}
+		n.Size += c.Size
+	}
+}
+
+func (n *driveTreeNode) prune(depth int) {
+	if depth <= 0 {
+		n.Children = nil
+		return
+	}
+	for _, c := range n.Children {
+		c.prune(depth - 1)
+	}
+}
+
+// flatten returns the subtree in breadth-first order, so every folder comes
+// before its contents.
+func (n *driveTreeNode) flatten() []*driveTreeNode {
+	out := []*driveTreeNode{n}
+	for i := 0; i < len(out); i++ {
+		out = append(out, out[i].Children...)
+	}
+	return out
+}
+
+func getDriveTreeRoot(ctx context.Context, svc *drive.Service, folderID string) (*drive.File, error) {
+	root, err := svc.Files.Get(folderID).
+		SupportsAllDrives(true).
+		Fields(driveTreeRootFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	if root.MimeType != driveMimeFolder {
+		return nil, usagef("%s is not a folder (mimeType=%q)", folderID, root.MimeType)
+	}
+	return root, nil
+}
+
+// walkDriveTree lists a folder hierarchy breadth-first, skipping trashed items.
+func walkDriveTree(ctx context.Context, svc *drive.Service, root *drive.File) (*driveTreeNode, error) {
+	return walkDriveTreeFields(ctx, svc, root, driveTreeFileFields)
+}
+
+// walkDriveTreeFields is walkDriveTree with the per-item fields to request;
+// each node keeps the listed file for callers that need more than the tree.
+func walkDriveTreeFields(ctx context.Context, svc *drive.Service, root *drive.File, fields string) (*driveTreeNode, error) {
+	top := &driveTreeNode{ID: root.Id, Name: root.Name, MimeType: root.MimeType, file: root}
+	seen := map[string]bool{root.Id: true}
+	queue := []*driveTreeNode{top}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		pageToken := ""
+		for {
+			call := svc.Files.List().
+				Q(fmt.Sprintf("'%s' in parents and trashed = false", escapeDriveQueryString(cur.ID))).
+				PageSize(driveTreeListPerPage).
+				OrderBy("folder,name").
+				SupportsAllDrives(true).
+				IncludeItemsFromAllDrives(true).
+				Fields(gapi.Field("nextPageToken, files(" + fields + ")")).
+				Context(ctx)
+			if pageToken != "" {
+				call = call.PageToken(pageToken)
+			}
+			resp, err := call.Do()
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range resp.Files {
+				// Items with several parents show up once per parent; keep the first.
+				if f == nil || seen[f.Id] {
+					continue
+				}
+				seen[f.Id] = true
+				node := &driveTreeNode{ID: f.Id, Name: f.Name, MimeType: f.MimeType, Size: f.Size, parentID: cur.ID, file: f}
+				if f.ShortcutDetails != nil {
+					node.shortcutTarget = f.ShortcutDetails.TargetId
+				}
+				cur.Children = append(cur.Children, node)
+				if node.folder() {
+					queue = append(queue, node)
+				}
+			}
+			if resp.NextPageToken == "" {
+				break
+			}
+			pageToken = resp.NextPageToken
+		}
+	}
+	top.sumSizes()
+	return top, nil
+}
+
+type DriveTreeCmd struct {
+	FolderID string `arg:"" optional:"" name:"folderId" help:"Folder ID (default: root)"`
+	Depth    int    `name:"depth" help:"Levels to print below the folder (0 = all); sizes always cover the whole subtree"`
+}
+
+func (c *DriveTreeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Depth < 0 {
+		return usage("--depth must be >= 0")
+	}
+	folderID := normalizeGoogleID(strings.TrimSpace(c.FolderID))
+	if folderID == "" {
+		folderID = "root"
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	root, err := getDriveTreeRoot(ctx, svc, folderID)
+	if err != nil {
+		return err
+	}
+	tree, err := walkDriveTree(ctx, svc, root)
+	if err != nil {
+		return err
+	}
+	if c.Depth > 0 {
+		tree.prune(c.Depth)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"tree": tree})
+	}
+	u.Out().Printf("%s", driveTreeLabel(tree))
+	printDriveTreeChildren(u, tree, "")
+	return nil
+}
+
+func printDriveTreeChildren(u *ui.UI, n *driveTreeNode, prefix string) {
+	for i, c := range n.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		u.Out().Printf("%s%s%s", prefix, branch, driveTreeLabel(c))
+		printDriveTreeChildren(u, c, prefix+indent)
+	}
+}
+
+func driveTreeLabel(n *driveTreeNode) string {
+	if !n.folder() {
+		return fmt.Sprintf("%s  %s", n.Name, formatDriveSize(n.Size))
+	}
+	files := "files"
+	if n.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%s/  %s, %d %s", n.Name, formatDriveSize(n.Size), n.Files, files)
+}
+
+// runDriveBounded calls fn for 0..n-1 with at most limit calls in flight and
+// returns the per-index errors.
+func runDriveBounded(ctx context.Context, limit, n int, fn func(context.Context, int) error) []error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, max(limit, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+			errs[idx] = fn(ctx, idx)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
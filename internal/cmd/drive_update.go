@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var driveFolderColorPattern = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+type DriveUpdateCmd struct {
+	FileID      string   `arg:"" name:"fileId" help:"File ID"`
+	Starred     *bool    `name:"starred" negatable:"" help:"Star the file (--no-starred to unstar)"`
+	Description *string  `name:"description" help:"Set the description (use --description '' to clear)"`
+	FolderColor string   `name:"folder-color" help:"Folder color as #rrggbb (folders only)"`
+	Properties  []string `name:"properties" aliases:"property" help:"Set appProperties as key=value; key= removes the key (repeatable)" sep:"none"`
+}
+
+func (c *DriveUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	if fileID == "" {
+		return usage("empty fileId")
+	}
+	color, err := normalizeDriveFolderColor(c.FolderColor)
+	if err != nil {
+		return err
+	}
+
+	patch := &drive.File{FolderColorRgb: color}
+	if c.Starred != nil {
+		patch.Starred = *c.Starred
+		patch.ForceSendFields = append(patch.ForceSendFields, "Starred")
+	}
+	if c.Description != nil {
+		patch.Description = *c.Description
+		patch.ForceSendFields = append(patch.ForceSendFields, "Description")
+	}
+	for _, raw := range c.Properties {
+		key, value, ok := strings.Cut(raw, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return usagef("invalid --properties %q (want key=value)", raw)
+		}
+		if value == "" {
+			// Drive deletes an appProperty when its value is sent as null.
+			patch.NullFields = append(patch.NullFields, "AppProperties."+key)
+			continue
+		}
+		if patch.AppProperties == nil {
+			patch.AppProperties = map[string]string{}
+		}
+		patch.AppProperties[key] = value
+	}
+	if c.Starred == nil && c.Description == nil && color == "" && len(c.Properties) == 0 {
+		return usage("nothing to update (use --starred, --description, --folder-color, or --properties)")
+	}
+
+	_, svc, err := requireDriveService(ctx, flags)
+	if err != nil {
+		return err
+	}
+
+	updated, err := svc.Files.Update(fileID, patch).
+		SupportsAllDrives(true).
+		Fields("id, name, mimeType, starred, description, folderColorRgb, appProperties").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{strFile: updated})
+	}
+
+	u.Out().Printf("id\t%s", updated.Id)
+	u.Out().Printf("name\t%s", updated.Name)
+	u.Out().Printf("starred\t%t", updated.Starred)
+	if updated.Description != "" {
+		u.Out().Printf("description\t%s", updated.Description)
+	}
+	if updated.FolderColorRgb != "" {
+		u.Out().Printf("color\t%s", updated.FolderColorRgb)
+	}
+	printDriveAppProperties(u, updated.AppProperties)
+	return nil
+}
+
+// normalizeDriveFolderColor accepts "#rrggbb" or "rrggbb" and returns the
+// lowercase "#rrggbb" form Drive expects; empty input stays empty.
+func normalizeDriveFolderColor(raw string) (string, error) {
+	color := strings.TrimSpace(raw)
+	if color == "" {
+		return "", nil
+	}
+	if !driveFolderColorPattern.MatchString(color) {
+		return "", usagef("invalid --folder-color %q (want #rrggbb)", raw)
+	}
+	return "#" + strings.ToLower(strings.TrimPrefix(color, "#")), nil
+}
+
+func printDriveAppProperties(u *ui.UI, props map[string]string) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		u.Out().Printf("property\t%s=%s", k, props[k])
+	}
+}
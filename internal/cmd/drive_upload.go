@@ -27,31 +27,85 @@ type driveUploadOptions struct {
 	isExplicitName      bool
 	keepRevisionForever bool
 	convert             bool
+	chunkSize           int64
 }
 
+const driveUploadResultFields = "id, name, mimeType, size, webViewLink"
+
 func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
 	opts, err := prepareDriveUpload(c)
 	if err != nil {
 		return err
 	}
+	transfer, err := c.Transfer.options()
+	if err != nil {
+		return err
+	}
+	opts.chunkSize = transfer.chunkSize
 
 	media, err := openDriveUploadMedia(opts, c.KeepFrontmatter)
 	if err != nil {
 		return err
 	}
 	defer media.Close()
+	size, err := driveUploadMediaSize(media)
+	if err != nil {
+		return err
+	}
 
-	_, svc, err := requireDriveService(ctx, flags)
+	account, svc, err := requireDriveService(ctx, flags)
 	if err != nil {
 		return err
 	}
 
+	if c.Resume {
+		client, clientErr := newDriveHTTPClient(ctx, account)
+		if clientErr != nil {
+			return clientErr
+		}
+		return runDriveResumableUpload(ctx, svc, client, account, media, size, opts, transfer)
+	}
+
+	progress := newTransferProgress(transfer.progress, driveUploadLabel(opts), size)
+	reader := wrapTransferReader(ctx, media, transfer, progress)
+	defer progress.finish()
 	if opts.replaceFileID == "" {
-		return runDriveCreateUpload(ctx, svc, media, opts)
+		return runDriveCreateUpload(ctx, svc, reader, opts)
+	}
+	return runDriveReplaceUpload(ctx, svc, reader, opts)
+}
+
+func driveUploadLabel(opts driveUploadOptions) string {
+	if opts.fileName != "" {
+		return opts.fileName
+	}
+	return filepath.Base(opts.localPath)
+}
+
+// driveUploadMediaSize reports the length of a media reader returned by
+// openDriveUploadMedia.
+func driveUploadMediaSize(media io.ReadCloser) (int64, error) {
+	switch m := media.(type) {
+	case *os.File:
+		st, err := m.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return st.Size(), nil
+	case driveUploadBytes:
+		return m.Size(), nil
 	}
-	return runDriveReplaceUpload(ctx, svc, media, opts)
+	return -1, nil
+}
+
+// driveUploadBytes is in-memory media (e.g. Markdown with frontmatter
+// stripped) that still supports ReadAt for resumable uploads.
+type driveUploadBytes struct {
+	*bytes.Reader
 }
 
+func (driveUploadBytes) Close() error { return nil }
+
 func prepareDriveUpload(c *DriveUploadCmd) (driveUploadOptions, error) {
 	localPath := strings.TrimSpace(c.LocalPath)
 	if localPath == "" {
@@ -116,10 +170,18 @@ func openDriveUploadMedia(opts driveUploadOptions, keepFrontmatter bool) (io.Rea
 	if closeErr != nil {
 		return nil, closeErr
 	}
-	return io.NopCloser(bytes.NewReader(stripYAMLFrontmatter(data))), nil
+	return driveUploadBytes{bytes.NewReader(stripYAMLFrontmatter(data))}, nil
 }
 
 func runDriveCreateUpload(ctx context.Context, svc *drive.Service, file io.Reader, opts driveUploadOptions) error {
+	created, err := createDriveUpload(ctx, svc, file, opts)
+	if err != nil {
+		return err
+	}
+	return writeDriveUploadResult(ctx, created, false, "")
+}
+
+func driveCreateUploadMetadata(opts driveUploadOptions) *drive.File {
 	meta := &drive.File{Name: opts.fileName}
 	if opts.parent != "" {
 		meta.Parents = []string{opts.parent}
@@ -130,25 +192,48 @@ func runDriveCreateUpload(ctx context.Context, svc *drive.Service, file io.Reade
 			meta.Name = stripOfficeExt(meta.Name)
 		}
 	}
+	return meta
+}
+
+func driveUploadMediaOptions(opts driveUploadOptions) []gapi.MediaOption {
+	mediaOpts := []gapi.MediaOption{gapi.ContentType(opts.mimeType)}
+	if opts.chunkSize > 0 {
+		mediaOpts = append(mediaOpts, gapi.ChunkSize(int(opts.chunkSize)))
+	}
+	return mediaOpts
+}
 
-	call := svc.Files.Create(meta).
+func createDriveUpload(ctx context.Context, svc *drive.Service, file io.Reader, opts driveUploadOptions) (*drive.File, error) {
+	call := svc.Files.Create(driveCreateUploadMetadata(opts)).
 		SupportsAllDrives(true).
-		Media(file, gapi.ContentType(opts.mimeType)).
-		Fields("id, name, mimeType, size, webViewLink").
+		Media(file, driveUploadMediaOptions(opts)...).
+		Fields(driveUploadResultFields).
 		Context(ctx)
 	if opts.keepRevisionForever {
 		call = call.KeepRevisionForever(true)
 	}
 
-	created, err := call.Do()
-	if err != nil {
-		return err
+	return call.Do()
+}
+
+// uploadDriveFile creates name under parent with the same metadata and media
+// options as drive upload. Callers that upload on the user's behalf (e.g.
+// saved attachments) go through here instead of building their own request.
+func uploadDriveFile(ctx context.Context, svc *drive.Service, media io.Reader, parent, name, mimeType string) (*drive.File, error) {
+	opts := driveUploadOptions{
+		fileName:       name,
+		parent:         parent,
+		mimeType:       strings.TrimSpace(mimeType),
+		isExplicitName: true,
 	}
-	return writeDriveUploadResult(ctx, created, false, "")
+	if opts.mimeType == "" {
+		opts.mimeType = guessMimeType(name)
+	}
+	return createDriveUpload(ctx, svc, media, opts)
 }
 
-func runDriveReplaceUpload(ctx context.Context, svc *drive.Service, file io.Reader, opts driveUploadOptions) error {
-	existing, err := svc.Files.Get(opts.replaceFileID).
+func checkDriveReplaceTarget(ctx context.Context, svc *drive.Service, fileID string) error {
+	existing, err := svc.Files.Get(fileID).
 		SupportsAllDrives(true).
 		Fields("id, mimeType").
 		Context(ctx).
@@ -159,16 +244,26 @@ func runDriveReplaceUpload(ctx context.Context, svc *drive.Service, file io.Read
 	if strings.HasPrefix(existing.MimeType, "application/vnd.google-apps.") {
 		return fmt.Errorf("cannot replace content for Google Workspace files (mimeType=%s)", existing.MimeType)
 	}
+	return nil
+}
 
+func driveReplaceUploadMetadata(opts driveUploadOptions) *drive.File {
 	meta := &drive.File{}
 	if opts.fileName != "" {
 		meta.Name = opts.fileName
 	}
+	return meta
+}
+
+func runDriveReplaceUpload(ctx context.Context, svc *drive.Service, file io.Reader, opts driveUploadOptions) error {
+	if err := checkDriveReplaceTarget(ctx, svc, opts.replaceFileID); err != nil {
+		return err
+	}
 
-	call := svc.Files.Update(opts.replaceFileID, meta).
+	call := svc.Files.Update(opts.replaceFileID, driveReplaceUploadMetadata(opts)).
 		SupportsAllDrives(true).
-		Media(file, gapi.ContentType(opts.mimeType)).
-		Fields("id, name, mimeType, size, webViewLink").
+		Media(file, driveUploadMediaOptions(opts)...).
+		Fields(driveUploadResultFields).
 		Context(ctx)
 	if opts.keepRevisionForever {
 		call = call.KeepRevisionForever(true)
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+)
+
+// Google keeps resumable sessions for a week; start over a little earlier.
+const driveUploadSessionTTL = 6 * 24 * time.Hour
+
+var errDriveUploadSessionGone = errors.New("upload session expired")
+
+type driveUploadSession struct {
+	SessionURI  string `json:"sessionUri"`
+	LocalPath   string `json:"localPath"`
+	Target      string `json:"target"`
+	Size        int64  `json:"size"`
+	ModTimeNs   int64  `json:"modTimeNs"`
+	CreatedAtMs int64  `json:"createdAtMs"`
+}
+
+func driveUploadTarget(opts driveUploadOptions) string {
+	if opts.replaceFileID != "" {
+		return "replace:" + opts.replaceFileID
+	}
+	return "create:" + opts.parent + "/" + opts.fileName + "/" + opts.convertMimeType
+}
+
+func driveUploadSessionPath(account string, opts driveUploadOptions) (string, error) {
+	dir, err := config.EnsureDriveUploadSessionsDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(opts.localPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(account + "\n" + abs + "\n" + driveUploadTarget(opts)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func loadDriveUploadSession(path string) (*driveUploadSession, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path built from config dir
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil //nolint:nilnil // no saved session
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sess driveUploadSession
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("read upload session %s: %w", path, err)
+	}
+	return &sess, nil
+}
+
+func saveDriveUploadSession(path string, sess *driveUploadSession) error {
+	payload, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(payload, '\n'), 0o600)
+}
+
+// runDriveResumableUpload uploads media in chunks through a resumable session
+// whose URI is saved on disk, so an interrupted upload continues from the last
+// committed byte on the next run instead of starting over.
+func runDriveResumableUpload(ctx context.Context, svc *drive.Service, client *http.Client, account string, media io.ReadCloser, size int64, opts driveUploadOptions, transfer driveTransferOptions) error {
+	readerAt, ok := media.(io.ReaderAt)
+	if !ok || size < 0 {
+		return errors.New("--resume needs a regular file")
+	}
+	if opts.replaceFileID != "" {
+		if err := checkDriveReplaceTarget(ctx, svc, opts.replaceFileID); err != nil {
+			return err
+		}
+	}
+	info, err := os.Stat(opts.localPath)
+	if err != nil {
+		return err
+	}
+	sessionPath, err := driveUploadSessionPath(account, opts)
+	if err != nil {
+		return err
+	}
+
+	var (
+		sessionURI string
+		offset     int64
+	)
+	saved, err := loadDriveUploadSession(sessionPath)
+	if err != nil {
+		return err
+	}
+	if saved != nil && saved.Size == size && saved.ModTimeNs == info.ModTime().UnixNano() &&
+		time.Since(time.UnixMilli(saved.CreatedAtMs)) < driveUploadSessionTTL {
+		file, next, queryErr := queryDriveUploadSession(ctx, client, saved.SessionURI, size)
+		switch {
+		case queryErr == nil && file != nil:
+			_ = os.Remove(sessionPath)
+			return writeDriveUploadResult(ctx, file, opts.replaceFileID != "", opts.replaceFileID)
+		case queryErr == nil:
+			sessionURI, offset = saved.SessionURI, next
+		case !errors.Is(queryErr, errDriveUploadSessionGone):
+			return queryErr
+		}
+	}
+	if sessionURI == "" {
+		sessionURI, err = startDriveUploadSession(ctx, client, svc.BasePath, opts, size)
+		if err != nil {
+			return err
+		}
+		if err := saveDriveUploadSession(sessionPath, &driveUploadSession{
+			SessionURI:  sessionURI,
+			LocalPath:   opts.localPath,
+			Target:      driveUploadTarget(opts),
+			Size:        size,
+			ModTimeNs:   info.ModTime().UnixNano(),
+			CreatedAtMs: time.Now().UnixMilli(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	progress := newTransferProgress(transfer.progress, driveUploadLabel(opts), size)
+	progress.set(offset)
+	for {
+		n := min(transfer.chunkSize, size-offset)
+		file, next, err := putDriveUploadChunk(ctx, client, sessionURI, readerAt, offset, n, size, transfer, progress)
+		if err != nil {
+			progress.finish()
+			return fmt.Errorf("%w (rerun with --resume to continue from %s)", err, formatDriveSize(offset))
+		}
+		if file != nil {
+			progress.finish()
+			_ = os.Remove(sessionPath)
+			return writeDriveUploadResult(ctx, file, opts.replaceFileID != "", opts.replaceFileID)
+		}
+		if next <= offset && n > 0 {
+			progress.finish()
+			return fmt.Errorf("upload made no progress at %d bytes", offset)
+		}
+		offset = next
+	}
+}
+
+func startDriveUploadSession(ctx context.Context, client *http.Client, basePath string, opts driveUploadOptions, size int64) (string, error) {
+	method := http.MethodPost
+	endpoint := gapi.ResolveRelative(basePath, "/upload/drive/v3/files")
+	meta := driveCreateUploadMetadata(opts)
+	if opts.replaceFileID != "" {
+		method = http.MethodPatch
+		endpoint += "/" + url.PathEscape(opts.replaceFileID)
+		meta = driveReplaceUploadMetadata(opts)
+	}
+	q := url.Values{}
+	q.Set("uploadType", "resumable")
+	q.Set("supportsAllDrives", "true")
+	q.Set("fields", driveUploadResultFields)
+	if opts.keepRevisionForever {
+		q.Set("keepRevisionForever", "true")
+	}
+
+	body, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+"?"+q.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", opts.mimeType)
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := gapi.CheckResponse(resp); err != nil {
+		return "", err
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", errors.New("resumable upload: missing session location")
+	}
+	return location, nil
+}
+
+// queryDriveUploadSession asks how many bytes a session has committed.
+func queryDriveUploadSession(ctx context.Context, client *http.Client, sessionURI string, size int64) (*drive.File, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURI, http.NoBody)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, 0, errDriveUploadSessionGone
+	}
+	return parseDriveUploadResponse(resp)
+}
+
+func putDriveUploadChunk(ctx context.Context, client *http.Client, sessionURI string, media io.ReaderAt, offset, n, size int64, transfer driveTransferOptions, progress *transferProgress) (*drive.File, int64, error) {
+	chunk := &chunkReader{
+		SectionReader: io.NewSectionReader(media, offset, n),
+		ctx:           ctx,
+		base:          offset,
+		limiter:       transfer.limiter,
+		progress:      progress,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURI, http.NoBody)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n > 0 {
+		req.Body = io.NopCloser(chunk)
+		req.GetBody = func() (io.ReadCloser, error) {
+			if _, err := chunk.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(chunk), nil
+		}
+		req.ContentLength = n
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+n-1, size))
+	} else {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	return parseDriveUploadResponse(resp)
+}
+
+// parseDriveUploadResponse returns the file when the upload is complete, or
+// the next offset from a 308 Range header.
+func parseDriveUploadResponse(resp *http.Response) (*drive.File, int64, error) {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		var file drive.File
+		if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+			return nil, 0, fmt.Errorf("decode upload response: %w", err)
+		}
+		return &file, 0, nil
+	case http.StatusPermanentRedirect:
+		rng := resp.Header.Get("Range")
+		if rng == "" {
+			return nil, 0, nil
+		}
+		_, end, ok := strings.Cut(strings.TrimPrefix(rng, "bytes="), "-")
+		last, err := strconv.ParseInt(end, 10, 64)
+		if !ok || err != nil {
+			return nil, 0, fmt.Errorf("unexpected Range header %q", rng)
+		}
+		return nil, last + 1, nil
+	}
+	if err := gapi.CheckResponse(resp); err != nil {
+		return nil, 0, err
+	}
+	return nil, 0, fmt.Errorf("unexpected upload status %s", resp.Status)
+}
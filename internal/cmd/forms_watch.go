@@ -17,6 +17,7 @@ type FormsWatchCmd struct {
 	List   FormsWatchListCmd   `cmd:"" name:"list" aliases:"ls" help:"List active watches"`
 	Delete FormsWatchDeleteCmd `cmd:"" name:"delete" aliases:"rm,remove" help:"Delete a watch"`
 	Renew  FormsWatchRenewCmd  `cmd:"" name:"renew" aliases:"refresh" help:"Renew a watch (extends 7 days)"`
+	Serve  FormsWatchServeCmd  `cmd:"" name:"serve" help:"Receive Pub/Sub pushes and deliver new responses"`
 }
 
 // FormsWatchCreateCmd creates a push notification watch on form responses.
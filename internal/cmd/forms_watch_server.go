@@ -0,0 +1,631 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	formsapi "google.golang.org/api/forms/v1"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/steipete/gogcli/internal/authclient"
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var errNoNewResponses = errors.New("no new responses")
+
+const formsWatchEventResponses = "RESPONSES"
+
+type formsWatchState struct {
+	Account           string   `json:"account"`
+	FormID            string   `json:"formId"`
+	LastSubmittedTime string   `json:"lastSubmittedTime,omitempty"`
+	LastResponseIDs   []string `json:"lastResponseIds,omitempty"`
+	LastPushMessageID string   `json:"lastPushMessageId,omitempty"`
+	UpdatedAtMs       int64    `json:"updatedAtMs,omitempty"`
+}
+
+type formsWatchStore = jsonStateStore[formsWatchState]
+
+func formsWatchStatePath(account, formID string) (string, error) {
+	dir, err := config.EnsureFormsWatchDir()
+	if err != nil {
+		return "", err
+	}
+	name := sanitizeAccountForPath(account) + "--" + sanitizeAccountForPath(formID)
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// openFormsWatchStore loads the response cursor for one form, or starts an
+// empty one.
+func openFormsWatchStore(account, formID string) (*formsWatchStore, error) {
+	path, err := formsWatchStatePath(account, formID)
+	if err != nil {
+		return nil, err
+	}
+	return openJSONStateStore(path, formsWatchState{Account: account, FormID: formID}, func(st *formsWatchState, ms int64) {
+		st.UpdatedAtMs = ms
+	})
+}
+
+// formsResponseRecord is one response flattened to question title -> answer.
+type formsResponseRecord struct {
+	ResponseID      string            `json:"responseId"`
+	SubmittedAt     string            `json:"submittedAt"`
+	RespondentEmail string            `json:"respondentEmail,omitempty"`
+	Answers         map[string]string `json:"answers"`
+	// Same answers keyed by question ID, used for Sheet columns.
+	answersByID map[string]string
+}
+
+type formsHookPayload struct {
+	Source    string                `json:"source"`
+	Account   string                `json:"account"`
+	FormID    string                `json:"formId"`
+	FormTitle string                `json:"formTitle,omitempty"`
+	Responses []formsResponseRecord `json:"responses"`
+	// Question IDs in form order and their titles, used for Sheet columns.
+	questionIDs []string
+	titles      map[string]string
+}
+
+type formsWatchServer struct {
+	account    string
+	formID     string
+	path       string
+	auth       pushAuthConfig
+	validator  *idtoken.Validator
+	store      *formsWatchStore
+	newService func(context.Context, string) (*formsapi.Service, error)
+	sheetsSvc  func(context.Context, string) (*sheets.Service, error)
+	sheetID    string
+	sheetRange string
+	sinks      watchSinks
+	logf       func(string, ...any)
+	warnf      func(string, ...any)
+
+	// syncMu serializes response syncs and guards the Sheet column state.
+	syncMu       sync.Mutex
+	sheetHeader  bool
+	sheetOrigin  a1Range
+	sheetColumns []string
+}
+
+func (s *formsWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !pathMatches(s.path, r.URL.Path) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorizePush(r, s.auth, s.validator, s.warnf) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	push, err := readPubSubEnvelope(r)
+	if err != nil {
+		s.warnf("watch: invalid push payload: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	attrs := push.Message.Attributes
+	if formID := attrs["formId"]; formID != "" && formID != s.formID {
+		s.warnf("watch: ignoring push for form %s", formID)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if eventType := attrs["eventType"]; eventType != "" && eventType != formsWatchEventResponses {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if err := s.syncResponses(r.Context(), strings.TrimSpace(push.Message.MessageID)); err != nil {
+		if errors.Is(err, errNoNewResponses) {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		s.warnf("watch: sync responses failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// syncResponses delivers responses newer than the stored cursor. The cursor
+// only advances once delivery succeeded, so a failed push is retried by
+// Pub/Sub instead of losing responses.
+func (s *formsWatchServer) syncResponses(ctx context.Context, pushMessageID string) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	payload, err := s.fetchNewResponses(ctx, pushMessageID)
+	if err != nil {
+		return err
+	}
+	if err := s.deliver(ctx, payload); err != nil {
+		return err
+	}
+	return s.store.Update(func(st *formsWatchState) error {
+		st.LastPushMessageID = pushMessageID
+		last := payload.Responses[len(payload.Responses)-1].SubmittedAt
+		if last != st.LastSubmittedTime {
+			st.LastSubmittedTime = last
+			st.LastResponseIDs = nil
+		}
+		for _, record := range payload.Responses {
+			if record.SubmittedAt == last {
+				st.LastResponseIDs = append(st.LastResponseIDs, record.ResponseID)
+			}
+		}
+		return nil
+	})
+}
+
+// fetchNewResponses lists responses submitted at or after the stored cursor,
+// skipping the ones already delivered at that exact timestamp. Callers hold
+// syncMu and advance the cursor after delivery.
+func (s *formsWatchServer) fetchNewResponses(ctx context.Context, pushMessageID string) (*formsHookPayload, error) {
+	state := s.store.Get()
+	if pushMessageID != "" && state.LastPushMessageID == pushMessageID {
+		s.logf("watch: ignoring duplicate push %s", pushMessageID)
+		return nil, errNoNewResponses
+	}
+
+	svc, err := s.newService(ctx, s.account)
+	if err != nil {
+		return nil, err
+	}
+	form, err := svc.Forms.Get(s.formID).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	responses, err := listFormResponsesSince(ctx, svc, s.formID, state.LastSubmittedTime)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := stringSet(state.LastResponseIDs)
+	cursor, _ := time.Parse(time.RFC3339Nano, state.LastSubmittedTime)
+	var fresh []*formsapi.FormResponse
+	for _, resp := range responses {
+		if _, ok := seen[resp.ResponseId]; ok && formResponseTime(resp) == state.LastSubmittedTime {
+			continue
+		}
+		if formResponseTimeValue(resp).Before(cursor) {
+			continue
+		}
+		fresh = append(fresh, resp)
+	}
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return formResponseTimeValue(fresh[i]).Before(formResponseTimeValue(fresh[j]))
+	})
+
+	if len(fresh) == 0 {
+		if err := s.store.Update(func(st *formsWatchState) error {
+			st.LastPushMessageID = pushMessageID
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return nil, errNoNewResponses
+	}
+
+	titles, questionIDs := formQuestionTitles(form)
+	payload := &formsHookPayload{
+		Source:      "forms",
+		Account:     s.account,
+		FormID:      s.formID,
+		Responses:   make([]formsResponseRecord, 0, len(fresh)),
+		questionIDs: questionIDs,
+		titles:      titles,
+	}
+	if form.Info != nil {
+		payload.FormTitle = form.Info.Title
+	}
+	for _, resp := range fresh {
+		payload.Responses = append(payload.Responses, flattenFormResponse(resp, titles))
+	}
+	return payload, nil
+}
+
+func listFormResponsesSince(ctx context.Context, svc *formsapi.Service, formID, since string) ([]*formsapi.FormResponse, error) {
+	var (
+		out       []*formsapi.FormResponse
+		pageToken string
+	)
+	for {
+		call := svc.Forms.Responses.List(formID).PageSize(500).Context(ctx)
+		if since != "" {
+			call = call.Filter("timestamp >= " + since)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range resp.Responses {
+			if item != nil {
+				out = append(out, item)
+			}
+		}
+		if resp.NextPageToken == "" {
+			return out, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func formResponseTime(resp *formsapi.FormResponse) string {
+	return firstFormTime(resp.LastSubmittedTime, resp.CreateTime)
+}
+
+func formResponseTimeValue(resp *formsapi.FormResponse) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, formResponseTime(resp))
+	return t
+}
+
+// formQuestionTitles maps question IDs to display titles and returns the
+// IDs in form order. Grid rows become "Group [Row]".
+func formQuestionTitles(form *formsapi.Form) (map[string]string, []string) {
+	titles := map[string]string{}
+	var order []string
+	add := func(id, title string) {
+		if id == "" {
+			return
+		}
+		title = strings.TrimSpace(title)
+		if title == "" {
+			title = id
+		}
+		titles[id] = title
+		order = append(order, id)
+	}
+	if form == nil {
+		return titles, order
+	}
+	for _, item := range form.Items {
+		if item == nil {
+			continue
+		}
+		switch {
+		case item.QuestionItem != nil && item.QuestionItem.Question != nil:
+			add(item.QuestionItem.Question.QuestionId, item.Title)
+		case item.QuestionGroupItem != nil:
+			for _, q := range item.QuestionGroupItem.Questions {
+				if q == nil {
+					continue
+				}
+				row := ""
+				if q.RowQuestion != nil {
+					row = q.RowQuestion.Title
+				}
+				add(q.QuestionId, fmt.Sprintf("%s [%s]", item.Title, row))
+			}
+		}
+	}
+	return titles, order
+}
+
+func flattenFormResponse(resp *formsapi.FormResponse, titles map[string]string) formsResponseRecord {
+	record := formsResponseRecord{
+		ResponseID:      resp.ResponseId,
+		SubmittedAt:     formResponseTime(resp),
+		RespondentEmail: resp.RespondentEmail,
+		Answers:         make(map[string]string, len(resp.Answers)),
+		answersByID:     make(map[string]string, len(resp.Answers)),
+	}
+	for id, answer := range resp.Answers {
+		title := titles[id]
+		if title == "" {
+			title = id
+		}
+		var values []string
+		if answer.TextAnswers != nil {
+			for _, a := range answer.TextAnswers.Answers {
+				if a != nil {
+					values = append(values, a.Value)
+				}
+			}
+		}
+		if answer.FileUploadAnswers != nil {
+			for _, a := range answer.FileUploadAnswers.Answers {
+				if a != nil {
+					values = append(values, a.FileName+" (https://drive.google.com/open?id="+a.FileId+")")
+				}
+			}
+		}
+		record.Answers[title] = strings.Join(values, ", ")
+		record.answersByID[id] = record.Answers[title]
+	}
+	return record
+}
+
+// deliver hands payload to the sinks and the Sheet and returns the first
+// error so the caller keeps the cursor.
+func (s *formsWatchServer) deliver(ctx context.Context, payload *formsHookPayload) error {
+	err := s.sinks.deliver(ctx, s.warnf, payload,
+		"GOG_WATCH_SOURCE=forms",
+		"GOG_WATCH_ACCOUNT="+payload.Account,
+		"GOG_WATCH_FORM_ID="+payload.FormID,
+	)
+	if s.sheetID == "" {
+		return err
+	}
+	if sheetErr := s.appendToSheet(ctx, payload); sheetErr != nil {
+		s.warnf("watch: sheet append failed: %v", sheetErr)
+		if err == nil {
+			err = sheetErr
+		}
+	}
+	return err
+}
+
+// appendToSheet appends one row per response: submitted time, email,
+// response ID, then one column per question ID. The header row is read once
+// and extended in place when new questions show up. Callers hold syncMu.
+func (s *formsWatchServer) appendToSheet(ctx context.Context, payload *formsHookPayload) error {
+	svc, err := s.sheetsSvc(ctx, s.account)
+	if err != nil {
+		return err
+	}
+
+	if !s.sheetHeader {
+		existing, getErr := svc.Spreadsheets.Values.Get(s.sheetID, s.sheetRange).Context(ctx).Do()
+		if getErr != nil {
+			return getErr
+		}
+		origin, parseErr := parseA1Range(existing.Range)
+		if parseErr != nil {
+			return fmt.Errorf("sheet range %q: %w", existing.Range, parseErr)
+		}
+		s.sheetOrigin = origin
+		s.sheetColumns = nil
+		if len(existing.Values) > 0 {
+			s.sheetColumns = formsSheetColumns(existing.Values[0], payload.titles)
+		}
+		s.sheetHeader = true
+	}
+
+	known := make(map[string]struct{}, len(s.sheetColumns))
+	for _, id := range s.sheetColumns {
+		known[id] = struct{}{}
+	}
+	var added []string
+	add := func(id string) {
+		if _, ok := known[id]; !ok {
+			known[id] = struct{}{}
+			added = append(added, id)
+		}
+	}
+	for _, id := range payload.questionIDs {
+		add(id)
+	}
+	var extra []string
+	for _, record := range payload.Responses {
+		for id := range record.answersByID {
+			if _, ok := known[id]; !ok {
+				extra = append(extra, id)
+			}
+		}
+	}
+	sort.Strings(extra)
+	for _, id := range extra {
+		add(id)
+	}
+
+	var rows [][]interface{}
+	if len(added) > 0 {
+		title := func(id string) interface{} { return firstNonEmpty(payload.titles[id], id) }
+		if s.sheetColumns == nil {
+			header := []interface{}{"Submitted", "Email", "Response ID"}
+			for _, id := range added {
+				header = append(header, title(id))
+			}
+			rows = append(rows, header)
+		} else {
+			cells := make([]interface{}, 0, len(added))
+			for _, id := range added {
+				cells = append(cells, title(id))
+			}
+			start := formatA1Cell(s.sheetOrigin.SheetName, s.sheetOrigin.StartRow, s.sheetOrigin.StartCol+3+len(s.sheetColumns))
+			if _, updateErr := svc.Spreadsheets.Values.Update(s.sheetID, start, &sheets.ValueRange{Values: [][]interface{}{cells}}).
+				ValueInputOption("RAW").
+				Context(ctx).
+				Do(); updateErr != nil {
+				return updateErr
+			}
+		}
+		s.sheetColumns = append(s.sheetColumns, added...)
+	}
+
+	for _, record := range payload.Responses {
+		row := []interface{}{record.SubmittedAt, record.RespondentEmail, record.ResponseID}
+		for _, id := range s.sheetColumns {
+			row = append(row, record.answersByID[id])
+		}
+		rows = append(rows, row)
+	}
+	_, err = svc.Spreadsheets.Values.Append(s.sheetID, s.sheetRange, &sheets.ValueRange{Values: rows}).
+		ValueInputOption("RAW").
+		InsertDataOption("INSERT_ROWS").
+		Context(ctx).
+		Do()
+	if err != nil {
+		// Re-read the header next time; it may not have been written.
+		s.sheetHeader = false
+	}
+	return err
+}
+
+// formsSheetColumns maps an existing header row back to question IDs.
+// Titles that no longer match a question keep their own text, which is also
+// how answers to deleted questions are labelled.
+func formsSheetColumns(header []interface{}, titles map[string]string) []string {
+	byTitle := make(map[string]string, len(titles))
+	for id, title := range titles {
+		if _, ok := byTitle[title]; !ok {
+			byTitle[title] = id
+		}
+	}
+	columns := []string{}
+	for i := 3; i < len(header); i++ {
+		title := fmt.Sprint(header[i])
+		if id, ok := byTitle[title]; ok {
+			columns = append(columns, id)
+		} else {
+			columns = append(columns, title)
+		}
+	}
+	return columns
+}
+
+type FormsWatchServeCmd struct {
+	FormID       string         `arg:"" name:"formId" help:"Form ID"`
+	Bind         string         `name:"bind" help:"Bind address" default:"127.0.0.1"`
+	Port         int            `name:"port" help:"Listen port" default:"8790"`
+	Path         string         `name:"path" help:"Push handler path" default:"/forms-pubsub"`
+	VerifyOIDC   bool           `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
+	OIDCEmail    string         `name:"oidc-email" help:"Expected service account email"`
+	OIDCAudience string         `name:"oidc-audience" help:"Expected OIDC audience"`
+	SharedToken  string         `name:"token" help:"Shared token for x-gog-token or ?token="`
+	Since        string         `name:"since" help:"Deliver responses submitted since this time on the first push (default: now)"`
+	HookURL      string         `name:"hook-url" help:"Webhook URL to forward responses"`
+	HookToken    string         `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret   string         `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature header)"`
+	Sheet        string         `name:"sheet" help:"Spreadsheet ID to append responses to"`
+	SheetRange   string         `name:"sheet-range" help:"Range to append to (A1 notation or named range)" default:"Sheet1"`
+	Sinks        WatchSinkFlags `embed:""`
+}
+
+func (c *FormsWatchServeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	formID := strings.TrimSpace(normalizeGoogleID(c.FormID))
+	if formID == "" {
+		return usage("empty formId")
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return usage("--path must start with '/'")
+	}
+	if c.Port <= 0 {
+		return usage("--port must be > 0")
+	}
+	if !c.VerifyOIDC && c.SharedToken == "" && !isLoopbackHost(c.Bind) {
+		return usage("--verify-oidc or --token required when binding non-loopback")
+	}
+	if c.OIDCEmail != "" && !c.VerifyOIDC {
+		return usage("--oidc-email requires --verify-oidc")
+	}
+	if c.OIDCAudience != "" && !c.VerifyOIDC {
+		return usage("--oidc-audience requires --verify-oidc")
+	}
+	if c.HookSecret != "" && strings.TrimSpace(c.HookURL) == "" {
+		return usage("--hook-secret requires --hook-url")
+	}
+	sheetID := strings.TrimSpace(normalizeGoogleID(c.Sheet))
+	sheetRange := cleanRange(c.SheetRange)
+	if sheetID != "" && strings.TrimSpace(sheetRange) == "" {
+		return usage("empty --sheet-range")
+	}
+	since := time.Now()
+	if strings.TrimSpace(c.Since) != "" {
+		since, err = parseTimeExpr(c.Since, time.Now(), time.Local)
+		if err != nil {
+			return usagef("invalid --since: %v", err)
+		}
+	}
+
+	sinks, err := c.Sinks.build()
+	if err != nil {
+		return err
+	}
+	if hookURL := strings.TrimSpace(c.HookURL); hookURL != "" {
+		sinks = append(sinks, newWatchHookSink(hookURL, c.HookToken, c.HookSecret))
+	}
+	if len(sinks) == 0 && sheetID == "" {
+		sinks = watchSinks{&watchWriterSink{w: os.Stdout}}
+	}
+
+	store, err := openFormsWatchStore(account, formID)
+	if err != nil {
+		return err
+	}
+	if store.Get().LastSubmittedTime == "" || strings.TrimSpace(c.Since) != "" {
+		if err := store.Update(func(st *formsWatchState) error {
+			st.LastSubmittedTime = since.UTC().Format(time.RFC3339Nano)
+			st.LastResponseIDs = nil
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	validator := (*idtoken.Validator)(nil)
+	if c.VerifyOIDC {
+		validator, err = newOIDCValidator(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	selectedClient := strings.TrimSpace(flags.Client)
+	withClient := func(ctx context.Context) context.Context {
+		if selectedClient != "" {
+			return authclient.WithClient(ctx, selectedClient)
+		}
+		return ctx
+	}
+	server := &formsWatchServer{
+		account: account,
+		formID:  formID,
+		path:    c.Path,
+		auth: pushAuthConfig{
+			VerifyOIDC:   c.VerifyOIDC,
+			OIDCEmail:    c.OIDCEmail,
+			OIDCAudience: c.OIDCAudience,
+			SharedToken:  c.SharedToken,
+		},
+		validator: validator,
+		store:     store,
+		newService: func(ctx context.Context, account string) (*formsapi.Service, error) {
+			return newFormsService(withClient(ctx), account)
+		},
+		sheetsSvc: func(ctx context.Context, account string) (*sheets.Service, error) {
+			return newSheetsService(withClient(ctx), account)
+		},
+		sheetID:    sheetID,
+		sheetRange: sheetRange,
+		sinks:      sinks,
+		logf:       u.Err().Printf,
+		warnf:      u.Err().Printf,
+	}
+
+	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
+	u.Err().Printf("watch: listening on %s%s (responses since %s)", addr, c.Path, store.Get().LastSubmittedTime)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return listenAndServe(httpServer)
+}
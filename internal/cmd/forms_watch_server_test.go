@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	formsapi "google.golang.org/api/forms/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+func newFormsWatchAPI(t *testing.T, filters *[]string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/forms/f1/responses"):
+			mu.Lock()
+			*filters = append(*filters, r.URL.Query().Get("filter"))
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"responses": []map[string]any{
+					{
+						"responseId": "r2", "lastSubmittedTime": "2026-01-01T10:05:00.5Z", "respondentEmail": "b@example.com",
+						"answers": map[string]any{"q1": map[string]any{"textAnswers": map[string]any{"answers": []map[string]any{{"value": "Bob"}}}}},
+					},
+					{
+						"responseId": "r1", "lastSubmittedTime": "2026-01-01T10:00:00Z",
+						"answers": map[string]any{
+							"q1":  map[string]any{"textAnswers": map[string]any{"answers": []map[string]any{{"value": "Ann"}}}},
+							"g1":  map[string]any{"textAnswers": map[string]any{"answers": []map[string]any{{"value": "Yes"}, {"value": "Maybe"}}}},
+							"old": map[string]any{"textAnswers": map[string]any{"answers": []map[string]any{{"value": "x"}}}},
+						},
+					},
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, "/forms/f1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"formId": "f1",
+				"info":   map[string]any{"title": "Signup"},
+				"items": []map[string]any{
+					{"title": "Name", "questionItem": map[string]any{"question": map[string]any{"questionId": "q1"}}},
+					{"title": "Diet", "questionGroupItem": map[string]any{"questions": []map[string]any{{"questionId": "g1", "rowQuestion": map[string]any{"title": "Vegan"}}}}},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newFormsWatchTestServer(t *testing.T, api *httptest.Server) *formsWatchServer {
+	t.Helper()
+	setWatchTestConfigHome(t)
+	svc := newFormsTestService(t, context.Background(), api)
+	store, err := openFormsWatchStore("a@b.com", "f1")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	_ = store.Update(func(st *formsWatchState) error {
+		st.LastSubmittedTime = "2026-01-01T00:00:00Z"
+		return nil
+	})
+	return &formsWatchServer{
+		account:    "a@b.com",
+		formID:     "f1",
+		path:       "/forms-pubsub",
+		auth:       pushAuthConfig{SharedToken: "tok"},
+		store:      store,
+		newService: func(context.Context, string) (*formsapi.Service, error) { return svc, nil },
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+}
+
+func formsPush(server http.Handler, token, messageID, eventType string) int {
+	body, _ := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"messageId":  messageID,
+			"attributes": map[string]string{"formId": "f1", "eventType": eventType, "watchId": "w1"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/forms-pubsub", bytes.NewReader(body))
+	req.Header.Set("x-gog-token", token)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestFormsWatchServer_DeliversFlattenedResponses(t *testing.T) {
+	var filters []string
+	api := newFormsWatchAPI(t, &filters)
+	defer api.Close()
+	server := newFormsWatchTestServer(t, api)
+	var out bytes.Buffer
+	server.sinks = watchSinks{&watchWriterSink{w: &out}}
+
+	if code := formsPush(server, "wrong", "m0", "RESPONSES"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", code)
+	}
+	if code := formsPush(server, "tok", "m0", "SCHEMA"); code != http.StatusAccepted || len(filters) != 0 {
+		t.Fatalf("expected schema push to be ignored, got %d", code)
+	}
+
+	if code := formsPush(server, "tok", "m1", "RESPONSES"); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if filters[0] != "timestamp >= 2026-01-01T00:00:00Z" {
+		t.Fatalf("unexpected filter %q", filters[0])
+	}
+	var payload formsHookPayload
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v (%s)", err, out.String())
+	}
+	if payload.FormTitle != "Signup" || len(payload.Responses) != 2 || payload.Responses[0].ResponseID != "r1" {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+	answers := payload.Responses[0].Answers
+	if answers["Name"] != "Ann" || answers["Diet [Vegan]"] != "Yes, Maybe" || answers["old"] != "x" {
+		t.Fatalf("unexpected answers: %#v", answers)
+	}
+	state := server.store.Get()
+	if state.LastSubmittedTime != "2026-01-01T10:05:00.5Z" || len(state.LastResponseIDs) != 1 || state.LastResponseIDs[0] != "r2" {
+		t.Fatalf("unexpected cursor: %#v", state)
+	}
+
+	// r2 was already delivered at the cursor and r1 is older: nothing new.
+	out.Reset()
+	if code := formsPush(server, "tok", "m2", "RESPONSES"); code != http.StatusAccepted || out.Len() != 0 {
+		t.Fatalf("expected no new responses, got %d %q", code, out.String())
+	}
+	if filters[1] != "timestamp >= 2026-01-01T10:05:00.5Z" {
+		t.Fatalf("unexpected filter %q", filters[1])
+	}
+	if code := formsPush(server, "tok", "m2", "RESPONSES"); code != http.StatusAccepted || len(filters) != 2 {
+		t.Fatalf("expected duplicate push to skip the API, got %d (%d calls)", code, len(filters))
+	}
+}
+
+func TestFormsWatchServer_KeepsCursorWhenDeliveryFails(t *testing.T) {
+	var filters []string
+	api := newFormsWatchAPI(t, &filters)
+	defer api.Close()
+	server := newFormsWatchTestServer(t, api)
+	// A directory cannot be opened for appending.
+	server.sinks = watchSinks{&watchFileSink{path: t.TempDir()}}
+
+	if code := formsPush(server, "tok", "m1", "RESPONSES"); code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", code)
+	}
+	if state := server.store.Get(); state.LastSubmittedTime != "2026-01-01T00:00:00Z" || state.LastPushMessageID != "" {
+		t.Fatalf("cursor advanced after failed delivery: %#v", state)
+	}
+
+	// Pub/Sub redelivers the same message; now it goes through.
+	var out bytes.Buffer
+	server.sinks = watchSinks{&watchWriterSink{w: &out}}
+	if code := formsPush(server, "tok", "m1", "RESPONSES"); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if !strings.Contains(out.String(), `"responseId":"r1"`) || server.store.Get().LastSubmittedTime != "2026-01-01T10:05:00.5Z" {
+		t.Fatalf("expected redelivery, got %q %#v", out.String(), server.store.Get())
+	}
+}
+
+func TestFormsWatchServer_SheetColumnsFollowQuestionIDs(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		text := func(v string) map[string]any {
+			return map[string]any{"textAnswers": map[string]any{"answers": []map[string]any{{"value": v}}}}
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/forms/f1/responses"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"responses": []map[string]any{{
+				"responseId": "r1", "lastSubmittedTime": "2026-01-01T10:00:00Z",
+				"answers": map[string]any{"q1": text("Ann"), "q2": text("30")},
+			}}})
+		case strings.HasSuffix(r.URL.Path, "/forms/f1"):
+			// The new question sits before the existing one in form order.
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"formId": "f1",
+				"items": []map[string]any{
+					{"title": "Age", "questionItem": map[string]any{"question": map[string]any{"questionId": "q2"}}},
+					{"title": "Name", "questionItem": map[string]any{"question": map[string]any{"questionId": "q1"}}},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+	server := newFormsWatchTestServer(t, api)
+
+	var (
+		appended [][]any
+		updated  []string
+	)
+	sheetsSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, ":append"):
+			var vr sheets.ValueRange
+			_ = json.NewDecoder(r.Body).Decode(&vr)
+			appended = append(appended, vr.Values...)
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		case r.Method == http.MethodPut:
+			var vr sheets.ValueRange
+			_ = json.NewDecoder(r.Body).Decode(&vr)
+			updated = append(updated, r.URL.Path)
+			if len(vr.Values) != 1 || len(vr.Values[0]) != 1 || vr.Values[0][0] != "Age" {
+				t.Errorf("unexpected header update: %v", vr.Values)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		case strings.Contains(r.URL.Path, "/values/"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"range":  "Responses!A1:D5",
+				"values": [][]string{{"Submitted", "Email", "Response ID", "Name"}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer sheetsSrv.Close()
+	sheetsSvc, err := sheets.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(sheetsSrv.Client()),
+		option.WithEndpoint(sheetsSrv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("sheets: %v", err)
+	}
+	server.sheetsSvc = func(context.Context, string) (*sheets.Service, error) { return sheetsSvc, nil }
+	server.sheetID = "s1"
+	server.sheetRange = "Responses"
+
+	if code := formsPush(server, "tok", "m1", "RESPONSES"); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if len(updated) != 1 || !strings.HasSuffix(updated[0], "/values/Responses!E1") {
+		t.Fatalf("expected the new column header at E1, got %v", updated)
+	}
+	if len(appended) != 1 || appended[0][3] != "Ann" || appended[0][4] != "30" {
+		t.Fatalf("unexpected rows: %v", appended)
+	}
+}
+
+func TestFormsWatchServer_AppendsToSheet(t *testing.T) {
+	var filters []string
+	api := newFormsWatchAPI(t, &filters)
+	defer api.Close()
+	server := newFormsWatchTestServer(t, api)
+
+	var (
+		appended [][]any
+		input    string
+	)
+	sheetsSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, ":append"):
+			input = r.URL.Query().Get("valueInputOption")
+			var vr sheets.ValueRange
+			_ = json.NewDecoder(r.Body).Decode(&vr)
+			appended = append(appended, vr.Values...)
+			_ = json.NewEncoder(w).Encode(map[string]any{"updates": map[string]any{"updatedRange": "Responses!A1:F3"}})
+		case strings.Contains(r.URL.Path, "/values/"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"range": "Responses!A1:Z1000"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer sheetsSrv.Close()
+	sheetsSvc, err := sheets.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(sheetsSrv.Client()),
+		option.WithEndpoint(sheetsSrv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("sheets: %v", err)
+	}
+	server.sheetsSvc = func(context.Context, string) (*sheets.Service, error) { return sheetsSvc, nil }
+	server.sheetID = "s1"
+	server.sheetRange = "Responses"
+	server.warnf = func(format string, args ...any) { t.Errorf(format, args...) }
+
+	if code := formsPush(server, "tok", "m1", "RESPONSES"); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	if input != "RAW" || len(appended) != 3 {
+		t.Fatalf("unexpected append: input=%q rows=%v", input, appended)
+	}
+	header := appended[0]
+	if header[0] != "Submitted" || header[3] != "Name" || header[4] != "Diet [Vegan]" || header[5] != "old" {
+		t.Fatalf("unexpected header: %v", header)
+	}
+	if appended[1][2] != "r1" || appended[1][3] != "Ann" || appended[2][1] != "b@example.com" {
+		t.Fatalf("unexpected rows: %v", appended[1:])
+	}
+}
+
+func TestFormsWatchServeCmd_Validation(t *testing.T) {
+	setWatchTestConfigHome(t)
+	flags := &RootFlags{Account: "a@b.com"}
+	cases := [][]string{
+		{"f1", "--bind", "0.0.0.0"},
+		{"f1", "--oidc-email", "svc@example.com"},
+		{"f1", "--hook-secret", "s"},
+		{"f1", "--since", "not a time"},
+		{"f1", "--path", "nope"},
+	}
+	for _, args := range cases {
+		if err := runKong(t, &FormsWatchServeCmd{}, args, newQuietUIContext(t), flags); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestFormsWatchServeCmd_SetsCursor(t *testing.T) {
+	origListen := listenAndServe
+	t.Cleanup(func() { listenAndServe = origListen })
+	setWatchTestConfigHome(t)
+
+	var got *formsWatchServer
+	listenAndServe = func(srv *http.Server) error {
+		got, _ = srv.Handler.(*formsWatchServer)
+		return nil
+	}
+	flags := &RootFlags{Account: "a@b.com"}
+	if err := runKong(t, &FormsWatchServeCmd{}, []string{"f1", "--since", "2026-02-01T00:00:00Z"}, newQuietUIContext(t), flags); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	if got == nil || got.store.Get().LastSubmittedTime != "2026-02-01T00:00:00Z" || len(got.sinks) != 1 {
+		t.Fatalf("unexpected server: %#v", got)
+	}
+}
@@ -5,13 +5,17 @@ import "github.com/steipete/gogcli/internal/googleapi"
 var newGmailService = googleapi.NewGmail
 
 type GmailCmd struct {
-	Search     GmailSearchCmd     `cmd:"" name:"search" aliases:"find,query,ls,list" group:"Read" help:"Search threads using Gmail query syntax"`
-	Messages   GmailMessagesCmd   `cmd:"" name:"messages" aliases:"message,msg,msgs" group:"Read" help:"Message operations"`
-	Thread     GmailThreadCmd     `cmd:"" name:"thread" aliases:"threads,read" group:"Organize" help:"Thread operations (get, modify)"`
-	Get        GmailGetCmd        `cmd:"" name:"get" aliases:"info,show" group:"Read" help:"Get a message (full|metadata|raw)"`
-	Attachment GmailAttachmentCmd `cmd:"" name:"attachment" group:"Read" help:"Download a single attachment"`
-	URL        GmailURLCmd        `cmd:"" name:"url" group:"Read" help:"Print Gmail web URLs for threads"`
-	History    GmailHistoryCmd    `cmd:"" name:"history" group:"Read" help:"Gmail history"`
+	Search      GmailSearchCmd      `cmd:"" name:"search" aliases:"find,query,ls,list" group:"Read" help:"Search threads using Gmail query syntax"`
+	Messages    GmailMessagesCmd    `cmd:"" name:"messages" aliases:"message,msg,msgs" group:"Read" help:"Message operations"`
+	Thread      GmailThreadCmd      `cmd:"" name:"thread" aliases:"threads,read" group:"Organize" help:"Thread operations (get, modify)"`
+	Get         GmailGetCmd         `cmd:"" name:"get" aliases:"info,show" group:"Read" help:"Get a message (full|metadata|raw)"`
+	Attachment  GmailAttachmentCmd  `cmd:"" name:"attachment" group:"Read" help:"Download a single attachment"`
+	Attachments GmailAttachmentsCmd `cmd:"" name:"attachments" group:"Read" help:"Bulk attachment extraction (templated paths, dedup, Drive upload)"`
+	URL         GmailURLCmd         `cmd:"" name:"url" group:"Read" help:"Print Gmail web URLs for threads"`
+	History     GmailHistoryCmd     `cmd:"" name:"history" group:"Read" help:"Gmail history"`
+	Stats       GmailStatsCmd       `cmd:"" name:"stats" aliases:"analytics" group:"Read" help:"Mailbox analytics (top senders, weekly volume, storage hogs, unread backlog)"`
+	Sync        GmailSyncCmd        `cmd:"" name:"sync" group:"Read" help:"Sync messages into a local full-text index (incremental via history)"`
+	Local       GmailLocalCmd       `cmd:"" name:"local" aliases:"offline" group:"Read" help:"Search the local index offline"`
 
 	Labels  GmailLabelsCmd   `cmd:"" name:"labels" aliases:"label" group:"Organize" help:"Label operations"`
 	Batch   GmailBatchCmd    `cmd:"" name:"batch" group:"Organize" help:"Batch operations"`
@@ -24,6 +28,7 @@ type GmailCmd struct {
 	Forward   GmailForwardCmd   `cmd:"" name:"forward" aliases:"fwd" group:"Write" help:"Forward a message to new recipients"`
 	AutoReply GmailAutoReplyCmd `cmd:"" name:"autoreply" group:"Write" help:"Reply once to matching messages"`
 	Track     GmailTrackCmd     `cmd:"" name:"track" group:"Write" help:"Email open tracking"`
+	Keys      GmailKeysCmd      `cmd:"" name:"keys" group:"Write" help:"OpenPGP/S/MIME keys for --sign/--encrypt (stored in the keyring)"`
 	Drafts    GmailDraftsCmd    `cmd:"" name:"drafts" aliases:"draft" group:"Write" help:"Draft operations"`
 
 	Settings GmailSettingsCmd `cmd:"" name:"settings" group:"Admin" help:"Settings and admin"`
@@ -0,0 +1,597 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	defaultAttachmentManifestName = ".gog-attachments.json"
+	attachmentManifestVersion     = 1
+
+	attachmentStatusSaved     = "saved"
+	attachmentStatusDuplicate = "duplicate"
+	attachmentStatusSeen      = "seen"
+)
+
+type GmailAttachmentsCmd struct {
+	Extract GmailAttachmentsExtractCmd `cmd:"" name:"extract" aliases:"export,pull" help:"Bulk-extract attachments from messages matching a query"`
+}
+
+type GmailAttachmentsExtractCmd struct {
+	Query       string   `name:"query" aliases:"q" required:"" help:"Gmail query selecting messages (e.g. 'has:attachment from:billing newer_than:1m')"`
+	Out         string   `name:"out" aliases:"output,out-dir" default:"." help:"Output root directory"`
+	Template    string   `name:"template" default:"{date}/{from}/{name}" help:"Relative path template: {date} {year} {month} {day} {from} {domain} {subject} {name} {base} {ext} {messageId}"`
+	Mime        []string `name:"mime" aliases:"mime-type" help:"Only extract these MIME types (repeatable; supports type/* wildcards)"`
+	MinSize     string   `name:"min-size" help:"Skip attachments smaller than this (e.g. 10k, 1.5MB)"`
+	MaxSize     string   `name:"max-size" help:"Skip attachments larger than this (e.g. 25MB)"`
+	Max         int64    `name:"max" aliases:"limit" default:"500" help:"Max messages to scan (0 = no limit)"`
+	Manifest    string   `name:"manifest" help:"Dedup manifest path (default: <out>/.gog-attachments.json)"`
+	DriveFolder string   `name:"drive-folder" aliases:"upload-to" help:"Also upload each unique file to this Drive folder ID"`
+	Timezone    string   `name:"timezone" short:"z" help:"Timezone for {date} placeholders (IANA name). Default: local"`
+	Local       bool     `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
+}
+
+type attachmentExtractFilter struct {
+	MimeTypes []string
+	MinSize   int64
+	MaxSize   int64
+}
+
+type attachmentExtractResult struct {
+	MessageID   string `json:"messageId"`
+	Filename    string `json:"filename"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size"`
+	Status      string `json:"status"`
+	Path        string `json:"path,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+	DriveFileID string `json:"driveFileId,omitempty"`
+	Uploaded    bool   `json:"uploaded,omitempty"`
+}
+
+type attachmentExtractSummary struct {
+	Messages   int  `json:"messages"`
+	Saved      int  `json:"saved"`
+	Duplicates int  `json:"duplicates"`
+	Seen       int  `json:"seen"`
+	Filtered   int  `json:"filtered"`
+	Uploaded   int  `json:"uploaded"`
+	Truncated  bool `json:"truncated,omitempty"`
+}
+
+// attachmentManifest records extracted content by SHA-256 so repeated runs
+// skip both already-processed attachments and identical files sent twice.
+type attachmentManifest struct {
+	Version int                                `json:"version"`
+	Files   map[string]*attachmentManifestFile `json:"files"`
+	Seen    map[string]string                  `json:"seen"`
+	path    string
+}
+
+type attachmentManifestFile struct {
+	Path          string `json:"path"`
+	Size          int64  `json:"size"`
+	MimeType      string `json:"mimeType,omitempty"`
+	MessageID     string `json:"messageId"`
+	SavedAtMs     int64  `json:"savedAtMs"`
+	DriveFileID   string `json:"driveFileId,omitempty"`
+	DriveFolderID string `json:"driveFolderId,omitempty"`
+}
+
+type attachmentUploader func(ctx context.Context, localPath, name, mimeType string) (string, error)
+
+type attachmentExtractInput struct {
+	Query       string
+	Root        string
+	Template    string
+	Filter      attachmentExtractFilter
+	Max         int64
+	Loc         *time.Location
+	DriveFolder string
+	Upload      attachmentUploader
+}
+
+func (c *GmailAttachmentsExtractCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	query := strings.TrimSpace(c.Query)
+	if query == "" {
+		return usage("--query required")
+	}
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	template := strings.TrimSpace(c.Template)
+	if err := validateAttachmentTemplate(template); err != nil {
+		return err
+	}
+	filter, err := parseAttachmentExtractFilter(c.Mime, c.MinSize, c.MaxSize)
+	if err != nil {
+		return err
+	}
+	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	if err != nil {
+		return err
+	}
+	root, err := config.ExpandPath(strings.TrimSpace(c.Out))
+	if err != nil {
+		return err
+	}
+	root = filepath.Clean(root)
+	manifestPath := filepath.Join(root, defaultAttachmentManifestName)
+	if strings.TrimSpace(c.Manifest) != "" {
+		manifestPath, err = config.ExpandPath(strings.TrimSpace(c.Manifest))
+		if err != nil {
+			return err
+		}
+	}
+	driveFolder := strings.TrimSpace(c.DriveFolder)
+
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.attachments.extract", map[string]any{
+		"query":        query,
+		"out":          root,
+		"template":     template,
+		"mime":         filter.MimeTypes,
+		"min_size":     filter.MinSize,
+		"max_size":     filter.MaxSize,
+		"max":          c.Max,
+		"manifest":     manifestPath,
+		"drive_folder": driveFolder,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	input := attachmentExtractInput{
+		Query:       query,
+		Root:        root,
+		Template:    template,
+		Filter:      filter,
+		Max:         c.Max,
+		Loc:         loc,
+		DriveFolder: driveFolder,
+	}
+	if driveFolder != "" {
+		driveSvc, driveErr := newDriveService(ctx, account)
+		if driveErr != nil {
+			return driveErr
+		}
+		input.Upload = driveAttachmentUploader(driveSvc, driveFolder)
+	}
+
+	manifest, err := loadAttachmentManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	results, summary, runErr := runAttachmentExtract(ctx, svc, manifest, input)
+	// Persist progress even when a later download fails so reruns resume.
+	if err := manifest.save(); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"attachments": results,
+			"summary":     summary,
+			"manifest":    manifestPath,
+		})
+	}
+
+	if len(results) == 0 {
+		u.Err().Println("No attachments extracted")
+	} else {
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "STATUS\tSIZE\tMESSAGE\tPATH")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Status, formatBytes(r.Size), r.MessageID, r.Path)
+		}
+		flush()
+	}
+	u.Err().Printf("messages=%d saved=%d duplicates=%d seen=%d filtered=%d uploaded=%d", summary.Messages, summary.Saved, summary.Duplicates, summary.Seen, summary.Filtered, summary.Uploaded)
+	if summary.Truncated {
+		u.Err().Println("# More messages match; raise --max to scan them")
+	}
+	return nil
+}
+
+func runAttachmentExtract(ctx context.Context, svc *gmail.Service, manifest *attachmentManifest, input attachmentExtractInput) ([]attachmentExtractResult, attachmentExtractSummary, error) {
+	var summary attachmentExtractSummary
+	ids, truncated, err := listGmailMessageIDs(ctx, svc, input.Query, input.Max)
+	if err != nil {
+		return nil, summary, err
+	}
+	summary.Truncated = truncated
+
+	msgs, err := fetchGmailSyncMessages(ctx, svc, ids, gmailFormatFull)
+	if err != nil {
+		return nil, summary, err
+	}
+	// Oldest first so the first copy of duplicated content keeps its name.
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].InternalDate < msgs[j].InternalDate })
+	summary.Messages = len(msgs)
+
+	loc := input.Loc
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var results []attachmentExtractResult
+	for _, msg := range msgs {
+		for _, a := range collectAttachments(msg.Payload) {
+			if !input.Filter.matches(a) {
+				summary.Filtered++
+				continue
+			}
+			result, err := extractAttachment(ctx, svc, manifest, input, msg, a, loc)
+			if err != nil {
+				return results, summary, err
+			}
+			switch result.Status {
+			case attachmentStatusSaved:
+				summary.Saved++
+			case attachmentStatusDuplicate:
+				summary.Duplicates++
+			case attachmentStatusSeen:
+				summary.Seen++
+			}
+			if result.Uploaded {
+				summary.Uploaded++
+			}
+			results = append(results, result)
+		}
+	}
+	return results, summary, nil
+}
+
+func extractAttachment(ctx context.Context, svc *gmail.Service, manifest *attachmentManifest, input attachmentExtractInput, msg *gmail.Message, a attachmentInfo, loc *time.Location) (attachmentExtractResult, error) {
+	result := attachmentExtractResult{
+		MessageID: msg.Id,
+		Filename:  a.Filename,
+		MimeType:  a.MimeType,
+		Size:      a.Size,
+	}
+
+	// Gmail attachment IDs are not stable between fetches; key on the part's
+	// identity within its message instead.
+	seenKey := fmt.Sprintf("%s/%s/%d", msg.Id, a.Filename, a.Size)
+	if hash, ok := manifest.Seen[seenKey]; ok {
+		if entry := manifest.Files[hash]; entry != nil && (input.Upload == nil || entry.DriveFolderID == input.DriveFolder) {
+			result.Status = attachmentStatusSeen
+			result.SHA256 = hash
+			result.Path = entry.Path
+			result.DriveFileID = entry.DriveFileID
+			return result, nil
+		}
+	}
+
+	data, err := fetchAttachmentBytes(ctx, svc, msg.Id, a.AttachmentID)
+	if err != nil {
+		return result, fmt.Errorf("message %s attachment %s: %w", msg.Id, a.Filename, err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	result.SHA256 = hash
+	result.Size = int64(len(data))
+
+	entry, exists := manifest.Files[hash]
+	if exists {
+		result.Status = attachmentStatusDuplicate
+	} else {
+		rel := renderAttachmentTemplate(input.Template, msg, a, loc)
+		outPath, pathErr := attachmentOutputPath(input.Root, rel, data)
+		if pathErr != nil {
+			return result, pathErr
+		}
+		if err := writeFileAtomic(outPath, data); err != nil {
+			return result, err
+		}
+		entry = &attachmentManifestFile{
+			Path:      outPath,
+			Size:      int64(len(data)),
+			MimeType:  a.MimeType,
+			MessageID: msg.Id,
+			SavedAtMs: time.Now().UnixMilli(),
+		}
+		manifest.Files[hash] = entry
+		result.Status = attachmentStatusSaved
+	}
+	result.Path = entry.Path
+
+	if input.Upload != nil && (entry.DriveFileID == "" || entry.DriveFolderID != input.DriveFolder) {
+		fileID, err := input.Upload(ctx, entry.Path, filepath.Base(entry.Path), entry.MimeType)
+		if err != nil {
+			return result, fmt.Errorf("upload %s: %w", entry.Path, err)
+		}
+		entry.DriveFileID = fileID
+		entry.DriveFolderID = input.DriveFolder
+		result.Uploaded = true
+	}
+	result.DriveFileID = entry.DriveFileID
+
+	manifest.Seen[seenKey] = hash
+	return result, nil
+}
+
+func driveAttachmentUploader(svc *drive.Service, folderID string) attachmentUploader {
+	return func(ctx context.Context, localPath, name, mimeType string) (string, error) {
+		// #nosec G304 -- path was written by this command under the output root
+		f, err := os.Open(localPath)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		if strings.TrimSpace(mimeType) == "" {
+			mimeType = guessMimeType(localPath)
+		}
+		created, err := uploadDriveFile(ctx, svc, f, folderID, name, mimeType)
+		if err != nil {
+			return "", err
+		}
+		return created.Id, nil
+	}
+}
+
+func parseAttachmentExtractFilter(mimeTypes []string, minSize, maxSize string) (attachmentExtractFilter, error) {
+	var filter attachmentExtractFilter
+	for _, raw := range mimeTypes {
+		for _, part := range strings.Split(raw, ",") {
+			if mt := strings.ToLower(strings.TrimSpace(part)); mt != "" {
+				filter.MimeTypes = append(filter.MimeTypes, mt)
+			}
+		}
+	}
+	var err error
+	if filter.MinSize, err = parseByteSize(minSize); err != nil {
+		return filter, usagef("invalid --min-size: %v", err)
+	}
+	if filter.MaxSize, err = parseByteSize(maxSize); err != nil {
+		return filter, usagef("invalid --max-size: %v", err)
+	}
+	if filter.MaxSize > 0 && filter.MinSize > filter.MaxSize {
+		return filter, usage("--min-size must be <= --max-size")
+	}
+	return filter, nil
+}
+
+func (f attachmentExtractFilter) matches(a attachmentInfo) bool {
+	if f.MinSize > 0 && a.Size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && a.Size > f.MaxSize {
+		return false
+	}
+	if len(f.MimeTypes) == 0 {
+		return true
+	}
+	mt := strings.ToLower(strings.TrimSpace(a.MimeType))
+	for _, want := range f.MimeTypes {
+		if prefix, ok := strings.CutSuffix(want, "/*"); ok {
+			if strings.HasPrefix(mt, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mt == want {
+			return true
+		}
+	}
+	return false
+}
+
+// parseByteSize parses sizes like "512", "10k", "1.5MB", or "2GiB".
+// Units are binary (1k = 1024 bytes). Empty input means no limit.
+func parseByteSize(raw string) (int64, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return 0, nil
+	}
+	num := strings.TrimRightFunc(value, unicode.IsLetter)
+	unit := strings.TrimSpace(value[len(num):])
+	mult := float64(1)
+	switch strings.TrimSuffix(strings.TrimSuffix(unit, "ib"), "b") {
+	case "":
+	case "k":
+		mult = 1 << 10
+	case "m":
+		mult = 1 << 20
+	case "g":
+		mult = 1 << 30
+	default:
+		return 0, fmt.Errorf("unknown unit %q", unit)
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", raw)
+	}
+	return int64(n * mult), nil
+}
+
+var attachmentTemplatePlaceholders = []string{"date", "year", "month", "day", "from", "domain", "subject", "name", "base", "ext", "messageId"}
+
+func validateAttachmentTemplate(template string) error {
+	if template == "" {
+		return usage("--template must not be empty")
+	}
+	rest := template
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return usagef("unterminated placeholder in --template %q", template)
+		}
+		key := rest[start+1 : start+end]
+		known := false
+		for _, p := range attachmentTemplatePlaceholders {
+			if key == p {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return usagef("unknown --template placeholder {%s} (use %s)", key, strings.Join(attachmentTemplatePlaceholders, ", "))
+		}
+		rest = rest[start+end+1:]
+	}
+	if filepath.IsAbs(template) {
+		return usage("--template must be a relative path")
+	}
+	return nil
+}
+
+func renderAttachmentTemplate(template string, msg *gmail.Message, a attachmentInfo, loc *time.Location) string {
+	at := time.UnixMilli(msg.InternalDate).In(loc)
+	if msg.InternalDate == 0 {
+		if parsed, err := mailParseDate(headerValue(msg.Payload, "Date")); err == nil {
+			at = parsed.In(loc)
+		}
+	}
+	from := ""
+	if addrs := parseEmailAddresses(headerValue(msg.Payload, "From")); len(addrs) > 0 {
+		from = addrs[0]
+	}
+	domain := ""
+	if _, d, ok := strings.Cut(from, "@"); ok {
+		domain = d
+	}
+	name := sanitizeAttachmentFilename(a.Filename, "attachment")
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+
+	values := map[string]string{
+		"date":      at.Format("2006-01-02"),
+		"year":      at.Format("2006"),
+		"month":     at.Format("01"),
+		"day":       at.Format("02"),
+		"from":      from,
+		"domain":    domain,
+		"subject":   headerValue(msg.Payload, "Subject"),
+		"name":      name,
+		"base":      strings.TrimSuffix(name, filepath.Ext(name)),
+		"ext":       ext,
+		"messageId": msg.Id,
+	}
+	pairs := make([]string, 0, len(values)*2)
+	for key, value := range values {
+		pairs = append(pairs, "{"+key+"}", sanitizeAttachmentPathSegment(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// sanitizeAttachmentPathSegment keeps placeholder values from introducing
+// extra path segments or characters that are invalid on common filesystems.
+func sanitizeAttachmentPathSegment(value string) string {
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == '\\' || r == ':' || r == '*' || r == '?' || r == '"' || r == '<' || r == '>' || r == '|':
+			return '_'
+		case unicode.IsControl(r):
+			return -1
+		default:
+			return r
+		}
+	}, strings.TrimSpace(value))
+	value = strings.Trim(value, ". ")
+	if value == "" {
+		return "unknown"
+	}
+	if len(value) > 120 {
+		value = strings.TrimSpace(value[:120])
+	}
+	return value
+}
+
+// attachmentOutputPath resolves rel under root and picks a free name when a
+// different file already occupies the target path.
+func attachmentOutputPath(root, rel string, data []byte) (string, error) {
+	target := filepath.Join(root, filepath.Clean(filepath.FromSlash(rel)))
+	if within, err := filepath.Rel(root, target); err != nil || within == ".." || strings.HasPrefix(within, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("template path escapes output dir: %s", rel)
+	}
+	ext := filepath.Ext(target)
+	stem := strings.TrimSuffix(target, ext)
+	candidate := target
+	for i := 2; ; i++ {
+		existing, err := os.ReadFile(candidate) // #nosec G304 -- candidate is under the output root
+		if errors.Is(err, os.ErrNotExist) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if bytes.Equal(existing, data) {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", stem, i, ext)
+	}
+}
+
+func loadAttachmentManifest(path string) (*attachmentManifest, error) {
+	m := &attachmentManifest{
+		Version: attachmentManifestVersion,
+		Files:   map[string]*attachmentManifestFile{},
+		Seen:    map[string]string{},
+		path:    path,
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- user-selected manifest path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return m, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", path, err)
+	}
+	if m.Version != attachmentManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d in %s", m.Version, path)
+	}
+	if m.Files == nil {
+		m.Files = map[string]*attachmentManifestFile{}
+	}
+	if m.Seen == nil {
+		m.Seen = map[string]string{}
+	}
+	return m, nil
+}
+
+func (m *attachmentManifest) save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(m.path, append(data, '\n'))
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{
+		"":      0,
+		"512":   512,
+		"10k":   10 * 1024,
+		"1.5MB": 1536 * 1024,
+		"2GiB":  2 << 30,
+		" 3 kb": 3 * 1024,
+	}
+	for in, want := range cases {
+		got, err := parseByteSize(in)
+		if err != nil || got != want {
+			t.Fatalf("parseByteSize(%q)=%d,%v want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"abc", "10x", "-1k"} {
+		if _, err := parseByteSize(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestAttachmentExtractFilter(t *testing.T) {
+	filter, err := parseAttachmentExtractFilter([]string{"application/pdf,image/*"}, "1k", "1MB")
+	if err != nil {
+		t.Fatalf("parse filter: %v", err)
+	}
+	if !filter.matches(attachmentInfo{MimeType: "application/pdf", Size: 2048}) {
+		t.Fatalf("expected pdf match")
+	}
+	if !filter.matches(attachmentInfo{MimeType: "IMAGE/PNG", Size: 2048}) {
+		t.Fatalf("expected image wildcard match")
+	}
+	if filter.matches(attachmentInfo{MimeType: "text/plain", Size: 2048}) {
+		t.Fatalf("unexpected text match")
+	}
+	if filter.matches(attachmentInfo{MimeType: "application/pdf", Size: 10}) || filter.matches(attachmentInfo{MimeType: "application/pdf", Size: 2 << 20}) {
+		t.Fatalf("expected size bounds to apply")
+	}
+	if _, err := parseAttachmentExtractFilter(nil, "2MB", "1MB"); err == nil {
+		t.Fatalf("expected min > max error")
+	}
+}
+
+func TestRenderAttachmentTemplate(t *testing.T) {
+	msg := &gmail.Message{
+		Id:           "m1",
+		InternalDate: time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC).UnixMilli(),
+		Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{
+			{Name: "From", Value: "Billing <Billing@Vendor.example>"},
+			{Name: "Subject", Value: "Invoice 03/2026"},
+		}},
+	}
+	got := renderAttachmentTemplate("{year}/{domain}/{subject}-{base}.{ext}", msg, attachmentInfo{Filename: "../inv.pdf"}, time.UTC)
+	if got != "2026/vendor.example/Invoice 03_2026-inv.pdf" {
+		t.Fatalf("unexpected render: %q", got)
+	}
+	if got := renderAttachmentTemplate("{date}/{from}/{name}", msg, attachmentInfo{Filename: "a.pdf"}, time.UTC); got != "2026-03-05/billing@vendor.example/a.pdf" {
+		t.Fatalf("unexpected default render: %q", got)
+	}
+
+	if err := validateAttachmentTemplate("{date}/{nope}"); err == nil {
+		t.Fatalf("expected unknown placeholder error")
+	}
+	if err := validateAttachmentTemplate("{date"); err == nil {
+		t.Fatalf("expected unterminated placeholder error")
+	}
+	if _, err := attachmentOutputPath(t.TempDir(), "../escape.pdf", nil); err == nil {
+		t.Fatalf("expected escape error")
+	}
+}
+
+func TestRunAttachmentExtract_DedupAndResume(t *testing.T) {
+	invoice := base64.RawURLEncoding.EncodeToString([]byte("%PDF invoice"))
+	attachmentCalls := 0
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/users/me/messages" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"messages": []map[string]any{{"id": "m2"}, {"id": "m1"}},
+			})
+		case path == "/users/me/messages/m1" || path == "/users/me/messages/m2":
+			id := strings.TrimPrefix(path, "/users/me/messages/")
+			date := "1772704800000"
+			if id == "m2" {
+				date = "1772791200000"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":           id,
+				"internalDate": date,
+				"payload": map[string]any{
+					"headers": []map[string]any{{"name": "From", "value": "billing@vendor.example"}},
+					"parts": []map[string]any{
+						{"filename": "invoice.pdf", "mimeType": "application/pdf", "body": map[string]any{"attachmentId": "att-" + id, "size": 12}},
+						{"filename": "logo.png", "mimeType": "image/png", "body": map[string]any{"attachmentId": "logo-" + id, "size": 50}},
+					},
+				},
+			})
+		case strings.Contains(path, "/attachments/att-"):
+			attachmentCalls++
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": invoice, "size": 12})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	root := t.TempDir()
+	manifest, err := loadAttachmentManifest(filepath.Join(root, defaultAttachmentManifestName))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	var uploads []string
+	input := attachmentExtractInput{
+		Query:       "has:attachment",
+		Root:        root,
+		Template:    "{date}/{name}",
+		Filter:      attachmentExtractFilter{MimeTypes: []string{"application/pdf"}},
+		Loc:         time.UTC,
+		DriveFolder: "folder-1",
+		Upload: func(_ context.Context, _, name, _ string) (string, error) {
+			uploads = append(uploads, name)
+			return "drive-" + name, nil
+		},
+	}
+
+	results, summary, err := runAttachmentExtract(context.Background(), svc, manifest, input)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if summary.Saved != 1 || summary.Duplicates != 1 || summary.Filtered != 2 || summary.Uploaded != 1 {
+		t.Fatalf("unexpected summary: %#v", summary)
+	}
+	if results[0].MessageID != "m1" || results[0].Status != attachmentStatusSaved || results[1].Status != attachmentStatusDuplicate {
+		t.Fatalf("expected oldest message to be saved first: %#v", results)
+	}
+	want := filepath.Join(root, "2026-03-05", "invoice.pdf")
+	if data, err := os.ReadFile(want); err != nil || string(data) != "%PDF invoice" {
+		t.Fatalf("expected saved file at %s: %v", want, err)
+	}
+	if len(uploads) != 1 {
+		t.Fatalf("expected a single upload for duplicated content, got %v", uploads)
+	}
+	if err := manifest.save(); err != nil {
+		t.Fatalf("save manifest: %v", err)
+	}
+
+	reloaded, err := loadAttachmentManifest(filepath.Join(root, defaultAttachmentManifestName))
+	if err != nil {
+		t.Fatalf("reload manifest: %v", err)
+	}
+	calls := attachmentCalls
+	_, summary, err = runAttachmentExtract(context.Background(), svc, reloaded, input)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if summary.Seen != 2 || summary.Saved != 0 || attachmentCalls != calls || len(uploads) != 1 {
+		t.Fatalf("expected rerun to skip seen attachments: %#v calls=%d", summary, attachmentCalls-calls)
+	}
+}
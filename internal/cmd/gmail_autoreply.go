@@ -188,90 +188,102 @@ func runGmailAutoReply(ctx context.Context, svc *gmail.Service, account string,
 		return summary, nil
 	}
 
-	selfAddrs := []string{account}
-	if from.sendingEmail != "" && !strings.EqualFold(from.sendingEmail, account) {
-		selfAddrs = append(selfAddrs, from.sendingEmail)
-	}
+	selfAddrs := autoReplySelfAddresses(account, from)
 
 	for _, messageID := range messageIDs {
 		msg, err := fetchMessageForAutoReply(ctx, svc, messageID)
 		if err != nil {
 			return summary, err
 		}
-		result := gmailAutoReplyResult{
-			MessageID: messageID,
-		}
-		if msg != nil {
-			result.ThreadID = msg.ThreadId
-			result.Subject = headerValue(msg.Payload, "Subject")
-		}
-		if msg == nil {
-			result.Action = autoReplyActionSkipped
-			result.Reason = "missing_message"
-			summary.Results = append(summary.Results, result)
-			summary.Skipped++
-			continue
+		result, err := autoReplyToMessage(ctx, svc, messageID, msg, from, labelID, selfAddrs, input)
+		if err != nil {
+			return summary, err
 		}
-		if hasMessageLabel(msg, labelID) {
-			result.Action = autoReplyActionSkipped
-			result.Reason = "already_labeled"
-			summary.Results = append(summary.Results, result)
+		summary.Results = append(summary.Results, result)
+		if result.Action == autoReplyActionSkipped {
 			summary.Skipped++
-			continue
-		}
-		if input.SkipBulk {
-			if skip, reason := shouldSkipAutoReplyMessage(msg); skip {
-				result.Action = autoReplyActionSkipped
-				result.Reason = reason
-				summary.Results = append(summary.Results, result)
-				summary.Skipped++
-				continue
-			}
+		} else {
+			summary.Replied++
 		}
+	}
 
-		replyMeta := replyInfoFromMessage(msg, false)
-		recipients := autoReplyRecipients(replyMeta, selfAddrs)
-		if len(recipients) == 0 && input.AllowSelf {
-			recipients = autoReplyRecipients(replyMeta, nil)
-		}
-		if len(recipients) == 0 {
+	return summary, nil
+}
+
+// autoReplyToMessage replies to a single message (fetched with
+// fetchMessageForAutoReply) unless it is already labeled, bulk mail, or has no
+// reply recipient, then labels the thread.
+func autoReplyToMessage(ctx context.Context, svc *gmail.Service, messageID string, msg *gmail.Message, from composeFromResult, labelID string, selfAddrs []string, input gmailAutoReplyInput) (gmailAutoReplyResult, error) {
+	result := gmailAutoReplyResult{
+		MessageID: messageID,
+	}
+	if msg == nil {
+		result.Action = autoReplyActionSkipped
+		result.Reason = "missing_message"
+		return result, nil
+	}
+	result.ThreadID = msg.ThreadId
+	result.Subject = headerValue(msg.Payload, "Subject")
+	if hasMessageLabel(msg, labelID) {
+		result.Action = autoReplyActionSkipped
+		result.Reason = "already_labeled"
+		return result, nil
+	}
+	if input.SkipBulk {
+		if skip, reason := shouldSkipAutoReplyMessage(msg); skip {
 			result.Action = autoReplyActionSkipped
-			result.Reason = "no_reply_recipient"
-			summary.Results = append(summary.Results, result)
-			summary.Skipped++
-			continue
-		}
-		result.ReplyTo = recipients[0]
-
-		sendResults, err := sendGmailBatches(ctx, svc, sendMessageOptions{
-			FromAddr:  from.header,
-			ReplyTo:   input.ReplyTo,
-			Subject:   autoReplySubject(input.Subject, headerValue(msg.Payload, "Subject")),
-			Body:      input.Body,
-			BodyHTML:  input.BodyHTML,
-			ReplyInfo: replyMeta,
-			Headers: map[string]string{
-				"Auto-Submitted":           "auto-replied",
-				"X-Auto-Response-Suppress": "All",
-			},
-		}, []sendBatch{{To: recipients}})
-		if err != nil {
-			return summary, err
-		}
-		if len(sendResults) > 0 {
-			result.ReplyMessageID = sendResults[0].MessageID
-			result.ReplyThreadID = sendResults[0].ThreadID
+			result.Reason = reason
+			return result, nil
 		}
+	}
 
-		if err := modifyAutoReplyThread(ctx, svc, msg.ThreadId, labelID, input.Archive, input.MarkRead); err != nil {
-			return summary, err
-		}
-		result.Action = "replied"
-		summary.Results = append(summary.Results, result)
-		summary.Replied++
+	replyMeta := replyInfoFromMessage(msg, false)
+	recipients := autoReplyRecipients(replyMeta, selfAddrs)
+	if len(recipients) == 0 && input.AllowSelf {
+		recipients = autoReplyRecipients(replyMeta, nil)
 	}
+	if len(recipients) == 0 {
+		result.Action = autoReplyActionSkipped
+		result.Reason = "no_reply_recipient"
+		return result, nil
+	}
+	result.ReplyTo = recipients[0]
 
-	return summary, nil
+	sendResults, err := sendGmailBatches(ctx, svc, sendMessageOptions{
+		FromAddr:  from.header,
+		ReplyTo:   input.ReplyTo,
+		Subject:   autoReplySubject(input.Subject, headerValue(msg.Payload, "Subject")),
+		Body:      input.Body,
+		BodyHTML:  input.BodyHTML,
+		ReplyInfo: replyMeta,
+		Headers: map[string]string{
+			"Auto-Submitted":           "auto-replied",
+			"X-Auto-Response-Suppress": "All",
+		},
+	}, []sendBatch{{To: recipients}})
+	if err != nil {
+		return result, err
+	}
+	if len(sendResults) > 0 {
+		result.ReplyMessageID = sendResults[0].MessageID
+		result.ReplyThreadID = sendResults[0].ThreadID
+	}
+
+	if err := modifyAutoReplyThread(ctx, svc, msg.ThreadId, labelID, input.Archive, input.MarkRead); err != nil {
+		return result, err
+	}
+	result.Action = "replied"
+	return result, nil
+}
+
+// autoReplySelfAddresses lists the addresses treated as "self" when picking
+// reply recipients.
+func autoReplySelfAddresses(account string, from composeFromResult) []string {
+	selfAddrs := []string{account}
+	if from.sendingEmail != "" && !strings.EqualFold(from.sendingEmail, account) {
+		selfAddrs = append(selfAddrs, from.sendingEmail)
+	}
+	return selfAddrs
 }
 
 func fetchMessageForAutoReply(ctx context.Context, svc *gmail.Service, messageID string) (*gmail.Message, error) {
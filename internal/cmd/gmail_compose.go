@@ -132,6 +132,7 @@ func buildGmailMessage(opts sendMessageOptions, batch sendBatch, cfg *rfc822Conf
 		References:        reply.References,
 		AdditionalHeaders: opts.Headers,
 		Attachments:       opts.Attachments,
+		Security:          opts.Security,
 	}, cfg)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/mailcrypt"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/secrets"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	mailKeyPassphraseEnv = "GOG_MAIL_KEY_PASSPHRASE" //nolint:gosec // env var name, not a credential
+	mailKeyKeyringPrefix = "keyring:"
+	mailKeySecretPrefix  = "mailkey:"
+)
+
+// MailSecurityFlags adds OpenPGP / S/MIME signing and encryption to compose
+// commands.
+type MailSecurityFlags struct {
+	Sign           bool     `name:"sign" help:"Sign the message (PGP/MIME or S/MIME, see --crypto)"`
+	Encrypt        bool     `name:"encrypt" help:"Encrypt the message to every recipient (and to the sender when --sign-key is set)"`
+	Crypto         string   `name:"crypto" help:"Standard for --sign/--encrypt: pgp|smime" enum:"pgp,smime" default:"pgp"`
+	SignKey        string   `name:"sign-key" help:"Signing key: OpenPGP secret key or PKCS#12 file, or keyring:<name>"`
+	RecipientKeys  []string `name:"recipient-key" help:"Recipient OpenPGP public key or X.509 certificate: file or keyring:<name> (repeatable)"`
+	PassphraseFile string   `name:"key-passphrase-file" help:"File with the key passphrase or PKCS#12 password (default: $GOG_MAIL_KEY_PASSPHRASE)"`
+}
+
+func (f MailSecurityFlags) enabled() bool {
+	return f.Sign || f.Encrypt
+}
+
+func (f MailSecurityFlags) validate() error {
+	if !f.enabled() {
+		if strings.TrimSpace(f.SignKey) != "" || len(f.RecipientKeys) > 0 {
+			return usage("--sign-key/--recipient-key require --sign or --encrypt")
+		}
+		return nil
+	}
+	if f.Sign && strings.TrimSpace(f.SignKey) == "" {
+		return usage("--sign requires --sign-key")
+	}
+	if f.Encrypt && len(f.RecipientKeys) == 0 {
+		return usage("--encrypt requires --recipient-key")
+	}
+	return nil
+}
+
+func (f MailSecurityFlags) dryRunFields(fields map[string]any) map[string]any {
+	if f.enabled() {
+		fields["sign"] = f.Sign
+		fields["encrypt"] = f.Encrypt
+		fields["crypto"] = f.Crypto
+	}
+	return fields
+}
+
+// apply loads the configured keys into opts. recipients are checked for key
+// coverage when encrypting.
+func (f MailSecurityFlags) apply(opts *sendMessageOptions, recipients []string) error {
+	if !f.enabled() {
+		return nil
+	}
+
+	passphrase, err := readMailKeyPassphrase(f.PassphraseFile)
+	if err != nil {
+		return err
+	}
+
+	cfg := mailcrypt.Config{
+		Standard:   f.Crypto,
+		Sign:       f.Sign,
+		Encrypt:    f.Encrypt,
+		Passphrase: passphrase,
+		Recipients: parseEmailAddresses(strings.Join(recipients, ", ")),
+	}
+	if strings.TrimSpace(f.SignKey) != "" {
+		cfg.SignKey, err = readMailKey(f.SignKey)
+		if err != nil {
+			return err
+		}
+	}
+	if f.Encrypt {
+		for _, spec := range f.RecipientKeys {
+			data, readErr := readMailKey(spec)
+			if readErr != nil {
+				return readErr
+			}
+			cfg.RecipientKeys = append(cfg.RecipientKeys, data)
+		}
+	}
+
+	p, err := mailcrypt.New(cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Crypto, err)
+	}
+	opts.Security = p
+	return nil
+}
+
+func composeRecipients(to, cc, bcc []string) []string {
+	return append(append(append([]string{}, to...), cc...), bcc...)
+}
+
+// readMailKey loads key material from a file path or from the keyring
+// ("keyring:<name>", see 'gog gmail keys import').
+func readMailKey(spec string) ([]byte, error) {
+	spec = strings.TrimSpace(spec)
+	if name, ok := strings.CutPrefix(spec, mailKeyKeyringPrefix); ok {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, usage("empty keyring key name")
+		}
+		data, err := secrets.GetSecret(mailKeySecretPrefix + name)
+		if err != nil {
+			return nil, fmt.Errorf("keyring key %q: %w", name, err)
+		}
+		return data, nil
+	}
+
+	path, err := config.ExpandPath(spec)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided key path
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	return data, nil
+}
+
+func readMailKeyPassphrase(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return os.Getenv(mailKeyPassphraseEnv), nil
+	}
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(expanded) //nolint:gosec // user-provided passphrase path
+	if err != nil {
+		return "", fmt.Errorf("read passphrase file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// GmailKeysCmd manages signing/encryption keys stored in the keyring.
+type GmailKeysCmd struct {
+	Import GmailKeysImportCmd `cmd:"" name:"import" aliases:"add" help:"Store an OpenPGP key, PKCS#12 bundle or certificate in the keyring"`
+	List   GmailKeysListCmd   `cmd:"" name:"list" aliases:"ls" help:"List keys stored in the keyring"`
+}
+
+type GmailKeysImportCmd struct {
+	Name string `arg:"" name:"name" help:"Key name (use as keyring:<name>)"`
+	Path string `arg:"" name:"path" help:"Key file path"`
+}
+
+func (c *GmailKeysImportCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return usage("key name must be non-empty and contain no whitespace")
+	}
+	path, err := config.ExpandPath(c.Path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided key path
+	if err != nil {
+		return fmt.Errorf("read key: %w", err)
+	}
+	if len(data) == 0 {
+		return usage("key file is empty")
+	}
+	if err := secrets.SetSecret(mailKeySecretPrefix+name, data); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"name": name,
+			"ref":  mailKeyKeyringPrefix + name,
+		})
+	}
+	u.Out().Printf("stored\t%s%s", mailKeyKeyringPrefix, name)
+	return nil
+}
+
+type GmailKeysListCmd struct{}
+
+func (c *GmailKeysListCmd) Run(ctx context.Context) error {
+	u := ui.FromContext(ctx)
+
+	store, err := secrets.OpenDefault()
+	if err != nil {
+		return err
+	}
+	keys, err := store.Keys()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if name, ok := strings.CutPrefix(k, mailKeySecretPrefix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"keys": names})
+	}
+	if len(names) == 0 {
+		u.Err().Println("No keys")
+		return nil
+	}
+	for _, name := range names {
+		u.Out().Printf("%s%s", mailKeyKeyringPrefix, name)
+	}
+	return nil
+}
+
+// inspectGmailSecurity verifies and/or decrypts a raw message locally.
+func inspectGmailSecurity(raw []byte, decryptKey string, trustKeys []string, passphraseFile string) (*mailcrypt.Result, error) {
+	passphrase, err := readMailKeyPassphrase(passphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := mailcrypt.VerifyConfig{Passphrase: passphrase}
+	if strings.TrimSpace(decryptKey) != "" {
+		cfg.DecryptKey, err = readMailKey(decryptKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, spec := range trustKeys {
+		data, readErr := readMailKey(spec)
+		if readErr != nil {
+			return nil, readErr
+		}
+		cfg.TrustKeys = append(cfg.TrustKeys, data)
+	}
+	return mailcrypt.Inspect(raw, cfg)
+}
+
+func mailSecurityOutput(res *mailcrypt.Result) map[string]any {
+	out := map[string]any{
+		"signed":         res.Signed,
+		"signatureValid": res.SignatureValid,
+		"trusted":        res.Trusted,
+		"encrypted":      res.Encrypted,
+		"decrypted":      res.Decrypted,
+	}
+	if res.Standard != "" {
+		out["standard"] = res.Standard
+	}
+	if res.Signer != "" {
+		out["signer"] = res.Signer
+	}
+	if len(res.Errors) > 0 {
+		out["errors"] = res.Errors
+	}
+	return out
+}
+
+func printMailSecurity(u *ui.UI, res *mailcrypt.Result) {
+	if res.Standard == "" {
+		u.Out().Printf("security\tnone")
+	} else {
+		u.Out().Printf("security\t%s", res.Standard)
+	}
+	if res.Signed {
+		status := "invalid"
+		switch {
+		case res.SignatureValid && res.Trusted:
+			status = "valid"
+		case res.SignatureValid:
+			status = "valid (untrusted)"
+		}
+		u.Out().Printf("signature\t%s", status)
+		if res.Signer != "" {
+			u.Out().Printf("signer\t%s", res.Signer)
+		}
+	}
+	if res.Encrypted {
+		u.Out().Printf("decrypted\t%t", res.Decrypted)
+	}
+	for _, e := range res.Errors {
+		u.Out().Printf("security_error\t%s", e)
+	}
+}
+
+func mailSecurityBody(res *mailcrypt.Result) string {
+	if res.Text != "" {
+		return res.Text
+	}
+	if res.HTML != "" {
+		return stripHTMLTags(res.HTML)
+	}
+	return ""
+}
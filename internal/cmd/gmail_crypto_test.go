@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func writeTestPGPKeys(t *testing.T, dir, name, email string) (string, string) {
+	t.Helper()
+	e, err := openpgp.NewEntity(name, "", email, nil)
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+	write := func(file, blockType string, serialize func(w *bytes.Buffer) error) string {
+		var buf bytes.Buffer
+		w, err := armor.Encode(&buf, blockType, nil)
+		if err != nil {
+			t.Fatalf("armor: %v", err)
+		}
+		var raw bytes.Buffer
+		if err := serialize(&raw); err != nil {
+			t.Fatalf("serialize: %v", err)
+		}
+		_, _ = w.Write(raw.Bytes())
+		_ = w.Close()
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+			t.Fatalf("write key: %v", err)
+		}
+		return path
+	}
+	sec := write(name+".sec.asc", openpgp.PrivateKeyType, func(w *bytes.Buffer) error { return e.SerializePrivate(w, nil) })
+	pub := write(name+".pub.asc", openpgp.PublicKeyType, func(w *bytes.Buffer) error { return e.Serialize(w) })
+	return sec, pub
+}
+
+func TestMailSecurityFlags_Validate(t *testing.T) {
+	cases := []MailSecurityFlags{
+		{Sign: true},
+		{Encrypt: true},
+		{SignKey: "key.asc"},
+	}
+	for _, f := range cases {
+		if err := f.validate(); err == nil {
+			t.Fatalf("expected usage error for %#v", f)
+		}
+	}
+	if err := (MailSecurityFlags{Sign: true, SignKey: "key.asc"}).validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildGmailMessage_SignedAndEncryptedRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	aliceSec, alicePub := writeTestPGPKeys(t, dir, "alice", "alice@example.com")
+	bobSec, bobPub := writeTestPGPKeys(t, dir, "bob", "bob@example.com")
+
+	flags := MailSecurityFlags{Sign: true, Encrypt: true, Crypto: "pgp", SignKey: aliceSec, RecipientKeys: []string{bobPub}}
+	opts := sendMessageOptions{
+		FromAddr: "Alice <alice@example.com>",
+		Subject:  "Plans",
+		Body:     "Meet at noon.",
+		BodyHTML: "<p>Meet at <b>noon</b>.</p>",
+	}
+	if err := flags.apply(&opts, []string{"carol@example.com"}); err == nil || !strings.Contains(err.Error(), "carol@example.com") {
+		t.Fatalf("expected missing recipient key error, got %v", err)
+	}
+	if err := flags.apply(&opts, []string{"Bob <bob@example.com>"}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	msg, err := buildGmailMessage(opts, sendBatch{To: []string{"Bob <bob@example.com>"}}, nil)
+	if err != nil {
+		t.Fatalf("buildGmailMessage: %v", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(msg.Raw)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Contains(raw, []byte("Subject: Plans\r\n")) || !bytes.Contains(raw, []byte("multipart/encrypted")) || bytes.Contains(raw, []byte("noon")) {
+		t.Fatalf("unexpected raw message:\n%s", raw)
+	}
+
+	res, err := inspectGmailSecurity(raw, bobSec, []string{alicePub}, "")
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if !res.Decrypted || !res.Signed || !res.SignatureValid || res.Signer != "alice <alice@example.com>" {
+		t.Fatalf("unexpected security result: %#v", res)
+	}
+	if mailSecurityBody(res) != "Meet at noon." || !strings.Contains(res.HTML, "<b>noon</b>") {
+		t.Fatalf("unexpected decrypted body: %q / %q", res.Text, res.HTML)
+	}
+	out := mailSecurityOutput(res)
+	if out["standard"] != "pgp" || out["signatureValid"] != true {
+		t.Fatalf("unexpected output: %#v", out)
+	}
+}
@@ -252,6 +252,8 @@ type GmailDraftsCreateCmd struct {
 	Quote            bool     `name:"quote" help:"Include quoted original message in reply (requires --reply-to-message-id)"`
 	Attach           []string `name:"attach" help:"Attachment file path (repeatable)"`
 	From             string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+
+	Security MailSecurityFlags `embed:""`
 }
 
 type draftComposeInput struct {
@@ -267,6 +269,7 @@ type draftComposeInput struct {
 	Quote            bool
 	Attach           []string
 	From             string
+	Security         MailSecurityFlags
 }
 
 func (c draftComposeInput) validate() error {
@@ -276,7 +279,7 @@ func (c draftComposeInput) validate() error {
 	if strings.TrimSpace(c.Body) == "" && strings.TrimSpace(c.BodyHTML) == "" {
 		return usage("required: --body, --body-file, or --body-html")
 	}
-	return nil
+	return c.Security.validate()
 }
 
 func buildDraftMessage(ctx context.Context, svc *gmail.Service, account string, input draftComposeInput) (*gmail.Message, string, error) {
@@ -292,7 +295,7 @@ func buildDraftMessage(ctx context.Context, svc *gmail.Service, account string,
 	threadID := info.ThreadID
 	atts := attachmentsFromPaths(input.Attach)
 
-	msg, err := buildGmailMessage(sendMessageOptions{
+	opts := sendMessageOptions{
 		FromAddr:    from.header,
 		ReplyTo:     input.ReplyTo,
 		Subject:     input.Subject,
@@ -300,11 +303,17 @@ func buildDraftMessage(ctx context.Context, svc *gmail.Service, account string,
 		BodyHTML:    htmlBody,
 		ReplyInfo:   info,
 		Attachments: atts,
-	}, sendBatch{
+	}
+	batch := sendBatch{
 		To:  splitCSV(input.To),
 		Cc:  splitCSV(input.Cc),
 		Bcc: splitCSV(input.Bcc),
-	}, &rfc822Config{allowMissingTo: true})
+	}
+	if err := input.Security.apply(&opts, composeRecipients(batch.To, batch.Cc, batch.Bcc)); err != nil {
+		return nil, "", err
+	}
+
+	msg, err := buildGmailMessage(opts, batch, &rfc822Config{allowMissingTo: true})
 	if err != nil {
 		return nil, "", err
 	}
@@ -450,12 +459,13 @@ func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 		Quote:            c.Quote,
 		Attach:           attachPaths,
 		From:             c.From,
+		Security:         c.Security,
 	}
 	if validateErr := input.validate(); validateErr != nil {
 		return validateErr
 	}
 
-	if dryRunErr := dryRunExit(ctx, flags, "gmail.drafts.create", map[string]any{
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.drafts.create", c.Security.dryRunFields(map[string]any{
 		"to":                  splitCSV(input.To),
 		"cc":                  splitCSV(input.Cc),
 		"bcc":                 splitCSV(input.Bcc),
@@ -467,7 +477,7 @@ func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 		"quote":               input.Quote,
 		"from":                strings.TrimSpace(input.From),
 		"attachments":         attachPaths,
-	}); dryRunErr != nil {
+	})); dryRunErr != nil {
 		return dryRunErr
 	}
 
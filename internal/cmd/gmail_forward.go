@@ -7,6 +7,8 @@ import (
 	"net/mail"
 	"strings"
 
+	"google.golang.org/api/gmail/v1"
+
 	"github.com/steipete/gogcli/internal/ui"
 )
 
@@ -19,6 +21,8 @@ type GmailForwardCmd struct {
 	NoteFile        string `name:"note-file" help:"Note file path (plain text; '-' for stdin)"`
 	From            string `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
 	SkipAttachments bool   `name:"skip-attachments" help:"Do not include original attachments"`
+
+	Security MailSecurityFlags `embed:""`
 }
 
 func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -39,7 +43,11 @@ func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return usage("required: --to")
 	}
 
-	if dryRunErr := dryRunExit(ctx, flags, "gmail.forward", map[string]any{
+	if err := c.Security.validate(); err != nil {
+		return err
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.forward", c.Security.dryRunFields(map[string]any{
 		"message_id":       messageID,
 		"to":               toRecipients,
 		"cc":               splitCSV(c.Cc),
@@ -47,7 +55,7 @@ func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
 		"from":             strings.TrimSpace(c.From),
 		"note_len":         len(strings.TrimSpace(note)),
 		"skip_attachments": c.SkipAttachments,
-	}); dryRunErr != nil {
+	})); dryRunErr != nil {
 		return dryRunErr
 	}
 
@@ -67,6 +75,40 @@ func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return fmt.Errorf("fetch original message: %w", err)
 	}
 
+	opts, err := forwardMessageOptions(ctx, svc, origMsg, from.header, note, c.SkipAttachments)
+	if err != nil {
+		return err
+	}
+	ccRecipients := splitCSV(c.Cc)
+	bccRecipients := splitCSV(c.Bcc)
+	if err := c.Security.apply(&opts, composeRecipients(toRecipients, ccRecipients, bccRecipients)); err != nil {
+		return err
+	}
+
+	msg, err := buildGmailMessage(opts, sendBatch{
+		To:  toRecipients,
+		Cc:  ccRecipients,
+		Bcc: bccRecipients,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	sent, err := svc.Users.Messages.Send("me", msg).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("send forward: %w", err)
+	}
+
+	return writeGmailMessageResults(ctx, u, []gmailMessageResult{{
+		From:      from.header,
+		MessageID: sent.Id,
+		ThreadID:  sent.ThreadId,
+	}})
+}
+
+// forwardMessageOptions builds the forward of origMsg (fetched in full
+// format): prefixed subject, quoted headers/body, and re-attached files.
+func forwardMessageOptions(ctx context.Context, svc *gmail.Service, origMsg *gmail.Message, fromHeader, note string, skipAttachments bool) (sendMessageOptions, error) {
 	origFrom := headerValue(origMsg.Payload, "From")
 	origTo := headerValue(origMsg.Payload, "To")
 	origCc := headerValue(origMsg.Payload, "Cc")
@@ -75,12 +117,6 @@ func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
 	origPlain := findPartBody(origMsg.Payload, "text/plain")
 	origHTML := findPartBody(origMsg.Payload, "text/html")
 
-	// Build forward subject (avoid stacking prefixes).
-	fwdSubject := buildForwardSubject(origSubject)
-
-	// Build forwarded body (plain text).
-	fwdPlain := formatForwardedMessage(note, origFrom, origDate, origSubject, origTo, origCc, origPlain)
-
 	// Build forwarded body (HTML) if original had HTML.
 	var fwdHTML string
 	if origHTML != "" {
@@ -89,12 +125,11 @@ func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	// Download and re-attach original attachments.
 	var attachments []mailAttachment
-	if !c.SkipAttachments {
-		origAtts := collectAttachments(origMsg.Payload)
-		for _, att := range origAtts {
-			data, dlErr := fetchAttachmentBytes(ctx, svc, messageID, att.AttachmentID)
-			if dlErr != nil {
-				return fmt.Errorf("download attachment %q: %w", att.Filename, dlErr)
+	if !skipAttachments {
+		for _, att := range collectAttachments(origMsg.Payload) {
+			data, err := fetchAttachmentBytes(ctx, svc, origMsg.Id, att.AttachmentID)
+			if err != nil {
+				return sendMessageOptions{}, fmt.Errorf("download attachment %q: %w", att.Filename, err)
 			}
 			attachments = append(attachments, mailAttachment{
 				Filename: att.Filename,
@@ -104,38 +139,14 @@ func (c *GmailForwardCmd) Run(ctx context.Context, flags *RootFlags) error {
 		}
 	}
 
-	// Build threading info to keep the forward in the sender's thread.
-	info := replyInfoFromMessage(origMsg, false)
-
-	ccRecipients := splitCSV(c.Cc)
-	bccRecipients := splitCSV(c.Bcc)
-
-	msg, err := buildGmailMessage(sendMessageOptions{
-		FromAddr:    from.header,
-		Subject:     fwdSubject,
-		Body:        fwdPlain,
+	return sendMessageOptions{
+		FromAddr:    fromHeader,
+		Subject:     buildForwardSubject(origSubject), // avoid stacking prefixes
+		Body:        formatForwardedMessage(note, origFrom, origDate, origSubject, origTo, origCc, origPlain),
 		BodyHTML:    fwdHTML,
-		ReplyInfo:   info,
+		ReplyInfo:   replyInfoFromMessage(origMsg, false), // keep the forward in the sender's thread
 		Attachments: attachments,
-	}, sendBatch{
-		To:  toRecipients,
-		Cc:  ccRecipients,
-		Bcc: bccRecipients,
-	}, nil)
-	if err != nil {
-		return fmt.Errorf("build message: %w", err)
-	}
-
-	sent, err := svc.Users.Messages.Send("me", msg).Context(ctx).Do()
-	if err != nil {
-		return fmt.Errorf("send forward: %w", err)
-	}
-
-	return writeGmailMessageResults(ctx, u, []gmailMessageResult{{
-		From:      from.header,
-		MessageID: sent.Id,
-		ThreadID:  sent.ThreadId,
-	}})
+	}, nil
 }
 
 type forwardedHeader struct {
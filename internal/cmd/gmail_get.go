@@ -7,6 +7,9 @@ import (
 	"os"
 	"strings"
 
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/mailcrypt"
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
 )
@@ -15,11 +18,18 @@ type GmailGetCmd struct {
 	MessageID string `arg:"" name:"messageId" help:"Message ID"`
 	Format    string `name:"format" help:"Message format: full|metadata|raw" default:"full"`
 	Headers   string `name:"headers" help:"Metadata headers (comma-separated; only for --format=metadata)"`
+
+	Verify         bool     `name:"verify" help:"Verify PGP/MIME or S/MIME signatures locally"`
+	Decrypt        bool     `name:"decrypt" help:"Decrypt PGP/MIME or S/MIME messages locally (requires --decrypt-key)"`
+	DecryptKey     string   `name:"decrypt-key" help:"Decryption key: OpenPGP secret key or PKCS#12 file, or keyring:<name>"`
+	TrustKeys      []string `name:"trust-key" help:"Signer OpenPGP public key or trusted X.509 CA certificate: file or keyring:<name> (repeatable)"`
+	PassphraseFile string   `name:"key-passphrase-file" help:"File with the key passphrase or PKCS#12 password (default: $GOG_MAIL_KEY_PASSPHRASE)"`
 }
 
 const (
 	gmailFormatFull     = "full"
 	gmailFormatMetadata = "metadata"
+	gmailFormatMinimal  = "minimal"
 	gmailFormatRaw      = "raw"
 )
 
@@ -44,6 +54,9 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 	default:
 		return fmt.Errorf("invalid --format: %q (expected full|metadata|raw)", format)
 	}
+	if c.Decrypt && strings.TrimSpace(c.DecryptKey) == "" {
+		return usage("--decrypt requires --decrypt-key")
+	}
 
 	svc, err := newGmailService(ctx, account)
 	if err != nil {
@@ -67,6 +80,14 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
+	var security *mailcrypt.Result
+	if c.Verify || c.Decrypt {
+		security, err = c.inspectSecurity(ctx, svc, msg)
+		if err != nil {
+			return err
+		}
+	}
+
 	unsubscribe := bestUnsubscribeLink(msg.Payload)
 	if outfmt.IsJSON(ctx) {
 		// Include a flattened headers map for easier querying
@@ -91,6 +112,12 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 				payload["body"] = body
 			}
 		}
+		if security != nil {
+			payload["security"] = mailSecurityOutput(security)
+			if body := mailSecurityBody(security); body != "" && security.Decrypted {
+				payload["body"] = body
+			}
+		}
 		if format == gmailFormatFull || format == gmailFormatMetadata {
 			attachments := collectAttachments(msg.Payload)
 			if len(attachments) > 0 {
@@ -106,6 +133,9 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	switch format {
 	case gmailFormatRaw:
+		if security != nil {
+			printMailSecurity(u, security)
+		}
 		if msg.Raw == "" {
 			u.Err().Println("Empty raw message")
 			return nil
@@ -127,6 +157,9 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		if unsubscribe != "" {
 			u.Out().Printf("unsubscribe\t%s", unsubscribe)
 		}
+		if security != nil {
+			printMailSecurity(u, security)
+		}
 		attachments := attachmentOutputs(collectAttachments(msg.Payload))
 		if len(attachments) > 0 {
 			u.Out().Println("")
@@ -134,6 +167,9 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		}
 		if format == gmailFormatFull {
 			body := bestBodyText(msg.Payload)
+			if security != nil && security.Decrypted {
+				body = mailSecurityBody(security)
+			}
 			if body != "" {
 				u.Out().Println("")
 				u.Out().Println(body)
@@ -144,3 +180,25 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return nil
 	}
 }
+
+// inspectSecurity verifies/decrypts the message locally; it needs the raw
+// RFC 822 bytes, so non-raw formats trigger a second fetch.
+func (c *GmailGetCmd) inspectSecurity(ctx context.Context, svc *gmail.Service, msg *gmail.Message) (*mailcrypt.Result, error) {
+	rawMsg := msg
+	if msg.Raw == "" {
+		var err error
+		rawMsg, err = svc.Users.Messages.Get("me", msg.Id).Format(gmailFormatRaw).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(rawMsg.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode raw message: %w", err)
+	}
+	decryptKey := ""
+	if c.Decrypt {
+		decryptKey = c.DecryptKey
+	}
+	return inspectGmailSecurity(raw, decryptKey, c.TrustKeys, c.PassphraseFile)
+}
@@ -40,6 +40,14 @@ type mailOptions struct {
 	References        string
 	AdditionalHeaders map[string]string
 	Attachments       []mailAttachment
+	// Security signs and/or encrypts the MIME body after it is built.
+	Security mailSecurity
+}
+
+// mailSecurity wraps a complete MIME entity (content headers, blank line, body)
+// into a signed or encrypted entity.
+type mailSecurity interface {
+	Wrap(entity []byte) ([]byte, error)
 }
 
 func buildRFC822(opts mailOptions, cfg *rfc822Config) ([]byte, error) {
@@ -116,6 +124,25 @@ func buildRFC822(opts mailOptions, cfg *rfc822Config) ([]byte, error) {
 		}
 	}
 
+	var entity bytes.Buffer
+	if err := writeMIMEEntity(&entity, opts); err != nil {
+		return nil, err
+	}
+	if opts.Security != nil {
+		wrapped, err := opts.Security.Wrap(entity.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		b.Write(wrapped)
+		return b.Bytes(), nil
+	}
+	b.Write(entity.Bytes())
+	return b.Bytes(), nil
+}
+
+// writeMIMEEntity writes the content headers and body of the message (the part
+// that signing and encryption operate on).
+func writeMIMEEntity(b *bytes.Buffer, opts mailOptions) error {
 	plainBody := normalizeCRLF(opts.Body)
 	htmlBody := normalizeCRLF(opts.BodyHTML)
 	hasPlain := strings.TrimSpace(plainBody) != ""
@@ -126,58 +153,60 @@ func buildRFC822(opts mailOptions, cfg *rfc822Config) ([]byte, error) {
 		case hasPlain && hasHTML:
 			altBoundary, err := randomBoundary()
 			if err != nil {
-				return nil, err
+				return err
 			}
-			writeHeader(&b, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
+			writeHeader(b, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
 			b.WriteString("\r\n")
 
-			writeTextPart(&b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody)
-			writeTextPart(&b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody)
-			fmt.Fprintf(&b, "--%s--\r\n", altBoundary)
-			return b.Bytes(), nil
+			writeTextPart(b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody, opts.Security != nil)
+			writeTextPart(b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody, opts.Security != nil)
+			fmt.Fprintf(b, "--%s--\r\n", altBoundary)
+			return nil
 		case hasHTML && !hasPlain:
-			writeHeader(&b, "Content-Type", "text/html; charset=\"utf-8\"")
-			writeHeader(&b, "Content-Transfer-Encoding", textTransferEncoding(htmlBody))
+			writeHeader(b, "Content-Type", "text/html; charset=\"utf-8\"")
+			encoding := htmlTransferEncoding(htmlBody, opts.Security != nil)
+			writeHeader(b, "Content-Transfer-Encoding", encoding)
 			b.WriteString("\r\n")
-			writeBodyWithTrailingCRLF(&b, htmlBody)
-			return b.Bytes(), nil
+			writeEncodedTextBody(b, encoding, htmlBody)
+			return nil
 		default:
-			writeHeader(&b, "Content-Type", "text/plain; charset=\"utf-8\"")
-			writeHeader(&b, "Content-Transfer-Encoding", "quoted-printable")
+			writeHeader(b, "Content-Type", "text/plain; charset=\"utf-8\"")
+			writeHeader(b, "Content-Transfer-Encoding", "quoted-printable")
 			b.WriteString("\r\n")
-			writeQuotedPrintableBody(&b, plainBody)
-			return b.Bytes(), nil
+			writeQuotedPrintableBody(b, plainBody)
+			return nil
 		}
 	}
 
 	mixedBoundary, err := randomBoundary()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	writeHeader(&b, "Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", mixedBoundary))
+	writeHeader(b, "Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", mixedBoundary))
 	b.WriteString("\r\n")
 
 	// Body part
-	fmt.Fprintf(&b, "--%s\r\n", mixedBoundary)
+	fmt.Fprintf(b, "--%s\r\n", mixedBoundary)
 	switch {
 	case hasPlain && hasHTML:
 		altBoundary, err := randomBoundary()
 		if err != nil {
-			return nil, err
+			return err
 		}
-		fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", altBoundary)
-		writeTextPart(&b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody)
-		writeTextPart(&b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody)
-		fmt.Fprintf(&b, "--%s--\r\n", altBoundary)
+		fmt.Fprintf(b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", altBoundary)
+		writeTextPart(b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody, opts.Security != nil)
+		writeTextPart(b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody, opts.Security != nil)
+		fmt.Fprintf(b, "--%s--\r\n", altBoundary)
 	case hasHTML && !hasPlain:
 		b.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
-		fmt.Fprintf(&b, "Content-Transfer-Encoding: %s\r\n\r\n", textTransferEncoding(htmlBody))
-		writeBodyWithTrailingCRLF(&b, htmlBody)
+		encoding := htmlTransferEncoding(htmlBody, opts.Security != nil)
+		fmt.Fprintf(b, "Content-Transfer-Encoding: %s\r\n\r\n", encoding)
+		writeEncodedTextBody(b, encoding, htmlBody)
 	default:
 		b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
 		b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
-		writeQuotedPrintableBody(&b, plainBody)
+		writeQuotedPrintableBody(b, plainBody)
 	}
 
 	// Attachments
@@ -194,21 +223,21 @@ func buildRFC822(opts mailOptions, cfg *rfc822Config) ([]byte, error) {
 		if len(a.Data) == 0 {
 			data, err := os.ReadFile(a.Path)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			a.Data = data
 		}
 
-		fmt.Fprintf(&b, "\r\n--%s\r\n", mixedBoundary)
-		fmt.Fprintf(&b, "Content-Type: %s\r\n", a.MIMEType)
+		fmt.Fprintf(b, "\r\n--%s\r\n", mixedBoundary)
+		fmt.Fprintf(b, "Content-Type: %s\r\n", a.MIMEType)
 		b.WriteString("Content-Transfer-Encoding: base64\r\n")
-		fmt.Fprintf(&b, "Content-Disposition: attachment; %s\r\n\r\n", contentDispositionFilename(a.Filename))
+		fmt.Fprintf(b, "Content-Disposition: attachment; %s\r\n\r\n", contentDispositionFilename(a.Filename))
 		b.WriteString(wrapBase64(a.Data))
 		b.WriteString("\r\n")
 	}
 
-	fmt.Fprintf(&b, "--%s--\r\n", mixedBoundary)
-	return b.Bytes(), nil
+	fmt.Fprintf(b, "--%s--\r\n", mixedBoundary)
+	return nil
 }
 
 func writeHeader(b *bytes.Buffer, name, value string) {
@@ -300,18 +329,27 @@ func writeBodyWithTrailingCRLF(b *bytes.Buffer, body string) {
 	}
 }
 
-func writeTextPart(b *bytes.Buffer, boundary string, contentType string, body string) {
+func writeTextPart(b *bytes.Buffer, boundary string, contentType string, body string, protected bool) {
 	_, _ = fmt.Fprintf(b, "--%s\r\n", boundary)
 	_, _ = fmt.Fprintf(b, "Content-Type: %s\r\n", contentType)
 	if strings.HasPrefix(contentType, "text/plain") {
 		b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
 		writeQuotedPrintableBody(b, body)
 	} else {
-		_, _ = fmt.Fprintf(b, "Content-Transfer-Encoding: %s\r\n\r\n", textTransferEncoding(body))
-		writeBodyWithTrailingCRLF(b, body)
+		encoding := htmlTransferEncoding(body, protected)
+		_, _ = fmt.Fprintf(b, "Content-Transfer-Encoding: %s\r\n\r\n", encoding)
+		writeEncodedTextBody(b, encoding, body)
 	}
 }
 
+func writeEncodedTextBody(b *bytes.Buffer, encoding string, body string) {
+	if encoding == "quoted-printable" {
+		writeQuotedPrintableBody(b, body)
+		return
+	}
+	writeBodyWithTrailingCRLF(b, body)
+}
+
 func textTransferEncoding(body string) string {
 	if isASCII(body) {
 		return "7bit"
@@ -320,6 +358,16 @@ func textTransferEncoding(body string) string {
 	return "8bit"
 }
 
+// htmlTransferEncoding keeps HTML readable on the wire, except for signed or
+// encrypted messages: those must be 7-bit clean so relays cannot re-encode the
+// signed bytes (RFC 1847).
+func htmlTransferEncoding(body string, protected bool) string {
+	if protected {
+		return "quoted-printable"
+	}
+	return textTransferEncoding(body)
+}
+
 func randomBoundary() (string, error) {
 	var b [18]byte
 	if _, err := rand.Read(b[:]); err != nil {
@@ -30,6 +30,8 @@ type GmailSendCmd struct {
 	Track            bool     `name:"track" help:"Enable open tracking (requires tracking setup)"`
 	TrackSplit       bool     `name:"track-split" help:"Send tracked messages separately per recipient"`
 	Quote            bool     `name:"quote" help:"Include quoted original message in reply (requires --reply-to-message-id or --thread-id)"`
+
+	Security MailSecurityFlags `embed:""`
 }
 
 type sendBatch struct {
@@ -57,6 +59,7 @@ type sendMessageOptions struct {
 	Attachments []mailAttachment
 	Track       bool
 	TrackingCfg *tracking.Config
+	Security    mailSecurity
 }
 
 func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -100,13 +103,19 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if c.Track && strings.TrimSpace(c.BodyHTML) == "" {
 		return fmt.Errorf("--track requires --body-html (pixel must be in HTML)")
 	}
+	if c.Track && c.Security.Encrypt {
+		return usage("--track cannot be combined with --encrypt")
+	}
+	if err := c.Security.validate(); err != nil {
+		return err
+	}
 
 	attachPaths, err := expandComposeAttachmentPaths(c.Attach)
 	if err != nil {
 		return err
 	}
 
-	if dryRunErr := dryRunExit(ctx, flags, "gmail.send", map[string]any{
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.send", c.Security.dryRunFields(map[string]any{
 		"to":                  splitCSV(c.To),
 		"cc":                  splitCSV(c.Cc),
 		"bcc":                 splitCSV(c.Bcc),
@@ -121,7 +130,7 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		"attachments":         attachPaths,
 		"track":               c.Track,
 		"track_split":         c.TrackSplit,
-	}); dryRunErr != nil {
+	})); dryRunErr != nil {
 		return dryRunErr
 	}
 
@@ -171,8 +180,7 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		}
 	}
 
-	batches := buildSendBatches(toRecipients, ccRecipients, bccRecipients, c.Track, c.TrackSplit)
-	results, err := sendGmailBatches(ctx, svc, sendMessageOptions{
+	opts := sendMessageOptions{
 		FromAddr:    from.header,
 		ReplyTo:     c.ReplyTo,
 		Subject:     c.Subject,
@@ -182,7 +190,13 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		Attachments: atts,
 		Track:       c.Track,
 		TrackingCfg: trackingCfg,
-	}, batches)
+	}
+	if err = c.Security.apply(&opts, composeRecipients(toRecipients, ccRecipients, bccRecipients)); err != nil {
+		return err
+	}
+
+	batches := buildSendBatches(toRecipients, ccRecipients, bccRecipients, c.Track, c.TrackSplit)
+	results, err := sendGmailBatches(ctx, svc, opts, batches)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,614 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailStatsListPageSize   = 500
+	gmailStatsMaxConcurrency = 10
+)
+
+type GmailStatsCmd struct {
+	Query    string `name:"query" aliases:"q" help:"Gmail query to restrict the analyzed messages"`
+	Since    string `name:"since" help:"Only analyze messages newer than this (duration like 90d/2w/24h, date YYYY-MM-DD, or RFC3339)" default:"90d"`
+	Max      int64  `name:"max" aliases:"limit" help:"Max messages to analyze (0 = no limit)" default:"5000"`
+	Top      int    `name:"top" help:"Rows per ranking (senders, domains, largest messages/attachments)" default:"10"`
+	Timezone string `name:"timezone" short:"z" help:"Timezone for weekly buckets (IANA name, e.g. America/New_York, UTC). Default: local"`
+	Local    bool   `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
+}
+
+type gmailStatsInput struct {
+	Query string
+	Since time.Time
+	Max   int64
+	Top   int
+	Loc   *time.Location
+	Now   time.Time
+}
+
+type gmailStatsCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+type gmailStatsWeek struct {
+	Week  string `json:"week"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+type gmailStatsMessage struct {
+	ID        string `json:"id"`
+	ThreadID  string `json:"threadId,omitempty"`
+	Date      string `json:"date,omitempty"`
+	From      string `json:"from,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	Size      int64  `json:"size"`
+	SizeHuman string `json:"sizeHuman"`
+}
+
+type gmailStatsAttachment struct {
+	MessageID string `json:"messageId"`
+	Filename  string `json:"filename"`
+	MimeType  string `json:"mimeType,omitempty"`
+	Size      int64  `json:"size"`
+	SizeHuman string `json:"sizeHuman"`
+}
+
+type gmailStatsUnread struct {
+	Count   int            `json:"count"`
+	Oldest  string         `json:"oldest,omitempty"`
+	AgeDays map[string]int `json:"ageDays"`
+}
+
+type gmailStatsReport struct {
+	Query              string                 `json:"query"`
+	Since              string                 `json:"since,omitempty"`
+	Messages           int                    `json:"messages"`
+	TotalBytes         int64                  `json:"totalBytes"`
+	TotalHuman         string                 `json:"totalHuman"`
+	Truncated          bool                   `json:"truncated,omitempty"`
+	TopSenders         []gmailStatsCount      `json:"topSenders"`
+	TopDomains         []gmailStatsCount      `json:"topDomains"`
+	Weekly             []gmailStatsWeek       `json:"weekly"`
+	LargestMessages    []gmailStatsMessage    `json:"largestMessages"`
+	LargestAttachments []gmailStatsAttachment `json:"largestAttachments"`
+	Unread             gmailStatsUnread       `json:"unread"`
+}
+
+func (c *GmailStatsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	if c.Top <= 0 {
+		return usage("--top must be > 0")
+	}
+
+	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	input := gmailStatsInput{
+		Query: strings.TrimSpace(c.Query),
+		Max:   c.Max,
+		Top:   c.Top,
+		Loc:   loc,
+		Now:   now,
+	}
+	if since := strings.TrimSpace(c.Since); since != "" {
+		parsed, parseErr := timeparse.ParseSince(since, now, loc)
+		if parseErr != nil {
+			return usagef("invalid --since %q (use duration like 90d or 24h, date YYYY-MM-DD, or RFC3339)", since)
+		}
+		input.Since = parsed.Time
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	report, err := runGmailStats(ctx, svc, input)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"stats": report})
+	}
+	if report.Messages == 0 {
+		u.Err().Println("No messages")
+		return nil
+	}
+	printGmailStats(ctx, u, report)
+	return nil
+}
+
+func runGmailStats(ctx context.Context, svc *gmail.Service, input gmailStatsInput) (gmailStatsReport, error) {
+	query := gmailStatsQuery(input.Query, input.Since)
+	report := gmailStatsReport{Query: query}
+	if !input.Since.IsZero() {
+		report.Since = input.Since.Format(time.RFC3339)
+	}
+
+	ids, truncated, err := listGmailMessageIDs(ctx, svc, query, input.Max)
+	if err != nil {
+		return report, err
+	}
+	report.Truncated = truncated
+	if len(ids) == 0 {
+		report.TopSenders = []gmailStatsCount{}
+		report.TopDomains = []gmailStatsCount{}
+		report.Weekly = []gmailStatsWeek{}
+		report.LargestMessages = []gmailStatsMessage{}
+		report.LargestAttachments = []gmailStatsAttachment{}
+		report.Unread.AgeDays = map[string]int{}
+		return report, nil
+	}
+
+	idToName, err := fetchLabelIDToName(svc)
+	if err != nil {
+		return report, err
+	}
+
+	messages, err := fetchGmailStatsMetadata(ctx, svc, ids)
+	if err != nil {
+		return report, err
+	}
+
+	agg := newGmailStatsAggregator(idToName, input.Loc, input.Now)
+	for _, msg := range messages {
+		agg.add(msg)
+	}
+	agg.fill(&report, input.Top)
+
+	report.LargestAttachments, err = fetchGmailStatsAttachments(ctx, svc, agg.largest, input.Top)
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func gmailStatsQuery(query string, since time.Time) string {
+	query = strings.TrimSpace(query)
+	if since.IsZero() {
+		return query
+	}
+	after := fmt.Sprintf("after:%d", since.Unix())
+	if query == "" {
+		return after
+	}
+	// Parenthesize so the bound applies to every branch of an OR query.
+	return "(" + query + ") " + after
+}
+
+func listGmailMessageIDs(ctx context.Context, svc *gmail.Service, query string, maxMessages int64) ([]string, bool, error) {
+	var ids []string
+	pageToken := ""
+	for {
+		pageSize := int64(gmailStatsListPageSize)
+		if maxMessages > 0 && maxMessages-int64(len(ids)) < pageSize {
+			pageSize = maxMessages - int64(len(ids))
+		}
+		call := svc.Users.Messages.List("me").
+			MaxResults(pageSize).
+			Fields("messages(id),nextPageToken").
+			Context(ctx)
+		if query != "" {
+			call = call.Q(query)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, false, err
+		}
+		for _, m := range resp.Messages {
+			if m != nil && m.Id != "" {
+				ids = append(ids, m.Id)
+			}
+		}
+		if resp.NextPageToken == "" {
+			return ids, false, nil
+		}
+		if maxMessages > 0 && int64(len(ids)) >= maxMessages {
+			return ids, true, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// runGmailStatsWorkers calls fn for indices 0..n-1 on at most
+// gmailStatsMaxConcurrency goroutines and returns the first error by index.
+func runGmailStatsWorkers(ctx context.Context, n int, fn func(i int) error) error {
+	jobs := make(chan int)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for range min(n, gmailStatsMaxConcurrency) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+feed:
+	for i := range n {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func fetchGmailStatsMetadata(ctx context.Context, svc *gmail.Service, ids []string) ([]*gmail.Message, error) {
+	out := make([]*gmail.Message, len(ids))
+	err := runGmailStatsWorkers(ctx, len(ids), func(i int) error {
+		msg, err := svc.Users.Messages.Get("me", ids[i]).
+			Format(gmailFormatMetadata).
+			MetadataHeaders("From", "Subject", "Date").
+			Fields("id,threadId,labelIds,sizeEstimate,internalDate,payload(headers)").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return fmt.Errorf("message %s: %w", ids[i], err)
+		}
+		out[i] = msg
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// fetchGmailStatsAttachments ranks attachments across all analyzed messages.
+// Messages are scanned largest first, a batch at a time; an attachment is
+// never larger than its message, so the scan stops once the next message is
+// smaller than the smallest attachment in a full top list.
+func fetchGmailStatsAttachments(ctx context.Context, svc *gmail.Service, messages []gmailStatsMessage, top int) ([]gmailStatsAttachment, error) {
+	sorted := append([]gmailStatsMessage(nil), messages...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Size > sorted[j].Size
+	})
+
+	out := []gmailStatsAttachment{}
+	for lo := 0; lo < len(sorted); lo += gmailStatsMaxConcurrency {
+		if len(out) >= top && sorted[lo].Size < out[len(out)-1].Size {
+			break
+		}
+		batch := sorted[lo:min(lo+gmailStatsMaxConcurrency, len(sorted))]
+		found := make([][]gmailStatsAttachment, len(batch))
+		err := runGmailStatsWorkers(ctx, len(batch), func(i int) error {
+			id := batch[i].ID
+			msg, err := svc.Users.Messages.Get("me", id).
+				Format(gmailFormatFull).
+				Fields("id,payload").
+				Context(ctx).
+				Do()
+			if err != nil {
+				return fmt.Errorf("message %s: %w", id, err)
+			}
+			for _, a := range collectAttachments(msg.Payload) {
+				found[i] = append(found[i], gmailStatsAttachment{
+					MessageID: id,
+					Filename:  a.Filename,
+					MimeType:  a.MimeType,
+					Size:      a.Size,
+					SizeHuman: formatBytes(a.Size),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			out = append(out, f...)
+		}
+		out = topGmailStatsAttachments(out, top)
+	}
+	return out, nil
+}
+
+func topGmailStatsAttachments(attachments []gmailStatsAttachment, top int) []gmailStatsAttachment {
+	sort.SliceStable(attachments, func(i, j int) bool {
+		return attachments[i].Size > attachments[j].Size
+	})
+	if len(attachments) > top {
+		attachments = attachments[:top]
+	}
+	if attachments == nil {
+		return []gmailStatsAttachment{}
+	}
+	return attachments
+}
+
+type gmailStatsAggregator struct {
+	idToName map[string]string
+	loc      *time.Location
+	now      time.Time
+
+	messages   int
+	totalBytes int64
+	senders    map[string]*gmailStatsCount
+	domains    map[string]*gmailStatsCount
+	weekly     map[[2]string]int
+	largest    []gmailStatsMessage
+	unread     int
+	oldest     time.Time
+	ageBuckets map[string]int
+}
+
+// Unread age buckets, in ascending order of age.
+var gmailStatsAgeBuckets = []struct {
+	name string
+	max  time.Duration
+}{
+	{name: "<1d", max: 24 * time.Hour},
+	{name: "1-7d", max: 7 * 24 * time.Hour},
+	{name: "7-30d", max: 30 * 24 * time.Hour},
+	{name: "30-90d", max: 90 * 24 * time.Hour},
+	{name: ">90d", max: 0},
+}
+
+func newGmailStatsAggregator(idToName map[string]string, loc *time.Location, now time.Time) *gmailStatsAggregator {
+	if loc == nil {
+		loc = time.Local
+	}
+	buckets := make(map[string]int, len(gmailStatsAgeBuckets))
+	for _, b := range gmailStatsAgeBuckets {
+		buckets[b.name] = 0
+	}
+	return &gmailStatsAggregator{
+		idToName:   idToName,
+		loc:        loc,
+		now:        now,
+		senders:    map[string]*gmailStatsCount{},
+		domains:    map[string]*gmailStatsCount{},
+		weekly:     map[[2]string]int{},
+		ageBuckets: buckets,
+	}
+}
+
+func (a *gmailStatsAggregator) add(msg *gmail.Message) {
+	if msg == nil || msg.Id == "" {
+		return
+	}
+	a.messages++
+	a.totalBytes += msg.SizeEstimate
+
+	from := headerValue(msg.Payload, "From")
+	sender := "(unknown)"
+	if addrs := parseEmailAddresses(from); len(addrs) > 0 {
+		sender = addrs[0]
+	}
+	bumpGmailStatsCount(a.senders, sender, msg.SizeEstimate)
+	domain := "(unknown)"
+	if at := strings.LastIndex(sender, "@"); at >= 0 && at < len(sender)-1 {
+		domain = sender[at+1:]
+	}
+	bumpGmailStatsCount(a.domains, domain, msg.SizeEstimate)
+
+	date := gmailStatsMessageTime(msg)
+	if !date.IsZero() {
+		week := startOfWeek(date.In(a.loc), time.Monday).Format("2006-01-02")
+		for _, id := range msg.LabelIds {
+			a.weekly[[2]string{week, a.labelName(id)}]++
+		}
+	}
+
+	dateText := ""
+	if !date.IsZero() {
+		dateText = date.In(a.loc).Format(time.RFC3339)
+	}
+	a.largest = append(a.largest, gmailStatsMessage{
+		ID:        msg.Id,
+		ThreadID:  msg.ThreadId,
+		Date:      dateText,
+		From:      sanitizeTab(from),
+		Subject:   sanitizeTab(headerValue(msg.Payload, "Subject")),
+		Size:      msg.SizeEstimate,
+		SizeHuman: formatBytes(msg.SizeEstimate),
+	})
+
+	if hasMessageLabel(msg, "UNREAD") {
+		a.unread++
+		if !date.IsZero() {
+			if a.oldest.IsZero() || date.Before(a.oldest) {
+				a.oldest = date
+			}
+			a.ageBuckets[gmailStatsAgeBucket(a.now.Sub(date))]++
+		}
+	}
+}
+
+func (a *gmailStatsAggregator) labelName(id string) string {
+	if name, ok := a.idToName[id]; ok {
+		return name
+	}
+	return id
+}
+
+func (a *gmailStatsAggregator) fill(report *gmailStatsReport, top int) {
+	report.Messages = a.messages
+	report.TotalBytes = a.totalBytes
+	report.TotalHuman = formatBytes(a.totalBytes)
+	report.TopSenders = topGmailStatsCounts(a.senders, top)
+	report.TopDomains = topGmailStatsCounts(a.domains, top)
+
+	weekly := make([]gmailStatsWeek, 0, len(a.weekly))
+	for key, count := range a.weekly {
+		weekly = append(weekly, gmailStatsWeek{Week: key[0], Label: key[1], Count: count})
+	}
+	sort.Slice(weekly, func(i, j int) bool {
+		if weekly[i].Week != weekly[j].Week {
+			return weekly[i].Week < weekly[j].Week
+		}
+		return weekly[i].Label < weekly[j].Label
+	})
+	report.Weekly = weekly
+
+	largest := append([]gmailStatsMessage(nil), a.largest...)
+	sort.SliceStable(largest, func(i, j int) bool {
+		return largest[i].Size > largest[j].Size
+	})
+	if len(largest) > top {
+		largest = largest[:top]
+	}
+	report.LargestMessages = largest
+
+	report.Unread = gmailStatsUnread{Count: a.unread, AgeDays: a.ageBuckets}
+	if !a.oldest.IsZero() {
+		report.Unread.Oldest = a.oldest.In(a.loc).Format(time.RFC3339)
+	}
+}
+
+func bumpGmailStatsCount(m map[string]*gmailStatsCount, key string, size int64) {
+	entry, ok := m[key]
+	if !ok {
+		entry = &gmailStatsCount{Key: key}
+		m[key] = entry
+	}
+	entry.Count++
+	entry.Bytes += size
+}
+
+func topGmailStatsCounts(m map[string]*gmailStatsCount, top int) []gmailStatsCount {
+	out := make([]gmailStatsCount, 0, len(m))
+	for _, entry := range m {
+		out = append(out, *entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	if len(out) > top {
+		out = out[:top]
+	}
+	return out
+}
+
+func gmailStatsMessageTime(msg *gmail.Message) time.Time {
+	if msg.InternalDate > 0 {
+		return time.UnixMilli(msg.InternalDate)
+	}
+	if t, err := mailParseDate(headerValue(msg.Payload, "Date")); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+func gmailStatsAgeBucket(age time.Duration) string {
+	for _, b := range gmailStatsAgeBuckets {
+		if b.max == 0 || age < b.max {
+			return b.name
+		}
+	}
+	return gmailStatsAgeBuckets[len(gmailStatsAgeBuckets)-1].name
+}
+
+func printGmailStats(ctx context.Context, u *ui.UI, report gmailStatsReport) {
+	u.Out().Printf("messages\t%d", report.Messages)
+	u.Out().Printf("total_size\t%s", report.TotalHuman)
+	if report.Since != "" {
+		u.Out().Printf("since\t%s", report.Since)
+	}
+	if report.Truncated {
+		u.Err().Println("# Results truncated by --max; increase it to analyze more messages")
+	}
+
+	u.Out().Println("")
+	u.Out().Println("TOP SENDERS")
+	printGmailStatsCounts(ctx, "SENDER", report.TopSenders)
+
+	u.Out().Println("")
+	u.Out().Println("TOP DOMAINS")
+	printGmailStatsCounts(ctx, "DOMAIN", report.TopDomains)
+
+	u.Out().Println("")
+	u.Out().Println("WEEKLY VOLUME")
+	func() {
+		w, flush := tableWriter(ctx)
+		defer flush()
+		fmt.Fprintln(w, "WEEK\tLABEL\tCOUNT")
+		for _, row := range report.Weekly {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", row.Week, row.Label, row.Count)
+		}
+	}()
+
+	u.Out().Println("")
+	u.Out().Println("LARGEST MESSAGES")
+	func() {
+		w, flush := tableWriter(ctx)
+		defer flush()
+		fmt.Fprintln(w, "ID\tSIZE\tDATE\tFROM\tSUBJECT")
+		for _, m := range report.LargestMessages {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.ID, m.SizeHuman, m.Date, m.From, m.Subject)
+		}
+	}()
+
+	u.Out().Println("")
+	u.Out().Println("LARGEST ATTACHMENTS")
+	func() {
+		w, flush := tableWriter(ctx)
+		defer flush()
+		fmt.Fprintln(w, "MESSAGE\tSIZE\tMIME\tFILENAME")
+		for _, a := range report.LargestAttachments {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.MessageID, a.SizeHuman, a.MimeType, sanitizeTab(a.Filename))
+		}
+	}()
+
+	u.Out().Println("")
+	u.Out().Println("UNREAD BACKLOG")
+	u.Out().Printf("unread\t%d", report.Unread.Count)
+	if report.Unread.Oldest != "" {
+		u.Out().Printf("oldest\t%s", report.Unread.Oldest)
+	}
+	for _, b := range gmailStatsAgeBuckets {
+		u.Out().Printf("age_%s\t%d", b.name, report.Unread.AgeDays[b.name])
+	}
+}
+
+func printGmailStatsCounts(ctx context.Context, keyHeader string, rows []gmailStatsCount) {
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintf(w, "%s\tCOUNT\tSIZE\n", keyHeader)
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", sanitizeTab(row.Key), row.Count, formatBytes(row.Bytes))
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGmailStatsQuery(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	if got := gmailStatsQuery("", time.Time{}); got != "" {
+		t.Fatalf("unexpected empty query: %q", got)
+	}
+	if got := gmailStatsQuery("", since); got != "after:1700000000" {
+		t.Fatalf("unexpected since-only query: %q", got)
+	}
+	if got := gmailStatsQuery("from:a OR from:b", since); got != "(from:a OR from:b) after:1700000000" {
+		t.Fatalf("unexpected OR query: %q", got)
+	}
+	if got := gmailStatsQuery(" in:inbox ", since); got != "(in:inbox) after:1700000000" {
+		t.Fatalf("unexpected combined query: %q", got)
+	}
+}
+
+func TestGmailStatsAggregator(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	agg := newGmailStatsAggregator(map[string]string{"Label_1": "Receipts"}, time.UTC, now)
+
+	msg := func(id, from string, size int64, at time.Time, labels ...string) *gmail.Message {
+		return &gmail.Message{
+			Id:           id,
+			LabelIds:     labels,
+			SizeEstimate: size,
+			InternalDate: at.UnixMilli(),
+			Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{
+				{Name: "From", Value: from},
+				{Name: "Subject", Value: "subject " + id},
+			}},
+		}
+	}
+	agg.add(msg("m1", "Shop <orders@shop.example>", 100, now.Add(-2*time.Hour), "INBOX", "UNREAD", "Label_1"))
+	agg.add(msg("m2", "orders@shop.example", 5000, now.Add(-10*24*time.Hour), "INBOX"))
+	agg.add(msg("m3", "Alice <alice@corp.example>", 300, now.Add(-40*24*time.Hour), "UNREAD"))
+	agg.add(nil)
+
+	var report gmailStatsReport
+	agg.fill(&report, 2)
+
+	if report.Messages != 3 || report.TotalBytes != 5400 {
+		t.Fatalf("unexpected totals: %#v", report)
+	}
+	if len(report.TopSenders) != 2 || report.TopSenders[0].Key != "orders@shop.example" || report.TopSenders[0].Count != 2 {
+		t.Fatalf("unexpected top senders: %#v", report.TopSenders)
+	}
+	if report.TopDomains[0].Key != "shop.example" || report.TopDomains[0].Bytes != 5100 {
+		t.Fatalf("unexpected top domains: %#v", report.TopDomains)
+	}
+	if len(report.LargestMessages) != 2 || report.LargestMessages[0].ID != "m2" || report.LargestMessages[1].ID != "m3" {
+		t.Fatalf("unexpected largest messages: %#v", report.LargestMessages)
+	}
+	if report.Unread.Count != 2 || report.Unread.AgeDays["<1d"] != 1 || report.Unread.AgeDays["30-90d"] != 1 {
+		t.Fatalf("unexpected unread stats: %#v", report.Unread)
+	}
+	if report.Unread.Oldest != now.Add(-40*24*time.Hour).Format(time.RFC3339) {
+		t.Fatalf("unexpected oldest unread: %q", report.Unread.Oldest)
+	}
+
+	found := false
+	for _, row := range report.Weekly {
+		if row.Week == "2026-03-16" && row.Label == "Receipts" && row.Count == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected Receipts weekly bucket, got %#v", report.Weekly)
+	}
+}
+
+func TestRunGmailStats_AggregatesAcrossPages(t *testing.T) {
+	var queries []string
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/users/me/messages" && r.Method == http.MethodGet:
+			queries = append(queries, r.URL.Query().Get("q"))
+			if r.URL.Query().Get("pageToken") == "" {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"messages":      []map[string]any{{"id": "m1"}},
+					"nextPageToken": "p2",
+				})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"messages": []map[string]any{{"id": "m2"}},
+			})
+		case strings.HasPrefix(path, "/users/me/labels"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"labels": []map[string]any{{"id": "INBOX", "name": "INBOX", "type": "system"}},
+			})
+		case path == "/users/me/messages/m1" || path == "/users/me/messages/m2":
+			id := strings.TrimPrefix(path, "/users/me/messages/")
+			size := 10
+			if id == "m2" {
+				size = 2048
+			}
+			resp := map[string]any{
+				"id":           id,
+				"threadId":     "t-" + id,
+				"labelIds":     []string{"INBOX"},
+				"sizeEstimate": size,
+				"internalDate": "1773921600000",
+				"payload": map[string]any{
+					"headers": []map[string]any{{"name": "From", "value": "a@example.com"}},
+				},
+			}
+			if r.URL.Query().Get("format") == "full" {
+				resp["payload"] = map[string]any{
+					"parts": []map[string]any{{
+						"filename": "report.pdf",
+						"mimeType": "application/pdf",
+						"body":     map[string]any{"attachmentId": "att-" + id, "size": size - 5},
+					}},
+				}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	report, err := runGmailStats(context.Background(), svc, gmailStatsInput{
+		Query: "has:attachment",
+		Since: time.Unix(1700000000, 0),
+		Top:   5,
+		Loc:   time.UTC,
+		Now:   time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("runGmailStats: %v", err)
+	}
+	if len(queries) != 2 || queries[0] != "(has:attachment) after:1700000000" {
+		t.Fatalf("unexpected list queries: %#v", queries)
+	}
+	if report.Messages != 2 || report.TopSenders[0].Count != 2 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+	if len(report.LargestAttachments) != 2 || report.LargestAttachments[0].MessageID != "m2" || report.LargestAttachments[0].Size != 2043 {
+		t.Fatalf("unexpected largest attachments: %#v", report.LargestAttachments)
+	}
+}
+
+func TestFetchGmailStatsAttachments_ScansBeyondLargestMessages(t *testing.T) {
+	var mu sync.Mutex
+	var fetched []string
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me/messages/")
+		mu.Lock()
+		fetched = append(fetched, id)
+		mu.Unlock()
+		payload := map[string]any{}
+		if id == "big-attachment" {
+			payload["parts"] = []map[string]any{{
+				"filename": "scan.tiff",
+				"body":     map[string]any{"attachmentId": "att", "size": 2900},
+			}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "payload": payload})
+	})
+	defer cleanup()
+
+	// The largest message has no attachment; the top attachment sits in the
+	// second one. Messages after the first batch are too small to beat it, so
+	// they are never fetched.
+	messages := []gmailStatsMessage{{ID: "tiny", Size: 100}, {ID: "big-attachment", Size: 3000}, {ID: "big-body", Size: 5000}}
+	for i := range 12 {
+		messages = append(messages, gmailStatsMessage{ID: fmt.Sprintf("small-%d", i), Size: 50})
+	}
+	got, err := fetchGmailStatsAttachments(context.Background(), svc, messages, 1)
+	if err != nil {
+		t.Fatalf("fetchGmailStatsAttachments: %v", err)
+	}
+	if len(got) != 1 || got[0].MessageID != "big-attachment" || got[0].Size != 2900 {
+		t.Fatalf("unexpected attachments: %#v", got)
+	}
+	if len(fetched) != gmailStatsMaxConcurrency {
+		t.Fatalf("expected one batch of %d fetches, got %v", gmailStatsMaxConcurrency, fetched)
+	}
+}
+
+func TestListGmailMessageIDs_RespectsMax(t *testing.T) {
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("maxResults"); got != "1" {
+			t.Fatalf("expected maxResults=1, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"messages":      []map[string]any{{"id": "m1"}},
+			"nextPageToken": "more",
+		})
+	})
+	defer cleanup()
+
+	ids, truncated, err := listGmailMessageIDs(context.Background(), svc, "", 1)
+	if err != nil {
+		t.Fatalf("listGmailMessageIDs: %v", err)
+	}
+	if len(ids) != 1 || !truncated {
+		t.Fatalf("expected one truncated id, got %v truncated=%v", ids, truncated)
+	}
+}
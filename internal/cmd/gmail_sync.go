@@ -0,0 +1,614 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/mailindex"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailSyncModeFull        = "full"
+	gmailSyncModeIncremental = "incremental"
+	gmailSyncMaxConcurrency  = 10
+	gmailSyncHistoryPageSize = 500
+)
+
+var gmailSyncHistoryTypes = []string{"messageAdded", "messageDeleted", "labelAdded", "labelRemoved"}
+
+type GmailSyncCmd struct {
+	Query string `name:"query" aliases:"q" help:"Gmail query selecting the messages to index (empty = whole mailbox)"`
+	Max   int64  `name:"max" aliases:"limit" help:"Max messages to pull on a full sync (0 = no limit)" default:"0"`
+	Full  bool   `name:"full" help:"Discard the existing index and pull everything again"`
+	Index string `name:"index" help:"Index file path (default: per-account file in the gog state dir)"`
+}
+
+type GmailLocalCmd struct {
+	Search GmailLocalSearchCmd `cmd:"" name:"search" aliases:"find,query" default:"withargs" help:"Search the local index with Gmail query syntax (subset)"`
+	Status GmailLocalStatusCmd `cmd:"" name:"status" aliases:"info" help:"Show local index status"`
+}
+
+type gmailSyncInput struct {
+	Account string
+	Query   string
+	Max     int64
+	Full    bool
+}
+
+type gmailSyncResult struct {
+	Mode      string `json:"mode"`
+	Query     string `json:"query,omitempty"`
+	Added     int    `json:"added"`
+	Updated   int    `json:"updated"`
+	Deleted   int    `json:"deleted"`
+	Total     int    `json:"total"`
+	HistoryID string `json:"historyId"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+func (c *GmailSyncCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	path, err := resolveGmailIndexPath(c.Index, account)
+	if err != nil {
+		return err
+	}
+	idx, _, err := mailindex.Load(path)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	result, err := runGmailSync(ctx, svc, idx, gmailSyncInput{
+		Account: account,
+		Query:   strings.TrimSpace(c.Query),
+		Max:     c.Max,
+		Full:    c.Full,
+	})
+	if err != nil {
+		return err
+	}
+	if err := idx.Save(path); err != nil {
+		return err
+	}
+	result.Path = path
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"sync": result})
+	}
+	u.Out().Printf("mode\t%s", result.Mode)
+	if result.Reason != "" {
+		u.Out().Printf("reason\t%s", result.Reason)
+	}
+	u.Out().Printf("added\t%d", result.Added)
+	u.Out().Printf("updated\t%d", result.Updated)
+	u.Out().Printf("deleted\t%d", result.Deleted)
+	u.Out().Printf("total\t%d", result.Total)
+	u.Out().Printf("history_id\t%s", result.HistoryID)
+	u.Out().Printf("path\t%s", result.Path)
+	if result.Truncated {
+		u.Err().Println("# Full sync truncated by --max")
+	}
+	return nil
+}
+
+func resolveGmailIndexPath(flagPath, account string) (string, error) {
+	if strings.TrimSpace(flagPath) != "" {
+		return config.ExpandPath(strings.TrimSpace(flagPath))
+	}
+	dir, err := config.EnsureGmailIndexDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sanitizeAccountForPath(account)+".json.gz"), nil
+}
+
+func runGmailSync(ctx context.Context, svc *gmail.Service, idx *mailindex.Index, input gmailSyncInput) (gmailSyncResult, error) {
+	result := gmailSyncResult{Query: input.Query}
+	idToName, err := fetchLabelIDToName(svc)
+	if err != nil {
+		return result, err
+	}
+
+	reason := ""
+	switch {
+	case input.Full:
+		reason = "requested"
+	case idx.HistoryID == "":
+		reason = "empty_index"
+	case !strings.EqualFold(idx.Account, input.Account):
+		reason = "account_changed"
+	case idx.Query != input.Query:
+		reason = "query_changed"
+	}
+
+	if reason == "" {
+		result.Mode = gmailSyncModeIncremental
+		err = syncGmailIndexIncremental(ctx, svc, idx, input, idToName, &result)
+		if err == nil {
+			return finishGmailSync(idx, input, result), nil
+		}
+		if !isStaleHistoryError(err) {
+			return result, err
+		}
+		reason = "history_expired"
+		result = gmailSyncResult{Query: input.Query}
+	}
+
+	result.Mode = gmailSyncModeFull
+	result.Reason = reason
+	if err := syncGmailIndexFull(ctx, svc, idx, input, idToName, &result); err != nil {
+		return result, err
+	}
+	return finishGmailSync(idx, input, result), nil
+}
+
+func finishGmailSync(idx *mailindex.Index, input gmailSyncInput, result gmailSyncResult) gmailSyncResult {
+	idx.Account = input.Account
+	idx.Query = input.Query
+	idx.UpdatedMs = time.Now().UnixMilli()
+	result.HistoryID = idx.HistoryID
+	result.Total = idx.Len()
+	return result
+}
+
+func syncGmailIndexFull(ctx context.Context, svc *gmail.Service, idx *mailindex.Index, input gmailSyncInput, idToName map[string]string, result *gmailSyncResult) error {
+	// Capture the history cursor before listing so changes made during the
+	// pull are replayed by the next incremental sync.
+	profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	ids, truncated, err := listGmailMessageIDs(ctx, svc, input.Query, input.Max)
+	if err != nil {
+		return err
+	}
+	result.Truncated = truncated
+
+	msgs, err := fetchGmailSyncMessages(ctx, svc, ids, gmailFormatFull)
+	if err != nil {
+		return err
+	}
+
+	idx.Reset()
+	for _, msg := range msgs {
+		idx.Put(gmailIndexDocument(msg, idToName))
+	}
+	result.Added = idx.Len()
+	idx.HistoryID = formatHistoryID(profile.HistoryId)
+	return nil
+}
+
+func syncGmailIndexIncremental(ctx context.Context, svc *gmail.Service, idx *mailindex.Index, input gmailSyncInput, idToName map[string]string, result *gmailSyncResult) error {
+	startID, err := parseHistoryID(idx.HistoryID)
+	if err != nil {
+		return err
+	}
+
+	changes, latest, err := listGmailHistoryChanges(ctx, svc, startID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range changes.DeletedIDs {
+		if _, ok := idx.Get(id); ok {
+			idx.Delete(id)
+			result.Deleted++
+		}
+	}
+
+	var known, unknown []string
+	for _, id := range changes.FetchIDs {
+		if _, ok := idx.Get(id); ok {
+			known = append(known, id)
+		} else {
+			unknown = append(unknown, id)
+		}
+	}
+
+	// Label changes on indexed messages only need the label list, but they
+	// can move a message out of the sync query, so re-check those too.
+	refreshed, err := fetchGmailSyncMessages(ctx, svc, known, gmailFormatMinimal)
+	if err != nil {
+		return err
+	}
+	refreshed, err = filterGmailSyncMatches(ctx, svc, input.Query, refreshed)
+	if err != nil {
+		return err
+	}
+	present := make(map[string]struct{}, len(refreshed))
+	for _, msg := range refreshed {
+		present[msg.Id] = struct{}{}
+		if idx.SetLabels(msg.Id, msg.LabelIds, gmailLabelNames(msg.LabelIds, idToName)) {
+			result.Updated++
+		}
+	}
+	for _, id := range known {
+		if _, ok := present[id]; !ok {
+			idx.Delete(id)
+			result.Deleted++
+		}
+	}
+
+	added, err := fetchGmailSyncMessages(ctx, svc, unknown, gmailFormatFull)
+	if err != nil {
+		return err
+	}
+	added, err = filterGmailSyncMatches(ctx, svc, input.Query, added)
+	if err != nil {
+		return err
+	}
+	for _, msg := range added {
+		idx.Put(gmailIndexDocument(msg, idToName))
+		result.Added++
+	}
+
+	if latest != 0 {
+		idx.HistoryID = formatHistoryID(latest)
+	}
+	return nil
+}
+
+func listGmailHistoryChanges(ctx context.Context, svc *gmail.Service, startID uint64) (historyMessageIDs, uint64, error) {
+	merged := &gmail.ListHistoryResponse{}
+	var latest uint64
+	pageToken := ""
+	for {
+		call := svc.Users.History.List("me").
+			StartHistoryId(startID).
+			MaxResults(gmailSyncHistoryPageSize).
+			HistoryTypes(gmailSyncHistoryTypes...).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return historyMessageIDs{}, 0, err
+		}
+		merged.History = append(merged.History, resp.History...)
+		if resp.HistoryId > latest {
+			latest = resp.HistoryId
+		}
+		if resp.NextPageToken == "" {
+			return collectHistoryMessageIDs(merged), latest, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// filterGmailSyncMatches drops fetched messages that do not match the sync
+// query. Gmail has no per-message query check, so the query is listed only
+// over the days the messages fall on (adjacent days merged into one window)
+// and intersected; a label change on an old message costs one day of mail.
+func filterGmailSyncMatches(ctx context.Context, svc *gmail.Service, query string, msgs []*gmail.Message) ([]*gmail.Message, error) {
+	if query == "" || len(msgs) == 0 {
+		return msgs, nil
+	}
+	const day = int64(24 * time.Hour / time.Second)
+	seen := map[int64]struct{}{}
+	var days []int64
+	undated := false
+	for _, msg := range msgs {
+		if msg.InternalDate <= 0 {
+			undated = true
+			continue
+		}
+		d := msg.InternalDate / 1000 / day
+		if _, ok := seen[d]; !ok {
+			seen[d] = struct{}{}
+			days = append(days, d)
+		}
+	}
+
+	var windows []string
+	if undated {
+		windows = append(windows, query)
+	} else {
+		slices.Sort(days)
+		for i := 0; i < len(days); {
+			j := i
+			for j+1 < len(days) && days[j+1] == days[j]+1 {
+				j++
+			}
+			// Pad by a second on either side; after:/before: are exclusive.
+			windows = append(windows, fmt.Sprintf("(%s) after:%d before:%d", query, days[i]*day-1, (days[j]+1)*day+1))
+			i = j + 1
+		}
+	}
+
+	matching := map[string]struct{}{}
+	for _, w := range windows {
+		ids, _, err := listGmailMessageIDs(ctx, svc, w, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			matching[id] = struct{}{}
+		}
+	}
+	out := msgs[:0]
+	for _, msg := range msgs {
+		if _, ok := matching[msg.Id]; ok {
+			out = append(out, msg)
+		}
+	}
+	return out, nil
+}
+
+// fetchGmailSyncMessages fetches messages with bounded concurrency. Messages
+// that no longer exist are skipped.
+func fetchGmailSyncMessages(ctx context.Context, svc *gmail.Service, ids []string, format string) ([]*gmail.Message, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	sem := make(chan struct{}, gmailSyncMaxConcurrency)
+	msgs := make([]*gmail.Message, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		wg.Add(1)
+		go func(idx int, messageID string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+
+			msg, err := svc.Users.Messages.Get("me", messageID).Format(format).Context(ctx).Do()
+			if err != nil {
+				if isNotFoundAPIError(err) {
+					return
+				}
+				errs[idx] = fmt.Errorf("message %s: %w", messageID, err)
+				return
+			}
+			msgs[idx] = msg
+		}(i, id)
+	}
+	wg.Wait()
+
+	out := make([]*gmail.Message, 0, len(msgs))
+	for i, msg := range msgs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if msg != nil && msg.Id != "" {
+			out = append(out, msg)
+		}
+	}
+	return out, nil
+}
+
+func gmailIndexDocument(msg *gmail.Message, idToName map[string]string) *mailindex.Document {
+	body := bestBodyText(msg.Payload)
+	if looksLikeHTML(body) {
+		body = stripHTMLTags(body)
+	}
+	attachments := collectAttachments(msg.Payload)
+	names := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		names = append(names, a.Filename)
+	}
+	date := msg.InternalDate
+	if date == 0 {
+		if t, err := mailParseDate(headerValue(msg.Payload, "Date")); err == nil {
+			date = t.UnixMilli()
+		}
+	}
+	return &mailindex.Document{
+		ID:          msg.Id,
+		ThreadID:    msg.ThreadId,
+		From:        headerValue(msg.Payload, "From"),
+		To:          headerValue(msg.Payload, "To"),
+		Cc:          headerValue(msg.Payload, "Cc"),
+		Subject:     headerValue(msg.Payload, "Subject"),
+		DateMs:      date,
+		Labels:      gmailLabelNames(msg.LabelIds, idToName),
+		LabelIDs:    msg.LabelIds,
+		Snippet:     msg.Snippet,
+		Body:        body,
+		Attachments: names,
+		SizeBytes:   msg.SizeEstimate,
+	}
+}
+
+func gmailLabelNames(ids []string, idToName map[string]string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := idToName[id]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+type GmailLocalSearchCmd struct {
+	Query       []string `arg:"" name:"query" help:"Search query (supports words, \"phrases\", -negation, OR, from:, to:, cc:, subject:, label:, in:, is:, has:attachment, filename:, after:, before:, newer_than:, older_than:, larger:, smaller:)"`
+	Max         int      `name:"max" aliases:"limit" help:"Max results (0 = no limit)" default:"20"`
+	Index       string   `name:"index" help:"Index file path (default: per-account file in the gog state dir)"`
+	FailEmpty   bool     `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
+	Timezone    string   `name:"timezone" short:"z" help:"Output timezone (IANA name, e.g. America/New_York, UTC). Default: local"`
+	Local       bool     `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
+	IncludeBody bool     `name:"include-body" help:"Include indexed message body (JSON is full; text output is truncated)"`
+}
+
+func (c *GmailLocalSearchCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	query := strings.TrimSpace(strings.Join(c.Query, " "))
+	if query == "" {
+		return usage("missing query")
+	}
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	if err != nil {
+		return err
+	}
+	parsed, err := mailindex.Parse(query, time.Now().In(loc))
+	if err != nil {
+		return usage(err.Error())
+	}
+
+	idx, err := loadGmailLocalIndex(c.Index, flags)
+	if err != nil {
+		return err
+	}
+
+	docs := idx.Search(parsed, c.Max)
+	items := make([]messageItem, 0, len(docs))
+	for _, doc := range docs {
+		item := messageItem{
+			ID:       doc.ID,
+			ThreadID: doc.ThreadID,
+			From:     sanitizeTab(doc.From),
+			Subject:  sanitizeTab(doc.Subject),
+			Labels:   doc.Labels,
+		}
+		if doc.DateMs > 0 {
+			item.Date = time.UnixMilli(doc.DateMs).In(loc).Format("2006-01-02 15:04")
+		}
+		if c.IncludeBody {
+			item.Body = doc.Body
+		}
+		items = append(items, item)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"messages": items,
+			"count":    len(items),
+		}); err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return failEmptyExit(c.FailEmpty)
+		}
+		return nil
+	}
+	if len(items) == 0 {
+		u.Err().Println("No results")
+		return failEmptyExit(c.FailEmpty)
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	if c.IncludeBody {
+		fmt.Fprintln(w, "ID\tTHREAD\tDATE\tFROM\tSUBJECT\tLABELS\tBODY")
+	} else {
+		fmt.Fprintln(w, "ID\tTHREAD\tDATE\tFROM\tSUBJECT\tLABELS")
+	}
+	for _, it := range items {
+		if c.IncludeBody {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", it.ID, it.ThreadID, it.Date, it.From, it.Subject, strings.Join(it.Labels, ","), sanitizeMessageBody(it.Body, false))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", it.ID, it.ThreadID, it.Date, it.From, it.Subject, strings.Join(it.Labels, ","))
+		}
+	}
+	return nil
+}
+
+type GmailLocalStatusCmd struct {
+	Index string `name:"index" help:"Index file path (default: per-account file in the gog state dir)"`
+}
+
+func (c *GmailLocalStatusCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	path, err := gmailLocalIndexPath(c.Index, flags)
+	if err != nil {
+		return err
+	}
+	idx, exists, err := mailindex.Load(path)
+	if err != nil {
+		return err
+	}
+	updated := formatUnixMillis(idx.UpdatedMs)
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"path":      path,
+			"exists":    exists,
+			"account":   idx.Account,
+			"query":     idx.Query,
+			"historyId": idx.HistoryID,
+			"messages":  idx.Len(),
+			"updatedAt": updated,
+		})
+	}
+	u.Out().Printf("path\t%s", path)
+	u.Out().Printf("exists\t%t", exists)
+	if !exists {
+		u.Err().Println("No local index; run `gog gmail sync` first")
+		return nil
+	}
+	u.Out().Printf("account\t%s", idx.Account)
+	u.Out().Printf("query\t%s", idx.Query)
+	u.Out().Printf("history_id\t%s", idx.HistoryID)
+	u.Out().Printf("messages\t%d", idx.Len())
+	u.Out().Printf("updated_at\t%s", updated)
+	return nil
+}
+
+// gmailLocalIndexPath resolves the index file without touching the network,
+// so local commands also work on machines without stored credentials when
+// --index is given.
+func gmailLocalIndexPath(flagPath string, flags *RootFlags) (string, error) {
+	if strings.TrimSpace(flagPath) != "" {
+		return config.ExpandPath(strings.TrimSpace(flagPath))
+	}
+	account, err := requireAccount(flags)
+	if err != nil {
+		return "", err
+	}
+	return resolveGmailIndexPath("", account)
+}
+
+func loadGmailLocalIndex(flagPath string, flags *RootFlags) (*mailindex.Index, error) {
+	path, err := gmailLocalIndexPath(flagPath, flags)
+	if err != nil {
+		return nil, err
+	}
+	idx, exists, err := mailindex.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, usagef("no local index at %s; run `gog gmail sync` first", path)
+	}
+	return idx, nil
+}
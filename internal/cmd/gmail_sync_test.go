@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/mailindex"
+)
+
+func TestRunGmailSync_FullThenIncremental(t *testing.T) {
+	historyCalls := 0
+	listCalls := 0
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		w.Header().Set("Content-Type", "application/json")
+		message := func(id, subject, body string, labels ...string) map[string]any {
+			return map[string]any{
+				"id":           id,
+				"threadId":     "t-" + id,
+				"labelIds":     labels,
+				"internalDate": "1773921600000",
+				"sizeEstimate": 42,
+				"payload": map[string]any{
+					"mimeType": "text/plain",
+					"headers": []map[string]any{
+						{"name": "From", "value": "alice@example.com"},
+						{"name": "Subject", "value": subject},
+					},
+					"body": map[string]any{"data": base64.RawURLEncoding.EncodeToString([]byte(body))},
+				},
+			}
+		}
+		switch {
+		case path == "/users/me/profile":
+			_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": "100"})
+		case strings.HasPrefix(path, "/users/me/labels"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"labels": []map[string]any{
+					{"id": "INBOX", "name": "INBOX", "type": "system"},
+					{"id": "Label_1", "name": "Receipts", "type": "user"},
+				},
+			})
+		case path == "/users/me/messages" && r.Method == http.MethodGet:
+			listCalls++
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"messages": []map[string]any{{"id": "m1"}, {"id": "m2"}},
+			})
+		case path == "/users/me/history":
+			historyCalls++
+			if got := r.URL.Query().Get("startHistoryId"); got != "100" {
+				t.Fatalf("unexpected startHistoryId %q", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "120",
+				"history": []map[string]any{
+					{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m3"}}}},
+					{"messagesDeleted": []map[string]any{{"message": map[string]any{"id": "m2"}}}},
+					{"labelsAdded": []map[string]any{{"message": map[string]any{"id": "m1"}, "labelIds": []string{"Label_1"}}}},
+				},
+			})
+		case path == "/users/me/messages/m1":
+			if r.URL.Query().Get("format") == gmailFormatMinimal {
+				_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1", "labelIds": []string{"INBOX", "Label_1"}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(message("m1", "Quarterly report", "numbers inside", "INBOX"))
+		case path == "/users/me/messages/m2":
+			_ = json.NewEncoder(w).Encode(message("m2", "Lunch", "ramen today", "INBOX"))
+		case path == "/users/me/messages/m3":
+			_ = json.NewEncoder(w).Encode(message("m3", "Invoice", "<html><body><p>total due</p></body></html>", "INBOX"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	idx := mailindex.New()
+	input := gmailSyncInput{Account: "a@b.com"}
+
+	first, err := runGmailSync(context.Background(), svc, idx, input)
+	if err != nil {
+		t.Fatalf("full sync: %v", err)
+	}
+	if first.Mode != gmailSyncModeFull || first.Reason != "empty_index" || first.Added != 2 || first.HistoryID != "100" {
+		t.Fatalf("unexpected full sync result: %#v", first)
+	}
+
+	second, err := runGmailSync(context.Background(), svc, idx, input)
+	if err != nil {
+		t.Fatalf("incremental sync: %v", err)
+	}
+	if second.Mode != gmailSyncModeIncremental || second.Added != 1 || second.Deleted != 1 || second.Updated != 1 || second.Total != 2 {
+		t.Fatalf("unexpected incremental result: %#v", second)
+	}
+	if historyCalls != 1 || listCalls != 1 || idx.HistoryID != "120" {
+		t.Fatalf("unexpected calls history=%d list=%d historyId=%q", historyCalls, listCalls, idx.HistoryID)
+	}
+
+	q, err := mailindex.Parse("total label:receipts OR subject:invoice", time.Now())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	docs := idx.Search(q, 0)
+	if len(docs) != 1 || docs[0].ID != "m3" || strings.Contains(docs[0].Body, "<p>") {
+		t.Fatalf("unexpected search results: %#v", docs)
+	}
+	if doc, ok := idx.Get("m1"); !ok || len(doc.Labels) != 2 || doc.Labels[1] != "Receipts" {
+		t.Fatalf("expected refreshed labels on m1, got %#v", doc)
+	}
+
+	third, err := runGmailSync(context.Background(), svc, idx, gmailSyncInput{Account: "a@b.com", Query: "in:inbox"})
+	if err != nil {
+		t.Fatalf("query change sync: %v", err)
+	}
+	if third.Mode != gmailSyncModeFull || third.Reason != "query_changed" {
+		t.Fatalf("expected rebuild on query change, got %#v", third)
+	}
+}
+
+func TestRunGmailSync_IncrementalPrunesQueryDropouts(t *testing.T) {
+	incremental := false
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/users/me/profile":
+			_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": "100"})
+		case strings.HasPrefix(path, "/users/me/labels"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"labels": []map[string]any{{"id": "INBOX", "name": "INBOX", "type": "system"}},
+			})
+		case path == "/users/me/messages" && r.Method == http.MethodGet:
+			q := r.URL.Query().Get("q")
+			if incremental {
+				// One day around the changed messages, not everything since.
+				if q != "(in:inbox) after:1773878399 before:1773964801" {
+					t.Fatalf("expected a one-day windowed query, got %q", q)
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"messages": []map[string]any{{"id": "m2"}}})
+				return
+			}
+			if q != "in:inbox" {
+				t.Fatalf("unexpected query %q", q)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"messages": []map[string]any{{"id": "m1"}, {"id": "m2"}}})
+		case path == "/users/me/history":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "110",
+				"history": []map[string]any{
+					{"labelsRemoved": []map[string]any{{"message": map[string]any{"id": "m1"}, "labelIds": []string{"INBOX"}}}},
+					{"labelsAdded": []map[string]any{{"message": map[string]any{"id": "m2"}, "labelIds": []string{"STARRED"}}}},
+				},
+			})
+		case strings.HasPrefix(path, "/users/me/messages/"):
+			id := strings.TrimPrefix(path, "/users/me/messages/")
+			labels := []string{"INBOX"}
+			if incremental {
+				if got := r.URL.Query().Get("format"); got != gmailFormatMinimal {
+					t.Fatalf("expected minimal refresh, got %q", got)
+				}
+				labels = map[string][]string{"m1": {}, "m2": {"INBOX", "STARRED"}}[id]
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":           id,
+				"labelIds":     labels,
+				"internalDate": "1773921600000",
+				"payload":      map[string]any{"mimeType": "text/plain"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	idx := mailindex.New()
+	input := gmailSyncInput{Account: "a@b.com", Query: "in:inbox"}
+	if _, err := runGmailSync(context.Background(), svc, idx, input); err != nil {
+		t.Fatalf("full sync: %v", err)
+	}
+
+	incremental = true
+	result, err := runGmailSync(context.Background(), svc, idx, input)
+	if err != nil {
+		t.Fatalf("incremental sync: %v", err)
+	}
+	if result.Mode != gmailSyncModeIncremental || result.Deleted != 1 || result.Updated != 1 || result.Total != 1 {
+		t.Fatalf("unexpected incremental result: %#v", result)
+	}
+	if _, ok := idx.Get("m1"); ok {
+		t.Fatal("expected m1 to be pruned after leaving the query")
+	}
+}
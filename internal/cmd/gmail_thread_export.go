@@ -0,0 +1,730 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	xhtml "golang.org/x/net/html"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	threadExportFormatMarkdown = "md"
+	threadExportFormatHTML     = "html"
+	threadExportFormatEML      = "eml"
+)
+
+// GmailThreadExportCmd writes a thread transcript to disk.
+type GmailThreadExportCmd struct {
+	ThreadID      string `arg:"" name:"threadId" help:"Thread ID"`
+	Format        string `name:"format" short:"f" help:"Export format: md|html|eml" enum:"md,html,eml" default:"md"`
+	Out           string `name:"out" aliases:"output,out-dir" default:"." help:"Output directory"`
+	Name          string `name:"name" help:"Base filename (default: slug of the thread subject)"`
+	KeepQuotes    bool   `name:"keep-quotes" help:"Keep quoted replies instead of stripping them"`
+	NoAttachments bool   `name:"no-attachments" help:"Do not save attachments next to the transcript"`
+	UploadDoc     bool   `name:"upload-doc" aliases:"upload" help:"Upload the transcript to Drive, converted to a Google Doc (md|html only)"`
+	Parent        string `name:"parent" help:"Drive folder ID for --upload-doc"`
+	Timezone      string `name:"timezone" short:"z" help:"Timezone for message dates (IANA name). Default: local"`
+	Local         bool   `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
+}
+
+type gmailThreadExportResult struct {
+	ThreadID    string      `json:"threadId"`
+	Format      string      `json:"format"`
+	Path        string      `json:"path,omitempty"`
+	Files       []string    `json:"files,omitempty"`
+	Messages    int         `json:"messages"`
+	Attachments []string    `json:"attachments,omitempty"`
+	Doc         *drive.File `json:"doc,omitempty"`
+}
+
+type threadExportOptions struct {
+	Format        string
+	Dir           string
+	Base          string
+	KeepQuotes    bool
+	NoAttachments bool
+	Loc           *time.Location
+}
+
+type threadExportMessage struct {
+	ID          string
+	From        string
+	To          string
+	Cc          string
+	Subject     string
+	Date        string
+	Text        string
+	HTML        string
+	Attachments []threadExportAttachment
+}
+
+type threadExportAttachment struct {
+	Filename  string
+	MimeType  string
+	Size      int64
+	Rel       string
+	ContentID string
+	Inline    bool
+}
+
+func (c *GmailThreadExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	threadID := normalizeGmailThreadID(strings.TrimSpace(c.ThreadID))
+	if threadID == "" {
+		return usage("empty threadId")
+	}
+	format := strings.ToLower(strings.TrimSpace(c.Format))
+	if c.UploadDoc && format == threadExportFormatEML {
+		return usage("--upload-doc requires --format md or html")
+	}
+	if strings.TrimSpace(c.Parent) != "" && !c.UploadDoc {
+		return usage("--parent requires --upload-doc")
+	}
+	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	if err != nil {
+		return err
+	}
+	dir, err := config.ExpandPath(strings.TrimSpace(c.Out))
+	if err != nil {
+		return err
+	}
+	dir = filepath.Clean(dir)
+
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.thread.export", map[string]any{
+		"thread_id":   threadID,
+		"format":      format,
+		"out":         dir,
+		"name":        strings.TrimSpace(c.Name),
+		"upload_doc":  c.UploadDoc,
+		"parent":      strings.TrimSpace(c.Parent),
+		"attachments": !c.NoAttachments,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	result, err := exportGmailThread(ctx, svc, threadID, threadExportOptions{
+		Format:        format,
+		Dir:           dir,
+		Base:          strings.TrimSpace(c.Name),
+		KeepQuotes:    c.KeepQuotes,
+		NoAttachments: c.NoAttachments,
+		Loc:           loc,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.UploadDoc {
+		driveSvc, driveErr := newDriveService(ctx, account)
+		if driveErr != nil {
+			return driveErr
+		}
+		doc, uploadErr := uploadThreadExportDoc(ctx, driveSvc, result.Path, strings.TrimSpace(c.Parent))
+		if uploadErr != nil {
+			return uploadErr
+		}
+		result.Doc = doc
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"export": result})
+	}
+	if result.Path != "" {
+		u.Out().Printf("path\t%s", result.Path)
+	}
+	for _, f := range result.Files {
+		u.Out().Printf("file\t%s", f)
+	}
+	u.Out().Printf("messages\t%d", result.Messages)
+	for _, a := range result.Attachments {
+		u.Out().Printf("attachment\t%s", a)
+	}
+	if result.Doc != nil {
+		u.Out().Printf("doc_id\t%s", result.Doc.Id)
+		if result.Doc.WebViewLink != "" {
+			u.Out().Printf("link\t%s", result.Doc.WebViewLink)
+		}
+	}
+	return nil
+}
+
+func exportGmailThread(ctx context.Context, svc *gmail.Service, threadID string, opts threadExportOptions) (gmailThreadExportResult, error) {
+	result := gmailThreadExportResult{ThreadID: threadID, Format: opts.Format}
+	thread, err := svc.Users.Threads.Get("me", threadID).Format(gmailFormatFull).Context(ctx).Do()
+	if err != nil {
+		return result, err
+	}
+	var msgs []*gmail.Message
+	if thread != nil {
+		for _, msg := range thread.Messages {
+			if msg != nil {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	if len(msgs) == 0 {
+		return result, fmt.Errorf("thread %s has no messages", threadID)
+	}
+	result.Messages = len(msgs)
+
+	base := opts.Base
+	if base == "" {
+		base = threadExportSlug(headerValue(msgs[0].Payload, "Subject"), threadID)
+	}
+	base = sanitizeAttachmentFilename(base, "thread-"+threadID)
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return result, err
+	}
+
+	if opts.Format == threadExportFormatEML {
+		files, emlErr := exportThreadEML(ctx, svc, msgs, opts.Dir, base)
+		result.Files = files
+		return result, emlErr
+	}
+
+	filesDir := base + "_files"
+	exported := make([]threadExportMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		em := threadExportMessageFrom(msg, opts)
+		if !opts.NoAttachments {
+			saved, saveErr := saveThreadExportAttachments(ctx, svc, msg, opts.Dir, filesDir)
+			if saveErr != nil {
+				return result, saveErr
+			}
+			em.Attachments = saved
+			for _, a := range saved {
+				result.Attachments = append(result.Attachments, filepath.Join(opts.Dir, filepath.FromSlash(a.Rel)))
+			}
+		}
+		exported = append(exported, em)
+	}
+
+	subject := headerValue(msgs[0].Payload, "Subject")
+	var content string
+	switch opts.Format {
+	case threadExportFormatHTML:
+		content = renderThreadExportHTML(threadID, subject, exported, !opts.KeepQuotes)
+	default:
+		content = renderThreadExportMarkdown(threadID, subject, exported)
+	}
+	result.Path = filepath.Join(opts.Dir, base+"."+opts.Format)
+	if err := writeFileAtomic(result.Path, []byte(content)); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func threadExportMessageFrom(msg *gmail.Message, opts threadExportOptions) threadExportMessage {
+	em := threadExportMessage{
+		ID:      msg.Id,
+		From:    headerValue(msg.Payload, "From"),
+		To:      headerValue(msg.Payload, "To"),
+		Cc:      headerValue(msg.Payload, "Cc"),
+		Subject: headerValue(msg.Payload, "Subject"),
+	}
+	switch {
+	case msg.InternalDate > 0:
+		em.Date = time.UnixMilli(msg.InternalDate).In(opts.Loc).Format("2006-01-02 15:04 MST")
+	default:
+		em.Date = formatGmailDateInLocation(headerValue(msg.Payload, "Date"), opts.Loc)
+	}
+
+	plain := findPartBody(msg.Payload, "text/plain")
+	htmlBody := findPartBody(msg.Payload, "text/html")
+	if plain != "" && looksLikeHTML(plain) && htmlBody == "" {
+		htmlBody, plain = plain, ""
+	}
+	if htmlBody != "" {
+		em.HTML = htmlBody
+	}
+	switch {
+	case plain != "":
+		em.Text = plain
+	case htmlBody != "":
+		em.Text = exportHTMLToText(cleanThreadExportHTML(htmlBody, !opts.KeepQuotes, nil))
+	default:
+		em.Text = msg.Snippet
+	}
+	if !opts.KeepQuotes {
+		em.Text = stripQuotedReply(em.Text)
+	}
+	return em
+}
+
+func saveThreadExportAttachments(ctx context.Context, svc *gmail.Service, msg *gmail.Message, dir, filesDir string) ([]threadExportAttachment, error) {
+	var out []threadExportAttachment
+	var walk func(p *gmail.MessagePart) error
+	walk = func(p *gmail.MessagePart) error {
+		if p == nil {
+			return nil
+		}
+		if p.Body != nil && (p.Body.AttachmentId != "" || (p.Filename != "" && p.Body.Data != "")) {
+			var data []byte
+			var err error
+			if p.Body.AttachmentId != "" {
+				data, err = fetchAttachmentBytes(ctx, svc, msg.Id, p.Body.AttachmentId)
+			} else {
+				data, err = decodeBase64URLBytes(p.Body.Data)
+			}
+			if err != nil {
+				return fmt.Errorf("message %s attachment %s: %w", msg.Id, p.Filename, err)
+			}
+			name := sanitizeAttachmentFilename(p.Filename, "attachment")
+			outPath, err := attachmentOutputPath(dir, filesDir+"/"+name, data)
+			if err != nil {
+				return err
+			}
+			if err := writeFileAtomic(outPath, data); err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, outPath)
+			if err != nil {
+				return err
+			}
+			contentID := strings.Trim(strings.TrimSpace(headerValue(p, "Content-ID")), "<>")
+			disposition := strings.ToLower(headerValue(p, "Content-Disposition"))
+			out = append(out, threadExportAttachment{
+				Filename:  name,
+				MimeType:  p.MimeType,
+				Size:      int64(len(data)),
+				Rel:       filepath.ToSlash(rel),
+				ContentID: contentID,
+				Inline:    contentID != "" && !strings.HasPrefix(disposition, "attachment"),
+			})
+		}
+		for _, part := range p.Parts {
+			if err := walk(part); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(msg.Payload); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func exportThreadEML(ctx context.Context, svc *gmail.Service, msgs []*gmail.Message, dir, base string) ([]string, error) {
+	files := make([]string, 0, len(msgs))
+	for i, msg := range msgs {
+		raw, err := svc.Users.Messages.Get("me", msg.Id).Format(gmailFormatRaw).Context(ctx).Do()
+		if err != nil {
+			return files, err
+		}
+		data, err := decodeBase64URLBytes(raw.Raw)
+		if err != nil {
+			return files, fmt.Errorf("message %s: decode raw: %w", msg.Id, err)
+		}
+		name := base + ".eml"
+		if len(msgs) > 1 {
+			name = fmt.Sprintf("%s-%02d.eml", base, i+1)
+		}
+		path := filepath.Join(dir, name)
+		if err := writeFileAtomic(path, data); err != nil {
+			return files, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+func uploadThreadExportDoc(ctx context.Context, svc *drive.Service, path, parent string) (*drive.File, error) {
+	opts, err := prepareDriveUpload(&DriveUploadCmd{LocalPath: path, Parent: parent, Convert: true})
+	if err != nil {
+		return nil, err
+	}
+	media, err := openDriveUploadMedia(opts, false)
+	if err != nil {
+		return nil, err
+	}
+	defer media.Close()
+	return createDriveUpload(ctx, svc, media, opts)
+}
+
+var threadExportSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+func threadExportSlug(subject, threadID string) string {
+	slug := strings.Trim(threadExportSlugPattern.ReplaceAllString(strings.ToLower(subject), "-"), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+	}
+	if slug == "" {
+		return "thread-" + threadID
+	}
+	return slug
+}
+
+var (
+	quoteAttributionPattern = regexp.MustCompile(`(?i)^on\s.+\swrote:$`)
+	imagePlaceholderPattern = regexp.MustCompile(`\[image: ([^\]]+)\]`)
+	htmlBlockBreakPattern   = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|blockquote|table)>`)
+	blankLinesPattern       = regexp.MustCompile(`\n{3,}`)
+)
+
+// stripQuotedReply removes the quoted history that mail clients append to
+// replies ("On ... wrote:", Outlook headers, trailing "> " blocks). If the
+// whole body is quoted, the original text is returned.
+func stripQuotedReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	cut := len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		next := ""
+		if i+1 < len(lines) {
+			next = strings.TrimSpace(lines[i+1])
+		}
+		switch {
+		case quoteAttributionPattern.MatchString(trimmed),
+			strings.HasPrefix(strings.ToLower(trimmed), "on ") && strings.HasSuffix(next, "wrote:") && !strings.HasSuffix(trimmed, "wrote:"),
+			strings.Contains(trimmed, "Original Message") && strings.HasPrefix(trimmed, "-----"),
+			strings.HasPrefix(trimmed, "________________________________") && strings.HasPrefix(next, "From:"):
+			cut = i
+		}
+		if cut != len(lines) {
+			break
+		}
+	}
+	lines = lines[:cut]
+	for len(lines) > 0 {
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if last != "" && !strings.HasPrefix(last, ">") {
+			break
+		}
+		lines = lines[:len(lines)-1]
+	}
+	stripped := strings.Join(lines, "\n")
+	if strings.TrimSpace(stripped) == "" {
+		return strings.TrimSpace(text)
+	}
+	return strings.TrimRight(stripped, " \t\n")
+}
+
+func renderThreadExportMarkdown(threadID, subject string, msgs []threadExportMessage) string {
+	var b strings.Builder
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	fmt.Fprintf(&b, "# %s\n\n", subject)
+	fmt.Fprintf(&b, "- Thread: `%s`\n", threadID)
+	fmt.Fprintf(&b, "- Messages: %d\n", len(msgs))
+	if participants := threadExportParticipants(msgs); len(participants) > 0 {
+		fmt.Fprintf(&b, "- Participants: %s\n", strings.Join(participants, ", "))
+	}
+
+	for _, m := range msgs {
+		b.WriteString("\n---\n\n")
+		fmt.Fprintf(&b, "## %s — %s\n\n", markdownInline(threadExportSender(m.From)), m.Date)
+		fmt.Fprintf(&b, "**From:** %s  \n", markdownInline(m.From))
+		if m.To != "" {
+			fmt.Fprintf(&b, "**To:** %s  \n", markdownInline(m.To))
+		}
+		if m.Cc != "" {
+			fmt.Fprintf(&b, "**Cc:** %s  \n", markdownInline(m.Cc))
+		}
+		fmt.Fprintf(&b, "**Date:** %s\n\n", m.Date)
+
+		text := m.Text
+		referenced := map[string]bool{}
+		text = imagePlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+			name := imagePlaceholderPattern.FindStringSubmatch(match)[1]
+			for _, a := range m.Attachments {
+				if a.Inline && a.Filename == name {
+					referenced[a.Rel] = true
+					return fmt.Sprintf("![%s](%s)", a.Filename, markdownLinkTarget(a.Rel))
+				}
+			}
+			return match
+		})
+		b.WriteString(strings.TrimSpace(text))
+		b.WriteString("\n")
+
+		var inline, files []threadExportAttachment
+		for _, a := range m.Attachments {
+			switch {
+			case a.Inline && referenced[a.Rel]:
+			case a.Inline && strings.HasPrefix(a.MimeType, "image/"):
+				inline = append(inline, a)
+			default:
+				files = append(files, a)
+			}
+		}
+		for _, a := range inline {
+			fmt.Fprintf(&b, "\n![%s](%s)\n", a.Filename, markdownLinkTarget(a.Rel))
+		}
+		if len(files) > 0 {
+			b.WriteString("\n**Attachments:**\n\n")
+			for _, a := range files {
+				fmt.Fprintf(&b, "- [%s](%s) (%s)\n", a.Filename, markdownLinkTarget(a.Rel), formatBytes(a.Size))
+			}
+		}
+	}
+	return b.String()
+}
+
+func markdownInline(value string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;", "*", "\\*", "_", "\\_").Replace(value)
+}
+
+func markdownLinkTarget(rel string) string {
+	return "<" + rel + ">"
+}
+
+func renderThreadExportHTML(threadID, subject string, msgs []threadExportMessage, stripQuotes bool) string {
+	var b strings.Builder
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	esc := xhtml.EscapeString
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", esc(subject))
+	b.WriteString("<style>body{font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;max-width:820px;margin:2em auto;padding:0 1em;color:#222}" +
+		".message{border-top:1px solid #ddd;padding:1em 0}.meta{color:#555;font-size:.9em;margin-bottom:1em}.meta div{margin:.1em 0}" +
+		".plain{white-space:pre-wrap}.attachments{font-size:.9em}img{max-width:100%}</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", esc(subject))
+	fmt.Fprintf(&b, "<p class=\"meta\">Thread %s &middot; %d message(s)", esc(threadID), len(msgs))
+	if participants := threadExportParticipants(msgs); len(participants) > 0 {
+		fmt.Fprintf(&b, " &middot; %s", esc(strings.Join(participants, ", ")))
+	}
+	b.WriteString("</p>\n")
+
+	for _, m := range msgs {
+		fmt.Fprintf(&b, "<div class=\"message\" id=\"msg-%s\">\n<div class=\"meta\">\n", esc(m.ID))
+		fmt.Fprintf(&b, "<div><strong>From:</strong> %s</div>\n", esc(m.From))
+		if m.To != "" {
+			fmt.Fprintf(&b, "<div><strong>To:</strong> %s</div>\n", esc(m.To))
+		}
+		if m.Cc != "" {
+			fmt.Fprintf(&b, "<div><strong>Cc:</strong> %s</div>\n", esc(m.Cc))
+		}
+		fmt.Fprintf(&b, "<div><strong>Date:</strong> %s</div>\n</div>\n", esc(m.Date))
+
+		cidPaths := map[string]string{}
+		for _, a := range m.Attachments {
+			if a.ContentID != "" {
+				cidPaths[a.ContentID] = a.Rel
+			}
+		}
+		if m.HTML != "" {
+			b.WriteString("<div class=\"body\">\n")
+			b.WriteString(cleanThreadExportHTML(m.HTML, stripQuotes, cidPaths))
+			b.WriteString("\n</div>\n")
+		} else {
+			fmt.Fprintf(&b, "<div class=\"body plain\">%s</div>\n", esc(m.Text))
+		}
+
+		var files []threadExportAttachment
+		for _, a := range m.Attachments {
+			if a.Inline && m.HTML != "" {
+				continue
+			}
+			files = append(files, a)
+		}
+		if len(files) > 0 {
+			b.WriteString("<div class=\"attachments\"><strong>Attachments:</strong><ul>\n")
+			for _, a := range files {
+				fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a> (%s)</li>\n", esc(a.Rel), esc(a.Filename), esc(formatBytes(a.Size)))
+			}
+			b.WriteString("</ul></div>\n")
+		}
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// cleanThreadExportHTML returns the body content of an HTML message with
+// scripts, styles, event handlers, and (optionally) quoted history removed,
+// and cid: image references rewritten to the saved attachment paths.
+func cleanThreadExportHTML(raw string, stripQuotes bool, cidPaths map[string]string) string {
+	doc, err := xhtml.Parse(strings.NewReader(raw))
+	if err != nil {
+		return xhtml.EscapeString(stripHTMLTags(raw))
+	}
+	root := findHTMLElement(doc, "body")
+	if root == nil {
+		root = doc
+	}
+
+	localPaths := make(map[string]bool, len(cidPaths))
+	for _, rel := range cidPaths {
+		localPaths[rel] = true
+	}
+
+	var clean func(n *xhtml.Node)
+	clean = func(n *xhtml.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == xhtml.CommentNode || (c.Type == xhtml.ElementNode && isDroppedExportElement(c, stripQuotes)) {
+				n.RemoveChild(c)
+				c = next
+				continue
+			}
+			if c.Type == xhtml.ElementNode {
+				c.Attr = cleanExportAttrs(c, cidPaths, localPaths)
+			}
+			clean(c)
+			c = next
+		}
+	}
+	clean(root)
+
+	var buf bytes.Buffer
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if err := xhtml.Render(&buf, c); err != nil {
+			return xhtml.EscapeString(stripHTMLTags(raw))
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// cleanExportAttrs drops event handlers and any URL attribute that does not
+// point at http(s), mailto, cid, or a saved attachment, rewriting cid: image
+// sources to their local paths first.
+func cleanExportAttrs(n *xhtml.Node, cidPaths map[string]string, localPaths map[string]bool) []xhtml.Attribute {
+	out := n.Attr[:0]
+	for _, attr := range n.Attr {
+		key := strings.ToLower(attr.Key)
+		if strings.HasPrefix(key, "on") {
+			continue
+		}
+		if !isExportURLAttr(key) {
+			out = append(out, attr)
+			continue
+		}
+		if n.Data == "img" && key == "src" && strings.HasPrefix(strings.ToLower(attr.Val), "cid:") {
+			if rel, ok := cidPaths[strings.Trim(attr.Val[len("cid:"):], "<>")]; ok {
+				attr.Val = rel
+			}
+		}
+		if localPaths[attr.Val] || isSafeExportURL(attr.Val) {
+			out = append(out, attr)
+		}
+	}
+	return out
+}
+
+func isExportURLAttr(key string) bool {
+	switch key {
+	case "href", "src", "srcset", "action", "formaction", "background", "poster", "cite", "data", "longdesc", "lowsrc", "dynsrc", "ping", "xlink:href":
+		return true
+	default:
+		return false
+	}
+}
+
+// isSafeExportURL reports whether raw uses an allowed scheme. Browsers ignore
+// whitespace and control characters inside the scheme, so strip them first.
+func isSafeExportURL(raw string) bool {
+	compact := strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw))
+	for _, scheme := range []string{"http:", "https:", "mailto:", "cid:"} {
+		if strings.HasPrefix(compact, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDroppedExportElement(n *xhtml.Node, stripQuotes bool) bool {
+	switch n.Data {
+	case "script", "style", "head", "meta", "link", "title", "iframe", "object", "embed":
+		return true
+	}
+	if !stripQuotes {
+		return false
+	}
+	for _, attr := range n.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "class":
+			for _, class := range strings.Fields(attr.Val) {
+				if class == "gmail_quote" || class == "gmail_quote_container" || class == "moz-cite-prefix" || class == "yahoo_quoted" {
+					return true
+				}
+			}
+		case "type":
+			if n.Data == "blockquote" && strings.EqualFold(attr.Val, "cite") {
+				return true
+			}
+		case "id":
+			if attr.Val == "divRplyFwdMsg" || attr.Val == "appendonsend" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func findHTMLElement(n *xhtml.Node, tag string) *xhtml.Node {
+	if n.Type == xhtml.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findHTMLElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func exportHTMLToText(fragment string) string {
+	text := htmlBlockBreakPattern.ReplaceAllString(fragment, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = xhtml.UnescapeString(text)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(strings.Join(strings.Fields(line), " "))
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func threadExportSender(from string) string {
+	if from == "" {
+		return "(unknown sender)"
+	}
+	if name, _, ok := strings.Cut(from, "<"); ok && strings.TrimSpace(name) != "" {
+		return strings.Trim(strings.TrimSpace(name), `"`)
+	}
+	return from
+}
+
+func threadExportParticipants(msgs []threadExportMessage) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range msgs {
+		for _, header := range []string{m.From, m.To, m.Cc} {
+			for _, addr := range parseEmailAddresses(header) {
+				if !seen[addr] {
+					seen[addr] = true
+					out = append(out, addr)
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStripQuotedReply(t *testing.T) {
+	cases := map[string]string{
+		"Thanks!\n\nOn Mon, Mar 2, 2026 at 10:00 AM Alice <a@example.com> wrote:\n> hi\n> there": "Thanks!",
+		"Sounds good\n\nOn Mon, Mar 2, 2026 at 10:00 AM Alice\n<a@example.com> wrote:\n> hi":     "Sounds good",
+		"See below\n\n-----Original Message-----\nFrom: Bob":                                     "See below",
+		"Ok\n________________________________\nFrom: Bob\nSent: today":                           "Ok",
+		"Inline reply\n> quoted tail\n>":                                                         "Inline reply",
+		"> only quoted":                                                                          "> only quoted",
+	}
+	for in, want := range cases {
+		if got := stripQuotedReply(in); got != want {
+			t.Fatalf("stripQuotedReply(%q)=%q want %q", in, got, want)
+		}
+	}
+}
+
+func TestCleanThreadExportHTML(t *testing.T) {
+	raw := `<html><head><style>p{}</style></head><body><p>Hello <img src="cid:logo@x"></p><script>x()</script>` +
+		`<div class="gmail_quote"><blockquote>old</blockquote></div></body></html>`
+	got := cleanThreadExportHTML(raw, true, map[string]string{"logo@x": "t_files/logo.png"})
+	if got != `<p>Hello <img src="t_files/logo.png"/></p>` {
+		t.Fatalf("unexpected cleaned html: %q", got)
+	}
+	if kept := cleanThreadExportHTML(raw, false, nil); !strings.Contains(kept, "old") || strings.Contains(kept, "x()") {
+		t.Fatalf("expected quotes kept and scripts dropped: %q", kept)
+	}
+	if got := exportHTMLToText("<p>a &amp; b</p><p>c<br>d</p>"); got != "a & b\nc\nd" {
+		t.Fatalf("unexpected text: %q", got)
+	}
+}
+
+func TestCleanThreadExportHTML_DropsActiveContent(t *testing.T) {
+	raw := `<p onmouseover="steal()"><a href="javascript:alert(1)" onclick="steal()">bad</a> ` +
+		`<a href=" JaVa&#x09;script:alert(1)">tab</a> <a href="https://example.com" title="ok">good</a> ` +
+		`<a href="mailto:a@example.com">mail</a> <a href="t_files/report.pdf">file</a> ` +
+		`<img src="data:image/svg+xml;base64,AAAA" onerror="steal()"><img src="cid:logo@x"></p>`
+	got := cleanThreadExportHTML(raw, false, map[string]string{"logo@x": "t_files/logo.png", "doc@x": "t_files/report.pdf"})
+	want := `<p><a>bad</a> <a>tab</a> <a href="https://example.com" title="ok">good</a> ` +
+		`<a href="mailto:a@example.com">mail</a> <a href="t_files/report.pdf">file</a> ` +
+		`<img/><img src="t_files/logo.png"/></p>`
+	if got != want {
+		t.Fatalf("unexpected cleaned html:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportGmailThread_MarkdownHTMLAndEML(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/users/me/threads/t1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "t1",
+				"messages": []map[string]any{
+					{
+						"id":           "m1",
+						"internalDate": "1772704800000",
+						"payload": map[string]any{
+							"mimeType": "multipart/related",
+							"headers": []map[string]any{
+								{"name": "From", "value": "Alice <alice@example.com>"},
+								{"name": "To", "value": "bob@example.com"},
+								{"name": "Subject", "value": "Order #42: status?"},
+							},
+							"parts": []map[string]any{
+								{"mimeType": "text/plain", "body": map[string]any{"data": enc("Where is it? [image: logo.png]")}},
+								{"mimeType": "text/html", "body": map[string]any{"data": enc(`<div>Where is it? <img src="cid:logo1"></div>`)}},
+								{
+									"mimeType": "image/png", "filename": "logo.png",
+									"headers": []map[string]any{{"name": "Content-ID", "value": "<logo1>"}, {"name": "Content-Disposition", "value": "inline"}},
+									"body":    map[string]any{"attachmentId": "img1", "size": 3},
+								},
+							},
+						},
+					},
+					{
+						"id":           "m2",
+						"internalDate": "1772791200000",
+						"payload": map[string]any{
+							"mimeType": "multipart/mixed",
+							"headers": []map[string]any{
+								{"name": "From", "value": "bob@example.com"},
+								{"name": "To", "value": "Alice <alice@example.com>"},
+							},
+							"parts": []map[string]any{
+								{"mimeType": "text/plain", "body": map[string]any{"data": enc("Shipped today.\n\nOn Thu, Alice wrote:\n> Where is it?")}},
+								{"mimeType": "application/pdf", "filename": "label.pdf", "body": map[string]any{"attachmentId": "pdf1", "size": 4}},
+							},
+						},
+					},
+				},
+			})
+		case strings.HasSuffix(path, "/attachments/img1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": enc("PNG")})
+		case strings.HasSuffix(path, "/attachments/pdf1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": enc("%PDF")})
+		case path == "/users/me/messages/m1" || path == "/users/me/messages/m2":
+			id := strings.TrimPrefix(path, "/users/me/messages/")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "raw": enc("Subject: " + id + "\r\n\r\nbody")})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	dir := t.TempDir()
+	opts := threadExportOptions{Format: threadExportFormatMarkdown, Dir: dir, Loc: time.UTC}
+	result, err := exportGmailThread(context.Background(), svc, "t1", opts)
+	if err != nil {
+		t.Fatalf("export md: %v", err)
+	}
+	if result.Path != filepath.Join(dir, "order-42-status.md") || len(result.Attachments) != 2 {
+		t.Fatalf("unexpected md result: %#v", result)
+	}
+	md, err := os.ReadFile(result.Path)
+	if err != nil {
+		t.Fatalf("read md: %v", err)
+	}
+	for _, want := range []string{
+		"# Order #42: status?",
+		"Participants: alice@example.com, bob@example.com",
+		"Where is it? ![logo.png](<order-42-status_files/logo.png>)",
+		"Shipped today.\n",
+		"- [label.pdf](<order-42-status_files/label.pdf>) (4 B)",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(string(md), "wrote:") {
+		t.Fatalf("expected quoted reply to be stripped:\n%s", md)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "order-42-status_files", "label.pdf")); err != nil || string(data) != "%PDF" {
+		t.Fatalf("expected saved attachment: %v", err)
+	}
+
+	opts.Format = threadExportFormatHTML
+	result, err = exportGmailThread(context.Background(), svc, "t1", opts)
+	if err != nil {
+		t.Fatalf("export html: %v", err)
+	}
+	page, err := os.ReadFile(result.Path)
+	if err != nil {
+		t.Fatalf("read html: %v", err)
+	}
+	if !strings.Contains(string(page), `<img src="order-42-status_files/logo.png"/>`) || !strings.Contains(string(page), `<div class="body plain">Shipped today.</div>`) {
+		t.Fatalf("unexpected html export:\n%s", page)
+	}
+	if len(result.Attachments) != 2 {
+		t.Fatalf("expected re-export to reuse attachment files: %#v", result.Attachments)
+	}
+
+	opts.Format = threadExportFormatEML
+	opts.Base = "conv"
+	result, err = exportGmailThread(context.Background(), svc, "t1", opts)
+	if err != nil {
+		t.Fatalf("export eml: %v", err)
+	}
+	if len(result.Files) != 2 || filepath.Base(result.Files[1]) != "conv-02.eml" {
+		t.Fatalf("unexpected eml files: %#v", result.Files)
+	}
+	if data, err := os.ReadFile(result.Files[0]); err != nil || !strings.HasPrefix(string(data), "Subject: m1") {
+		t.Fatalf("unexpected eml content: %q %v", data, err)
+	}
+}
@@ -26,11 +26,14 @@ var (
 )
 
 type GmailWatchCmd struct {
-	Start  GmailWatchStartCmd  `cmd:"" name:"start" aliases:"begin" help:"Start Gmail watch for Pub/Sub"`
-	Status GmailWatchStatusCmd `cmd:"" name:"status" aliases:"ls" help:"Show stored watch state"`
-	Renew  GmailWatchRenewCmd  `cmd:"" name:"renew" aliases:"update" help:"Renew Gmail watch using stored config"`
-	Stop   GmailWatchStopCmd   `cmd:"" name:"stop" aliases:"rm,delete" help:"Stop Gmail watch and clear stored state"`
-	Serve  GmailWatchServeCmd  `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Start      GmailWatchStartCmd      `cmd:"" name:"start" aliases:"begin" help:"Start Gmail watch for Pub/Sub"`
+	Status     GmailWatchStatusCmd     `cmd:"" name:"status" aliases:"ls" help:"Show stored watch state"`
+	Renew      GmailWatchRenewCmd      `cmd:"" name:"renew" aliases:"update" help:"Renew Gmail watch using stored config"`
+	Stop       GmailWatchStopCmd       `cmd:"" name:"stop" aliases:"rm,delete" help:"Stop Gmail watch and clear stored state"`
+	Serve      GmailWatchServeCmd      `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Poll       GmailWatchPollCmd       `cmd:"" name:"poll" help:"Poll Gmail history and forward new messages (no Pub/Sub needed)"`
+	Deliveries GmailWatchDeliveriesCmd `cmd:"" name:"deliveries" help:"Inspect and replay queued hook deliveries"`
+	Rules      GmailWatchRulesCmd      `cmd:"" name:"rules" help:"Validate a rules file and preview which rules match messages"`
 }
 
 type GmailWatchStartCmd struct {
@@ -39,6 +42,7 @@ type GmailWatchStartCmd struct {
 	TTL         string   `name:"ttl" help:"Renew after duration (seconds or Go duration)"`
 	HookURL     string   `name:"hook-url" help:"Webhook URL to forward messages"`
 	HookToken   string   `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret  string   `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature header)"`
 	IncludeBody bool     `name:"include-body" help:"Include text/plain body in hook payload"`
 	MaxBytes    int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
 }
@@ -60,6 +64,9 @@ func (c *GmailWatchStartCmd) Run(ctx context.Context, kctx *kong.Context, flags
 			return err
 		}
 	}
+	if err := applyHookSecret(hook, c.HookSecret); err != nil {
+		return err
+	}
 
 	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.start", map[string]any{
 		"topic":   strings.TrimSpace(c.Topic),
@@ -216,23 +223,28 @@ func (c *GmailWatchStopCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type GmailWatchServeCmd struct {
-	Bind          string   `name:"bind" help:"Bind address" default:"127.0.0.1"`
-	Port          int      `name:"port" help:"Listen port" default:"8788"`
-	Path          string   `name:"path" help:"Push handler path" default:"/gmail-pubsub"`
-	FetchDelay    string   `name:"fetch-delay" help:"Delay before fetching Gmail history (seconds or duration)" default:"3s"`
-	Timezone      string   `name:"timezone" short:"z" help:"Output timezone (IANA name, e.g. America/New_York, UTC). Default: local"`
-	Local         bool     `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
-	VerifyOIDC    bool     `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
-	OIDCEmail     string   `name:"oidc-email" help:"Expected service account email"`
-	OIDCAudience  string   `name:"oidc-audience" help:"Expected OIDC audience"`
-	SharedToken   string   `name:"token" help:"Shared token for x-gog-token or ?token="`
-	HookURL       string   `name:"hook-url" help:"Webhook URL to forward messages"`
-	HookToken     string   `name:"hook-token" help:"Webhook bearer token"`
-	IncludeBody   bool     `name:"include-body" help:"Include text/plain body in hook payload"`
-	MaxBytes      int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
-	HistoryTypes  []string `name:"history-types" help:"History types to include (repeatable, comma-separated: messageAdded,messageDeleted,labelAdded,labelRemoved). Default: messageAdded"`
-	ExcludeLabels string   `name:"exclude-labels" help:"List of Gmail label IDs to exclude from hook payload (e.g. SPAM,TRASH,Label_123). Set to empty string to disable." default:"SPAM,TRASH"`
-	SaveHook      bool     `name:"save-hook" help:"Persist hook settings to watch state"`
+	Bind          string         `name:"bind" help:"Bind address" default:"127.0.0.1"`
+	Port          int            `name:"port" help:"Listen port" default:"8788"`
+	Path          string         `name:"path" help:"Push handler path" default:"/gmail-pubsub"`
+	FetchDelay    string         `name:"fetch-delay" help:"Delay before fetching Gmail history (seconds or duration)" default:"3s"`
+	Timezone      string         `name:"timezone" short:"z" help:"Output timezone (IANA name, e.g. America/New_York, UTC). Default: local"`
+	Local         bool           `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
+	VerifyOIDC    bool           `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
+	OIDCEmail     string         `name:"oidc-email" help:"Expected service account email"`
+	OIDCAudience  string         `name:"oidc-audience" help:"Expected OIDC audience"`
+	SharedToken   string         `name:"token" help:"Shared token for x-gog-token or ?token="`
+	HookURL       string         `name:"hook-url" help:"Webhook URL to forward messages"`
+	HookToken     string         `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret    string         `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature header)"`
+	HookAttempts  int            `name:"hook-max-attempts" help:"Delivery attempts before a payload is dead-lettered" default:"10"`
+	HookBackoff   string         `name:"hook-retry-backoff" help:"Initial retry delay, doubled per attempt up to 10m (seconds or duration)" default:"5s"`
+	IncludeBody   bool           `name:"include-body" help:"Include text/plain body in hook payload"`
+	MaxBytes      int            `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
+	HistoryTypes  []string       `name:"history-types" help:"History types to include (repeatable, comma-separated: messageAdded,messageDeleted,labelAdded,labelRemoved). Default: messageAdded"`
+	ExcludeLabels string         `name:"exclude-labels" help:"List of Gmail label IDs to exclude from hook payload (e.g. SPAM,TRASH,Label_123). Set to empty string to disable." default:"SPAM,TRASH"`
+	SaveHook      bool           `name:"save-hook" help:"Persist hook settings to watch state"`
+	Rules         string         `name:"rules" help:"Rules file (YAML/JSON) evaluated for each new message before the hook"`
+	Sinks         WatchSinkFlags `embed:""`
 }
 
 func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
@@ -266,6 +278,10 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if err != nil {
 		return err
 	}
+	sinks, err := c.Sinks.build()
+	if err != nil {
+		return err
+	}
 	fetchDelay, err := parseDurationSeconds(c.FetchDelay)
 	if err != nil {
 		return err
@@ -273,6 +289,16 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if fetchDelay < 0 {
 		return usage("--fetch-delay must be >= 0")
 	}
+	if c.HookAttempts <= 0 {
+		return usage("--hook-max-attempts must be > 0")
+	}
+	retryBackoff, err := parseDurationSeconds(c.HookBackoff)
+	if err != nil {
+		return err
+	}
+	if retryBackoff <= 0 {
+		return usage("--hook-retry-backoff must be > 0")
+	}
 
 	store, err := loadGmailWatchStore(account)
 	if err != nil {
@@ -282,14 +308,18 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 
 	hookURL := c.HookURL
 	hookToken := c.HookToken
+	hookSecret := c.HookSecret
 	includeBody := c.IncludeBody
 	maxBytes := c.MaxBytes
 
-	if hookURL == "" && state.Hook != nil {
+	if hookURL == "" && !c.Sinks.enabled() && state.Hook != nil {
 		hookURL = state.Hook.URL
 		if !flagProvided(kctx, "hook-token") {
 			hookToken = state.Hook.Token
 		}
+		if !flagProvided(kctx, "hook-secret") {
+			hookSecret = state.Hook.Secret
+		}
 		if !flagProvided(kctx, "include-body") {
 			includeBody = state.Hook.IncludeBody
 		}
@@ -307,6 +337,9 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 			return err
 		}
 	}
+	if err := applyHookSecret(hook, hookSecret); err != nil {
+		return err
+	}
 	if c.SaveHook && hook != nil {
 		if updateErr := store.Update(func(s *gmailWatchState) error {
 			s.Hook = hook
@@ -349,6 +382,7 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if hook != nil {
 		cfg.HookURL = hook.URL
 		cfg.HookToken = hook.Token
+		cfg.HookSecret = hook.Secret
 		cfg.IncludeBody = hook.IncludeBody
 		cfg.MaxBodyBytes = hook.MaxBytes
 	}
@@ -373,9 +407,26 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		newService:      serviceFactory,
 		hookClient:      hookClient,
 		excludeLabelIDs: stringSet(cfg.ExcludeLabels),
+		sinks:           sinks,
 		logf:            u.Err().Printf,
 		warnf:           u.Err().Printf,
 	}
+	if strings.TrimSpace(c.Rules) != "" {
+		server.rules, err = loadWatchRules(c.Rules, account)
+		if err != nil {
+			return err
+		}
+		server.rulesDryRun = flags.DryRun
+	}
+	if hook != nil {
+		server.deliveries, err = newGmailDeliveryQueue(account, c.HookAttempts, retryBackoff)
+		if err != nil {
+			return err
+		}
+		retryCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go server.runDeliveryRetries(retryCtx, retryBackoff)
+	}
 
 	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
 	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
@@ -390,10 +441,15 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 
 func writeWatchState(ctx context.Context, state gmailWatchState, showSecrets bool) error {
 	if outfmt.IsJSON(ctx) {
-		if !showSecrets && state.Hook != nil && state.Hook.Token != "" {
+		if !showSecrets && state.Hook != nil && (state.Hook.Token != "" || state.Hook.Secret != "") {
 			redacted := state
 			h := *state.Hook
-			h.Token = "[REDACTED]"
+			if h.Token != "" {
+				h.Token = "[REDACTED]"
+			}
+			if h.Secret != "" {
+				h.Secret = "[REDACTED]"
+			}
 			redacted.Hook = &h
 			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"watch": redacted})
 		}
@@ -436,6 +492,13 @@ func writeWatchState(ctx context.Context, state gmailWatchState, showSecrets boo
 				u.Out().Printf("hook_token\t[REDACTED]")
 			}
 		}
+		if state.Hook.Secret != "" {
+			if showSecrets {
+				u.Out().Printf("hook_secret\t%s", state.Hook.Secret)
+			} else {
+				u.Out().Printf("hook_secret\t[REDACTED]")
+			}
+		}
 	}
 	if state.LastDeliveryStatus != "" {
 		u.Out().Printf("last_delivery_status\t%s", state.LastDeliveryStatus)
@@ -510,6 +573,17 @@ func hookFromFlags(url, token string, includeBody bool, maxBytes int, maxBytesCh
 	}, nil
 }
 
+func applyHookSecret(hook *gmailWatchHook, secret string) error {
+	if secret == "" {
+		return nil
+	}
+	if hook == nil {
+		return usage("--hook-url required when using --hook-secret")
+	}
+	hook.Secret = secret
+	return nil
+}
+
 func isLoopbackHost(host string) bool {
 	trimmed := strings.TrimSpace(host)
 	if trimmed == "" {
@@ -0,0 +1,557 @@
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailHookSignatureHeader = "X-Gog-Signature"
+	gmailHookDeliveryHeader  = "X-Gog-Delivery"
+	gmailHookAttemptHeader   = "X-Gog-Delivery-Attempt"
+
+	gmailDeliveryPending = "pending"
+	gmailDeliveryDead    = "dead"
+
+	defaultHookMaxAttempts  = 10
+	defaultHookRetryBackoff = 5 * time.Second
+	maxHookRetryBackoff     = 10 * time.Minute
+)
+
+// gmailDelivery is one queued hook payload. Pending deliveries live in
+// <watch dir>/deliveries/<account>/pending, exhausted ones in .../dead.
+// Deliveries carry no URL: they always go to the hook configured when they
+// are sent.
+type gmailDelivery struct {
+	ID            string          `json:"id"`
+	Account       string          `json:"account"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAtMs   int64           `json:"createdAtMs"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptMs int64           `json:"nextAttemptMs,omitempty"`
+	LastAttemptMs int64           `json:"lastAttemptMs,omitempty"`
+	LastError     string          `json:"lastError,omitempty"`
+	DeadAtMs      int64           `json:"deadAtMs,omitempty"`
+	Status        string          `json:"status,omitempty"`
+}
+
+type gmailDeliveryQueue struct {
+	dir         string
+	maxAttempts int
+	backoff     time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	inflight map[string]struct{}
+}
+
+func gmailDeliveriesDir(account string) (string, error) {
+	dir, err := config.EnsureGmailWatchDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "deliveries", sanitizeAccountForPath(account)), nil
+}
+
+func newGmailDeliveryQueue(account string, maxAttempts int, backoff time.Duration) (*gmailDeliveryQueue, error) {
+	dir, err := gmailDeliveriesDir(account)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range []string{gmailDeliveryPending, gmailDeliveryDead} {
+		if err := os.MkdirAll(filepath.Join(dir, status), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultHookMaxAttempts
+	}
+	if backoff <= 0 {
+		backoff = defaultHookRetryBackoff
+	}
+	return &gmailDeliveryQueue{
+		dir:         dir,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		now:         time.Now,
+		inflight:    make(map[string]struct{}),
+	}, nil
+}
+
+func newGmailDeliveryID(now time.Time) string {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("%d-%s", now.UnixMilli(), hex.EncodeToString(b[:]))
+}
+
+func (q *gmailDeliveryQueue) path(status, id string) string {
+	return filepath.Join(q.dir, status, id+".json")
+}
+
+func (q *gmailDeliveryQueue) enqueue(account string, payload []byte) (*gmailDelivery, error) {
+	now := q.now()
+	d := &gmailDelivery{
+		ID:            newGmailDeliveryID(now),
+		Account:       account,
+		Payload:       append(json.RawMessage(nil), payload...),
+		CreatedAtMs:   now.UnixMilli(),
+		NextAttemptMs: now.Add(q.retryDelay(1)).UnixMilli(),
+	}
+	if err := q.save(gmailDeliveryPending, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (q *gmailDeliveryQueue) save(status string, d *gmailDelivery) error {
+	stored := *d
+	stored.Status = ""
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(q.path(status, d.ID), data)
+}
+
+func (q *gmailDeliveryQueue) remove(status, id string) error {
+	if err := os.Remove(q.path(status, id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (q *gmailDeliveryQueue) load(status, id string) (*gmailDelivery, error) {
+	data, err := os.ReadFile(q.path(status, id))
+	if err != nil {
+		return nil, err
+	}
+	var d gmailDelivery
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("delivery %s: %w", id, err)
+	}
+	d.Status = status
+	return &d, nil
+}
+
+// list returns the deliveries with the given status, oldest first.
+func (q *gmailDeliveryQueue) list(status string) ([]gmailDelivery, error) {
+	entries, err := os.ReadDir(filepath.Join(q.dir, status))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out := make([]gmailDelivery, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		d, err := q.load(status, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAtMs != out[j].CreatedAtMs {
+			return out[i].CreatedAtMs < out[j].CreatedAtMs
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (q *gmailDeliveryQueue) due() ([]gmailDelivery, error) {
+	pending, err := q.list(gmailDeliveryPending)
+	if err != nil {
+		return nil, err
+	}
+	now := q.now().UnixMilli()
+	out := pending[:0]
+	for _, d := range pending {
+		if d.NextAttemptMs <= now {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (q *gmailDeliveryQueue) claim(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.inflight[id]; ok {
+		return false
+	}
+	q.inflight[id] = struct{}{}
+	return true
+}
+
+func (q *gmailDeliveryQueue) release(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.inflight, id)
+}
+
+// retryDelay doubles the base backoff per failed attempt, capped at
+// maxHookRetryBackoff.
+func (q *gmailDeliveryQueue) retryDelay(attempts int) time.Duration {
+	delay := q.backoff
+	for i := 1; i < attempts && delay < maxHookRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxHookRetryBackoff {
+		delay = maxHookRetryBackoff
+	}
+	return delay
+}
+
+// recordFailure reschedules d, or moves it to the dead-letter directory once
+// maxAttempts is reached. It reports whether the delivery is now dead.
+func (q *gmailDeliveryQueue) recordFailure(d *gmailDelivery, cause error) (bool, error) {
+	now := q.now()
+	d.LastAttemptMs = now.UnixMilli()
+	d.LastError = cause.Error()
+	if d.Attempts >= q.maxAttempts {
+		d.NextAttemptMs = 0
+		d.DeadAtMs = now.UnixMilli()
+		if err := q.save(gmailDeliveryDead, d); err != nil {
+			return false, err
+		}
+		return true, q.remove(gmailDeliveryPending, d.ID)
+	}
+	d.NextAttemptMs = now.Add(q.retryDelay(d.Attempts)).UnixMilli()
+	return false, q.save(gmailDeliveryPending, d)
+}
+
+func signHookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *gmailWatchServer) enqueueAndDeliver(ctx context.Context, data []byte) error {
+	d, err := s.deliveries.enqueue(s.cfg.Account, data)
+	if err != nil {
+		s.warnf("watch: delivery queue: %v", err)
+		return s.postHook(ctx, data, "", 0)
+	}
+	return s.attemptDelivery(ctx, d)
+}
+
+// attemptDelivery posts a pending delivery once and updates the queue. d may
+// be a stale copy from an earlier listing; the queued file is authoritative.
+func (s *gmailWatchServer) attemptDelivery(ctx context.Context, d *gmailDelivery) error {
+	if !s.deliveries.claim(d.ID) {
+		return nil
+	}
+	defer s.deliveries.release(d.ID)
+
+	current, err := s.deliveries.load(gmailDeliveryPending, d.ID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Delivered or dead-lettered since d was read.
+			return nil
+		}
+		return err
+	}
+	if current.Attempts != d.Attempts {
+		// Attempted (and rescheduled) since d was read.
+		return nil
+	}
+	d = current
+
+	d.Attempts++
+	err = s.postHook(ctx, d.Payload, d.ID, d.Attempts)
+	if err == nil {
+		return s.deliveries.remove(gmailDeliveryPending, d.ID)
+	}
+	dead, qerr := s.deliveries.recordFailure(d, err)
+	if qerr != nil {
+		s.warnf("watch: delivery queue: %v", qerr)
+	}
+	if dead {
+		s.warnf("watch: delivery %s moved to dead letter after %d attempts", d.ID, d.Attempts)
+	}
+	return err
+}
+
+func (s *gmailWatchServer) retryDueDeliveries(ctx context.Context) {
+	due, err := s.deliveries.due()
+	if err != nil {
+		s.warnf("watch: delivery queue: %v", err)
+		return
+	}
+	for i := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.attemptDelivery(ctx, &due[i]); err != nil {
+			s.warnf("watch: hook retry %s failed: %v", due[i].ID, err)
+		}
+	}
+}
+
+// runDeliveryRetries retries due deliveries (including ones left over from a
+// previous run) until ctx is done.
+func (s *gmailWatchServer) runDeliveryRetries(ctx context.Context, interval time.Duration) {
+	s.retryDueDeliveries(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retryDueDeliveries(ctx)
+		}
+	}
+}
+
+type GmailWatchDeliveriesCmd struct {
+	List   GmailWatchDeliveriesListCmd   `cmd:"" name:"list" aliases:"ls" help:"List queued and dead-lettered hook deliveries"`
+	Replay GmailWatchDeliveriesReplayCmd `cmd:"" name:"replay" aliases:"retry" help:"Re-send queued or dead-lettered hook deliveries"`
+}
+
+type GmailWatchDeliveriesListCmd struct {
+	Status string `name:"status" help:"Filter by status: all|pending|dead" enum:"all,pending,dead" default:"all"`
+}
+
+func (c *GmailWatchDeliveriesListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	queue, err := newGmailDeliveryQueue(account, 0, 0)
+	if err != nil {
+		return err
+	}
+	deliveries, err := listGmailDeliveries(queue, c.Status)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		items := make([]map[string]any, 0, len(deliveries))
+		for _, d := range deliveries {
+			items = append(items, gmailDeliveryOutput(d))
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"deliveries": items})
+	}
+	if len(deliveries) == 0 {
+		u.Err().Println("No deliveries")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tSTATUS\tATTEMPTS\tCREATED\tNEXT_ATTEMPT\tLAST_ERROR")
+	for _, d := range deliveries {
+		next := ""
+		if d.NextAttemptMs > 0 && d.Status == gmailDeliveryPending {
+			next = formatUnixMillis(d.NextAttemptMs)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", d.ID, d.Status, d.Attempts, formatUnixMillis(d.CreatedAtMs), next, sanitizeTab(d.LastError))
+	}
+	return nil
+}
+
+func listGmailDeliveries(queue *gmailDeliveryQueue, status string) ([]gmailDelivery, error) {
+	statuses := []string{gmailDeliveryPending, gmailDeliveryDead}
+	if status != "" && status != "all" {
+		statuses = []string{status}
+	}
+	var out []gmailDelivery
+	for _, st := range statuses {
+		items, err := queue.list(st)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+	}
+	return out, nil
+}
+
+func gmailDeliveryOutput(d gmailDelivery) map[string]any {
+	out := map[string]any{
+		"id":          d.ID,
+		"status":      d.Status,
+		"account":     d.Account,
+		"attempts":    d.Attempts,
+		"createdAtMs": d.CreatedAtMs,
+	}
+	if d.NextAttemptMs > 0 && d.Status == gmailDeliveryPending {
+		out["nextAttemptMs"] = d.NextAttemptMs
+	}
+	if d.LastAttemptMs > 0 {
+		out["lastAttemptMs"] = d.LastAttemptMs
+	}
+	if d.LastError != "" {
+		out["lastError"] = d.LastError
+	}
+	if d.DeadAtMs > 0 {
+		out["deadAtMs"] = d.DeadAtMs
+	}
+	return out
+}
+
+type GmailWatchDeliveriesReplayCmd struct {
+	IDs        []string `arg:"" name:"id" optional:"" help:"Delivery IDs (see 'gog gmail watch deliveries list')"`
+	All        bool     `name:"all" help:"Replay every delivery with --status"`
+	Status     string   `name:"status" help:"Which deliveries to replay: dead|pending" enum:"dead,pending" default:"dead"`
+	HookURL    string   `name:"hook-url" help:"Webhook URL (default: stored hook)"`
+	HookToken  string   `name:"hook-token" help:"Webhook bearer token (default: stored hook, unless --hook-url is set)"`
+	HookSecret string   `name:"hook-secret" help:"HMAC-SHA256 signing secret (default: stored hook, unless --hook-url is set)"`
+}
+
+func (c *GmailWatchDeliveriesReplayCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.All == (len(c.IDs) > 0) {
+		return usage("specify delivery IDs or --all")
+	}
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	store, err := loadGmailWatchStore(account)
+	if err != nil {
+		return err
+	}
+	state := store.Get()
+
+	hookURL := strings.TrimSpace(c.HookURL)
+	hookToken := c.HookToken
+	hookSecret := c.HookSecret
+	if state.Hook != nil && hookURL == "" {
+		// The stored credentials belong to the stored hook; a --hook-url
+		// elsewhere only gets the token and secret given on the command line.
+		hookURL = state.Hook.URL
+		if !flagProvided(kctx, "hook-token") {
+			hookToken = state.Hook.Token
+		}
+		if !flagProvided(kctx, "hook-secret") {
+			hookSecret = state.Hook.Secret
+		}
+	}
+	if hookURL == "" {
+		return usage("--hook-url required (no stored hook)")
+	}
+
+	queue, err := newGmailDeliveryQueue(account, 0, 0)
+	if err != nil {
+		return err
+	}
+	var deliveries []gmailDelivery
+	if c.All {
+		deliveries, err = queue.list(c.Status)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, id := range c.IDs {
+			d, loadErr := queue.load(c.Status, strings.TrimSpace(id))
+			if loadErr != nil {
+				if errors.Is(loadErr, os.ErrNotExist) {
+					return usagef("no %s delivery %q", c.Status, id)
+				}
+				return loadErr
+			}
+			deliveries = append(deliveries, *d)
+		}
+	}
+
+	ids := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.ID)
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.deliveries.replay", map[string]any{
+		"status":   c.Status,
+		"ids":      ids,
+		"hook_url": hookURL,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	server := &gmailWatchServer{
+		cfg: gmailWatchServeConfig{
+			Account:    account,
+			HookURL:    hookURL,
+			HookToken:  hookToken,
+			HookSecret: hookSecret,
+		},
+		store:      store,
+		hookClient: &http.Client{Timeout: defaultHookRequestTimeoutSec * time.Second},
+	}
+
+	results := make([]map[string]any, 0, len(deliveries))
+	failed := 0
+	for i := range deliveries {
+		d := &deliveries[i]
+		d.Attempts++
+		sendErr := server.postHook(ctx, d.Payload, d.ID, d.Attempts)
+		result := map[string]any{"id": d.ID, "attempts": d.Attempts, "delivered": sendErr == nil}
+		if sendErr == nil {
+			if err := queue.remove(c.Status, d.ID); err != nil {
+				return err
+			}
+		} else {
+			failed++
+			d.LastAttemptMs = time.Now().UnixMilli()
+			d.LastError = sendErr.Error()
+			if err := queue.save(c.Status, d); err != nil {
+				return err
+			}
+			result["error"] = sendErr.Error()
+		}
+		results = append(results, result)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"replayed":  len(deliveries) - failed,
+			"failed":    failed,
+			"results":   results,
+			"hookUrl":   hookURL,
+			"fromQueue": c.Status,
+		}); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			if r["delivered"] == true {
+				u.Out().Printf("delivered\t%s", r["id"])
+			} else {
+				u.Out().Printf("failed\t%s\t%s", r["id"], r["error"])
+			}
+		}
+		u.Out().Printf("replayed\t%d", len(deliveries)-failed)
+		u.Out().Printf("failed\t%d", failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d deliveries failed", failed)
+	}
+	return nil
+}
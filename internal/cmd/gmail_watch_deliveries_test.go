@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGmailDeliveryQueue_RetryThenDeadLetter(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	queue, err := newGmailDeliveryQueue("a@b.com", 3, time.Second)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	now := time.UnixMilli(1_700_000_000_000)
+	queue.now = func() time.Time { return now }
+
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	var warnings []string
+	server := &gmailWatchServer{
+		cfg:   gmailWatchServeConfig{Account: "a@b.com", HookURL: "https://example.com/hook"},
+		store: store,
+		hookClient: &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("dial failed")
+		})},
+		deliveries: queue,
+		logf:       func(string, ...any) {},
+		warnf:      func(format string, args ...any) { warnings = append(warnings, format) },
+	}
+
+	if err := server.sendHook(context.Background(), &gmailHookPayload{Source: "gmail", Account: "a@b.com", HistoryID: "1"}); err == nil {
+		t.Fatalf("expected hook error")
+	}
+	pending, err := queue.list(gmailDeliveryPending)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected one pending delivery, got %#v %v", pending, err)
+	}
+	if pending[0].Attempts != 1 || pending[0].NextAttemptMs != now.Add(time.Second).UnixMilli() {
+		t.Fatalf("unexpected schedule: %#v", pending[0])
+	}
+
+	if due, _ := queue.due(); len(due) != 0 {
+		t.Fatalf("expected nothing due yet, got %d", len(due))
+	}
+	now = now.Add(time.Second)
+	server.retryDueDeliveries(context.Background())
+	pending, _ = queue.list(gmailDeliveryPending)
+	if len(pending) != 1 || pending[0].Attempts != 2 || pending[0].NextAttemptMs != now.Add(2*time.Second).UnixMilli() {
+		t.Fatalf("expected doubled backoff, got %#v", pending)
+	}
+
+	now = now.Add(2 * time.Second)
+	server.retryDueDeliveries(context.Background())
+	pending, _ = queue.list(gmailDeliveryPending)
+	dead, _ := queue.list(gmailDeliveryDead)
+	if len(pending) != 0 || len(dead) != 1 {
+		t.Fatalf("expected dead letter, got pending=%d dead=%d", len(pending), len(dead))
+	}
+	if dead[0].Attempts != 3 || dead[0].LastError != "Post \"https://example.com/hook\": dial failed" || dead[0].DeadAtMs == 0 {
+		t.Fatalf("unexpected dead delivery: %#v", dead[0])
+	}
+	if !strings.Contains(strings.Join(warnings, "\n"), "dead letter") {
+		t.Fatalf("expected dead-letter warning, got %v", warnings)
+	}
+}
+
+func TestGmailWatchServer_DeliverAndRetryConcurrently(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	var (
+		mu    sync.Mutex
+		posts = map[string]int{}
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posts[r.Header.Get(gmailHookDeliveryHeader)]++
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hook.Close()
+
+	queue, err := newGmailDeliveryQueue("a@b.com", 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	// Everything enqueued is already due, so retry passes compete with the
+	// first delivery attempt.
+	later := time.Now().Add(time.Hour)
+	queue.now = func() time.Time { return later }
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	server := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com", HookURL: hook.URL},
+		store:      store,
+		hookClient: hook.Client(),
+		deliveries: queue,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	retried := make(chan struct{})
+	go func() {
+		defer close(retried)
+		for ctx.Err() == nil {
+			server.retryDueDeliveries(ctx)
+		}
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.enqueueAndDeliver(context.Background(), []byte(`{"historyId":"1"}`)); err != nil {
+				t.Errorf("deliver: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	cancel()
+	<-retried
+	server.retryDueDeliveries(context.Background())
+
+	mu.Lock()
+	if len(posts) != 20 {
+		t.Fatalf("expected 20 deliveries, got %d", len(posts))
+	}
+	for id, n := range posts {
+		if n != 1 {
+			t.Fatalf("delivery %s posted %d times", id, n)
+		}
+	}
+	mu.Unlock()
+	if pending, _ := queue.list(gmailDeliveryPending); len(pending) != 0 {
+		t.Fatalf("expected queue to be empty, got %d", len(pending))
+	}
+
+	// A stale copy from an earlier listing must not be re-sent.
+	d, err := queue.enqueue("a@b.com", []byte(`{"historyId":"2"}`))
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	stale := *d
+	if err := server.attemptDelivery(context.Background(), d); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if err := server.attemptDelivery(context.Background(), &stale); err != nil {
+		t.Fatalf("stale retry: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posts[d.ID] != 1 {
+		t.Fatalf("stale copy re-sent: %d posts", posts[d.ID])
+	}
+}
+
+func TestGmailDeliveryQueue_RetryDelayCapped(t *testing.T) {
+	queue := &gmailDeliveryQueue{backoff: 5 * time.Second}
+	if got := queue.retryDelay(1); got != 5*time.Second {
+		t.Fatalf("attempt 1: %v", got)
+	}
+	if got := queue.retryDelay(4); got != 40*time.Second {
+		t.Fatalf("attempt 4: %v", got)
+	}
+	if got := queue.retryDelay(30); got != maxHookRetryBackoff {
+		t.Fatalf("attempt 30: %v", got)
+	}
+}
+
+func TestGmailWatchServer_SendHook_SignsAndClearsQueue(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	var (
+		mu      sync.Mutex
+		headers http.Header
+		body    []byte
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hook.Close()
+
+	queue, err := newGmailDeliveryQueue("a@b.com", 0, 0)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	server := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com", HookURL: hook.URL, HookSecret: "s3cret"},
+		store:      store,
+		hookClient: hook.Client(),
+		deliveries: queue,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+
+	if err := server.sendHook(context.Background(), &gmailHookPayload{Source: "gmail", Account: "a@b.com", HistoryID: "7"}); err != nil {
+		t.Fatalf("sendHook: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := headers.Get(gmailHookSignatureHeader), signHookPayload("s3cret", body); got != want || !strings.HasPrefix(got, "sha256=") {
+		t.Fatalf("signature %q, want %q", got, want)
+	}
+	if headers.Get(gmailHookDeliveryHeader) == "" || headers.Get(gmailHookAttemptHeader) != "1" {
+		t.Fatalf("missing delivery headers: %v", headers)
+	}
+	if pending, _ := queue.list(gmailDeliveryPending); len(pending) != 0 {
+		t.Fatalf("expected queue to be empty, got %d", len(pending))
+	}
+	if store.Get().LastDeliveryStatus != "ok" {
+		t.Fatalf("expected ok status, got %q", store.Get().LastDeliveryStatus)
+	}
+}
+
+func TestExecute_GmailWatchDeliveries_ListAndReplay(t *testing.T) {
+	setWatchTestConfigHome(t)
+	t.Setenv("GOG_ACCOUNT", "a@b.com")
+
+	var received []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(gmailHookSignatureHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer hook.Close()
+
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Account = "a@b.com"
+		s.HistoryID = "1"
+		s.Hook = &gmailWatchHook{URL: hook.URL, Token: "tok", Secret: "stored"}
+		return nil
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	queue, err := newGmailDeliveryQueue("a@b.com", 1, 0)
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	d, err := queue.enqueue("a@b.com", []byte(`{"historyId":"1"}`))
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	d.Attempts = 1
+	if _, err := queue.recordFailure(d, errors.New("boom")); err != nil {
+		t.Fatalf("recordFailure: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--json", "gmail", "watch", "deliveries", "list"}); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	var listed struct {
+		Deliveries []map[string]any `json:"deliveries"`
+	}
+	if err := json.Unmarshal([]byte(out), &listed); err != nil {
+		t.Fatalf("decode: %v (%s)", err, out)
+	}
+	if len(listed.Deliveries) != 1 || listed.Deliveries[0]["status"] != gmailDeliveryDead || listed.Deliveries[0]["lastError"] != "boom" {
+		t.Fatalf("unexpected list output: %s", out)
+	}
+
+	_ = captureStderr(t, func() {
+		if err := Execute([]string{"gmail", "watch", "deliveries", "replay"}); err == nil {
+			t.Fatalf("expected usage error without ids")
+		}
+	})
+
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"--json", "gmail", "watch", "deliveries", "replay", "--all"}); err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+	})
+	if len(received) != 1 || received[0] != signHookPayload("stored", []byte(`{"historyId":"1"}`)) {
+		t.Fatalf("unexpected hook calls: %v", received)
+	}
+	if dead, _ := queue.list(gmailDeliveryDead); len(dead) != 0 {
+		t.Fatalf("expected dead letter to be cleared, got %d", len(dead))
+	}
+
+	// A different --hook-url must not receive the stored token or secret.
+	var otherHeaders http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer other.Close()
+	d, err = queue.enqueue("a@b.com", []byte(`{"historyId":"2"}`))
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"--json", "gmail", "watch", "deliveries", "replay", "--status", "pending", "--hook-url", other.URL, d.ID}); err != nil {
+			t.Fatalf("replay elsewhere: %v", err)
+		}
+	})
+	if otherHeaders == nil {
+		t.Fatal("expected the other hook to be called")
+	}
+	if otherHeaders.Get("Authorization") != "" || otherHeaders.Get(gmailHookSignatureHeader) != "" {
+		t.Fatalf("stored credentials leaked to --hook-url: %v", otherHeaders)
+	}
+}
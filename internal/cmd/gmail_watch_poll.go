@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/authclient"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var watchPollSleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type GmailWatchPollCmd struct {
+	Interval      string         `name:"interval" help:"Polling interval (seconds or duration)" default:"30s"`
+	Once          bool           `name:"once" help:"Poll once and exit"`
+	Timezone      string         `name:"timezone" short:"z" help:"Output timezone (IANA name, e.g. America/New_York, UTC). Default: local"`
+	Local         bool           `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
+	HookURL       string         `name:"hook-url" help:"Webhook URL to forward messages"`
+	HookToken     string         `name:"hook-token" help:"Webhook bearer token"`
+	HookSecret    string         `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature header)"`
+	HookAttempts  int            `name:"hook-max-attempts" help:"Delivery attempts before a payload is dead-lettered" default:"10"`
+	HookBackoff   string         `name:"hook-retry-backoff" help:"Initial retry delay, doubled per attempt up to 10m (seconds or duration)" default:"5s"`
+	IncludeBody   bool           `name:"include-body" help:"Include text/plain body in hook payload"`
+	MaxBytes      int            `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
+	HistoryTypes  []string       `name:"history-types" help:"History types to include (repeatable, comma-separated: messageAdded,messageDeleted,labelAdded,labelRemoved). Default: messageAdded"`
+	ExcludeLabels string         `name:"exclude-labels" help:"List of Gmail label IDs to exclude from hook payload (e.g. SPAM,TRASH,Label_123). Set to empty string to disable." default:"SPAM,TRASH"`
+	SaveHook      bool           `name:"save-hook" help:"Persist hook settings to watch state"`
+	Rules         string         `name:"rules" help:"Rules file (YAML/JSON) evaluated for each new message before delivery"`
+	Sinks         WatchSinkFlags `embed:""`
+}
+
+func (c *GmailWatchPollCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	interval, err := parseDurationSeconds(c.Interval)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return usage("--interval must be > 0")
+	}
+	sinks, err := c.Sinks.build()
+	if err != nil {
+		return err
+	}
+	if c.HookAttempts <= 0 {
+		return usage("--hook-max-attempts must be > 0")
+	}
+	retryBackoff, err := parseDurationSeconds(c.HookBackoff)
+	if err != nil {
+		return err
+	}
+	if retryBackoff <= 0 {
+		return usage("--hook-retry-backoff must be > 0")
+	}
+	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	if err != nil {
+		return err
+	}
+	historyTypes, err := parseHistoryTypes(c.HistoryTypes)
+	if err != nil {
+		return err
+	}
+
+	store, err := openGmailWatchStore(account)
+	if err != nil {
+		return err
+	}
+	state := store.Get()
+
+	hookURL := c.HookURL
+	hookToken := c.HookToken
+	hookSecret := c.HookSecret
+	includeBody := c.IncludeBody
+	maxBytes := c.MaxBytes
+	if hookURL == "" && !c.Sinks.enabled() && state.Hook != nil {
+		hookURL = state.Hook.URL
+		if !flagProvided(kctx, "hook-token") {
+			hookToken = state.Hook.Token
+		}
+		if !flagProvided(kctx, "hook-secret") {
+			hookSecret = state.Hook.Secret
+		}
+		if !flagProvided(kctx, "include-body") {
+			includeBody = state.Hook.IncludeBody
+		}
+		if !flagProvided(kctx, "max-bytes") && state.Hook.MaxBytes > 0 {
+			maxBytes = state.Hook.MaxBytes
+		}
+	}
+
+	hook, err := hookFromFlags(hookURL, hookToken, includeBody, maxBytes, flagProvided(kctx, "max-bytes"), true)
+	if err != nil {
+		if errors.Is(err, errNoHookConfigured) {
+			hook = nil
+		} else {
+			return err
+		}
+	}
+	if err := applyHookSecret(hook, hookSecret); err != nil {
+		return err
+	}
+	if c.SaveHook && hook != nil {
+		if updateErr := store.Update(func(s *gmailWatchState) error {
+			s.Hook = hook
+			s.UpdatedAtMs = time.Now().UnixMilli()
+			return nil
+		}); updateErr != nil {
+			return updateErr
+		}
+	}
+
+	cfg := gmailWatchServeConfig{
+		Account:       account,
+		HookTimeout:   defaultHookRequestTimeoutSec * time.Second,
+		HistoryMax:    defaultHistoryMaxResults,
+		ResyncMax:     defaultHistoryResyncMax,
+		HistoryTypes:  historyTypes,
+		AllowNoHook:   hook == nil,
+		IncludeBody:   includeBody,
+		MaxBodyBytes:  maxBytes,
+		DateLocation:  loc,
+		ExcludeLabels: splitCommaList(c.ExcludeLabels),
+		VerboseOutput: flags.Verbose,
+	}
+	if hook != nil {
+		cfg.HookURL = hook.URL
+		cfg.HookToken = hook.Token
+		cfg.HookSecret = hook.Secret
+		cfg.IncludeBody = hook.IncludeBody
+		cfg.MaxBodyBytes = hook.MaxBytes
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaultHookMaxBytes
+	}
+
+	selectedClient := strings.TrimSpace(flags.Client)
+	server := &gmailWatchServer{
+		cfg:   cfg,
+		store: store,
+		newService: func(ctx context.Context, account string) (*gmail.Service, error) {
+			if selectedClient != "" {
+				ctx = authclient.WithClient(ctx, selectedClient)
+			}
+			return newGmailService(ctx, account)
+		},
+		hookClient:      &http.Client{Timeout: cfg.HookTimeout},
+		excludeLabelIDs: stringSet(cfg.ExcludeLabels),
+		logf:            u.Err().Printf,
+		warnf:           u.Err().Printf,
+	}
+
+	if strings.TrimSpace(c.Rules) != "" {
+		server.rules, err = loadWatchRules(c.Rules, account)
+		if err != nil {
+			return err
+		}
+		server.rulesDryRun = flags.DryRun
+	}
+
+	if len(sinks) == 0 && hook == nil {
+		sinks = watchSinks{&watchWriterSink{w: os.Stdout}}
+	}
+	server.sinks = sinks
+	if hook != nil {
+		server.deliveries, err = newGmailDeliveryQueue(account, c.HookAttempts, retryBackoff)
+		if err != nil {
+			return err
+		}
+		if !c.Once {
+			retryCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go server.runDeliveryRetries(retryCtx, retryBackoff)
+		}
+	}
+	deliver := func(ctx context.Context, payload *gmailHookPayload) error {
+		err := server.sinks.deliver(ctx, server.warnf, payload, gmailWatchSinkEnv(payload)...)
+		if hook != nil {
+			if hookErr := server.sendHook(ctx, payload); hookErr != nil && err == nil {
+				err = hookErr
+			}
+		}
+		return err
+	}
+
+	if c.Once {
+		return server.poll(ctx, deliver)
+	}
+
+	u.Err().Printf("watch: polling every %s", interval)
+	for {
+		if err := server.poll(ctx, deliver); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			u.Err().Printf("watch: poll failed: %v", err)
+		}
+		if err := watchPollSleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// poll compares the mailbox historyId with the stored one and, when it moved,
+// runs the push pipeline as if a Pub/Sub notification had arrived. The first
+// poll without stored state only records the current historyId.
+func (s *gmailWatchServer) poll(ctx context.Context, deliver func(context.Context, *gmailHookPayload) error) error {
+	svc, err := s.newService(ctx, s.cfg.Account)
+	if err != nil {
+		return err
+	}
+	profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	current := formatHistoryID(profile.HistoryId)
+	if current == "" {
+		return errors.New("profile missing historyId")
+	}
+
+	state := s.store.Get()
+	if strings.TrimSpace(state.HistoryID) == "" {
+		if err := s.store.Update(func(st *gmailWatchState) error {
+			st.Account = s.cfg.Account
+			st.HistoryID = current
+			st.UpdatedAtMs = time.Now().UnixMilli()
+			return nil
+		}); err != nil {
+			return err
+		}
+		s.logf("watch: starting at historyId=%s", current)
+		return nil
+	}
+	if stale, err := isStaleHistoryID(state.HistoryID, current); err != nil || stale {
+		return err
+	}
+
+	payload, err := s.handlePush(ctx, gmailPushPayload{EmailAddress: s.cfg.Account, HistoryID: current})
+	if err != nil {
+		if errors.Is(err, errNoNewMessages) {
+			return nil
+		}
+		return err
+	}
+	s.applyRules(ctx, payload)
+	if err := deliver(ctx, payload); err != nil {
+		s.warnf("watch: delivery failed: %v", err)
+	}
+	return nil
+}
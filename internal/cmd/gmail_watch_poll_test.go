@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+func TestGmailWatchPollCmd_SeedsThenDeliversNewMessages(t *testing.T) {
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+
+	setWatchTestConfigHome(t)
+
+	profileHistoryID := "100"
+	var historyCalls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/gmail/v1/users/me/profile"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": profileHistoryID})
+		case strings.Contains(r.URL.Path, "/gmail/v1/users/me/history"):
+			historyCalls++
+			if got := r.URL.Query().Get("startHistoryId"); got != "100" {
+				t.Errorf("unexpected startHistoryId %q", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "105",
+				"history": []map[string]any{
+					{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m1"}}, {"message": map[string]any{"id": "m2"}}}},
+				},
+			})
+		case strings.Contains(r.URL.Path, "/gmail/v1/users/me/messages/m1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":       "m1",
+				"threadId": "t1",
+				"labelIds": []string{"INBOX"},
+				"payload":  map[string]any{"headers": []map[string]any{{"name": "Subject", "value": "Hello"}}},
+			})
+		case strings.Contains(r.URL.Path, "/gmail/v1/users/me/messages/m2"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m2", "threadId": "t2", "labelIds": []string{"SPAM"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	ctx := ui.WithUI(context.Background(), u)
+	flags := &RootFlags{Account: "a@b.com"}
+	outFile := filepath.Join(t.TempDir(), "payload.json")
+	args := []string{"--once", "--exec", "cat > " + outFile}
+
+	// First poll only records the starting point.
+	if err := runKong(t, &GmailWatchPollCmd{}, args, ctx, flags); err != nil {
+		t.Fatalf("seed poll: %v", err)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load store: %v", err)
+	}
+	if store.Get().HistoryID != "100" || historyCalls != 0 {
+		t.Fatalf("expected seeded historyId 100 without history call, got %q (%d calls)", store.Get().HistoryID, historyCalls)
+	}
+
+	profileHistoryID = "105"
+	if err := runKong(t, &GmailWatchPollCmd{}, args, ctx, flags); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("read exec output: %v", err)
+	}
+	var payload gmailHookPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("decode payload: %v (%s)", err, data)
+	}
+	if payload.HistoryID != "105" || len(payload.Messages) != 1 || payload.Messages[0].Subject != "Hello" {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+	store, _ = loadGmailWatchStore("a@b.com")
+	if store.Get().HistoryID != "105" {
+		t.Fatalf("expected historyId 105, got %q", store.Get().HistoryID)
+	}
+
+	// Nothing new: no history call.
+	if err := runKong(t, &GmailWatchPollCmd{}, args, ctx, flags); err != nil {
+		t.Fatalf("idle poll: %v", err)
+	}
+	if historyCalls != 1 {
+		t.Fatalf("expected no history call when idle, got %d", historyCalls)
+	}
+}
+
+func TestGmailWatchPollCmd_Validation(t *testing.T) {
+	setWatchTestConfigHome(t)
+	flags := &RootFlags{Account: "a@b.com"}
+	cases := [][]string{
+		{"--interval", "0"},
+		{"--exec", "cat", "--exec-concurrency", "0"},
+		{"--exec", "cat", "--exec-timeout", "0"},
+		{"--hook-secret", "s"},
+	}
+	for _, args := range cases {
+		if err := runKong(t, &GmailWatchPollCmd{}, args, context.Background(), flags); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,721 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/chat/v1"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/tasks/v1"
+	"gopkg.in/yaml.v3"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const defaultRuleAutoReplyLabel = "AutoReplied"
+
+// gmailRuleSet is the rules file: YAML or JSON (JSON is valid YAML).
+type gmailRuleSet struct {
+	Rules []gmailRule `yaml:"rules"`
+}
+
+type gmailRule struct {
+	Name    string            `yaml:"name"`
+	Match   gmailRuleMatch    `yaml:"match"`
+	Actions []gmailRuleAction `yaml:"actions"`
+	Stop    bool              `yaml:"stop"`
+}
+
+// gmailRuleMatch conditions are ANDed. Text conditions are case-insensitive
+// regular expressions.
+type gmailRuleMatch struct {
+	From          string   `yaml:"from"`
+	To            string   `yaml:"to"`
+	Subject       string   `yaml:"subject"`
+	Body          string   `yaml:"body"`
+	Labels        []string `yaml:"labels"`
+	HasAttachment *bool    `yaml:"hasAttachment"`
+	Attachment    string   `yaml:"attachment"`
+}
+
+// gmailRuleAction sets exactly one action.
+type gmailRuleAction struct {
+	Label       string               `yaml:"label"`
+	RemoveLabel string               `yaml:"removeLabel"`
+	Archive     bool                 `yaml:"archive"`
+	MarkRead    bool                 `yaml:"markRead"`
+	Forward     string               `yaml:"forward"`
+	AutoReply   *gmailRuleAutoReply  `yaml:"autoReply"`
+	SaveToDrive *gmailRuleDriveSave  `yaml:"saveToDrive"`
+	Task        *gmailRuleTask       `yaml:"task"`
+	Chat        *gmailRuleChatAction `yaml:"chat"`
+}
+
+type gmailRuleAutoReply struct {
+	Subject   string `yaml:"subject"`
+	Body      string `yaml:"body"`
+	BodyFile  string `yaml:"bodyFile"`
+	BodyHTML  string `yaml:"bodyHtml"`
+	From      string `yaml:"from"`
+	ReplyTo   string `yaml:"replyTo"`
+	Label     string `yaml:"label"`
+	Archive   bool   `yaml:"archive"`
+	MarkRead  bool   `yaml:"markRead"`
+	SkipBulk  *bool  `yaml:"skipBulk"`
+	AllowSelf bool   `yaml:"allowSelf"`
+}
+
+type gmailRuleDriveSave struct {
+	Folder string `yaml:"folder"`
+}
+
+type gmailRuleTask struct {
+	List  string `yaml:"list"`
+	Title string `yaml:"title"`
+	Notes string `yaml:"notes"`
+}
+
+type gmailRuleChatAction struct {
+	Space string `yaml:"space"`
+	Text  string `yaml:"text"`
+}
+
+type gmailRuleEngine struct {
+	rules []compiledGmailRule
+}
+
+type compiledGmailRule struct {
+	gmailRule
+	from       *regexp.Regexp
+	to         *regexp.Regexp
+	subject    *regexp.Regexp
+	body       *regexp.Regexp
+	attachment *regexp.Regexp
+}
+
+// gmailRuleMessage is the view of a message that rules match against.
+type gmailRuleMessage struct {
+	msg         *gmail.Message
+	from        string
+	to          string
+	subject     string
+	body        string
+	labels      []string
+	attachments []attachmentInfo
+}
+
+type gmailRuleOutcome struct {
+	Rule    string   `json:"rule"`
+	Actions []string `json:"actions"`
+	Errors  []string `json:"errors,omitempty"`
+	// Repeat is set when the rule already ran for the message; its actions
+	// were skipped.
+	Repeat bool `json:"repeat,omitempty"`
+}
+
+func loadGmailRules(path string) (*gmailRuleEngine, error) {
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(expanded) //nolint:gosec // user-provided rules path
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	return parseGmailRules(data)
+}
+
+func parseGmailRules(data []byte) (*gmailRuleEngine, error) {
+	var set gmailRuleSet
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&set); err != nil {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+	if len(set.Rules) == 0 {
+		return nil, usage("rules file has no rules")
+	}
+
+	engine := &gmailRuleEngine{rules: make([]compiledGmailRule, 0, len(set.Rules))}
+	for i, rule := range set.Rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		compiled := compiledGmailRule{gmailRule: rule}
+		for _, field := range []struct {
+			name string
+			expr string
+			dst  **regexp.Regexp
+		}{
+			{"from", rule.Match.From, &compiled.from},
+			{"to", rule.Match.To, &compiled.to},
+			{"subject", rule.Match.Subject, &compiled.subject},
+			{"body", rule.Match.Body, &compiled.body},
+			{"attachment", rule.Match.Attachment, &compiled.attachment},
+		} {
+			if field.expr == "" {
+				continue
+			}
+			re, err := regexp.Compile("(?i)" + field.expr)
+			if err != nil {
+				return nil, usagef("rule %q: invalid %s pattern: %v", rule.Name, field.name, err)
+			}
+			*field.dst = re
+		}
+		if len(rule.Actions) == 0 {
+			return nil, usagef("rule %q has no actions", rule.Name)
+		}
+		for j := range rule.Actions {
+			if err := validateGmailRuleAction(&compiled.Actions[j]); err != nil {
+				return nil, usagef("rule %q action %d: %v", rule.Name, j+1, err)
+			}
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+func validateGmailRuleAction(a *gmailRuleAction) error {
+	set := 0
+	for _, ok := range []bool{
+		strings.TrimSpace(a.Label) != "",
+		strings.TrimSpace(a.RemoveLabel) != "",
+		a.Archive,
+		a.MarkRead,
+		strings.TrimSpace(a.Forward) != "",
+		a.AutoReply != nil,
+		a.SaveToDrive != nil,
+		a.Task != nil,
+		a.Chat != nil,
+	} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("set exactly one of label, removeLabel, archive, markRead, forward, autoReply, saveToDrive, task, chat")
+	}
+	switch {
+	case a.AutoReply != nil:
+		body, err := resolveBodyInput(a.AutoReply.Body, a.AutoReply.BodyFile)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(body) == "" && strings.TrimSpace(a.AutoReply.BodyHTML) == "" {
+			return errors.New("autoReply requires body, bodyFile, or bodyHtml")
+		}
+		a.AutoReply.Body = body
+		a.AutoReply.BodyFile = ""
+	case a.SaveToDrive != nil && strings.TrimSpace(a.SaveToDrive.Folder) == "":
+		return errors.New("saveToDrive requires folder")
+	case a.Task != nil && strings.TrimSpace(a.Task.Title) == "":
+		return errors.New("task requires title")
+	case a.Chat != nil:
+		if _, err := normalizeSpace(a.Chat.Space); err != nil {
+			return fmt.Errorf("chat: %w", err)
+		}
+		if strings.TrimSpace(a.Chat.Text) == "" {
+			return errors.New("chat requires text")
+		}
+	}
+	return nil
+}
+
+func (a gmailRuleAction) kind() string {
+	switch {
+	case a.Label != "":
+		return "label:" + a.Label
+	case a.RemoveLabel != "":
+		return "removeLabel:" + a.RemoveLabel
+	case a.Archive:
+		return "archive"
+	case a.MarkRead:
+		return "markRead"
+	case a.Forward != "":
+		return "forward:" + a.Forward
+	case a.AutoReply != nil:
+		return "autoReply"
+	case a.SaveToDrive != nil:
+		return "saveToDrive:" + a.SaveToDrive.Folder
+	case a.Task != nil:
+		return "task"
+	default:
+		return "chat:" + a.Chat.Space
+	}
+}
+
+func (e *gmailRuleEngine) sends() bool {
+	for _, r := range e.rules {
+		for _, a := range r.Actions {
+			if a.Forward != "" || a.AutoReply != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newGmailRuleMessage(msg *gmail.Message) gmailRuleMessage {
+	return gmailRuleMessage{
+		msg:         msg,
+		from:        headerValue(msg.Payload, "From"),
+		to:          strings.Join([]string{headerValue(msg.Payload, "To"), headerValue(msg.Payload, "Cc")}, ", "),
+		subject:     headerValue(msg.Payload, "Subject"),
+		body:        bestBodyText(msg.Payload),
+		labels:      msg.LabelIds,
+		attachments: collectAttachments(msg.Payload),
+	}
+}
+
+// matches reports whether m satisfies every condition of r. labelIDs maps
+// lowercase label names and IDs to label IDs.
+func (r compiledGmailRule) matches(m gmailRuleMessage, labelIDs map[string]string) bool {
+	if r.from != nil && !r.from.MatchString(m.from) {
+		return false
+	}
+	if r.to != nil && !r.to.MatchString(m.to) {
+		return false
+	}
+	if r.subject != nil && !r.subject.MatchString(m.subject) {
+		return false
+	}
+	if r.body != nil && !r.body.MatchString(m.body) {
+		return false
+	}
+	if r.Match.HasAttachment != nil && *r.Match.HasAttachment != (len(m.attachments) > 0) {
+		return false
+	}
+	if r.attachment != nil && len(r.ruleAttachments(m)) == 0 {
+		return false
+	}
+	if len(r.Match.Labels) > 0 {
+		have := stringSet(m.labels)
+		for _, want := range r.Match.Labels {
+			id := strings.TrimSpace(want)
+			if mapped, ok := labelIDs[strings.ToLower(id)]; ok {
+				id = mapped
+			}
+			if _, ok := have[id]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ruleAttachments returns the attachments selected by the attachment pattern
+// (all attachments when the rule has none).
+func (r compiledGmailRule) ruleAttachments(m gmailRuleMessage) []attachmentInfo {
+	if r.attachment == nil {
+		return m.attachments
+	}
+	var out []attachmentInfo
+	for _, a := range m.attachments {
+		if r.attachment.MatchString(a.Filename) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// gmailRuleRunner evaluates rules and performs their actions for one account.
+type gmailRuleRunner struct {
+	engine  *gmailRuleEngine
+	account string
+	svc     *gmail.Service
+	dryRun  bool
+
+	// applied and markApplied, when set, remember which rules already ran
+	// for a message so redelivered or resynced messages are not acted on
+	// twice.
+	applied     func(rule, messageID string) bool
+	markApplied func(rule, messageID string)
+
+	labelIDs map[string]string
+	from     *composeFromResult
+}
+
+func (r *gmailRuleRunner) labelMap() (map[string]string, error) {
+	if r.labelIDs != nil {
+		return r.labelIDs, nil
+	}
+	m, err := fetchLabelNameToID(r.svc)
+	if err != nil {
+		return nil, err
+	}
+	r.labelIDs = m
+	return m, nil
+}
+
+// run evaluates every rule against msg (fetched in full format). Outgoing
+// mail (SENT/DRAFT) is ignored so actions cannot trigger themselves.
+func (r *gmailRuleRunner) run(ctx context.Context, msg *gmail.Message) ([]gmailRuleOutcome, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	for _, id := range msg.LabelIds {
+		if id == "SENT" || id == "DRAFT" {
+			return nil, nil
+		}
+	}
+	labelIDs, err := r.labelMap()
+	if err != nil {
+		return nil, err
+	}
+
+	view := newGmailRuleMessage(msg)
+	var outcomes []gmailRuleOutcome
+	for _, rule := range r.engine.rules {
+		if !rule.matches(view, labelIDs) {
+			continue
+		}
+		outcome := gmailRuleOutcome{Rule: rule.Name}
+		for _, action := range rule.Actions {
+			outcome.Actions = append(outcome.Actions, action.kind())
+		}
+		switch {
+		case r.dryRun:
+		case r.applied != nil && r.applied(rule.Name, msg.Id):
+			outcome.Repeat = true
+		default:
+			for _, action := range rule.Actions {
+				if err := r.apply(ctx, rule, action, view); err != nil {
+					outcome.Errors = append(outcome.Errors, fmt.Sprintf("%s: %v", action.kind(), err))
+				}
+			}
+			// Recorded even after a failed action: a retry could repeat the
+			// ones that succeeded (a forward, a task, a chat message).
+			if r.markApplied != nil {
+				r.markApplied(rule.Name, msg.Id)
+			}
+		}
+		outcomes = append(outcomes, outcome)
+		if rule.Stop {
+			break
+		}
+	}
+	return outcomes, nil
+}
+
+func (r *gmailRuleRunner) apply(ctx context.Context, rule compiledGmailRule, a gmailRuleAction, m gmailRuleMessage) error {
+	switch {
+	case a.Label != "" || a.RemoveLabel != "" || a.Archive || a.MarkRead:
+		req := &gmail.ModifyMessageRequest{}
+		switch {
+		case a.Label != "":
+			id, err := ensureLabelExists(ctx, r.svc, a.Label)
+			if err != nil {
+				return err
+			}
+			r.labelIDs = nil
+			req.AddLabelIds = []string{id}
+		case a.RemoveLabel != "":
+			labelIDs, err := r.labelMap()
+			if err != nil {
+				return err
+			}
+			id, ok := labelIDs[strings.ToLower(strings.TrimSpace(a.RemoveLabel))]
+			if !ok {
+				return nil
+			}
+			req.RemoveLabelIds = []string{id}
+		case a.Archive:
+			req.RemoveLabelIds = []string{"INBOX"}
+		default:
+			req.RemoveLabelIds = []string{"UNREAD"}
+		}
+		_, err := r.svc.Users.Messages.Modify("me", m.msg.Id, req).Context(ctx).Do()
+		return err
+	case a.Forward != "":
+		return r.forward(ctx, a.Forward, m)
+	case a.AutoReply != nil:
+		return r.autoReply(ctx, a.AutoReply, m)
+	case a.SaveToDrive != nil:
+		return r.saveToDrive(ctx, rule, a.SaveToDrive.Folder, m)
+	case a.Task != nil:
+		return r.createTask(ctx, a.Task, m)
+	default:
+		return r.postChat(ctx, a.Chat, m)
+	}
+}
+
+func (r *gmailRuleRunner) sender(ctx context.Context, from string) (composeFromResult, error) {
+	if from == "" && r.from != nil {
+		return *r.from, nil
+	}
+	res, err := resolveComposeSender(ctx, r.svc, r.account, from)
+	if err != nil {
+		return composeFromResult{}, err
+	}
+	if from == "" {
+		r.from = &res
+	}
+	return res, nil
+}
+
+func (r *gmailRuleRunner) forward(ctx context.Context, to string, m gmailRuleMessage) error {
+	from, err := r.sender(ctx, "")
+	if err != nil {
+		return err
+	}
+	opts, err := forwardMessageOptions(ctx, r.svc, m.msg, from.header, "", false)
+	if err != nil {
+		return err
+	}
+	_, err = sendGmailBatches(ctx, r.svc, opts, []sendBatch{{To: splitCSV(to)}})
+	return err
+}
+
+func (r *gmailRuleRunner) autoReply(ctx context.Context, cfg *gmailRuleAutoReply, m gmailRuleMessage) error {
+	from, err := r.sender(ctx, strings.TrimSpace(cfg.From))
+	if err != nil {
+		return err
+	}
+	label := strings.TrimSpace(cfg.Label)
+	if label == "" {
+		label = defaultRuleAutoReplyLabel
+	}
+	labelID, err := ensureLabelExists(ctx, r.svc, label)
+	if err != nil {
+		return err
+	}
+	r.labelIDs = nil
+	skipBulk := cfg.SkipBulk == nil || *cfg.SkipBulk
+	_, err = autoReplyToMessage(ctx, r.svc, m.msg.Id, m.msg, from, labelID, autoReplySelfAddresses(r.account, from), gmailAutoReplyInput{
+		Subject:   strings.TrimSpace(cfg.Subject),
+		Body:      cfg.Body,
+		BodyHTML:  cfg.BodyHTML,
+		ReplyTo:   strings.TrimSpace(cfg.ReplyTo),
+		Label:     label,
+		Archive:   cfg.Archive,
+		MarkRead:  cfg.MarkRead,
+		SkipBulk:  skipBulk,
+		AllowSelf: cfg.AllowSelf,
+	})
+	return err
+}
+
+func (r *gmailRuleRunner) saveToDrive(ctx context.Context, rule compiledGmailRule, folder string, m gmailRuleMessage) error {
+	attachments := rule.ruleAttachments(m)
+	if len(attachments) == 0 {
+		return nil
+	}
+	driveSvc, err := newDriveService(ctx, r.account)
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		data, err := fetchAttachmentBytes(ctx, r.svc, m.msg.Id, a.AttachmentID)
+		if err != nil {
+			return fmt.Errorf("download attachment %q: %w", a.Filename, err)
+		}
+		if _, err := uploadDriveFile(ctx, driveSvc, bytes.NewReader(data), folder, a.Filename, a.MimeType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *gmailRuleRunner) createTask(ctx context.Context, cfg *gmailRuleTask, m gmailRuleMessage) error {
+	svc, err := newTasksService(ctx, r.account)
+	if err != nil {
+		return err
+	}
+	list := strings.TrimSpace(cfg.List)
+	if list == "" {
+		list = defaultTaskListID
+	}
+	listID, err := resolveTasklistID(ctx, svc, list)
+	if err != nil {
+		return err
+	}
+	_, err = svc.Tasks.Insert(listID, &tasks.Task{
+		Title: renderGmailRuleTemplate(cfg.Title, m),
+		Notes: renderGmailRuleTemplate(cfg.Notes, m),
+	}).Context(ctx).Do()
+	return err
+}
+
+func (r *gmailRuleRunner) postChat(ctx context.Context, cfg *gmailRuleChatAction, m gmailRuleMessage) error {
+	space, err := normalizeSpace(cfg.Space)
+	if err != nil {
+		return err
+	}
+	svc, err := newChatService(ctx, r.account)
+	if err != nil {
+		return err
+	}
+	_, err = svc.Spaces.Messages.Create(space, &chat.Message{Text: renderGmailRuleTemplate(cfg.Text, m)}).Context(ctx).Do()
+	return err
+}
+
+// renderGmailRuleTemplate expands {from}, {to}, {subject}, {snippet}, {id},
+// {threadId} and {link}.
+func renderGmailRuleTemplate(tmpl string, m gmailRuleMessage) string {
+	return strings.NewReplacer(
+		"{from}", m.from,
+		"{to}", m.to,
+		"{subject}", m.subject,
+		"{snippet}", m.msg.Snippet,
+		"{id}", m.msg.Id,
+		"{threadId}", m.msg.ThreadId,
+		"{link}", "https://mail.google.com/mail/#all/"+m.msg.ThreadId,
+	).Replace(tmpl)
+}
+
+// applyRules runs the rule engine for every message in payload and records
+// the matched rule names on the hook messages.
+func (s *gmailWatchServer) applyRules(ctx context.Context, payload *gmailHookPayload) {
+	if s.rules == nil || payload == nil || len(payload.Messages) == 0 {
+		return
+	}
+	svc, err := s.newService(ctx, s.cfg.Account)
+	if err != nil {
+		s.warnf("watch: rules: %v", err)
+		return
+	}
+	runner := &gmailRuleRunner{engine: s.rules, account: s.cfg.Account, svc: svc, dryRun: s.rulesDryRun}
+	if s.store != nil {
+		runner.applied = s.ruleApplied
+		runner.markApplied = s.markRuleApplied
+	}
+	for i := range payload.Messages {
+		id := payload.Messages[i].ID
+		msg, err := svc.Users.Messages.Get("me", id).Format(gmailFormatFull).Context(ctx).Do()
+		if err != nil {
+			s.warnf("watch: rules: fetch %s: %v", id, err)
+			continue
+		}
+		outcomes, err := runner.run(ctx, msg)
+		if err != nil {
+			s.warnf("watch: rules: %s: %v", id, err)
+			continue
+		}
+		for _, o := range outcomes {
+			payload.Messages[i].Rules = append(payload.Messages[i].Rules, o.Rule)
+			switch {
+			case o.Repeat:
+				s.logf("watch: rule %q already applied to %s; skipped", o.Rule, id)
+			case runner.dryRun:
+				s.logf("watch: rule %q matched %s (dry run): %s", o.Rule, id, strings.Join(o.Actions, ", "))
+			default:
+				s.logf("watch: rule %q matched %s: %s", o.Rule, id, strings.Join(o.Actions, ", "))
+			}
+			for _, e := range o.Errors {
+				s.warnf("watch: rule %q on %s: %s", o.Rule, id, e)
+			}
+		}
+	}
+}
+
+// maxGmailWatchRulesApplied bounds the applied-rule history kept in the
+// watch state; older entries are dropped first.
+const maxGmailWatchRulesApplied = 5000
+
+func gmailRuleAppliedKey(rule, messageID string) string {
+	return messageID + "/" + rule
+}
+
+func (s *gmailWatchServer) ruleApplied(rule, messageID string) bool {
+	return slices.Contains(s.store.Get().RulesApplied, gmailRuleAppliedKey(rule, messageID))
+}
+
+func (s *gmailWatchServer) markRuleApplied(rule, messageID string) {
+	err := s.store.Update(func(state *gmailWatchState) error {
+		state.RulesApplied = append(state.RulesApplied, gmailRuleAppliedKey(rule, messageID))
+		if n := len(state.RulesApplied); n > maxGmailWatchRulesApplied {
+			state.RulesApplied = append([]string(nil), state.RulesApplied[n-maxGmailWatchRulesApplied:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		s.warnf("watch: rules: record %s: %v", messageID, err)
+	}
+}
+
+// loadWatchRules loads --rules for watch serve/poll.
+func loadWatchRules(path, account string) (*gmailRuleEngine, error) {
+	engine, err := loadGmailRules(path)
+	if err != nil {
+		return nil, err
+	}
+	if engine.sends() {
+		if err := checkAccountNoSend(account); err != nil {
+			return nil, err
+		}
+	}
+	return engine, nil
+}
+
+type GmailWatchRulesCmd struct {
+	File       string   `arg:"" name:"file" help:"Rules file (YAML or JSON)"`
+	MessageIDs []string `arg:"" name:"messageId" optional:"" help:"Messages to evaluate (no actions are run)"`
+}
+
+func (c *GmailWatchRulesCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	engine, err := loadGmailRules(c.File)
+	if err != nil {
+		return err
+	}
+	if len(c.MessageIDs) == 0 {
+		if outfmt.IsJSON(ctx) {
+			names := make([]string, 0, len(engine.rules))
+			for _, r := range engine.rules {
+				names = append(names, r.Name)
+			}
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"valid": true, "rules": names})
+		}
+		u.Out().Printf("valid\ttrue")
+		u.Out().Printf("rules\t%d", len(engine.rules))
+		return nil
+	}
+
+	account, svc, err := requireGmailService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	runner := &gmailRuleRunner{engine: engine, account: account, svc: svc, dryRun: true}
+	results := make([]map[string]any, 0, len(c.MessageIDs))
+	for _, raw := range c.MessageIDs {
+		id := normalizeGmailMessageID(raw)
+		msg, err := svc.Users.Messages.Get("me", id).Format(gmailFormatFull).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		outcomes, err := runner.run(ctx, msg)
+		if err != nil {
+			return err
+		}
+		if outcomes == nil {
+			outcomes = []gmailRuleOutcome{}
+		}
+		results = append(results, map[string]any{"messageId": id, "matches": outcomes})
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"results": results})
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "MESSAGE\tRULE\tACTIONS")
+	for _, r := range results {
+		outcomes, _ := r["matches"].([]gmailRuleOutcome)
+		if len(outcomes) == 0 {
+			fmt.Fprintf(w, "%s\t-\t-\n", r["messageId"])
+			continue
+		}
+		for _, o := range outcomes {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r["messageId"], sanitizeTab(o.Rule), strings.Join(o.Actions, ", "))
+		}
+	}
+	return nil
+}
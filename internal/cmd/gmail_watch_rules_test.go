@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+const testGmailRules = `
+rules:
+  - name: invoices
+    match:
+      from: "@billing\\.example\\.com"
+      subject: invoice
+      labels: [inbox]
+      hasAttachment: true
+      attachment: "\\.pdf$"
+    actions:
+      - label: Finance/Invoices
+      - archive: true
+    stop: true
+  - name: everything
+    actions:
+      - markRead: true
+`
+
+func testRuleMessage() *gmail.Message {
+	return &gmail.Message{
+		Id:       "m1",
+		ThreadId: "t1",
+		Snippet:  "Your invoice",
+		LabelIds: []string{"INBOX", "UNREAD"},
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "From", Value: "Billing <no-reply@billing.example.com>"},
+				{Name: "To", Value: "me@example.com"},
+				{Name: "Subject", Value: "Invoice #42"},
+			},
+			Parts: []*gmail.MessagePart{
+				{MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: base64.RawURLEncoding.EncodeToString([]byte("Amount due: 10 EUR"))}},
+				{MimeType: "application/pdf", Filename: "invoice.pdf", Body: &gmail.MessagePartBody{AttachmentId: "a1", Size: 10}},
+			},
+		},
+	}
+}
+
+func TestParseGmailRules_Validation(t *testing.T) {
+	cases := map[string]string{
+		"empty":         "rules: []",
+		"no actions":    "rules:\n  - name: x\n",
+		"two kinds":     "rules:\n  - actions:\n      - label: A\n        archive: true\n",
+		"bad regex":     "rules:\n  - match: {subject: \"(\"}\n    actions: [{archive: true}]\n",
+		"unknown field": "rules:\n  - match: {sender: x}\n    actions: [{archive: true}]\n",
+		"chat text":     "rules:\n  - actions: [{chat: {space: spaces/AAA}}]\n",
+		"autoreply":     "rules:\n  - actions: [{autoReply: {subject: hi}}]\n",
+	}
+	for name, data := range cases {
+		if _, err := parseGmailRules([]byte(data)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	// JSON is accepted as well.
+	engine, err := parseGmailRules([]byte(`{"rules":[{"match":{"from":"a@b"},"actions":[{"task":{"title":"Follow up: {subject}"}}]}]}`))
+	if err != nil {
+		t.Fatalf("json rules: %v", err)
+	}
+	if engine.rules[0].Name != "rule-1" || engine.sends() {
+		t.Fatalf("unexpected engine: %#v", engine.rules[0])
+	}
+}
+
+func TestGmailRules_MatchAndTemplate(t *testing.T) {
+	engine, err := parseGmailRules([]byte(testGmailRules))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	view := newGmailRuleMessage(testRuleMessage())
+	labels := map[string]string{"inbox": "INBOX"}
+	if !engine.rules[0].matches(view, labels) {
+		t.Fatalf("expected invoices rule to match")
+	}
+	if engine.rules[0].matches(view, map[string]string{"inbox": "Label_9"}) {
+		t.Fatalf("expected label mismatch")
+	}
+
+	other := testRuleMessage()
+	other.Payload.Parts[1].Filename = "invoice.xlsx"
+	if engine.rules[0].matches(newGmailRuleMessage(other), labels) {
+		t.Fatalf("expected attachment pattern mismatch")
+	}
+
+	got := renderGmailRuleTemplate("{subject} from {from} ({id})", view)
+	if got != "Invoice #42 from Billing <no-reply@billing.example.com> (m1)" {
+		t.Fatalf("unexpected template: %q", got)
+	}
+}
+
+func TestGmailRuleRunner_AppliesActionsAndStops(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		modifies []gmail.ModifyMessageRequest
+		created  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/users/me/labels") && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{{"id": "INBOX", "name": "INBOX"}}})
+		case strings.HasSuffix(r.URL.Path, "/users/me/labels") && r.Method == http.MethodPost:
+			var body struct {
+				Name string `json:"name"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body.Name)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "Label_1", "name": body.Name})
+		case strings.HasSuffix(r.URL.Path, "/messages/m1/modify"):
+			var req gmail.ModifyMessageRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			modifies = append(modifies, req)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	engine, err := parseGmailRules([]byte(testGmailRules))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	runner := &gmailRuleRunner{engine: engine, account: "me@example.com", svc: svc, dryRun: true}
+	outcomes, err := runner.run(context.Background(), testRuleMessage())
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(outcomes) != 1 || outcomes[0].Rule != "invoices" || len(modifies) != 0 {
+		t.Fatalf("unexpected dry-run outcomes: %#v (modifies=%d)", outcomes, len(modifies))
+	}
+
+	runner.dryRun = false
+	outcomes, err = runner.run(context.Background(), testRuleMessage())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(outcomes) != 1 || len(outcomes[0].Errors) != 0 {
+		t.Fatalf("unexpected outcomes: %#v", outcomes)
+	}
+	if len(created) != 1 || created[0] != "Finance/Invoices" {
+		t.Fatalf("expected label to be created, got %v", created)
+	}
+	if len(modifies) != 2 || modifies[0].AddLabelIds[0] != "Label_1" || modifies[1].RemoveLabelIds[0] != "INBOX" {
+		t.Fatalf("unexpected modify calls: %#v", modifies)
+	}
+
+	sent := testRuleMessage()
+	sent.LabelIds = []string{"SENT"}
+	if outcomes, _ := runner.run(context.Background(), sent); outcomes != nil {
+		t.Fatalf("expected sent mail to be ignored, got %#v", outcomes)
+	}
+}
+
+func TestGmailWatchServer_ApplyRulesAnnotatesPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/users/me/labels"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{}})
+		case strings.HasSuffix(r.URL.Path, "/messages/m1") && r.URL.Query().Get("format") == "full":
+			_ = json.NewEncoder(w).Encode(testRuleMessage())
+		case strings.HasSuffix(r.URL.Path, "/messages/m1/modify"):
+			_, _ = io.Copy(io.Discard, r.Body)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	engine, err := parseGmailRules([]byte("rules:\n  - name: all\n    actions: [{markRead: true}]\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	server := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "me@example.com"},
+		newService: func(context.Context, string) (*gmail.Service, error) { return svc, nil },
+		rules:      engine,
+		logf:       func(string, ...any) {},
+		warnf:      func(format string, args ...any) { t.Errorf(format, args...) },
+	}
+	payload := &gmailHookPayload{Messages: []gmailHookMessage{{ID: "m1"}}}
+	server.applyRules(context.Background(), payload)
+	if len(payload.Messages[0].Rules) != 1 || payload.Messages[0].Rules[0] != "all" {
+		t.Fatalf("expected rule annotation, got %#v", payload.Messages[0])
+	}
+}
+
+func TestGmailWatchServer_ApplyRulesOncePerMessage(t *testing.T) {
+	setWatchTestConfigHome(t)
+	modifies := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/users/me/labels"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{}})
+		case strings.HasSuffix(r.URL.Path, "/messages/m1") && r.URL.Query().Get("format") == "full":
+			_ = json.NewEncoder(w).Encode(testRuleMessage())
+		case strings.HasSuffix(r.URL.Path, "/messages/m1/modify"):
+			modifies++
+			_, _ = io.Copy(io.Discard, r.Body)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	engine, err := parseGmailRules([]byte("rules:\n  - name: all\n    actions: [{markRead: true}]\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	store, err := newGmailWatchStore("me@example.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	var logs []string
+	server := &gmailWatchServer{
+		cfg:         gmailWatchServeConfig{Account: "me@example.com"},
+		store:       store,
+		newService:  func(context.Context, string) (*gmail.Service, error) { return svc, nil },
+		rules:       engine,
+		rulesDryRun: true,
+		logf:        func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) },
+		warnf:       func(format string, args ...any) { t.Errorf(format, args...) },
+	}
+
+	server.applyRules(context.Background(), &gmailHookPayload{Messages: []gmailHookMessage{{ID: "m1"}}})
+	if modifies != 0 || len(logs) != 1 || !strings.Contains(logs[0], "(dry run)") {
+		t.Fatalf("dry run acted: modifies=%d logs=%v", modifies, logs)
+	}
+
+	server.rulesDryRun = false
+	for range 2 {
+		payload := &gmailHookPayload{Messages: []gmailHookMessage{{ID: "m1"}}}
+		server.applyRules(context.Background(), payload)
+		if len(payload.Messages[0].Rules) != 1 {
+			t.Fatalf("expected rule annotation, got %#v", payload.Messages[0])
+		}
+	}
+	if modifies != 1 {
+		t.Fatalf("expected actions to run once, got %d modify calls", modifies)
+	}
+	if got := store.Get().RulesApplied; len(got) != 1 || got[0] != "m1/all" {
+		t.Fatalf("unexpected applied rules: %v", got)
+	}
+	if !strings.Contains(logs[len(logs)-1], "already applied") {
+		t.Fatalf("expected repeat to be logged, got %v", logs)
+	}
+}
@@ -69,6 +69,47 @@ func TestGmailWatchServeCmd_UsesStoredHook(t *testing.T) {
 	}
 }
 
+func TestGmailWatchServeCmd_SinksSkipStoredHook(t *testing.T) {
+	origListen := listenAndServe
+	t.Cleanup(func() { listenAndServe = origListen })
+
+	setWatchTestConfigHome(t)
+
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Account = "a@b.com"
+		s.Hook = &gmailWatchHook{URL: "http://example.com/hook", Token: "tok"}
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	var got *gmailWatchServer
+	listenAndServe = func(srv *http.Server) error {
+		if gs, ok := srv.Handler.(*gmailWatchServer); ok {
+			got = gs
+		}
+		return nil
+	}
+
+	u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: io.Discard, Color: "never"})
+	if err != nil {
+		t.Fatalf("ui.New: %v", err)
+	}
+	if execErr := runKong(t, &GmailWatchServeCmd{}, []string{"--port", "9999", "--path", "/hook", "--stdout-ndjson"}, ui.WithUI(context.Background(), u), &RootFlags{Account: "a@b.com"}); execErr != nil {
+		t.Fatalf("execute: %v", execErr)
+	}
+	if got == nil {
+		t.Fatalf("expected server")
+	}
+	if got.cfg.HookURL != "" || len(got.sinks) != 1 {
+		t.Fatalf("expected sinks only, got hook %q and %d sinks", got.cfg.HookURL, len(got.sinks))
+	}
+}
+
 func TestGmailWatchServeCmd_DefaultMaxBytes(t *testing.T) {
 	origListen := listenAndServe
 	t.Cleanup(func() { listenAndServe = origListen })
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +33,10 @@ type gmailWatchServer struct {
 	newService      func(context.Context, string) (*gmail.Service, error)
 	sleep           func(context.Context, time.Duration) error
 	hookClient      *http.Client
+	deliveries      *gmailDeliveryQueue
+	rules           *gmailRuleEngine
+	rulesDryRun     bool
+	sinks           watchSinks
 	excludeLabelIDs map[string]struct{}
 	logf            func(string, ...any)
 	warnf           func(string, ...any)
@@ -85,6 +90,9 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.applyRules(r.Context(), result)
+	_ = s.sinks.deliver(r.Context(), s.warnf, result, gmailWatchSinkEnv(result)...)
+
 	if s.cfg.HookURL == "" {
 		if s.cfg.AllowNoHook {
 			_ = json.NewEncoder(w).Encode(result)
@@ -103,29 +111,50 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *gmailWatchServer) authorize(r *http.Request) bool {
-	if s.cfg.VerifyOIDC {
+	return authorizePush(r, pushAuthConfig{
+		VerifyOIDC:   s.cfg.VerifyOIDC,
+		OIDCEmail:    s.cfg.OIDCEmail,
+		OIDCAudience: s.cfg.OIDCAudience,
+		SharedToken:  s.cfg.SharedToken,
+	}, s.validator, s.warnf)
+}
+
+func (s *gmailWatchServer) oidcAudience(r *http.Request) string {
+	return pushAudience(r, s.cfg.OIDCAudience)
+}
+
+// pushAuthConfig describes how Pub/Sub push requests are authenticated.
+type pushAuthConfig struct {
+	VerifyOIDC   bool
+	OIDCEmail    string
+	OIDCAudience string
+	SharedToken  string
+}
+
+func authorizePush(r *http.Request, cfg pushAuthConfig, validator *idtoken.Validator, warnf func(string, ...any)) bool {
+	if cfg.VerifyOIDC {
 		bearer := bearerToken(r)
 		if bearer != "" {
-			if ok, err := verifyOIDCToken(r.Context(), s.validator, bearer, s.oidcAudience(r), s.cfg.OIDCEmail); ok {
+			if ok, err := verifyOIDCToken(r.Context(), validator, bearer, pushAudience(r, cfg.OIDCAudience), cfg.OIDCEmail); ok {
 				return true
 			} else if err != nil {
-				s.warnf("watch: oidc verify failed: %v", err)
+				warnf("watch: oidc verify failed: %v", err)
 			}
 		}
-		if s.cfg.SharedToken != "" {
-			return sharedTokenMatches(r, s.cfg.SharedToken)
+		if cfg.SharedToken != "" {
+			return sharedTokenMatches(r, cfg.SharedToken)
 		}
 		return false
 	}
-	if s.cfg.SharedToken == "" {
+	if cfg.SharedToken == "" {
 		return true
 	}
-	return sharedTokenMatches(r, s.cfg.SharedToken)
+	return sharedTokenMatches(r, cfg.SharedToken)
 }
 
-func (s *gmailWatchServer) oidcAudience(r *http.Request) string {
-	if s.cfg.OIDCAudience != "" {
-		return s.cfg.OIDCAudience
+func pushAudience(r *http.Request, configured string) string {
+	if configured != "" {
+		return configured
 	}
 	scheme := "http"
 	if r.TLS != nil {
@@ -361,6 +390,15 @@ func (s *gmailWatchServer) sendHook(ctx context.Context, payload *gmailHookPaylo
 	if err != nil {
 		return err
 	}
+	if s.deliveries != nil {
+		return s.enqueueAndDeliver(ctx, data)
+	}
+	return s.postHook(ctx, data, "", 0)
+}
+
+// postHook performs a single delivery attempt and records the outcome in the
+// watch state. deliveryID/attempt are set for queued deliveries.
+func (s *gmailWatchServer) postHook(ctx context.Context, data []byte, deliveryID string, attempt int) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.HookURL, bytes.NewReader(data))
 	if err != nil {
 		return err
@@ -369,6 +407,13 @@ func (s *gmailWatchServer) sendHook(ctx context.Context, payload *gmailHookPaylo
 	if s.cfg.HookToken != "" {
 		req.Header.Set("Authorization", "Bearer "+s.cfg.HookToken)
 	}
+	if s.cfg.HookSecret != "" {
+		req.Header.Set(gmailHookSignatureHeader, signHookPayload(s.cfg.HookSecret, data))
+	}
+	if deliveryID != "" {
+		req.Header.Set(gmailHookDeliveryHeader, deliveryID)
+		req.Header.Set(gmailHookAttemptHeader, strconv.Itoa(attempt))
+	}
 	resp, err := s.hookClient.Do(req)
 	if err != nil {
 		_ = s.store.Update(func(state *gmailWatchState) error {
@@ -398,7 +443,29 @@ func (s *gmailWatchServer) sendHook(ctx context.Context, payload *gmailHookPaylo
 	return nil
 }
 
+// gmailWatchSinkEnv is the extra environment for --exec sinks.
+func gmailWatchSinkEnv(payload *gmailHookPayload) []string {
+	return []string{
+		"GOG_WATCH_SOURCE=gmail",
+		"GOG_WATCH_ACCOUNT=" + payload.Account,
+		"GOG_WATCH_HISTORY_ID=" + payload.HistoryID,
+	}
+}
+
 func parsePubSubPush(r *http.Request) (*pubsubPushEnvelope, error) {
+	envelope, err := readPubSubEnvelope(r)
+	if err != nil {
+		return nil, err
+	}
+	if envelope.Message.Data == "" {
+		return nil, errors.New("missing message.data")
+	}
+	return envelope, nil
+}
+
+// readPubSubEnvelope decodes a push body without requiring message.data
+// (some producers, like Forms, only send attributes).
+func readPubSubEnvelope(r *http.Request) (*pubsubPushEnvelope, error) {
 	defer r.Body.Close()
 	limit := int64(defaultPushBodyLimitBytes)
 	data, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
@@ -412,9 +479,6 @@ func parsePubSubPush(r *http.Request) (*pubsubPushEnvelope, error) {
 	if err := json.Unmarshal(data, &envelope); err != nil {
 		return nil, err
 	}
-	if envelope.Message.Data == "" {
-		return nil, errors.New("missing message.data")
-	}
 	return &envelope, nil
 }
 
@@ -80,6 +80,27 @@ func loadGmailWatchStore(account string) (*gmailWatchStore, error) {
 	return store, nil
 }
 
+// openGmailWatchStore loads stored watch state, or starts an empty state for
+// flows that do not need 'gmail watch start' (e.g. polling).
+func openGmailWatchStore(account string) (*gmailWatchStore, error) {
+	store, err := newGmailWatchStore(account)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			store.state.Account = account
+			return store, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.state); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
 func (s *gmailWatchStore) Get() gmailWatchState {
 	s.mu.Lock()
 	defer s.mu.Unlock()
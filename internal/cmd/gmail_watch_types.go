@@ -20,6 +20,7 @@ const (
 type gmailWatchHook struct {
 	URL         string `json:"url"`
 	Token       string `json:"token,omitempty"`
+	Secret      string `json:"secret,omitempty"`
 	IncludeBody bool   `json:"includeBody,omitempty"`
 	MaxBytes    int    `json:"maxBytes,omitempty"`
 }
@@ -38,6 +39,9 @@ type gmailWatchState struct {
 	LastDeliveryAtMs       int64           `json:"lastDeliveryAtMs,omitempty"`
 	LastDeliveryStatusNote string          `json:"lastDeliveryStatusNote,omitempty"`
 	LastPushMessageID      string          `json:"lastPushMessageId,omitempty"`
+	// RulesApplied lists the most recent "<messageId>/<rule>" pairs whose
+	// actions already ran (see maxGmailWatchRulesApplied).
+	RulesApplied []string `json:"rulesApplied,omitempty"`
 }
 
 type gmailWatchServeConfig struct {
@@ -51,6 +55,7 @@ type gmailWatchServeConfig struct {
 	SharedToken   string
 	HookURL       string
 	HookToken     string
+	HookSecret    string
 	IncludeBody   bool
 	MaxBodyBytes  int
 	ExcludeLabels []string
@@ -172,6 +177,7 @@ type gmailHookMessage struct {
 	Body          string   `json:"body,omitempty"`
 	BodyTruncated bool     `json:"bodyTruncated,omitempty"`
 	Labels        []string `json:"labels,omitempty"`
+	Rules         []string `json:"rules,omitempty"`
 }
 
 type gmailHookPayload struct {
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// jsonStateStore keeps one small state document (cursors, channel IDs) in
+// memory and rewrites its JSON file on every update.
+type jsonStateStore[S any] struct {
+	path  string
+	mu    sync.Mutex
+	state S
+	stamp func(*S, int64)
+}
+
+// openJSONStateStore loads the state at path, or starts from initial when
+// the file does not exist yet. stamp records the update time (Unix millis)
+// before each write.
+func openJSONStateStore[S any](path string, initial S, stamp func(*S, int64)) (*jsonStateStore[S], error) {
+	store := &jsonStateStore[S]{path: path, stamp: stamp}
+	data, err := os.ReadFile(path) //nolint:gosec // path built from config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			store.state = initial
+			return store, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.state); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *jsonStateStore[S]) Get() S {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+func (s *jsonStateStore[S]) Update(fn func(*S) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := fn(&s.state); err != nil {
+		return err
+	}
+	if s.stamp != nil {
+		s.stamp(&s.state, time.Now().UnixMilli())
+	}
+	payload, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(payload, '\n'), 0o600)
+}
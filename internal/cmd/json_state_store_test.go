@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONStateStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	stamp := func(st *driveChangesState, ms int64) { st.UpdatedAtMs = ms }
+
+	store, err := openJSONStateStore(path, driveChangesState{Account: "a@b.com"}, stamp)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if got := store.Get(); got.Account != "a@b.com" || got.UpdatedAtMs != 0 {
+		t.Fatalf("unexpected initial state: %#v", got)
+	}
+	if err := store.Update(func(st *driveChangesState) error {
+		st.PageToken = "42"
+		return nil
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	reopened, err := openJSONStateStore(path, driveChangesState{}, stamp)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got := reopened.Get(); got.Account != "a@b.com" || got.PageToken != "42" || got.UpdatedAtMs == 0 {
+		t.Fatalf("unexpected persisted state: %#v", got)
+	}
+}
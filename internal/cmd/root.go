@@ -136,6 +136,7 @@ func Execute(args []string) (err error) {
 		_, _ = fmt.Fprintln(os.Stderr, errfmt.Format(err))
 		return err
 	}
+	applyDriveMkdirColor(kctx, &cli.RootFlags)
 
 	logLevel := slog.LevelWarn
 	if cli.Verbose {
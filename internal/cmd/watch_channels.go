@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// newWatchChannelToken returns a random token for X-Goog-Channel-Token.
+func newWatchChannelToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// runWatchChannelRenewals calls renew shortly before the channel returned by
+// expiresAt runs out, until ctx is done. Failures are retried every minute.
+func runWatchChannelRenewals(
+	ctx context.Context,
+	sleep func(context.Context, time.Duration) error,
+	renewBefore time.Duration,
+	expiresAt func() int64,
+	renew func(context.Context) error,
+	warnf func(string, ...any),
+) {
+	for {
+		wait := time.Minute
+		if exp := expiresAt(); exp > 0 {
+			if until := time.Until(time.UnixMilli(exp)) - renewBefore; until > wait {
+				wait = until
+			}
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return
+		}
+		if err := renew(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			warnf("watch: channel renewal failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+)
+
+// WatchSinkFlags adds local event sinks to watch servers, as an alternative
+// (or in addition) to --hook-url.
+type WatchSinkFlags struct {
+	Exec            string `name:"exec" help:"Run a shell command per event with the JSON payload on stdin"`
+	ExecConcurrency int    `name:"exec-concurrency" help:"Max concurrent --exec commands" default:"4"`
+	ExecTimeout     string `name:"exec-timeout" help:"Kill --exec commands after this long (seconds or duration)" default:"30s"`
+	StdoutNDJSON    bool   `name:"stdout-ndjson" help:"Write each event as one JSON line to stdout"`
+	AppendFile      string `name:"append-file" help:"Append each event as one JSON line to this file"`
+}
+
+func (f WatchSinkFlags) enabled() bool {
+	return strings.TrimSpace(f.Exec) != "" || f.StdoutNDJSON || strings.TrimSpace(f.AppendFile) != ""
+}
+
+type watchSink interface {
+	Deliver(ctx context.Context, payload []byte, env []string) error
+}
+
+type watchSinks []watchSink
+
+func (f WatchSinkFlags) build() (watchSinks, error) {
+	var sinks watchSinks
+	if command := strings.TrimSpace(f.Exec); command != "" {
+		if f.ExecConcurrency <= 0 {
+			return nil, usage("--exec-concurrency must be > 0")
+		}
+		timeout, err := parseDurationSeconds(f.ExecTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if timeout <= 0 {
+			return nil, usage("--exec-timeout must be > 0")
+		}
+		// Child output goes to stderr so stdout stays clean for
+		// --stdout-ndjson and --json.
+		sinks = append(sinks, &watchExecSink{
+			command: command,
+			timeout: timeout,
+			slots:   make(chan struct{}, f.ExecConcurrency),
+			stdout:  os.Stderr,
+			stderr:  os.Stderr,
+		})
+	}
+	if f.StdoutNDJSON {
+		sinks = append(sinks, &watchWriterSink{w: os.Stdout})
+	}
+	if path := strings.TrimSpace(f.AppendFile); path != "" {
+		expanded, err := config.ExpandPath(path)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, &watchFileSink{path: expanded})
+	}
+	return sinks, nil
+}
+
+// deliver marshals payload once and hands it to every sink. Sink errors are
+// reported through warnf; the first one is returned.
+func (s watchSinks) deliver(ctx context.Context, warnf func(string, ...any), payload any, env ...string) error {
+	if len(s) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, sink := range s {
+		if err := sink.Deliver(ctx, data, env); err != nil {
+			warnf("watch: sink failed: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// watchExecSink runs a shell command per event with a concurrency cap and a
+// per-command timeout.
+type watchExecSink struct {
+	command string
+	timeout time.Duration
+	slots   chan struct{}
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+func (s *watchExecSink) Deliver(ctx context.Context, payload []byte, env []string) error {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.slots }()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.command) //nolint:gosec // user-provided sink command
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.command) //nolint:gosec // user-provided sink command
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+	cmd.Env = append(os.Environ(), env...)
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("exec %q: timed out after %s", s.command, s.timeout)
+		}
+		return fmt.Errorf("exec %q: %w", s.command, err)
+	}
+	return nil
+}
+
+// watchHookSink POSTs each payload once (no queue), optionally signed.
+type watchHookSink struct {
+	url    string
+	token  string
+	secret string
+	client *http.Client
+}
+
+func newWatchHookSink(url, token, secret string) *watchHookSink {
+	return &watchHookSink{
+		url:    url,
+		token:  token,
+		secret: secret,
+		client: &http.Client{Timeout: defaultHookRequestTimeoutSec * time.Second},
+	}
+}
+
+func (s *watchHookSink) Deliver(ctx context.Context, payload []byte, _ []string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	if s.secret != "" {
+		req.Header.Set(gmailHookSignatureHeader, signHookPayload(s.secret, payload))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hook status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type watchWriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *watchWriterSink) Deliver(_ context.Context, payload []byte, _ []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(append(append([]byte{}, payload...), '\n'))
+	return err
+}
+
+type watchFileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *watchFileSink) Deliver(_ context.Context, payload []byte, _ []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // user-provided sink path
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(append([]byte{}, payload...), '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchSinks_FileAndWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	var buf bytes.Buffer
+	sinks := watchSinks{&watchWriterSink{w: &buf}, &watchFileSink{path: path}}
+
+	for _, id := range []string{"1", "2"} {
+		if err := sinks.deliver(context.Background(), t.Logf, map[string]string{"historyId": id}); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+	}
+	want := "{\"historyId\":\"1\"}\n{\"historyId\":\"2\"}\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected stdout sink output: %q", buf.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != want {
+		t.Fatalf("unexpected file sink output: %q %v", data, err)
+	}
+}
+
+func TestWatchExecSink_EnvAndTimeout(t *testing.T) {
+	var out bytes.Buffer
+	sink := &watchExecSink{
+		command: `printf '%s ' "$GOG_WATCH_SOURCE"; cat`,
+		timeout: 5 * time.Second,
+		slots:   make(chan struct{}, 1),
+		stdout:  &out,
+		stderr:  io.Discard,
+	}
+	if err := sink.Deliver(context.Background(), []byte(`{"a":1}`), []string{"GOG_WATCH_SOURCE=gmail"}); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if out.String() != `gmail {"a":1}` {
+		t.Fatalf("unexpected exec output: %q", out.String())
+	}
+
+	sink.command = "sleep 5"
+	sink.timeout = 50 * time.Millisecond
+	start := time.Now()
+	err := sink.Deliver(context.Background(), nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Fatalf("timeout not enforced: %s", time.Since(start))
+	}
+}
+
+func TestWatchExecSink_ConcurrencyCap(t *testing.T) {
+	sink := &watchExecSink{command: "true", timeout: time.Second, slots: make(chan struct{}, 1), stdout: io.Discard, stderr: io.Discard}
+	sink.slots <- struct{}{} // occupy the only slot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sink.Deliver(ctx, nil, nil); err == nil {
+		t.Fatalf("expected delivery to wait for a free slot")
+	}
+}
+
+func TestWatchSinkFlags_Build(t *testing.T) {
+	sinks, err := WatchSinkFlags{Exec: "cat", ExecConcurrency: 2, ExecTimeout: "10s", StdoutNDJSON: true, AppendFile: "events.ndjson"}.build()
+	if err != nil || len(sinks) != 3 {
+		t.Fatalf("unexpected sinks: %v %v", sinks, err)
+	}
+	if exec, ok := sinks[0].(*watchExecSink); !ok || cap(exec.slots) != 2 || exec.timeout != 10*time.Second {
+		t.Fatalf("unexpected exec sink: %#v", sinks[0])
+	}
+	if (WatchSinkFlags{}).enabled() {
+		t.Fatalf("expected no sinks by default")
+	}
+}
+
+func TestWatchHookSink_Signed(t *testing.T) {
+	var (
+		gotSig  string
+		gotAuth string
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotAuth = r.Header.Get("Authorization")
+		if r.Header.Get(gmailHookSignatureHeader) == signHookPayload("sec", body) {
+			gotSig = "ok"
+		}
+	}))
+	defer hook.Close()
+
+	sink := newWatchHookSink(hook.URL, "ht", "sec")
+	if err := sink.Deliver(context.Background(), []byte(`{"a":1}`), nil); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if gotSig != "ok" || gotAuth != "Bearer ht" {
+		t.Fatalf("unexpected hook headers: sig=%q auth=%q", gotSig, gotAuth)
+	}
+}
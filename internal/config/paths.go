@@ -37,33 +37,43 @@ func EnsureDir() (string, error) {
 	return dir, nil
 }
 
-// KeyringDir is where the keyring "file" backend stores encrypted entries.
-//
-// We keep this separate from the main config dir because the file backend creates
-// one file per key.
-func KeyringDir() (string, error) {
+// configSubdir joins elem onto the config dir.
+func configSubdir(elem ...string) (string, error) {
 	dir, err := Dir()
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(dir, "keyring"), nil
+	return filepath.Join(append([]string{dir}, elem...)...), nil
 }
 
-func EnsureKeyringDir() (string, error) {
-	dir, err := KeyringDir()
+// ensureConfigSubdir creates the directory returned by dirFn (0700, like the
+// keyring file backend) and returns it.
+func ensureConfigSubdir(dirFn func() (string, error), name string) (string, error) {
+	dir, err := dirFn()
 	if err != nil {
 		return "", err
 	}
-	// keyring's file backend uses 0700 by default; match that.
 
 	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return "", fmt.Errorf("ensure keyring dir: %w", err)
+		return "", fmt.Errorf("ensure %s dir: %w", name, err)
 	}
 
 	return dir, nil
 }
 
+// KeyringDir is where the keyring "file" backend stores encrypted entries.
+//
+// We keep this separate from the main config dir because the file backend creates
+// one file per key.
+func KeyringDir() (string, error) {
+	return configSubdir("keyring")
+}
+
+func EnsureKeyringDir() (string, error) {
+	return ensureConfigSubdir(KeyringDir, "keyring")
+}
+
 func ClientCredentialsPath() (string, error) {
 	return ClientCredentialsPathFor(DefaultClientName)
 }
@@ -87,56 +97,79 @@ func ClientCredentialsPathFor(client string) (string, error) {
 }
 
 func DriveDownloadsDir() (string, error) {
-	dir, err := Dir()
-	if err != nil {
-		return "", err
-	}
-
-	return filepath.Join(dir, "drive-downloads"), nil
+	return configSubdir("drive-downloads")
 }
 
 func EnsureDriveDownloadsDir() (string, error) {
-	dir, err := DriveDownloadsDir()
-	if err != nil {
-		return "", err
-	}
+	return ensureConfigSubdir(DriveDownloadsDir, "drive downloads")
+}
 
-	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return "", fmt.Errorf("ensure drive downloads dir: %w", err)
-	}
+func GmailAttachmentsDir() (string, error) {
+	return configSubdir("gmail-attachments")
+}
 
-	return dir, nil
+func EnsureGmailAttachmentsDir() (string, error) {
+	return ensureConfigSubdir(GmailAttachmentsDir, "gmail attachments")
 }
 
-func GmailAttachmentsDir() (string, error) {
-	dir, err := Dir()
-	if err != nil {
-		return "", err
-	}
+func GmailWatchDir() (string, error) {
+	return configSubdir("state", "gmail-watch")
+}
 
-	return filepath.Join(dir, "gmail-attachments"), nil
+func CalendarWatchDir() (string, error) {
+	return configSubdir("state", "calendar-watch")
 }
 
-func EnsureGmailAttachmentsDir() (string, error) {
-	dir, err := GmailAttachmentsDir()
-	if err != nil {
-		return "", err
-	}
+func EnsureCalendarWatchDir() (string, error) {
+	return ensureConfigSubdir(CalendarWatchDir, "calendar watch")
+}
 
-	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return "", fmt.Errorf("ensure gmail attachments dir: %w", err)
-	}
+func FormsWatchDir() (string, error) {
+	return configSubdir("state", "forms-watch")
+}
 
-	return dir, nil
+func EnsureFormsWatchDir() (string, error) {
+	return ensureConfigSubdir(FormsWatchDir, "forms watch")
 }
 
-func GmailWatchDir() (string, error) {
-	dir, err := Dir()
-	if err != nil {
-		return "", err
-	}
+func DriveChangesDir() (string, error) {
+	return configSubdir("state", "drive-changes")
+}
+
+func EnsureDriveChangesDir() (string, error) {
+	return ensureConfigSubdir(DriveChangesDir, "drive changes")
+}
+
+func DriveSyncDir() (string, error) {
+	return configSubdir("state", "drive-sync")
+}
+
+func EnsureDriveSyncDir() (string, error) {
+	return ensureConfigSubdir(DriveSyncDir, "drive sync")
+}
+
+func DriveUploadSessionsDir() (string, error) {
+	return configSubdir("state", "drive-uploads")
+}
+
+func EnsureDriveUploadSessionsDir() (string, error) {
+	return ensureConfigSubdir(DriveUploadSessionsDir, "drive upload sessions")
+}
 
-	return filepath.Join(dir, "state", "gmail-watch"), nil
+func GmailIndexDir() (string, error) {
+	return configSubdir("state", "gmail-index")
+}
+
+func EnsureGmailIndexDir() (string, error) {
+	return ensureConfigSubdir(GmailIndexDir, "gmail index")
+}
+
+func DriveGrepCacheDir() (string, error) {
+	return configSubdir("cache", "drive-grep")
+}
+
+func EnsureDriveGrepCacheDir() (string, error) {
+	return ensureConfigSubdir(DriveGrepCacheDir, "drive grep cache")
 }
 
 func KeepServiceAccountPath(email string) (string, error) {
@@ -233,16 +266,7 @@ func ListServiceAccountEmails() ([]string, error) {
 }
 
 func EnsureGmailWatchDir() (string, error) {
-	dir, err := GmailWatchDir()
-	if err != nil {
-		return "", err
-	}
-
-	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return "", fmt.Errorf("ensure gmail watch dir: %w", err)
-	}
-
-	return dir, nil
+	return ensureConfigSubdir(GmailWatchDir, "gmail watch")
 }
 
 // ExpandPath expands ~ at the beginning of a path to the user's home directory.
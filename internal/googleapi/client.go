@@ -51,6 +51,24 @@ func IsADCMode() bool {
 }
 
 func optionsForAccountScopes(ctx context.Context, serviceLabel string, email string, scopes []string) ([]option.ClientOption, error) {
+	c, err := httpClientForAccountScopes(ctx, serviceLabel, email, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return []option.ClientOption{option.WithHTTPClient(c)}, nil
+}
+
+func httpClientForAccount(ctx context.Context, service googleauth.Service, email string) (*http.Client, error) {
+	scopes, err := googleauth.Scopes(service)
+	if err != nil {
+		return nil, fmt.Errorf("resolve scopes: %w", err)
+	}
+
+	return httpClientForAccountScopes(ctx, string(service), email, scopes)
+}
+
+func httpClientForAccountScopes(ctx context.Context, serviceLabel string, email string, scopes []string) (*http.Client, error) {
 	slog.Debug("creating client options with custom scopes", "serviceLabel", serviceLabel, "email", email)
 
 	var ts oauth2.TokenSource
@@ -87,7 +105,7 @@ func optionsForAccountScopes(ctx context.Context, serviceLabel string, email str
 
 	slog.Debug("client options with custom scopes created successfully", "serviceLabel", serviceLabel, "email", email)
 
-	return []option.ClientOption{option.WithHTTPClient(c)}, nil
+	return c, nil
 }
 
 func newBaseTransport() *http.Transport {
@@ -3,12 +3,20 @@ package googleapi
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/driveactivity/v2"
+	"google.golang.org/api/drivelabels/v2"
 
 	"github.com/steipete/gogcli/internal/googleauth"
 )
 
+const (
+	scopeDriveLabelsRO   = "https://www.googleapis.com/auth/drive.labels.readonly"
+	scopeDriveActivityRO = "https://www.googleapis.com/auth/drive.activity.readonly"
+)
+
 func NewDrive(ctx context.Context, email string) (*drive.Service, error) {
 	if opts, err := optionsForAccount(ctx, googleauth.ServiceDrive, email); err != nil {
 		return nil, fmt.Errorf("drive options: %w", err)
@@ -18,3 +26,39 @@ func NewDrive(ctx context.Context, email string) (*drive.Service, error) {
 		return svc, nil
 	}
 }
+
+// NewDriveHTTPClient returns the authorized client used by NewDrive, for
+// requests the generated client does not expose (resumable sessions, ranges).
+func NewDriveHTTPClient(ctx context.Context, email string) (*http.Client, error) {
+	c, err := httpClientForAccount(ctx, googleauth.ServiceDrive, email)
+	if err != nil {
+		return nil, fmt.Errorf("drive client: %w", err)
+	}
+
+	return c, nil
+}
+
+// NewDriveLabels returns a Drive Labels API client. It asks only for
+// drive.labels.readonly, which is not part of the drive service scopes; grant
+// it with `auth add --extra-scopes`.
+func NewDriveLabels(ctx context.Context, email string) (*drivelabels.Service, error) {
+	if opts, err := optionsForAccountScopes(ctx, "drive", email, []string{scopeDriveLabelsRO}); err != nil {
+		return nil, fmt.Errorf("drive labels options: %w", err)
+	} else if svc, err := drivelabels.NewService(ctx, opts...); err != nil {
+		return nil, fmt.Errorf("create drive labels service: %w", err)
+	} else {
+		return svc, nil
+	}
+}
+
+// NewDriveActivity returns a Drive Activity API client. Like NewDriveLabels it
+// asks only for its own scope (drive.activity.readonly).
+func NewDriveActivity(ctx context.Context, email string) (*driveactivity.Service, error) {
+	if opts, err := optionsForAccountScopes(ctx, "drive", email, []string{scopeDriveActivityRO}); err != nil {
+		return nil, fmt.Errorf("drive activity options: %w", err)
+	} else if svc, err := driveactivity.NewService(ctx, opts...); err != nil {
+		return nil, fmt.Errorf("create drive activity service: %w", err)
+	} else {
+		return svc, nil
+	}
+}
@@ -11,4 +11,7 @@ const (
 	Max5xxRetries = 1
 	// ServerErrorRetryDelay is the delay before retrying on 5xx errors.
 	ServerErrorRetryDelay = 1 * time.Second
+	// MaxReplayBodyBytes caps how much of a non-seekable request body is
+	// buffered in memory so it can be replayed on retry.
+	MaxReplayBodyBytes = 32 << 20
 )
@@ -42,22 +42,34 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, &CircuitBreakerError{}
 	}
 
-	if err := ensureReplayableBody(req); err != nil {
+	replayable, err := ensureReplayableBody(req)
+	if err != nil {
 		return nil, err
 	}
+	if !replayable {
+		// Bodies too large to buffer are sent once; callers that need retries
+		// for big payloads (resumable uploads) send them in chunks instead.
+		resp, err := t.Base.RoundTrip(req)
+		if err != nil {
+			return nil, fmt.Errorf("round trip: %w", err)
+		}
+		t.record(resp.StatusCode)
+		return resp, nil
+	}
 
 	var resp *http.Response
-	var err error
 	retries429 := 0
 	retries5xx := 0
 
+	// Each attempt sends a fresh copy from GetBody, which the base transport
+	// closes; the original body is closed once all attempts are done.
+	if orig := req.Body; orig != nil && req.GetBody != nil {
+		defer func() { _ = orig.Close() }()
+	}
+
 	for {
 		// Reset body for retry
 		if req.GetBody != nil {
-			if req.Body != nil {
-				_ = req.Body.Close()
-			}
-
 			if body, getErr := req.GetBody(); getErr != nil {
 				return nil, fmt.Errorf("reset request body: %w", getErr)
 			} else {
@@ -132,6 +144,18 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 }
 
+func (t *RetryTransport) record(status int) {
+	if t.CircuitBreaker == nil {
+		return
+	}
+	switch {
+	case status < 400:
+		t.CircuitBreaker.RecordSuccess()
+	case status >= 500:
+		t.CircuitBreaker.RecordFailure()
+	}
+}
+
 func (t *RetryTransport) calculateBackoff(attempt int, resp *http.Response) time.Duration {
 	// Check Retry-After header
 	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
@@ -211,14 +235,45 @@ func (r *bytesReader) Read(p []byte) (n int, err error) {
 	return n, nil
 }
 
-func ensureReplayableBody(req *http.Request) error {
-	if req == nil || req.Body == nil || req.GetBody != nil {
-		return nil
+// ensureReplayableBody makes req.Body resettable for retries. Seekable bodies
+// rewind in place; other bodies are buffered up to MaxReplayBodyBytes. Larger
+// bodies are left streaming and reported as not replayable.
+func ensureReplayableBody(req *http.Request) (bool, error) {
+	if req == nil || req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true, nil
 	}
 
-	bodyBytes, err := io.ReadAll(req.Body)
+	if seeker, ok := req.Body.(io.ReadSeeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(seeker), nil
+			}
+			req.Body = struct {
+				io.ReadSeeker
+				io.Closer
+			}{seeker, req.Body}
+			return true, nil
+		}
+	}
+
+	if req.ContentLength > MaxReplayBodyBytes {
+		return false, nil
+	}
+
+	bodyBytes, err := io.ReadAll(io.LimitReader(req.Body, MaxReplayBodyBytes+1))
 	if err != nil {
-		return fmt.Errorf("read request body: %w", err)
+		return false, fmt.Errorf("read request body: %w", err)
+	}
+	if int64(len(bodyBytes)) > MaxReplayBodyBytes {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(newBytesReader(bodyBytes), req.Body), req.Body}
+		return false, nil
 	}
 	_ = req.Body.Close()
 
@@ -227,7 +282,7 @@ func ensureReplayableBody(req *http.Request) error {
 	}
 	req.Body = io.NopCloser(newBytesReader(bodyBytes))
 
-	return nil
+	return true, nil
 }
 
 func drainAndClose(body io.ReadCloser) {
@@ -283,7 +283,7 @@ func TestEnsureReplayableBodyMore(t *testing.T) {
 		t.Fatalf("new request: %v", err)
 	}
 
-	err = ensureReplayableBody(req)
+	_, err = ensureReplayableBody(req)
 	if err != nil {
 		t.Fatalf("ensureReplayableBody: %v", err)
 	}
@@ -383,3 +383,83 @@ func TestRetryTransportRoundTripError(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+type seekCounter struct {
+	*strings.Reader
+	seeks  int
+	closes int
+}
+
+func (s *seekCounter) Seek(offset int64, whence int) (int64, error) {
+	s.seeks++
+	return s.Reader.Seek(offset, whence)
+}
+
+func (s *seekCounter) Close() error {
+	s.closes++
+	return nil
+}
+
+func TestRetryTransportRewindsSeekableBody(t *testing.T) {
+	var bodies []string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			return newTestResponse(http.StatusServiceUnavailable, ""), nil
+		}
+		return newTestResponse(http.StatusOK, "ok"), nil
+	})
+	rt := &RetryTransport{Base: base, MaxRetries5xx: 1}
+
+	body := &seekCounter{Reader: strings.NewReader("chunk")}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, "http://example.com", body)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[0] != "chunk" || bodies[1] != "chunk" || body.seeks == 0 {
+		t.Fatalf("expected rewound replay, got %q (seeks=%d)", bodies, body.seeks)
+	}
+	if body.closes != 1 {
+		t.Fatalf("expected the original body to be closed once, got %d", body.closes)
+	}
+}
+
+func TestRetryTransportStreamsLargeBodyOnce(t *testing.T) {
+	calls := 0
+	var got int64
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		got, _ = io.Copy(io.Discard, req.Body)
+		return newTestResponse(http.StatusServiceUnavailable, ""), nil
+	})
+	rt := &RetryTransport{Base: base, MaxRetries5xx: 3}
+
+	size := int64(MaxReplayBodyBytes + 1)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com", io.NopCloser(io.LimitReader(zeroReader{}, size)))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if calls != 1 || got != size || req.GetBody != nil {
+		t.Fatalf("expected a single streamed attempt, got calls=%d bytes=%d", calls, got)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -407,7 +407,7 @@ func TestEnsureReplayableBody(t *testing.T) {
 		t.Fatalf("expected nil GetBody")
 	}
 
-	if err := ensureReplayableBody(req); err != nil {
+	if _, err := ensureReplayableBody(req); err != nil {
 		t.Fatalf("ensureReplayableBody: %v", err)
 	}
 
@@ -1,6 +1,9 @@
 package googleauth
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestParseService(t *testing.T) {
 	tests := []struct {
@@ -345,6 +348,12 @@ func TestScopesForManageWithOptions_DriveScopeFile(t *testing.T) {
 		t.Fatalf("unexpected drive in %v", scopes)
 	}
 
+	for _, s := range scopes {
+		if strings.HasPrefix(s, "https://www.googleapis.com/auth/drive.") && s != "https://www.googleapis.com/auth/drive.file" {
+			t.Fatalf("unexpected drive scope %q in %v", s, scopes)
+		}
+	}
+
 	if !containsScope(scopes, "https://www.googleapis.com/auth/documents") {
 		t.Fatalf("missing documents scope in %v", scopes)
 	}
@@ -0,0 +1,575 @@
+package mailcrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/smallstep/pkcs7"
+)
+
+const maxNesting = 4
+
+var (
+	errMalformedMultipart = errors.New("malformed multipart body")
+	errNoDecryptionKey    = errors.New("no decryption key")
+	errNoVerificationKey  = errors.New("no OpenPGP key to verify the signature")
+	errUnrecognizedKey    = errors.New("key is neither OpenPGP nor PKCS#12")
+)
+
+// VerifyConfig holds the keys used to inspect a received message.
+type VerifyConfig struct {
+	// DecryptKey is an OpenPGP secret key or a PKCS#12 bundle.
+	DecryptKey []byte
+	Passphrase string
+	// TrustKeys are OpenPGP public keys or X.509 certificates. Certificates
+	// replace the system roots when checking S/MIME signer chains.
+	TrustKeys [][]byte
+}
+
+// Result describes the protection found on a message and its readable content.
+type Result struct {
+	Standard       string
+	Encrypted      bool
+	Decrypted      bool
+	Signed         bool
+	SignatureValid bool
+	Trusted        bool
+	Signer         string
+	Errors         []string
+
+	// Text and HTML hold the innermost readable bodies.
+	Text string
+	HTML string
+}
+
+type inspectKeys struct {
+	pgp       openpgp.EntityList
+	smimeKey  crypto.PrivateKey
+	smimeCert *x509.Certificate
+	roots     *x509.CertPool
+}
+
+// Inspect verifies signatures and decrypts raw, a complete RFC 822 message.
+// Problems with the message itself are reported in Result.Errors; an error is
+// returned only for unusable keys.
+func Inspect(raw []byte, cfg VerifyConfig) (*Result, error) {
+	keys, err := loadInspectKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	entity := toCRLF(raw)
+
+	for range maxNesting {
+		header, body, err := splitEntity(entity)
+		if err != nil {
+			res.Errors = append(res.Errors, err.Error())
+			break
+		}
+
+		mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+		protocol := strings.ToLower(params["protocol"])
+
+		var next []byte
+
+		switch {
+		case mediaType == "multipart/encrypted" && protocol == "application/pgp-encrypted":
+			res.Standard, res.Encrypted = StandardPGP, true
+			next = keys.pgpDecrypt(res, body, params["boundary"])
+		case isPKCS7Mime(mediaType) && strings.EqualFold(params["smime-type"], "signed-data"):
+			res.Standard, res.Signed = StandardSMIME, true
+			next = keys.smimeOpaque(res, header, body)
+		case isPKCS7Mime(mediaType):
+			res.Standard, res.Encrypted = StandardSMIME, true
+			next = keys.smimeDecrypt(res, header, body)
+		case mediaType == "multipart/signed" && protocol == "application/pgp-signature":
+			res.Standard, res.Signed = StandardPGP, true
+			next = keys.pgpVerify(res, body, params["boundary"])
+		case mediaType == "multipart/signed" && isPKCS7Signature(protocol):
+			res.Standard, res.Signed = StandardSMIME, true
+			next = keys.smimeVerify(res, body, params["boundary"])
+		}
+
+		if next == nil {
+			break
+		}
+
+		entity = next
+	}
+
+	res.Text, res.HTML = entityText(entity)
+
+	return res, nil
+}
+
+func loadInspectKeys(cfg VerifyConfig) (*inspectKeys, error) {
+	keys := &inspectKeys{}
+
+	if len(cfg.DecryptKey) > 0 {
+		if secret, err := readPGPKeyRing(cfg.DecryptKey); err == nil {
+			for _, e := range secret {
+				if err := unlockPGPEntity(e, cfg.Passphrase); err != nil {
+					return nil, err
+				}
+			}
+
+			keys.pgp = append(keys.pgp, secret...)
+		} else {
+			key, cert, _, p12Err := readPKCS12(cfg.DecryptKey, cfg.Passphrase)
+			if p12Err != nil {
+				return nil, fmt.Errorf("%w: %w", errUnrecognizedKey, p12Err)
+			}
+
+			keys.smimeKey, keys.smimeCert = key, cert
+		}
+	}
+
+	for _, data := range cfg.TrustKeys {
+		if public, err := readPGPKeyRing(data); err == nil {
+			keys.pgp = append(keys.pgp, public...)
+			continue
+		}
+
+		certs, err := readCertificates(data)
+		if err != nil {
+			return nil, fmt.Errorf("trust key: %w", err)
+		}
+
+		if keys.roots == nil {
+			keys.roots = x509.NewCertPool()
+		}
+
+		for _, cert := range certs {
+			keys.roots.AddCert(cert)
+		}
+	}
+
+	return keys, nil
+}
+
+func (k *inspectKeys) pgpDecrypt(res *Result, body []byte, boundary string) []byte {
+	parts, err := splitMultipart(body, boundary)
+	if err != nil || len(parts) < 2 {
+		res.Errors = append(res.Errors, errMalformedMultipart.Error())
+		return nil
+	}
+
+	_, payload, err := splitEntity(parts[1])
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return nil
+	}
+
+	if len(k.pgp.DecryptionKeys()) == 0 {
+		res.Errors = append(res.Errors, errNoDecryptionKey.Error())
+		return nil
+	}
+
+	block, err := armor.Decode(bytes.NewReader(payload))
+	if err != nil {
+		res.Errors = append(res.Errors, "pgp armor: "+err.Error())
+		return nil
+	}
+
+	md, err := openpgp.ReadMessage(block.Body, k.pgp, nil, pgpConfig())
+	if err != nil {
+		res.Errors = append(res.Errors, "pgp decrypt: "+err.Error())
+		return nil
+	}
+
+	plain, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		res.Errors = append(res.Errors, "pgp decrypt: "+err.Error())
+		return nil
+	}
+
+	res.Decrypted = true
+
+	if md.IsSigned {
+		res.Signed = true
+		var signer *openpgp.Entity
+		if md.SignedBy != nil {
+			signer = md.SignedBy.Entity
+		}
+
+		res.Signer = pgpSignerName(signer, md.SignedByKeyId)
+
+		if md.SignatureError != nil {
+			res.Errors = append(res.Errors, "pgp signature: "+md.SignatureError.Error())
+		} else if md.SignedBy != nil {
+			res.SignatureValid, res.Trusted = true, true
+		}
+	}
+
+	return toCRLF(plain)
+}
+
+func (k *inspectKeys) pgpVerify(res *Result, body []byte, boundary string) []byte {
+	parts, err := splitMultipart(body, boundary)
+	if err != nil || len(parts) < 2 {
+		res.Errors = append(res.Errors, errMalformedMultipart.Error())
+		return nil
+	}
+
+	_, sig, err := splitEntity(parts[1])
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return parts[0]
+	}
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(k.pgp, bytes.NewReader(parts[0]), bytes.NewReader(sig), pgpConfig())
+
+	switch {
+	case err == nil:
+		res.SignatureValid, res.Trusted = true, true
+		res.Signer = pgpSignerName(signer, 0)
+	case errors.Is(err, pgperrors.ErrUnknownIssuer):
+		res.Signer = pgpSignerName(nil, pgpIssuerKeyID(sig))
+		res.Errors = append(res.Errors, errNoVerificationKey.Error())
+	default:
+		res.Signer = pgpSignerName(signer, pgpIssuerKeyID(sig))
+		res.Errors = append(res.Errors, "pgp signature: "+err.Error())
+	}
+
+	return parts[0]
+}
+
+func (k *inspectKeys) smimeDecrypt(res *Result, header textproto.MIMEHeader, body []byte) []byte {
+	der, err := decodeTransfer(header, body)
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return nil
+	}
+
+	if k.smimeKey == nil {
+		res.Errors = append(res.Errors, errNoDecryptionKey.Error())
+		return nil
+	}
+
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		res.Errors = append(res.Errors, "smime: "+err.Error())
+		return nil
+	}
+
+	plain, err := p7.Decrypt(k.smimeCert, k.smimeKey)
+	if err != nil {
+		res.Errors = append(res.Errors, "smime decrypt: "+err.Error())
+		return nil
+	}
+
+	res.Decrypted = true
+
+	return toCRLF(plain)
+}
+
+func (k *inspectKeys) smimeVerify(res *Result, body []byte, boundary string) []byte {
+	parts, err := splitMultipart(body, boundary)
+	if err != nil || len(parts) < 2 {
+		res.Errors = append(res.Errors, errMalformedMultipart.Error())
+		return nil
+	}
+
+	header, sigBody, err := splitEntity(parts[1])
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return parts[0]
+	}
+
+	der, err := decodeTransfer(header, sigBody)
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return parts[0]
+	}
+
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		res.Errors = append(res.Errors, "smime: "+err.Error())
+		return parts[0]
+	}
+
+	p7.Content = parts[0]
+	k.checkPKCS7(res, p7)
+
+	return parts[0]
+}
+
+func (k *inspectKeys) smimeOpaque(res *Result, header textproto.MIMEHeader, body []byte) []byte {
+	der, err := decodeTransfer(header, body)
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return nil
+	}
+
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		res.Errors = append(res.Errors, "smime: "+err.Error())
+		return nil
+	}
+
+	k.checkPKCS7(res, p7)
+
+	return toCRLF(p7.Content)
+}
+
+func (k *inspectKeys) checkPKCS7(res *Result, p7 *pkcs7.PKCS7) {
+	if cert := p7.GetOnlySigner(); cert != nil {
+		res.Signer = certificateName(cert)
+	}
+
+	if err := p7.Verify(); err != nil {
+		res.Errors = append(res.Errors, "smime signature: "+err.Error())
+		return
+	}
+
+	res.SignatureValid = true
+
+	roots := k.roots
+	if roots == nil {
+		system, err := x509.SystemCertPool()
+		if err != nil {
+			res.Errors = append(res.Errors, "smime trust: "+err.Error())
+			return
+		}
+
+		roots = system
+	}
+
+	if err := p7.VerifyWithChain(roots); err != nil {
+		res.Errors = append(res.Errors, "smime trust: "+err.Error())
+		return
+	}
+
+	res.Trusted = true
+}
+
+func pgpIssuerKeyID(armored []byte) uint64 {
+	block, err := armor.Decode(bytes.NewReader(armored))
+	if err != nil {
+		return 0
+	}
+
+	pkt, err := packet.Read(block.Body)
+	if err != nil {
+		return 0
+	}
+
+	if sig, ok := pkt.(*packet.Signature); ok && sig.IssuerKeyId != nil {
+		return *sig.IssuerKeyId
+	}
+
+	return 0
+}
+
+func pgpSignerName(e *openpgp.Entity, keyID uint64) string {
+	if e != nil {
+		if id := e.PrimaryIdentity(); id != nil {
+			return id.Name
+		}
+
+		return fmt.Sprintf("%X", e.PrimaryKey.KeyId)
+	}
+
+	if keyID != 0 {
+		return fmt.Sprintf("key %016X", keyID)
+	}
+
+	return ""
+}
+
+func certificateName(cert *x509.Certificate) string {
+	name := cert.Subject.CommonName
+	if len(cert.EmailAddresses) > 0 {
+		if name == "" || name == cert.EmailAddresses[0] {
+			return cert.EmailAddresses[0]
+		}
+
+		return fmt.Sprintf("%s <%s>", name, cert.EmailAddresses[0])
+	}
+
+	return name
+}
+
+func isPKCS7Mime(mediaType string) bool {
+	return mediaType == "application/pkcs7-mime" || mediaType == "application/x-pkcs7-mime"
+}
+
+func isPKCS7Signature(protocol string) bool {
+	return protocol == "application/pkcs7-signature" || protocol == "application/x-pkcs7-signature"
+}
+
+// splitEntity separates the header block from the body of a MIME entity.
+func splitEntity(entity []byte) (textproto.MIMEHeader, []byte, error) {
+	if bytes.HasPrefix(entity, []byte("\r\n")) {
+		return textproto.MIMEHeader{}, entity[2:], nil
+	}
+
+	end := bytes.Index(entity, []byte("\r\n\r\n"))
+	if end < 0 {
+		end = len(entity)
+	}
+
+	headerBlock := append(append([]byte{}, entity[:min(end+2, len(entity))]...), '\r', '\n')
+
+	header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(headerBlock))).ReadMIMEHeader()
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse headers: %w", err)
+	}
+
+	if end+4 > len(entity) {
+		return header, nil, nil
+	}
+
+	return header, entity[end+4:], nil
+}
+
+// splitMultipart returns the raw bytes of each body part, excluding the CRLF
+// that precedes every delimiter (RFC 2046), which is what multipart/signed
+// signatures cover.
+func splitMultipart(body []byte, boundary string) ([][]byte, error) {
+	if boundary == "" {
+		return nil, errMalformedMultipart
+	}
+
+	data := append([]byte("\r\n"), body...)
+	sep := []byte("\r\n--" + boundary)
+
+	idx := bytes.Index(data, sep)
+	if idx < 0 {
+		return nil, errMalformedMultipart
+	}
+
+	var parts [][]byte
+
+	for {
+		rest := data[idx+len(sep):]
+		if bytes.HasPrefix(rest, []byte("--")) {
+			return parts, nil
+		}
+
+		eol := bytes.Index(rest, []byte("\r\n"))
+		if eol < 0 {
+			return nil, errMalformedMultipart
+		}
+
+		content := rest[eol+2:]
+
+		next := bytes.Index(content, sep)
+		if next < 0 {
+			return nil, errMalformedMultipart
+		}
+
+		parts = append(parts, content[:next])
+		data, idx = content, next
+	}
+}
+
+func decodeTransfer(header textproto.MIMEHeader, body []byte) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		clean := strings.Map(func(r rune) rune {
+			if r == '\r' || r == '\n' || r == ' ' || r == '\t' {
+				return -1
+			}
+
+			return r
+		}, string(body))
+
+		data, err := base64.StdEncoding.DecodeString(clean)
+		if err != nil {
+			return nil, fmt.Errorf("decode base64: %w", err)
+		}
+
+		return data, nil
+	case "quoted-printable":
+		data, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(body)))
+		if err != nil {
+			return nil, fmt.Errorf("decode quoted-printable: %w", err)
+		}
+
+		return data, nil
+	default:
+		return body, nil
+	}
+}
+
+// entityText returns the first text/plain and text/html bodies in entity.
+func entityText(entity []byte) (string, string) {
+	header, body, err := splitEntity(entity)
+	if err != nil {
+		return "", ""
+	}
+
+	var text, html string
+
+	walkEntity(header, body, &text, &html, 0)
+
+	return text, html
+}
+
+func walkEntity(header textproto.MIMEHeader, body []byte, text, html *string, depth int) {
+	if depth > maxNesting {
+		return
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				return
+			}
+
+			data, err := io.ReadAll(part)
+			if err != nil {
+				return
+			}
+
+			// The multipart reader already decodes quoted-printable parts;
+			// base64 is handled by the recursive call.
+			walkEntity(part.Header, data, text, html, depth+1)
+		}
+	}
+
+	if disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition")); disposition == "attachment" {
+		return
+	}
+
+	decoded, err := decodeTransfer(header, body)
+	if err != nil {
+		return
+	}
+
+	switch mediaType {
+	case "text/plain":
+		if *text == "" {
+			*text = strings.ReplaceAll(string(decoded), "\r\n", "\n")
+		}
+	case "text/html":
+		if *html == "" {
+			*html = strings.ReplaceAll(string(decoded), "\r\n", "\n")
+		}
+	}
+}
@@ -0,0 +1,547 @@
+// Package mailcrypt signs, encrypts, verifies and decrypts MIME messages using
+// OpenPGP (PGP/MIME, RFC 3156) or S/MIME (RFC 8551).
+package mailcrypt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/smallstep/pkcs7"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Supported standards.
+const (
+	StandardPGP   = "pgp"
+	StandardSMIME = "smime"
+)
+
+var (
+	errUnknownStandard      = errors.New("unknown standard (expected pgp or smime)")
+	errNothingToDo          = errors.New("neither sign nor encrypt requested")
+	errMissingSignKey       = errors.New("signing requires a signing key")
+	errNoRecipientKeys      = errors.New("encryption requires at least one recipient key")
+	errMissingRecipientKey  = errors.New("no encryption key for recipient")
+	errNoPGPSecretKey       = errors.New("no OpenPGP secret key found")
+	errPassphraseRequired   = errors.New("key is passphrase protected; provide a passphrase")
+	errNoCertificate        = errors.New("no X.509 certificate found")
+	errUnsupportedPKCS12Key = errors.New("unsupported PKCS#12 private key type")
+)
+
+// smimeEncryptMu guards pkcs7.ContentEncryptionAlgorithm, which the library
+// exposes as a package-level setting.
+var smimeEncryptMu sync.Mutex
+
+// Config describes how outgoing messages should be protected.
+type Config struct {
+	Standard string
+	Sign     bool
+	Encrypt  bool
+	// SignKey is an OpenPGP secret key (armored or binary) or a PKCS#12
+	// bundle, depending on Standard.
+	SignKey    []byte
+	Passphrase string
+	// RecipientKeys are OpenPGP public keys or X.509 certificates (PEM or
+	// DER) used for encryption.
+	RecipientKeys [][]byte
+	// Recipients lists every address the message goes to. When encrypting,
+	// each one must be covered by a recipient key.
+	Recipients []string
+}
+
+// Protector wraps MIME entities into signed and/or encrypted entities.
+type Protector struct {
+	standard string
+	sign     bool
+	encrypt  bool
+
+	pgpSigner     *openpgp.Entity
+	pgpRecipients openpgp.EntityList
+
+	smimeCert       *x509.Certificate
+	smimeKey        crypto.PrivateKey
+	smimeChain      []*x509.Certificate
+	smimeRecipients []*x509.Certificate
+}
+
+// New loads the keys described by cfg. The signer's own key is added to the
+// encryption recipients so the sent copy stays readable.
+func New(cfg Config) (*Protector, error) {
+	if !cfg.Sign && !cfg.Encrypt {
+		return nil, errNothingToDo
+	}
+
+	p := &Protector{standard: cfg.Standard, sign: cfg.Sign, encrypt: cfg.Encrypt}
+
+	if cfg.Sign && len(cfg.SignKey) == 0 {
+		return nil, errMissingSignKey
+	}
+
+	if cfg.Encrypt && len(cfg.RecipientKeys) == 0 {
+		return nil, errNoRecipientKeys
+	}
+
+	var err error
+
+	switch cfg.Standard {
+	case StandardPGP:
+		err = p.loadPGP(cfg)
+	case StandardSMIME:
+		err = p.loadSMIME(cfg)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownStandard, cfg.Standard)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Encrypt {
+		if missing := p.missingRecipients(cfg.Recipients); len(missing) > 0 {
+			return nil, fmt.Errorf("%w: %s", errMissingRecipientKey, strings.Join(missing, ", "))
+		}
+	}
+
+	return p, nil
+}
+
+func (p *Protector) loadPGP(cfg Config) error {
+	if len(cfg.SignKey) > 0 {
+		signer, err := readPGPSecretKey(cfg.SignKey, cfg.Passphrase)
+		if err != nil {
+			return err
+		}
+
+		p.pgpSigner = signer
+	}
+
+	if !cfg.Encrypt {
+		return nil
+	}
+
+	for _, data := range cfg.RecipientKeys {
+		keys, err := readPGPKeyRing(data)
+		if err != nil {
+			return err
+		}
+
+		p.pgpRecipients = append(p.pgpRecipients, keys...)
+	}
+
+	if p.pgpSigner != nil {
+		p.pgpRecipients = append(p.pgpRecipients, p.pgpSigner)
+	}
+
+	return nil
+}
+
+func (p *Protector) loadSMIME(cfg Config) error {
+	if len(cfg.SignKey) > 0 {
+		key, cert, chain, err := readPKCS12(cfg.SignKey, cfg.Passphrase)
+		if err != nil {
+			return err
+		}
+
+		p.smimeKey, p.smimeCert, p.smimeChain = key, cert, chain
+	}
+
+	if !cfg.Encrypt {
+		return nil
+	}
+
+	for _, data := range cfg.RecipientKeys {
+		certs, err := readCertificates(data)
+		if err != nil {
+			return err
+		}
+
+		p.smimeRecipients = append(p.smimeRecipients, certs...)
+	}
+
+	if p.smimeCert != nil {
+		p.smimeRecipients = append(p.smimeRecipients, p.smimeCert)
+	}
+
+	return nil
+}
+
+func (p *Protector) missingRecipients(recipients []string) []string {
+	covered := map[string]bool{}
+
+	for _, e := range p.pgpRecipients {
+		for _, id := range e.Identities {
+			if id.UserId != nil {
+				covered[strings.ToLower(id.UserId.Email)] = true
+			}
+		}
+	}
+
+	for _, cert := range p.smimeRecipients {
+		for _, email := range cert.EmailAddresses {
+			covered[strings.ToLower(email)] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	missing := []string{}
+
+	for _, r := range recipients {
+		addr := strings.ToLower(strings.TrimSpace(r))
+		if addr == "" || covered[addr] || seen[addr] {
+			continue
+		}
+
+		seen[addr] = true
+		missing = append(missing, addr)
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// Wrap signs and/or encrypts entity, a MIME entity made of content headers, a
+// blank line and the body, and returns the replacement entity.
+func (p *Protector) Wrap(entity []byte) ([]byte, error) {
+	out := entity
+
+	var err error
+
+	if p.sign {
+		if p.standard == StandardSMIME {
+			out, err = p.smimeSign(out)
+		} else {
+			out, err = p.pgpSign(out)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.encrypt {
+		if p.standard == StandardSMIME {
+			out, err = p.smimeEncrypt(out)
+		} else {
+			out, err = p.pgpEncrypt(out)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+func (p *Protector) pgpSign(entity []byte) ([]byte, error) {
+	signed := bytes.TrimSuffix(entity, []byte("\r\n"))
+
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, p.pgpSigner, bytes.NewReader(signed), pgpConfig()); err != nil {
+		return nil, fmt.Errorf("pgp sign: %w", err)
+	}
+
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Content-Type: multipart/signed; boundary=%q; micalg=pgp-sha256;\r\n\tprotocol=\"application/pgp-signature\"\r\n\r\n", boundary)
+	b.WriteString("This is an OpenPGP/MIME signed message (RFC 4880 and 3156)\r\n")
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.Write(signed)
+	fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+	b.WriteString("Content-Type: application/pgp-signature; name=\"signature.asc\"\r\n")
+	b.WriteString("Content-Description: OpenPGP digital signature\r\n")
+	b.WriteString("Content-Disposition: attachment; filename=\"signature.asc\"\r\n\r\n")
+	b.Write(toCRLF(sig.Bytes()))
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+
+	return b.Bytes(), nil
+}
+
+func (p *Protector) pgpEncrypt(entity []byte) ([]byte, error) {
+	var armored bytes.Buffer
+
+	aw, err := armor.Encode(&armored, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, fmt.Errorf("pgp armor: %w", err)
+	}
+
+	w, err := openpgp.Encrypt(aw, p.pgpRecipients, nil, &openpgp.FileHints{IsBinary: true}, pgpConfig())
+	if err != nil {
+		return nil, fmt.Errorf("pgp encrypt: %w", err)
+	}
+
+	if _, err := w.Write(entity); err != nil {
+		return nil, fmt.Errorf("pgp encrypt: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("pgp encrypt: %w", err)
+	}
+
+	if err := aw.Close(); err != nil {
+		return nil, fmt.Errorf("pgp armor: %w", err)
+	}
+
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Content-Type: multipart/encrypted; boundary=%q;\r\n\tprotocol=\"application/pgp-encrypted\"\r\n\r\n", boundary)
+	b.WriteString("This is an OpenPGP/MIME encrypted message (RFC 4880 and 3156)\r\n")
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: application/pgp-encrypted\r\n")
+	b.WriteString("Content-Description: PGP/MIME version identification\r\n\r\n")
+	b.WriteString("Version: 1\r\n\r\n")
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: application/octet-stream; name=\"encrypted.asc\"\r\n")
+	b.WriteString("Content-Description: OpenPGP encrypted message\r\n")
+	b.WriteString("Content-Disposition: inline; filename=\"encrypted.asc\"\r\n\r\n")
+	b.Write(toCRLF(armored.Bytes()))
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+
+	return b.Bytes(), nil
+}
+
+func (p *Protector) smimeSign(entity []byte) ([]byte, error) {
+	signed := bytes.TrimSuffix(entity, []byte("\r\n"))
+
+	sd, err := pkcs7.NewSignedData(signed)
+	if err != nil {
+		return nil, fmt.Errorf("smime sign: %w", err)
+	}
+
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+
+	if err := sd.AddSignerChain(p.smimeCert, p.smimeKey, p.smimeChain, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, fmt.Errorf("smime sign: %w", err)
+	}
+
+	sd.Detach()
+
+	der, err := sd.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("smime sign: %w", err)
+	}
+
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Content-Type: multipart/signed; boundary=%q;\r\n\tprotocol=\"application/pkcs7-signature\"; micalg=sha-256\r\n\r\n", boundary)
+	b.WriteString("This is a cryptographically signed message in MIME format.\r\n")
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.Write(signed)
+	fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+	b.WriteString("Content-Type: application/pkcs7-signature; name=\"smime.p7s\"\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	b.WriteString("Content-Disposition: attachment; filename=\"smime.p7s\"\r\n")
+	b.WriteString("Content-Description: S/MIME Cryptographic Signature\r\n\r\n")
+	b.WriteString(wrapBase64(der))
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+
+	return b.Bytes(), nil
+}
+
+func (p *Protector) smimeEncrypt(entity []byte) ([]byte, error) {
+	smimeEncryptMu.Lock()
+	pkcs7.ContentEncryptionAlgorithm = pkcs7.EncryptionAlgorithmAES256CBC
+	der, err := pkcs7.Encrypt(entity, p.smimeRecipients)
+	smimeEncryptMu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("smime encrypt: %w", err)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("Content-Type: application/pkcs7-mime; smime-type=enveloped-data;\r\n\tname=\"smime.p7m\"\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	b.WriteString("Content-Disposition: attachment; filename=\"smime.p7m\"\r\n")
+	b.WriteString("Content-Description: S/MIME Encrypted Message\r\n\r\n")
+	b.WriteString(wrapBase64(der))
+	b.WriteString("\r\n")
+
+	return b.Bytes(), nil
+}
+
+func pgpConfig() *packet.Config {
+	return &packet.Config{DefaultHash: crypto.SHA256}
+}
+
+// readPGPKeyRing parses armored or binary OpenPGP keys.
+func readPGPKeyRing(data []byte) (openpgp.EntityList, error) {
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("read OpenPGP key: %w", err)
+		}
+
+		return keys, nil
+	}
+
+	keys, err := openpgp.ReadKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("read OpenPGP key: %w", err)
+	}
+
+	return keys, nil
+}
+
+// readPGPSecretKey returns the first entity that carries a private key,
+// unlocked with passphrase when needed.
+func readPGPSecretKey(data []byte, passphrase string) (*openpgp.Entity, error) {
+	keys, err := readPGPKeyRing(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range keys {
+		if e.PrivateKey == nil {
+			continue
+		}
+
+		if err := unlockPGPEntity(e, passphrase); err != nil {
+			return nil, err
+		}
+
+		return e, nil
+	}
+
+	return nil, errNoPGPSecretKey
+}
+
+func unlockPGPEntity(e *openpgp.Entity, passphrase string) error {
+	locked := e.PrivateKey != nil && e.PrivateKey.Encrypted
+
+	for _, sub := range e.Subkeys {
+		if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+			locked = true
+		}
+	}
+
+	if !locked {
+		return nil
+	}
+
+	if passphrase == "" {
+		return errPassphraseRequired
+	}
+
+	if err := e.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+		return fmt.Errorf("unlock OpenPGP key: %w", err)
+	}
+
+	return nil
+}
+
+func readPKCS12(data []byte, password string) (crypto.PrivateKey, *x509.Certificate, []*x509.Certificate, error) {
+	key, cert, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("read PKCS#12: %w", err)
+	}
+
+	if _, ok := key.(crypto.Signer); !ok {
+		return nil, nil, nil, errUnsupportedPKCS12Key
+	}
+
+	return key, cert, chain, nil
+}
+
+// readCertificates parses PEM (one or more CERTIFICATE blocks) or DER.
+func readCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	rest := data
+
+	for {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse certificate: %w", err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) > 0 {
+		return certs, nil
+	}
+
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return nil, errNoCertificate
+	}
+
+	parsed, err := x509.ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	if len(parsed) == 0 {
+		return nil, errNoCertificate
+	}
+
+	return parsed, nil
+}
+
+func randomBoundary() (string, error) {
+	var b [18]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		return "", fmt.Errorf("boundary: %w", err)
+	}
+
+	return "gogcli_" + base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+func wrapBase64(data []byte) string {
+	const width = 76
+
+	s := base64.StdEncoding.EncodeToString(data)
+
+	var out strings.Builder
+
+	for len(s) > width {
+		out.WriteString(s[:width])
+		out.WriteString("\r\n")
+		s = s[width:]
+	}
+
+	out.WriteString(s)
+
+	return out.String()
+}
+
+func toCRLF(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+}
@@ -0,0 +1,261 @@
+package mailcrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const testEntity = "Content-Type: text/plain; charset=\"utf-8\"\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n\r\n" +
+	"Meet at noon =E2=80=94 bring the plan.\r\n"
+
+func testMessage(entity []byte) []byte {
+	return append([]byte("From: a@example.com\r\nTo: b@example.com\r\nSubject: hi\r\nMIME-Version: 1.0\r\n"), entity...)
+}
+
+func pgpKey(t *testing.T, name, email, passphrase string) ([]byte, []byte) {
+	t.Helper()
+
+	e, err := openpgp.NewEntity(name, "", email, nil)
+	if err != nil {
+		t.Fatalf("NewEntity: %v", err)
+	}
+
+	var pub bytes.Buffer
+
+	w, err := armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("armor: %v", err)
+	}
+
+	if err := e.Serialize(w); err != nil {
+		t.Fatalf("serialize: %v", err)
+	}
+
+	_ = w.Close()
+
+	if passphrase != "" {
+		if err := e.EncryptPrivateKeys([]byte(passphrase), nil); err != nil {
+			t.Fatalf("encrypt private: %v", err)
+		}
+	}
+
+	var sec bytes.Buffer
+
+	w, err = armor.Encode(&sec, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatalf("armor: %v", err)
+	}
+
+	if err := e.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatalf("serialize private: %v", err)
+	}
+
+	_ = w.Close()
+
+	return sec.Bytes(), pub.Bytes()
+}
+
+func smimeKey(t *testing.T, email string) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: email},
+		EmailAddresses:        []string{email},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+
+	p12, err := pkcs12.Modern.Encode(key, cert, nil, "secret")
+	if err != nil {
+		t.Fatalf("pkcs12: %v", err)
+	}
+
+	return p12, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestPGPSignAndEncryptRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	aliceSec, alicePub := pgpKey(t, "Alice", "a@example.com", "pw")
+	bobSec, bobPub := pgpKey(t, "Bob", "b@example.com", "")
+
+	signer, err := New(Config{Standard: StandardPGP, Sign: true, SignKey: aliceSec, Passphrase: "pw"})
+	if err != nil {
+		t.Fatalf("New sign: %v", err)
+	}
+
+	signed, err := signer.Wrap([]byte(testEntity))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	res, err := Inspect(testMessage(signed), VerifyConfig{TrustKeys: [][]byte{alicePub}})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+
+	if !res.Signed || !res.SignatureValid || res.Signer != "Alice <a@example.com>" || res.Text != "Meet at noon — bring the plan." {
+		t.Fatalf("unexpected signed result: %#v", res)
+	}
+
+	tampered := bytes.Replace(testMessage(signed), []byte("noon"), []byte("nine"), 1)
+
+	res, err = Inspect(tampered, VerifyConfig{TrustKeys: [][]byte{alicePub}})
+	if err != nil || res.SignatureValid || len(res.Errors) == 0 {
+		t.Fatalf("expected tampered signature to fail: %#v %v", res, err)
+	}
+
+	res, err = Inspect(testMessage(signed), VerifyConfig{})
+	if err != nil || res.SignatureValid || !strings.HasPrefix(res.Signer, "key ") {
+		t.Fatalf("expected unknown key result: %#v %v", res, err)
+	}
+
+	both, err := New(Config{
+		Standard:      StandardPGP,
+		Sign:          true,
+		Encrypt:       true,
+		SignKey:       aliceSec,
+		Passphrase:    "pw",
+		RecipientKeys: [][]byte{bobPub},
+		Recipients:    []string{"B@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("New sign+encrypt: %v", err)
+	}
+
+	wrapped, err := both.Wrap([]byte(testEntity))
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+
+	if bytes.Contains(wrapped, []byte("noon")) || !bytes.Contains(wrapped, []byte("multipart/encrypted")) {
+		t.Fatalf("expected encrypted entity:\n%s", wrapped)
+	}
+
+	res, err = Inspect(testMessage(wrapped), VerifyConfig{DecryptKey: bobSec, TrustKeys: [][]byte{alicePub}})
+	if err != nil {
+		t.Fatalf("Inspect encrypted: %v", err)
+	}
+
+	if !res.Encrypted || !res.Decrypted || !res.Signed || !res.SignatureValid || !strings.Contains(res.Text, "bring the plan") {
+		t.Fatalf("unexpected decrypted result: %#v", res)
+	}
+
+	// The sender can read their own sent copy.
+	res, err = Inspect(testMessage(wrapped), VerifyConfig{DecryptKey: aliceSec, Passphrase: "pw"})
+	if err != nil || !res.Decrypted {
+		t.Fatalf("expected sender to decrypt: %#v %v", res, err)
+	}
+}
+
+func TestSMIMESignAndEncryptRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	aliceP12, aliceCert := smimeKey(t, "a@example.com")
+	bobP12, bobCert := smimeKey(t, "b@example.com")
+
+	p, err := New(Config{
+		Standard:      StandardSMIME,
+		Sign:          true,
+		Encrypt:       true,
+		SignKey:       aliceP12,
+		Passphrase:    "secret",
+		RecipientKeys: [][]byte{bobCert},
+		Recipients:    []string{"b@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	wrapped, err := p.Wrap([]byte(testEntity))
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+
+	if !bytes.HasPrefix(wrapped, []byte("Content-Type: application/pkcs7-mime; smime-type=enveloped-data")) {
+		t.Fatalf("unexpected entity:\n%s", wrapped)
+	}
+
+	res, err := Inspect(testMessage(wrapped), VerifyConfig{DecryptKey: bobP12, Passphrase: "secret", TrustKeys: [][]byte{aliceCert}})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+
+	if !res.Decrypted || !res.Signed || !res.SignatureValid || !res.Trusted || res.Signer != "a@example.com" || !strings.Contains(res.Text, "bring the plan") {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+
+	res, err = Inspect(testMessage(wrapped), VerifyConfig{})
+	if err != nil || res.Decrypted || len(res.Errors) == 0 {
+		t.Fatalf("expected missing key error: %#v %v", res, err)
+	}
+}
+
+func TestNewRequiresKeysForEveryRecipient(t *testing.T) {
+	t.Parallel()
+
+	_, bobPub := pgpKey(t, "Bob", "b@example.com", "")
+
+	_, err := New(Config{
+		Standard:      StandardPGP,
+		Encrypt:       true,
+		RecipientKeys: [][]byte{bobPub},
+		Recipients:    []string{"b@example.com", "carol@example.com"},
+	})
+	if !errors.Is(err, errMissingRecipientKey) || !strings.Contains(err.Error(), "carol@example.com") {
+		t.Fatalf("expected missing recipient error, got %v", err)
+	}
+
+	if _, err := New(Config{Standard: StandardPGP, Sign: true}); !errors.Is(err, errMissingSignKey) {
+		t.Fatalf("expected missing sign key error, got %v", err)
+	}
+}
+
+func TestSplitMultipart(t *testing.T) {
+	t.Parallel()
+
+	body := []byte("preamble\r\n--b\r\nA: 1\r\n\r\none\r\n--b \r\n\r\ntwo\r\n\r\n--b--\r\nepilogue")
+
+	parts, err := splitMultipart(body, "b")
+	if err != nil {
+		t.Fatalf("splitMultipart: %v", err)
+	}
+
+	if len(parts) != 2 || string(parts[0]) != "A: 1\r\n\r\none" || string(parts[1]) != "\r\ntwo\r\n" {
+		t.Fatalf("unexpected parts: %q", parts)
+	}
+}
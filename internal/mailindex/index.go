@@ -0,0 +1,316 @@
+// Package mailindex implements a small, dependency-free full-text index of
+// Gmail messages that can be persisted to disk and searched offline.
+package mailindex
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const indexVersion = 1
+
+var errUnsupportedVersion = errors.New("unsupported index version")
+
+// Document is a single indexed message.
+type Document struct {
+	ID          string   `json:"id"`
+	ThreadID    string   `json:"thread_id,omitempty"`
+	From        string   `json:"from,omitempty"`
+	To          string   `json:"to,omitempty"`
+	Cc          string   `json:"cc,omitempty"`
+	Subject     string   `json:"subject,omitempty"`
+	DateMs      int64    `json:"date_ms,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	LabelIDs    []string `json:"label_ids,omitempty"`
+	Snippet     string   `json:"snippet,omitempty"`
+	Body        string   `json:"body,omitempty"`
+	Attachments []string `json:"attachments,omitempty"`
+	SizeBytes   int64    `json:"size_bytes,omitempty"`
+}
+
+// Index holds documents plus an in-memory inverted index over their
+// searchable text. Only documents and sync metadata are persisted; postings
+// are rebuilt on load.
+type Index struct {
+	Account   string               `json:"account,omitempty"`
+	Query     string               `json:"query,omitempty"`
+	HistoryID string               `json:"history_id,omitempty"`
+	UpdatedMs int64                `json:"updated_ms,omitempty"`
+	Docs      map[string]*Document `json:"docs"`
+
+	postings map[string]map[string]struct{}
+}
+
+type fileIndex struct {
+	Version int `json:"version"`
+	*Index
+}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{
+		Docs:     map[string]*Document{},
+		postings: map[string]map[string]struct{}{},
+	}
+}
+
+// Load reads a gzip-compressed index file. A missing file yields an empty
+// index and ok=false.
+func Load(path string) (*Index, bool, error) {
+	// #nosec G304 -- path is the user-selected index location
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return New(), false, nil
+		}
+
+		return nil, false, fmt.Errorf("open index: %w", err)
+	}
+	defer f.Close()
+
+	idx, err := Read(f)
+	if err != nil {
+		return nil, false, fmt.Errorf("read index %s: %w", path, err)
+	}
+
+	return idx, true, nil
+}
+
+// Read decodes a gzip-compressed index.
+func Read(r io.Reader) (*Index, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	defer zr.Close()
+
+	file := fileIndex{Index: New()}
+	if err := json.NewDecoder(zr).Decode(&file); err != nil {
+		return nil, fmt.Errorf("decode index: %w", err)
+	}
+
+	if file.Version != indexVersion {
+		return nil, fmt.Errorf("%w: %d", errUnsupportedVersion, file.Version)
+	}
+
+	idx := file.Index
+	if idx.Docs == nil {
+		idx.Docs = map[string]*Document{}
+	}
+
+	idx.reindex()
+
+	return idx, nil
+}
+
+// Save writes the index atomically as gzip-compressed JSON.
+func (idx *Index) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("ensure index dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".gmail-index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp index: %w", err)
+	}
+
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := idx.Write(tmp); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp index: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace index: %w", err)
+	}
+
+	return nil
+}
+
+// Write encodes the index as gzip-compressed JSON.
+func (idx *Index) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(fileIndex{Version: indexVersion, Index: idx}); err != nil {
+		_ = zw.Close()
+
+		return fmt.Errorf("encode index: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("gzip: %w", err)
+	}
+
+	return nil
+}
+
+// Len returns the number of indexed documents.
+func (idx *Index) Len() int {
+	return len(idx.Docs)
+}
+
+// Get returns the document with the given ID.
+func (idx *Index) Get(id string) (*Document, bool) {
+	doc, ok := idx.Docs[id]
+
+	return doc, ok
+}
+
+// Put adds or replaces a document.
+func (idx *Index) Put(doc *Document) {
+	if doc == nil || doc.ID == "" {
+		return
+	}
+
+	idx.Delete(doc.ID)
+	idx.Docs[doc.ID] = doc
+	idx.addPostings(doc)
+}
+
+// Delete removes a document. Missing IDs are ignored.
+func (idx *Index) Delete(id string) {
+	doc, ok := idx.Docs[id]
+	if !ok {
+		return
+	}
+
+	for term := range docTerms(doc) {
+		set, ok := idx.postings[term]
+		if !ok {
+			continue
+		}
+
+		delete(set, id)
+
+		if len(set) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+
+	delete(idx.Docs, id)
+}
+
+// SetLabels replaces a document's labels without re-tokenizing its text.
+func (idx *Index) SetLabels(id string, labelIDs, labels []string) bool {
+	doc, ok := idx.Docs[id]
+	if !ok {
+		return false
+	}
+
+	doc.LabelIDs = labelIDs
+	doc.Labels = labels
+
+	return true
+}
+
+// Reset drops all documents and the stored history cursor.
+func (idx *Index) Reset() {
+	idx.Docs = map[string]*Document{}
+	idx.postings = map[string]map[string]struct{}{}
+	idx.HistoryID = ""
+}
+
+func (idx *Index) reindex() {
+	idx.postings = map[string]map[string]struct{}{}
+
+	for id, doc := range idx.Docs {
+		if doc == nil {
+			delete(idx.Docs, id)
+
+			continue
+		}
+
+		doc.ID = id
+		idx.addPostings(doc)
+	}
+}
+
+func (idx *Index) addPostings(doc *Document) {
+	for term := range docTerms(doc) {
+		set, ok := idx.postings[term]
+		if !ok {
+			set = map[string]struct{}{}
+			idx.postings[term] = set
+		}
+
+		set[doc.ID] = struct{}{}
+	}
+}
+
+// lookup returns IDs of documents containing every term.
+func (idx *Index) lookup(terms []string) map[string]struct{} {
+	sets := make([]map[string]struct{}, 0, len(terms))
+
+	for _, term := range terms {
+		set := idx.postings[term]
+		if len(set) == 0 {
+			return map[string]struct{}{}
+		}
+
+		sets = append(sets, set)
+	}
+
+	if len(sets) == 0 {
+		return map[string]struct{}{}
+	}
+
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+
+	out := make(map[string]struct{}, len(sets[0]))
+
+	for id := range sets[0] {
+		inAll := true
+
+		for _, set := range sets[1:] {
+			if _, ok := set[id]; !ok {
+				inAll = false
+
+				break
+			}
+		}
+
+		if inAll {
+			out[id] = struct{}{}
+		}
+	}
+
+	return out
+}
+
+func docTerms(doc *Document) map[string]struct{} {
+	terms := map[string]struct{}{}
+
+	for _, text := range []string{doc.Subject, doc.From, doc.To, doc.Cc, doc.Body, strings.Join(doc.Attachments, " ")} {
+		for _, term := range Tokenize(text) {
+			terms[term] = struct{}{}
+		}
+	}
+
+	return terms
+}
+
+// Tokenize splits text into lowercase letter/digit terms.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func normalizeText(text string) string {
+	return strings.Join(Tokenize(text), " ")
+}
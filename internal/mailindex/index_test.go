@@ -0,0 +1,191 @@
+package mailindex
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testIndex() *Index {
+	idx := New()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	idx.Put(&Document{
+		ID:          "m1",
+		From:        "Billing <billing@vendor.example>",
+		To:          "finance@corp.example",
+		Subject:     "Invoice 2026-03 ready",
+		DateMs:      base.UnixMilli(),
+		Labels:      []string{"INBOX", "Finance/Invoices"},
+		LabelIDs:    []string{"INBOX", "Label_7", "UNREAD"},
+		Body:        "Your monthly invoice is attached. Total due: 120 EUR.",
+		Attachments: []string{"invoice-2026-03.pdf"},
+		SizeBytes:   250 * 1024,
+	})
+	idx.Put(&Document{
+		ID:       "m2",
+		From:     "alice@corp.example",
+		To:       "team@corp.example",
+		Cc:       "bob@corp.example",
+		Subject:  "Lunch plans",
+		DateMs:   base.Add(48 * time.Hour).UnixMilli(),
+		Labels:   []string{"INBOX"},
+		LabelIDs: []string{"INBOX", "STARRED"},
+		Body:     "Shall we try the new ramen place? The invoice can wait.",
+	})
+	idx.Put(&Document{
+		ID:       "m3",
+		From:     "noreply@news.example",
+		Subject:  "Weekly digest",
+		DateMs:   base.Add(-60 * 24 * time.Hour).UnixMilli(),
+		LabelIDs: []string{"CATEGORY_UPDATES"},
+		Body:     "Top stories this week.",
+	})
+
+	return idx
+}
+
+func searchIDs(t *testing.T, idx *Index, query string) []string {
+	t.Helper()
+
+	now := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	q, err := Parse(query, now)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", query, err)
+	}
+
+	var ids []string
+	for _, doc := range idx.Search(q, 0) {
+		ids = append(ids, doc.ID)
+	}
+
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex()
+	testCases := []struct {
+		query string
+		want  []string
+	}{
+		{query: "invoice", want: []string{"m2", "m1"}},
+		{query: "invoice -from:alice", want: []string{"m1"}},
+		{query: `"monthly invoice"`, want: []string{"m1"}},
+		{query: `subject:"lunch plans"`, want: []string{"m2"}},
+		{query: "from:billing has:attachment", want: []string{"m1"}},
+		{query: "filename:pdf", want: []string{"m1"}},
+		{query: "label:finance-invoices", want: []string{"m1"}},
+		{query: "in:inbox is:unread", want: []string{"m1"}},
+		{query: "is:starred", want: []string{"m2"}},
+		{query: "to:bob", want: []string{"m2"}},
+		{query: "ramen OR digest", want: []string{"m2", "m3"}},
+		{query: "newer_than:30d", want: []string{"m2", "m1"}},
+		{query: "older_than:30d", want: []string{"m3"}},
+		{query: "after:2026/03/02", want: []string{"m2"}},
+		{query: "before:2026-03-02 in:inbox", want: []string{"m1"}},
+		{query: "larger:100k", want: []string{"m1"}},
+		{query: "in:anywhere stories", want: []string{"m3"}},
+		{query: "missingword", want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			t.Parallel()
+
+			got := searchIDs(t, idx, tc.query)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Search(%q)=%v want %v", tc.query, got, tc.want)
+			}
+
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("Search(%q)=%v want %v", tc.query, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	for _, query := range []string{"", `"open`, "OR foo", "foo OR", "is:muted", "has:drive", "after:soon", "newer_than:3x", "larger:big"} {
+		if _, err := Parse(query, now); err == nil {
+			t.Fatalf("expected error for %q", query)
+		}
+	}
+
+	if _, err := Parse("has:youtube", now); !errors.Is(err, ErrUnsupportedOperator) {
+		t.Fatalf("expected ErrUnsupportedOperator, got %v", err)
+	}
+}
+
+func TestIndex_PutReplacesPostings(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex()
+	idx.Put(&Document{ID: "m2", Subject: "Dinner plans"})
+
+	if got := searchIDs(t, idx, "lunch"); len(got) != 0 {
+		t.Fatalf("expected stale postings to be removed, got %v", got)
+	}
+
+	if got := searchIDs(t, idx, "dinner"); len(got) != 1 || got[0] != "m2" {
+		t.Fatalf("expected replacement doc, got %v", got)
+	}
+
+	idx.Delete("m2")
+
+	if _, ok := idx.postings["dinner"]; ok {
+		t.Fatalf("expected empty postings to be dropped")
+	}
+
+	if !idx.SetLabels("m1", []string{"STARRED"}, nil) || idx.SetLabels("missing", nil, nil) {
+		t.Fatalf("unexpected SetLabels result")
+	}
+
+	if got := searchIDs(t, idx, "is:starred"); len(got) != 1 || got[0] != "m1" {
+		t.Fatalf("expected label update to apply, got %v", got)
+	}
+}
+
+func TestIndex_SaveLoadRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "index.json.gz")
+	idx := testIndex()
+	idx.Account = "a@b.com"
+	idx.HistoryID = "12345"
+
+	if err := idx.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, ok, err := Load(path)
+	if err != nil || !ok {
+		t.Fatalf("Load: ok=%v err=%v", ok, err)
+	}
+
+	if loaded.Len() != 3 || loaded.HistoryID != "12345" || loaded.Account != "a@b.com" {
+		t.Fatalf("unexpected loaded index: %+v", loaded)
+	}
+
+	if got := searchIDs(t, loaded, "ramen"); len(got) != 1 || got[0] != "m2" {
+		t.Fatalf("expected postings to be rebuilt, got %v", got)
+	}
+
+	missing, ok, err := Load(filepath.Join(t.TempDir(), "missing.gz"))
+	if err != nil || ok || missing.Len() != 0 {
+		t.Fatalf("expected empty index for missing file, ok=%v err=%v", ok, err)
+	}
+
+	if _, err := Read(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Fatalf("expected error for corrupt index")
+	}
+}
@@ -0,0 +1,454 @@
+package mailindex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrEmptyQuery          = errors.New("empty query")
+	ErrInvalidQuery        = errors.New("invalid query")
+	ErrUnsupportedOperator = errors.New("unsupported search operator value")
+)
+
+// Query is a parsed Gmail-style search. Clauses are ANDed together; the
+// alternatives inside a clause are ORed.
+type Query struct {
+	clauses [][]term
+}
+
+type term struct {
+	op     string
+	value  string
+	negate bool
+	tokens []string
+	at     time.Time
+	size   int64
+}
+
+// supportedOps lists the Gmail operators understood by the local index.
+// Unknown "word:value" tokens are treated as plain text, like Gmail does.
+var supportedOps = map[string]struct{}{
+	"from": {}, "to": {}, "cc": {}, "subject": {}, "label": {}, "in": {},
+	"is": {}, "has": {}, "filename": {}, "after": {}, "before": {},
+	"newer_than": {}, "older_than": {}, "larger": {}, "smaller": {},
+}
+
+var systemLabels = map[string]string{
+	"inbox":     "INBOX",
+	"sent":      "SENT",
+	"trash":     "TRASH",
+	"spam":      "SPAM",
+	"draft":     "DRAFT",
+	"drafts":    "DRAFT",
+	"starred":   "STARRED",
+	"important": "IMPORTANT",
+	"unread":    "UNREAD",
+	"chat":      "CHAT",
+}
+
+// Parse parses the supported subset of Gmail search syntax:
+// bare words, "quoted phrases", -negation, OR, and the operators
+// from:, to:, cc:, subject:, label:, in:, is:, has:attachment, filename:,
+// after:, before:, newer_than:, older_than:, larger:, and smaller:.
+func Parse(raw string, now time.Time) (*Query, error) {
+	tokens, err := splitQuery(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	q := &Query{}
+	orPending := false
+
+	for _, tok := range tokens {
+		if tok == "OR" || tok == "|" {
+			if len(q.clauses) == 0 {
+				return nil, fmt.Errorf("%w: OR without left operand", ErrInvalidQuery)
+			}
+
+			orPending = true
+
+			continue
+		}
+
+		t, err := parseTerm(tok, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if orPending {
+			last := len(q.clauses) - 1
+			q.clauses[last] = append(q.clauses[last], t)
+			orPending = false
+
+			continue
+		}
+
+		q.clauses = append(q.clauses, []term{t})
+	}
+
+	if orPending {
+		return nil, fmt.Errorf("%w: OR without right operand", ErrInvalidQuery)
+	}
+
+	return q, nil
+}
+
+// Search returns matching documents, newest first. max <= 0 means no limit.
+func (idx *Index) Search(q *Query, maxResults int) []*Document {
+	candidates := idx.candidates(q)
+	out := make([]*Document, 0, len(candidates))
+
+	for id := range candidates {
+		doc := idx.Docs[id]
+		if doc != nil && q.matches(idx, doc) {
+			out = append(out, doc)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].DateMs != out[j].DateMs {
+			return out[i].DateMs > out[j].DateMs
+		}
+
+		return out[i].ID < out[j].ID
+	})
+
+	if maxResults > 0 && len(out) > maxResults {
+		out = out[:maxResults]
+	}
+
+	return out
+}
+
+// candidates narrows the search using postings of required text terms.
+func (idx *Index) candidates(q *Query) map[string]struct{} {
+	var required []string
+
+	for _, clause := range q.clauses {
+		if len(clause) != 1 || clause[0].negate {
+			continue
+		}
+
+		if t := clause[0]; t.op == "" || t.op == "phrase" {
+			required = append(required, t.tokens...)
+		}
+	}
+
+	if len(required) > 0 {
+		return idx.lookup(required)
+	}
+
+	all := make(map[string]struct{}, len(idx.Docs))
+	for id := range idx.Docs {
+		all[id] = struct{}{}
+	}
+
+	return all
+}
+
+func (q *Query) matches(idx *Index, doc *Document) bool {
+	for _, clause := range q.clauses {
+		matched := false
+
+		for _, t := range clause {
+			if t.matches(idx, doc) != t.negate {
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t term) matches(idx *Index, doc *Document) bool {
+	switch t.op {
+	case "":
+		return idx.hasTerms(doc.ID, t.tokens)
+	case "phrase":
+		if !idx.hasTerms(doc.ID, t.tokens) {
+			return false
+		}
+
+		needle := strings.Join(t.tokens, " ")
+		for _, field := range []string{doc.Subject, doc.Body, doc.From, doc.To, doc.Cc} {
+			if strings.Contains(normalizeText(field), needle) {
+				return true
+			}
+		}
+
+		return false
+	case "from":
+		return containsFold(doc.From, t.value)
+	case "to":
+		return containsFold(doc.To, t.value) || containsFold(doc.Cc, t.value)
+	case "cc":
+		return containsFold(doc.Cc, t.value)
+	case "subject":
+		return containsFold(doc.Subject, t.value)
+	case "label", "in":
+		return hasLabel(doc, t.value)
+	case "is":
+		if t.value == "read" {
+			return !hasLabel(doc, "UNREAD")
+		}
+
+		return hasLabel(doc, t.value)
+	case "has":
+		return len(doc.Attachments) > 0
+	case "filename":
+		for _, name := range doc.Attachments {
+			if containsFold(name, t.value) {
+				return true
+			}
+		}
+
+		return false
+	case "after", "newer_than":
+		return doc.DateMs >= t.at.UnixMilli()
+	case "before", "older_than":
+		return doc.DateMs > 0 && doc.DateMs < t.at.UnixMilli()
+	case "any":
+		return true
+	case "larger":
+		return doc.SizeBytes > t.size
+	case "smaller":
+		return doc.SizeBytes < t.size
+	default:
+		return false
+	}
+}
+
+func (idx *Index) hasTerms(id string, tokens []string) bool {
+	for _, tok := range tokens {
+		if _, ok := idx.postings[tok][id]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseTerm(tok string, now time.Time) (term, error) {
+	t := term{}
+	if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+		t.negate = true
+		tok = tok[1:]
+	}
+
+	if strings.HasPrefix(tok, `"`) {
+		t.op = "phrase"
+		t.value = strings.Trim(tok, `"`)
+		t.tokens = Tokenize(t.value)
+
+		return t, nil
+	}
+
+	op, value, ok := strings.Cut(tok, ":")
+	op = strings.ToLower(op)
+
+	if _, known := supportedOps[op]; !ok || !known || value == "" {
+		t.value = tok
+		t.tokens = Tokenize(tok)
+
+		return t, nil
+	}
+
+	t.op = op
+	t.value = strings.Trim(value, `"`)
+
+	return finishOperatorTerm(t, now)
+}
+
+func finishOperatorTerm(t term, now time.Time) (term, error) {
+	lower := strings.ToLower(t.value)
+
+	switch t.op {
+	case "in":
+		if lower == "anywhere" {
+			t.op = "any"
+
+			return t, nil
+		}
+
+		if id, ok := systemLabels[lower]; ok {
+			t.value = id
+		}
+	case "is":
+		switch lower {
+		case "unread", "starred", "important":
+			t.value = systemLabels[lower]
+		case "read":
+			t.value = "read"
+		default:
+			return t, fmt.Errorf("%w: is:%s", ErrUnsupportedOperator, t.value)
+		}
+	case "has":
+		if lower != "attachment" {
+			return t, fmt.Errorf("%w: has:%s", ErrUnsupportedOperator, t.value)
+		}
+	case "after", "before":
+		at, err := parseQueryDate(t.value, now.Location())
+		if err != nil {
+			return t, err
+		}
+
+		t.at = at
+	case "newer_than", "older_than":
+		at, err := parseRelativeAge(lower, now)
+		if err != nil {
+			return t, err
+		}
+
+		t.at = at
+	case "larger", "smaller":
+		size, err := parseQuerySize(lower)
+		if err != nil {
+			return t, err
+		}
+
+		t.size = size
+	}
+
+	return t, nil
+}
+
+func parseQueryDate(value string, loc *time.Location) (time.Time, error) {
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+
+	normalized := strings.ReplaceAll(value, "-", "/")
+	for _, layout := range []string{"2006/1/2", "2006/01/02"} {
+		if t, err := time.ParseInLocation(layout, normalized, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: date %q (use YYYY/MM/DD or epoch seconds)", ErrInvalidQuery, value)
+}
+
+func parseRelativeAge(value string, now time.Time) (time.Time, error) {
+	if len(value) < 2 {
+		return time.Time{}, fmt.Errorf("%w: age %q", ErrInvalidQuery, value)
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, fmt.Errorf("%w: age %q", ErrInvalidQuery, value)
+	}
+
+	switch value[len(value)-1] {
+	case 'h':
+		return now.Add(-time.Duration(n) * time.Hour), nil
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	case 'y':
+		return now.AddDate(-n, 0, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: age %q (use d, m, or y)", ErrInvalidQuery, value)
+	}
+}
+
+func parseQuerySize(value string) (int64, error) {
+	mult := int64(1)
+
+	switch {
+	case strings.HasSuffix(value, "k"):
+		mult = 1024
+		value = strings.TrimSuffix(value, "k")
+	case strings.HasSuffix(value, "m"):
+		mult = 1024 * 1024
+		value = strings.TrimSuffix(value, "m")
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: size %q", ErrInvalidQuery, value)
+	}
+
+	return n * mult, nil
+}
+
+// splitQuery splits on whitespace while keeping quoted sections together,
+// including quotes that follow an operator (subject:"hello world").
+func splitQuery(raw string) ([]string, error) {
+	var (
+		out     []string
+		cur     strings.Builder
+		inQuote bool
+	)
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuote:
+			if cur.Len() > 0 {
+				out = append(out, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+
+	if inQuote {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+	}
+
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+
+	return out, nil
+}
+
+func containsFold(haystack, needle string) bool {
+	return strings.Contains(strings.ToLower(haystack), strings.ToLower(needle))
+}
+
+func hasLabel(doc *Document, want string) bool {
+	want = normalizeLabel(want)
+
+	for _, id := range doc.LabelIDs {
+		if normalizeLabel(id) == want {
+			return true
+		}
+	}
+
+	for _, name := range doc.Labels {
+		if normalizeLabel(name) == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeLabel mirrors Gmail's label: matching, where spaces and slashes
+// in label names may be written as dashes.
+func normalizeLabel(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+
+	return strings.NewReplacer(" ", "-", "/", "-").Replace(label)
+}
@@ -0,0 +1,302 @@
+// Package pdftext pulls plain text out of PDF content streams.
+//
+// It is a best-effort extractor for searching, not a renderer: it inflates
+// Flate-compressed streams, reads the text-showing operators (Tj, TJ, ', ")
+// and breaks lines on text positioning. Text drawn with CID fonts that need a
+// ToUnicode map, and scanned PDFs without a text layer, come back empty or
+// garbled.
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxStreamBytes caps a single inflated stream so a hostile PDF cannot blow up
+// memory.
+const maxStreamBytes = 64 << 20
+
+var (
+	streamKeyword    = []byte("stream")
+	endstreamKeyword = []byte("endstream")
+	flateFilter      = []byte("/FlateDecode")
+	filterKey        = []byte("/Filter")
+)
+
+// Extract returns the text of every content stream in data, in file order.
+func Extract(data []byte) string {
+	var out strings.Builder
+	pos := 0
+	for {
+		idx := bytes.Index(data[pos:], streamKeyword)
+		if idx < 0 {
+			break
+		}
+		start := pos + idx
+		// Skip "endstream" matches.
+		if start >= 3 && bytes.HasPrefix(data[start-3:], endstreamKeyword) {
+			pos = start + len(streamKeyword)
+			continue
+		}
+		bodyStart := start + len(streamKeyword)
+		if bodyStart < len(data) && data[bodyStart] == '\r' {
+			bodyStart++
+		}
+		if bodyStart < len(data) && data[bodyStart] == '\n' {
+			bodyStart++
+		}
+		end := bytes.Index(data[bodyStart:], endstreamKeyword)
+		if end < 0 {
+			break
+		}
+		bodyEnd := bodyStart + end
+		dict := streamDict(data, start)
+		pos = bodyEnd + len(endstreamKeyword)
+
+		body := data[bodyStart:bodyEnd]
+		switch {
+		case bytes.Contains(dict, flateFilter):
+			inflated, err := inflate(body)
+			if err != nil {
+				continue
+			}
+			body = inflated
+		case bytes.Contains(dict, filterKey):
+			// Images and other encodings carry no text.
+			continue
+		}
+		if text := contentText(body); text != "" {
+			out.WriteString(text)
+			if !strings.HasSuffix(text, "\n") {
+				out.WriteByte('\n')
+			}
+		}
+	}
+	return out.String()
+}
+
+// streamDict returns the object dictionary that precedes the stream keyword
+// at offset start.
+func streamDict(data []byte, start int) []byte {
+	from := bytes.LastIndex(data[:start], []byte(" obj"))
+	if from < 0 {
+		from = max(0, start-1024)
+	}
+	return data[from:start]
+}
+
+func inflate(body []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, maxStreamBytes))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+// contentText interprets the text operators of one content stream.
+func contentText(content []byte) string {
+	if !bytes.Contains(content, []byte("BT")) {
+		return ""
+	}
+	var (
+		out      strings.Builder
+		operands []string
+		inText   bool
+	)
+	newline := func() {
+		s := out.String()
+		if s != "" && !strings.HasSuffix(s, "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, next := readLiteral(content, i)
+			operands = append(operands, s)
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			s, next := readHex(content, i)
+			operands = append(operands, s)
+			i = next
+		case c == '/':
+			// Names (fonts, resources) are operands we never need.
+			i++
+			for i < len(content) && !isSpace(content[i]) && !isDelimiter(content[i]) {
+				i++
+			}
+		case c == '[':
+			i++
+		case c == ']':
+			i++
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(content) && (content[j] == '.' || (content[j] >= '0' && content[j] <= '9')) {
+				j++
+			}
+			// Large negative TJ offsets stand for word gaps.
+			if n, err := strconv.ParseFloat(string(content[i:j]), 64); err == nil && n < -200 && inText {
+				operands = append(operands, " ")
+			}
+			i = j
+		default:
+			j := i
+			for j < len(content) && !isSpace(content[j]) && !isDelimiter(content[j]) {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			op := string(content[i:j])
+			i = j
+			switch op {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				newline()
+			case "Tj", "TJ":
+				for _, s := range operands {
+					out.WriteString(s)
+				}
+			case "'", "\"":
+				newline()
+				if len(operands) > 0 {
+					out.WriteString(operands[len(operands)-1])
+				}
+			case "T*", "Td", "TD", "Tm":
+				newline()
+			}
+			operands = operands[:0]
+		}
+	}
+	return strings.TrimLeft(out.String(), "\n")
+}
+
+func readLiteral(content []byte, i int) (string, int) {
+	var buf []byte
+	depth := 0
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			e := content[i]
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+			default:
+				if e >= '0' && e <= '7' {
+					j := i
+					for j < len(content) && j < i+3 && content[j] >= '0' && content[j] <= '7' {
+						j++
+					}
+					n, _ := strconv.ParseUint(string(content[i:j]), 8, 8)
+					buf = append(buf, byte(n))
+					i = j - 1
+				} else {
+					buf = append(buf, e)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				buf = append(buf, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return decodeText(buf), i + 1
+			}
+			buf = append(buf, c)
+		default:
+			buf = append(buf, c)
+		}
+		i++
+	}
+	return decodeText(buf), i
+}
+
+func readHex(content []byte, i int) (string, int) {
+	end := bytes.IndexByte(content[i:], '>')
+	if end < 0 {
+		return "", len(content)
+	}
+	var digits []byte
+	for _, c := range content[i+1 : i+end] {
+		if !isSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	buf := make([]byte, 0, len(digits)/2)
+	for j := 0; j+1 < len(digits); j += 2 {
+		n, err := strconv.ParseUint(string(digits[j:j+2]), 16, 8)
+		if err != nil {
+			return "", i + end + 1
+		}
+		buf = append(buf, byte(n))
+	}
+	return decodeText(buf), i + end + 1
+}
+
+// decodeText handles UTF-16BE strings (with a BOM) and treats everything else
+// as Latin-1, dropping control bytes.
+func decodeText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		units := make([]uint16, 0, len(b)/2)
+		for j := 2; j+1 < len(b); j += 2 {
+			units = append(units, uint16(b[j])<<8|uint16(b[j+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	var sb strings.Builder
+	for _, c := range b {
+		if c < 0x20 && c != '\n' && c != '\t' {
+			continue
+		}
+		sb.WriteRune(rune(c))
+	}
+	return sb.String()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
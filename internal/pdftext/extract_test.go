@@ -0,0 +1,54 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+)
+
+func pdfWithStreams(streams ...[]byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	for i, s := range streams {
+		fmt.Fprintf(&b, "%d 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", i+1, len(s), s)
+	}
+	b.WriteString("%%EOF\n")
+	return b.Bytes()
+}
+
+func TestExtract_PlainAndFlate(t *testing.T) {
+	t.Parallel()
+
+	page1 := []byte("BT /F1 12 Tf 72 720 Td (Hello \\(PDF\\) world) Tj T* [(Sec) -250 (ond)] TJ ET")
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	_, _ = w.Write([]byte("BT /F1 12 Tf 0 -14 Td <54686972642070616765> Tj (\\101\\102C) ' ET"))
+	_ = w.Close()
+
+	data := pdfWithStreams(page1, nil)
+	// Swap the second stream for a Flate-compressed one.
+	data = bytes.Replace(data, []byte("2 0 obj\n<< /Length 0 >>\nstream\n\nendstream"),
+		append(append([]byte("2 0 obj\n<< /Length 1 /Filter /FlateDecode >>\nstream\n"), z.Bytes()...), []byte("\nendstream")...), 1)
+
+	got := Extract(data)
+	want := "Hello (PDF) world\nSec ond\nThird page\nABC\n"
+	if got != want {
+		t.Fatalf("Extract:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestExtract_SkipsImagesAndUTF16(t *testing.T) {
+	t.Parallel()
+
+	image := []byte("\xff\xd8\xff binary (not text) Tj")
+	data := pdfWithStreams([]byte("BT <FEFF00E9007400E9> Tj ET"))
+	data = append(data, []byte("9 0 obj\n<< /Filter /DCTDecode >>\nstream\n"+string(image)+"\nendstream\nendobj\n")...)
+
+	if got := Extract(data); got != "été\n" {
+		t.Fatalf("Extract: got %q", got)
+	}
+	if got := Extract([]byte("no streams here")); got != "" {
+		t.Fatalf("expected empty text, got %q", got)
+	}
+}
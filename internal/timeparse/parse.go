@@ -3,6 +3,7 @@ package timeparse
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -125,8 +126,8 @@ func ParseRangeExpr(expr string, now time.Time, loc *time.Location) (time.Time,
 }
 
 // ParseSince parses --since values for tracking style queries.
-// Supported: duration (24h), date (YYYY-MM-DD), RFC3339(+nano), and
-// local datetime layouts.
+// Supported: duration (24h), day/week duration (7d, 2w), date (YYYY-MM-DD),
+// RFC3339(+nano), and local datetime layouts.
 func ParseSince(value string, now time.Time, loc *time.Location) (SinceResult, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -137,6 +138,10 @@ func ParseSince(value string, now time.Time, loc *time.Location) (SinceResult, e
 		return SinceResult{Time: now.Add(-d).UTC()}, nil
 	}
 
+	if d, ok := ParseDayDuration(value); ok {
+		return SinceResult{Time: now.Add(-d).UTC()}, nil
+	}
+
 	if t, err := ParseDate(value); err == nil {
 		return SinceResult{Time: t.UTC()}, nil
 	}
@@ -157,6 +162,30 @@ func ParseSince(value string, now time.Time, loc *time.Location) (SinceResult, e
 	return SinceResult{}, fmt.Errorf("%w: %q", ErrInvalidSince, value)
 }
 
+// ParseDayDuration parses whole-day durations like "7d" or "2w", which
+// time.ParseDuration does not accept.
+func ParseDayDuration(value string) (time.Duration, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return 0, false
+	}
+
+	unit := value[len(value)-1]
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	switch unit {
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
 var weekdayNames = map[string]time.Weekday{
 	"sunday":    time.Sunday,
 	"sun":       time.Sunday,
@@ -178,11 +178,14 @@ func TestParseSince(t *testing.T) {
 		wantNano bool
 	}{
 		{name: "duration", value: "24h", want: now.Add(-24 * time.Hour).UTC()},
+		{name: "days", value: "90d", want: now.Add(-90 * 24 * time.Hour).UTC()},
+		{name: "weeks", value: "2w", want: now.Add(-14 * 24 * time.Hour).UTC()},
 		{name: "date", value: "2026-02-01", want: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
 		{name: "rfc3339", value: "2026-02-01T10:20:30Z", want: time.Date(2026, 2, 1, 10, 20, 30, 0, time.UTC)},
 		{name: "rfc3339nano", value: "2026-02-01T10:20:30.123456789Z", want: time.Date(2026, 2, 1, 10, 20, 30, 123456789, time.UTC), wantNano: true},
 		{name: "local datetime", value: "2026-02-01 10:20", want: time.Date(2026, 2, 1, 18, 20, 0, 0, time.UTC)},
 		{name: "invalid", value: "nope", wantErr: true},
+		{name: "invalid days", value: "-3d", wantErr: true},
 	}
 
 	for _, tc := range testCases {